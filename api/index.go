@@ -1,12 +1,15 @@
 package handler
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"os"
 
 	"github.com/cloudinary/cloudinary-go/v2"
 	"github.com/federus1105/koda-b4-backend/internals/configs"
+	"github.com/federus1105/koda-b4-backend/internals/models"
+	"github.com/federus1105/koda-b4-backend/internals/pkg/libs"
 	"github.com/federus1105/koda-b4-backend/internals/routes"
 	"github.com/gin-gonic/gin"
 )
@@ -25,6 +28,11 @@ func setupApp() *gin.Engine {
 	app := gin.New()
 	app.Use(gin.Recovery())
 
+	// --- LOAD JWT SIGNING KEYS ---
+	if err := libs.InitJWTKeys(); err != nil {
+		panic("JWT keys failed: " + err.Error())
+	}
+
 	// --- CONNECT DATABASE ---
 	db, err := configs.ConnectDB()
 	if err != nil {
@@ -47,6 +55,9 @@ func setupApp() *gin.Engine {
 		fmt.Println("✅ Cloudinary connected")
 	}
 
+	// --- FLASH SALE START AND END, PRICES AND CACHE FOLLOW THE CAMPAIGN WINDOW ---
+	models.StartFlashSaleScheduler(context.Background(), db, rdb)
+
 	routes.InitRouter(app, db, rdb, cld)
 	app.GET("/", func(ctx *gin.Context) {
 		ctx.JSON(200, gin.H{
@@ -10,6 +10,7 @@ import (
 	"github.com/federus1105/koda-b4-backend/internals/configs"
 	"github.com/federus1105/koda-b4-backend/internals/middlewares"
 	"github.com/federus1105/koda-b4-backend/internals/models"
+	"github.com/federus1105/koda-b4-backend/internals/pkg/libs"
 	"github.com/federus1105/koda-b4-backend/internals/routes"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -25,6 +26,11 @@ import (
 // @in header
 // @name Authorization
 // @description Type "Bearer" followed by a space and your JWT token.
+
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @description API key for machine clients, accepted on admin routes within its scopes.
 func main() {
 	router := gin.Default()
 	router.Use(gin.Recovery())
@@ -35,6 +41,12 @@ func main() {
 		_ = godotenv.Load()
 	}
 
+	// --- LOAD JWT SIGNING KEYS ---
+	if err := libs.InitJWTKeys(); err != nil {
+		log.Println("❌ Failed to load JWT keys\nCause: ", err.Error())
+		return
+	}
+
 	// --- INIT DB ---
 	db, err := configs.InitDB()
 	if err != nil {
@@ -77,6 +89,9 @@ func main() {
 		})
 	})
 
+	// --- FLASH SALE START AND END, PRICES AND CACHE FOLLOW THE CAMPAIGN WINDOW ---
+	models.StartFlashSaleScheduler(context.Background(), db, rdb)
+
 	routes.InitRouter(router, db, rdb, cld)
 	router.Run(":8011")
 }
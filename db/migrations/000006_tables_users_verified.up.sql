@@ -0,0 +1,4 @@
+ALTER TABLE users ADD COLUMN verified_at TIMESTAMP;
+
+--- EXISTING ACCOUNTS ARE TREATED AS VERIFIED ---
+UPDATE users SET verified_at = CURRENT_TIMESTAMP WHERE verified_at IS NULL;
@@ -0,0 +1,7 @@
+ALTER TABLE recovery_codes DROP CONSTRAINT IF EXISTS "recovery_codes_id_users_fkey";
+
+DROP TABLE IF EXISTS role_settings;
+DROP TABLE IF EXISTS recovery_codes;
+
+ALTER TABLE users DROP COLUMN IF EXISTS totp_enabled_at;
+ALTER TABLE users DROP COLUMN IF EXISTS totp_secret;
@@ -0,0 +1,21 @@
+ALTER TABLE users ADD COLUMN totp_secret VARCHAR(64);
+ALTER TABLE users ADD COLUMN totp_enabled_at TIMESTAMP;
+
+CREATE TABLE recovery_codes (
+    id SERIAL PRIMARY KEY,
+    id_users INT NOT NULL,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE role_settings (
+    role role PRIMARY KEY,
+    require_2fa BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE recovery_codes ADD FOREIGN KEY (id_users) REFERENCES users(id);
+CREATE INDEX recovery_codes_id_users_idx ON recovery_codes (id_users);
+
+INSERT INTO role_settings (role) VALUES ('admin'), ('user');
@@ -0,0 +1,3 @@
+ALTER TABLE user_identities DROP CONSTRAINT IF EXISTS "user_identities_id_users_fkey";
+
+DROP TABLE IF EXISTS user_identities;
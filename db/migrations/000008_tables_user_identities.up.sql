@@ -0,0 +1,13 @@
+CREATE TABLE user_identities (
+    id SERIAL PRIMARY KEY,
+    id_users INT NOT NULL,
+    provider VARCHAR(30) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(100),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    last_login_at TIMESTAMP,
+    UNIQUE (provider, subject)
+);
+
+ALTER TABLE user_identities ADD FOREIGN KEY (id_users) REFERENCES users(id);
+CREATE INDEX user_identities_id_users_idx ON user_identities (id_users);
@@ -0,0 +1,15 @@
+ALTER TABLE role_settings DROP CONSTRAINT IF EXISTS "role_settings_role_fkey";
+ALTER TABLE users DROP CONSTRAINT IF EXISTS "users_role_fkey";
+
+--- USERS WITH CUSTOM ROLE FALL BACK TO CUSTOMER ---
+DELETE FROM role_settings WHERE role NOT IN ('admin', 'user');
+UPDATE users SET role = 'user' WHERE role NOT IN ('admin', 'user');
+
+ALTER TABLE role_settings ALTER COLUMN role TYPE role USING role::role;
+ALTER TABLE users ALTER COLUMN role DROP DEFAULT;
+ALTER TABLE users ALTER COLUMN role TYPE role USING role::role;
+ALTER TABLE users ALTER COLUMN role SET DEFAULT 'user';
+
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS permissions;
+DROP TABLE IF EXISTS roles;
@@ -0,0 +1,62 @@
+CREATE TABLE roles (
+    name VARCHAR(30) PRIMARY KEY,
+    description VARCHAR(255),
+    is_system BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE permissions (
+    code VARCHAR(50) PRIMARY KEY,
+    description VARCHAR(255)
+);
+
+CREATE TABLE role_permissions (
+    role VARCHAR(30) NOT NULL,
+    permission VARCHAR(50) NOT NULL,
+    PRIMARY KEY (role, permission)
+);
+
+ALTER TABLE role_permissions ADD FOREIGN KEY (role) REFERENCES roles(name) ON UPDATE CASCADE ON DELETE CASCADE;
+ALTER TABLE role_permissions ADD FOREIGN KEY (permission) REFERENCES permissions(code) ON DELETE CASCADE;
+
+INSERT INTO roles (name, description, is_system) VALUES
+    ('admin', 'Full access to the back office', TRUE),
+    ('user', 'Customer', TRUE),
+    ('barista', 'Prepares orders and updates their status', FALSE),
+    ('cashier', 'Handles orders at the counter', FALSE);
+
+INSERT INTO permissions (code, description) VALUES
+    ('products:read', 'View products in the back office'),
+    ('products:write', 'Create, edit and delete products'),
+    ('categories:read', 'View categories in the back office'),
+    ('categories:write', 'Create, edit and delete categories'),
+    ('orders:read', 'View all orders'),
+    ('orders:update', 'Update order status'),
+    ('users:read', 'View users'),
+    ('users:write', 'Create and edit users'),
+    ('roles:manage', 'Manage roles, permissions and role assignment'),
+    ('security:manage', 'Manage security policies');
+
+INSERT INTO role_permissions (role, permission)
+SELECT 'admin', code FROM permissions;
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('barista', 'orders:read'),
+    ('barista', 'orders:update'),
+    ('barista', 'products:read'),
+    ('cashier', 'orders:read'),
+    ('cashier', 'orders:update'),
+    ('cashier', 'products:read'),
+    ('cashier', 'categories:read');
+
+--- ROLE ENUM CAN'T GROW AT RUNTIME, REFERENCE ROLES TABLE INSTEAD ---
+ALTER TABLE users ALTER COLUMN role DROP DEFAULT;
+ALTER TABLE users ALTER COLUMN role TYPE VARCHAR(30) USING role::TEXT;
+ALTER TABLE users ALTER COLUMN role SET DEFAULT 'user';
+ALTER TABLE users ADD FOREIGN KEY (role) REFERENCES roles(name) ON UPDATE CASCADE;
+
+ALTER TABLE role_settings ALTER COLUMN role TYPE VARCHAR(30) USING role::TEXT;
+ALTER TABLE role_settings ADD FOREIGN KEY (role) REFERENCES roles(name) ON UPDATE CASCADE ON DELETE CASCADE;
+
+INSERT INTO role_settings (role) VALUES ('barista'), ('cashier');
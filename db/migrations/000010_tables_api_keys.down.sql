@@ -0,0 +1,5 @@
+DELETE FROM permissions WHERE code = 'api_keys:manage';
+
+ALTER TABLE api_keys DROP CONSTRAINT IF EXISTS "api_keys_created_by_fkey";
+
+DROP TABLE IF EXISTS api_keys;
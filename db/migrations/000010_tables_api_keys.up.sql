@@ -0,0 +1,19 @@
+CREATE TABLE api_keys (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(16) NOT NULL UNIQUE,
+    key_hash VARCHAR(64) NOT NULL,
+    scopes TEXT[] NOT NULL DEFAULT '{}',
+    created_by INT,
+    expires_at TIMESTAMP,
+    last_used_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE api_keys ADD FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL;
+
+INSERT INTO permissions (code, description) VALUES
+    ('api_keys:manage', 'Create and revoke API keys for machine clients');
+
+INSERT INTO role_permissions (role, permission) VALUES ('admin', 'api_keys:manage');
@@ -0,0 +1,3 @@
+ALTER TABLE passkeys DROP CONSTRAINT IF EXISTS "passkeys_id_users_fkey";
+
+DROP TABLE IF EXISTS passkeys;
@@ -0,0 +1,17 @@
+CREATE TABLE passkeys (
+    id SERIAL PRIMARY KEY,
+    id_users INT NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    credential_id BYTEA NOT NULL UNIQUE,
+    public_key BYTEA NOT NULL,
+    sign_count BIGINT NOT NULL DEFAULT 0,
+    transports TEXT[] NOT NULL DEFAULT '{}',
+    aaguid BYTEA,
+    backup_eligible BOOLEAN NOT NULL DEFAULT FALSE,
+    backed_up BOOLEAN NOT NULL DEFAULT FALSE,
+    last_used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE passkeys ADD FOREIGN KEY (id_users) REFERENCES users(id) ON DELETE CASCADE;
+CREATE INDEX passkeys_id_users_idx ON passkeys (id_users);
@@ -0,0 +1,3 @@
+ALTER TABLE users DROP COLUMN IF EXISTS pending_email;
+
+DROP INDEX IF EXISTS users_email_lower_key;
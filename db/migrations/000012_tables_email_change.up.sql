@@ -0,0 +1,4 @@
+-- Emails that only differ in case must be merged by hand before the index can be created
+CREATE UNIQUE INDEX users_email_lower_key ON users (LOWER(email));
+
+ALTER TABLE users ADD COLUMN pending_email VARCHAR(100);
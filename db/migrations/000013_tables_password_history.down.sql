@@ -0,0 +1,3 @@
+ALTER TABLE password_history DROP CONSTRAINT IF EXISTS "password_history_id_users_fkey";
+
+DROP TABLE IF EXISTS password_history;
@@ -0,0 +1,9 @@
+CREATE TABLE password_history (
+    id SERIAL PRIMARY KEY,
+    id_users INT NOT NULL,
+    password VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE password_history ADD FOREIGN KEY (id_users) REFERENCES users(id) ON DELETE CASCADE;
+CREATE INDEX password_history_id_users_idx ON password_history (id_users, created_at DESC);
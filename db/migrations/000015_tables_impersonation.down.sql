@@ -0,0 +1,8 @@
+DELETE FROM permissions WHERE code = 'users:impersonate';
+
+ALTER TABLE impersonation_audit DROP CONSTRAINT IF EXISTS "impersonation_audit_id_impersonation_fkey";
+ALTER TABLE impersonations DROP CONSTRAINT IF EXISTS "impersonations_actor_id_fkey";
+ALTER TABLE impersonations DROP CONSTRAINT IF EXISTS "impersonations_id_users_fkey";
+
+DROP TABLE IF EXISTS impersonation_audit;
+DROP TABLE IF EXISTS impersonations;
@@ -0,0 +1,29 @@
+CREATE TABLE impersonations (
+    id SERIAL PRIMARY KEY,
+    token_id VARCHAR(64) NOT NULL UNIQUE,
+    actor_id INT,
+    id_users INT NOT NULL,
+    reason VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE impersonation_audit (
+    id SERIAL PRIMARY KEY,
+    id_impersonation INT NOT NULL,
+    method VARCHAR(10) NOT NULL,
+    path VARCHAR(255) NOT NULL,
+    status INT NOT NULL,
+    ip VARCHAR(45),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE impersonations ADD FOREIGN KEY (actor_id) REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE impersonations ADD FOREIGN KEY (id_users) REFERENCES users(id);
+ALTER TABLE impersonation_audit ADD FOREIGN KEY (id_impersonation) REFERENCES impersonations(id) ON DELETE CASCADE;
+CREATE INDEX impersonation_audit_id_impersonation_idx ON impersonation_audit (id_impersonation, created_at);
+
+INSERT INTO permissions (code, description) VALUES
+    ('users:impersonate', 'Sign in as a customer to see what they see');
+
+INSERT INTO role_permissions (role, permission) VALUES ('admin', 'users:impersonate');
@@ -0,0 +1,5 @@
+ALTER TABLE product_skus DROP CONSTRAINT IF EXISTS "product_skus_id_product_fkey";
+ALTER TABLE product_skus DROP CONSTRAINT IF EXISTS "product_skus_id_size_fkey";
+ALTER TABLE product_skus DROP CONSTRAINT IF EXISTS "product_skus_id_variant_fkey";
+
+DROP TABLE IF EXISTS product_skus;
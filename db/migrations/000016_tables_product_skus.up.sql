@@ -0,0 +1,32 @@
+CREATE TABLE product_skus (
+    id SERIAL PRIMARY KEY,
+    id_product INT NOT NULL,
+    id_size INT,
+    id_variant INT,
+    price FLOAT CHECK (price IS NULL OR price > 0),
+    price_delta FLOAT NOT NULL DEFAULT 0,
+    stock INT NOT NULL DEFAULT 0 CHECK (stock >= 0),
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE product_skus ADD FOREIGN KEY (id_product) REFERENCES product(id) ON DELETE CASCADE;
+ALTER TABLE product_skus ADD FOREIGN KEY (id_size) REFERENCES sizes(id);
+ALTER TABLE product_skus ADD FOREIGN KEY (id_variant) REFERENCES variants(id);
+
+-- product without size or variant has a single sku with both NULL
+CREATE UNIQUE INDEX product_skus_combination_idx ON product_skus (id_product, COALESCE(id_size, 0), COALESCE(id_variant, 0));
+
+-- one sku per existing size x variant combination, stock split evenly and the remainder spread one each over the first skus
+INSERT INTO product_skus (id_product, id_size, id_variant, stock)
+SELECT c.id_product, c.id_size, c.id_variant,
+       c.stock / c.total + CASE WHEN c.rn <= c.stock % c.total THEN 1 ELSE 0 END
+FROM (
+    SELECT p.id AS id_product, sp.id_size, vp.id_variant, GREATEST(p.stock, 0) AS stock,
+           COUNT(*) OVER (PARTITION BY p.id) AS total,
+           ROW_NUMBER() OVER (PARTITION BY p.id ORDER BY sp.id_size NULLS FIRST, vp.id_variant NULLS FIRST) AS rn
+    FROM product p
+    LEFT JOIN (SELECT DISTINCT id_product, id_size FROM size_product) sp ON sp.id_product = p.id
+    LEFT JOIN (SELECT DISTINCT id_product, id_variant FROM variant_product) vp ON vp.id_product = p.id
+) c;
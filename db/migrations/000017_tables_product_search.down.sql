@@ -0,0 +1,13 @@
+DROP TRIGGER IF EXISTS categories_search_update ON categories;
+DROP TRIGGER IF EXISTS product_categories_search_update ON product_categories;
+DROP TRIGGER IF EXISTS product_search_vector_update ON product;
+
+DROP FUNCTION IF EXISTS categories_search_trigger();
+DROP FUNCTION IF EXISTS product_categories_search_trigger();
+DROP FUNCTION IF EXISTS product_search_vector_trigger();
+DROP FUNCTION IF EXISTS product_search_vector(TEXT, TEXT, INT);
+
+DROP INDEX IF EXISTS product_name_trgm_idx;
+DROP INDEX IF EXISTS product_search_vector_idx;
+
+ALTER TABLE product DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,59 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE product ADD COLUMN search_vector tsvector;
+
+-- name weighs most, then description, then category names; 'simple' because names mix English and Indonesian
+CREATE OR REPLACE FUNCTION product_search_vector(p_name TEXT, p_description TEXT, p_id INT) RETURNS tsvector AS $$
+    SELECT setweight(to_tsvector('simple', COALESCE(p_name, '')), 'A')
+        || setweight(to_tsvector('simple', COALESCE(p_description, '')), 'B')
+        || setweight(to_tsvector('simple', COALESCE((
+            SELECT string_agg(c.name, ' ')
+            FROM product_categories pc
+            JOIN categories c ON c.id = pc.id_categories
+            WHERE pc.id_product = p_id
+        ), '')), 'C')
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION product_search_vector_trigger() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector := product_search_vector(NEW.name, NEW.description, NEW.id);
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER product_search_vector_update
+BEFORE INSERT OR UPDATE OF name, description ON product
+FOR EACH ROW EXECUTE FUNCTION product_search_vector_trigger();
+
+CREATE OR REPLACE FUNCTION product_categories_search_trigger() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP IN ('DELETE', 'UPDATE') THEN
+        UPDATE product SET search_vector = product_search_vector(name, description, id) WHERE id = OLD.id_product;
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        UPDATE product SET search_vector = product_search_vector(name, description, id) WHERE id = NEW.id_product;
+    END IF;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER product_categories_search_update
+AFTER INSERT OR UPDATE OR DELETE ON product_categories
+FOR EACH ROW EXECUTE FUNCTION product_categories_search_trigger();
+
+CREATE OR REPLACE FUNCTION categories_search_trigger() RETURNS trigger AS $$
+BEGIN
+    UPDATE product SET search_vector = product_search_vector(name, description, id)
+    WHERE id IN (SELECT id_product FROM product_categories WHERE id_categories = NEW.id);
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER categories_search_update
+AFTER UPDATE OF name ON categories
+FOR EACH ROW EXECUTE FUNCTION categories_search_trigger();
+
+UPDATE product SET search_vector = product_search_vector(name, description, id);
+
+CREATE INDEX product_search_vector_idx ON product USING GIN (search_vector);
+CREATE INDEX product_name_trgm_idx ON product USING GIN (name gin_trgm_ops);
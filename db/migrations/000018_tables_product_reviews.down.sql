@@ -0,0 +1,23 @@
+DELETE FROM permissions WHERE code = 'reviews:moderate';
+
+DROP TRIGGER IF EXISTS product_review_votes_update ON product_review_votes;
+DROP TRIGGER IF EXISTS product_reviews_rating_update ON product_reviews;
+
+DROP FUNCTION IF EXISTS product_review_votes_trigger();
+DROP FUNCTION IF EXISTS product_reviews_rating_trigger();
+DROP FUNCTION IF EXISTS product_rating_refresh(INT);
+
+ALTER TABLE product_review_votes DROP CONSTRAINT IF EXISTS "product_review_votes_id_review_fkey";
+ALTER TABLE product_review_votes DROP CONSTRAINT IF EXISTS "product_review_votes_id_users_fkey";
+ALTER TABLE product_review_photos DROP CONSTRAINT IF EXISTS "product_review_photos_id_review_fkey";
+ALTER TABLE product_reviews DROP CONSTRAINT IF EXISTS "product_reviews_id_product_fkey";
+ALTER TABLE product_reviews DROP CONSTRAINT IF EXISTS "product_reviews_id_users_fkey";
+ALTER TABLE product_reviews DROP CONSTRAINT IF EXISTS "product_reviews_moderated_by_fkey";
+
+DROP TABLE IF EXISTS product_review_votes;
+DROP TABLE IF EXISTS product_review_photos;
+DROP TABLE IF EXISTS product_reviews;
+
+-- ratings computed from reviews are kept, the admin typed values can't be restored
+ALTER TABLE product ALTER COLUMN rating DROP DEFAULT;
+ALTER TABLE product DROP COLUMN IF EXISTS review_count;
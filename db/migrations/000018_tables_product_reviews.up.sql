@@ -0,0 +1,91 @@
+CREATE TABLE product_reviews (
+    id SERIAL PRIMARY KEY,
+    id_product INT NOT NULL,
+    id_users INT NOT NULL,
+    rating SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    content VARCHAR(1000) NOT NULL DEFAULT '',
+    status VARCHAR(10) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+    helpful_count INT NOT NULL DEFAULT 0,
+    moderated_by INT,
+    moderated_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (id_product, id_users)
+);
+
+CREATE TABLE product_review_photos (
+    id SERIAL PRIMARY KEY,
+    id_review INT NOT NULL,
+    photo VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE product_review_votes (
+    id_review INT NOT NULL,
+    id_users INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id_review, id_users)
+);
+
+ALTER TABLE product_reviews ADD FOREIGN KEY (id_product) REFERENCES product(id) ON DELETE CASCADE;
+ALTER TABLE product_reviews ADD FOREIGN KEY (id_users) REFERENCES users(id);
+ALTER TABLE product_reviews ADD FOREIGN KEY (moderated_by) REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE product_review_photos ADD FOREIGN KEY (id_review) REFERENCES product_reviews(id) ON DELETE CASCADE;
+ALTER TABLE product_review_votes ADD FOREIGN KEY (id_review) REFERENCES product_reviews(id) ON DELETE CASCADE;
+ALTER TABLE product_review_votes ADD FOREIGN KEY (id_users) REFERENCES users(id);
+
+CREATE INDEX product_reviews_id_product_idx ON product_reviews (id_product, status, created_at);
+CREATE INDEX product_reviews_status_idx ON product_reviews (status, created_at);
+
+-- rating is the average of approved reviews, the 1-10 value typed by the admin is dropped
+ALTER TABLE product ADD COLUMN review_count INT NOT NULL DEFAULT 0;
+ALTER TABLE product ALTER COLUMN rating SET DEFAULT 0;
+UPDATE product SET rating = 0;
+
+CREATE OR REPLACE FUNCTION product_rating_refresh(p_id INT) RETURNS void AS $$
+    UPDATE product p SET
+        rating = COALESCE(r.avg_rating, 0),
+        review_count = r.total
+    FROM (
+        SELECT ROUND(AVG(rating)::numeric, 1)::float8 AS avg_rating, COUNT(*) AS total
+        FROM product_reviews
+        WHERE id_product = p_id AND status = 'approved'
+    ) r
+    WHERE p.id = p_id
+$$ LANGUAGE sql;
+
+CREATE OR REPLACE FUNCTION product_reviews_rating_trigger() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP IN ('DELETE', 'UPDATE') THEN
+        PERFORM product_rating_refresh(OLD.id_product);
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        PERFORM product_rating_refresh(NEW.id_product);
+    END IF;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER product_reviews_rating_update
+AFTER INSERT OR UPDATE OF rating, status OR DELETE ON product_reviews
+FOR EACH ROW EXECUTE FUNCTION product_reviews_rating_trigger();
+
+CREATE OR REPLACE FUNCTION product_review_votes_trigger() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        UPDATE product_reviews SET helpful_count = helpful_count + 1 WHERE id = NEW.id_review;
+    ELSE
+        UPDATE product_reviews SET helpful_count = helpful_count - 1 WHERE id = OLD.id_review;
+    END IF;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER product_review_votes_update
+AFTER INSERT OR DELETE ON product_review_votes
+FOR EACH ROW EXECUTE FUNCTION product_review_votes_trigger();
+
+INSERT INTO permissions (code, description) VALUES
+    ('reviews:moderate', 'Approve, reject and delete product reviews');
+
+INSERT INTO role_permissions (role, permission) VALUES ('admin', 'reviews:moderate');
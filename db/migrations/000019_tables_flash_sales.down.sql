@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS product_orders_id_flash_sale_idx;
+ALTER TABLE product_orders DROP CONSTRAINT IF EXISTS "product_orders_id_flash_sale_fkey";
+ALTER TABLE product_orders DROP COLUMN IF EXISTS id_flash_sale;
+
+ALTER TABLE flash_sale_products DROP CONSTRAINT IF EXISTS "flash_sale_products_id_flash_sale_fkey";
+ALTER TABLE flash_sale_products DROP CONSTRAINT IF EXISTS "flash_sale_products_id_product_fkey";
+ALTER TABLE flash_sales DROP CONSTRAINT IF EXISTS "flash_sales_created_by_fkey";
+
+DROP TABLE IF EXISTS flash_sale_products;
+DROP TABLE IF EXISTS flash_sales;
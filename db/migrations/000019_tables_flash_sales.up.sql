@@ -0,0 +1,37 @@
+-- window is compared with NOW(), TIMESTAMPTZ so it doesn't depend on the server time zone
+CREATE TABLE flash_sales (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ NOT NULL,
+    created_by INT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (ends_at > starts_at)
+);
+
+-- either a sale price or a percentage off, the percentage also applies to every size x variant price
+CREATE TABLE flash_sale_products (
+    id_flash_sale INT NOT NULL,
+    id_product INT NOT NULL,
+    sale_price FLOAT CHECK (sale_price > 0),
+    discount_percent FLOAT CHECK (discount_percent > 0 AND discount_percent < 100),
+    max_per_customer INT CHECK (max_per_customer > 0),
+    PRIMARY KEY (id_flash_sale, id_product),
+    CHECK ((sale_price IS NULL) <> (discount_percent IS NULL))
+);
+
+ALTER TABLE flash_sales ADD FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE flash_sale_products ADD FOREIGN KEY (id_flash_sale) REFERENCES flash_sales(id) ON DELETE CASCADE;
+ALTER TABLE flash_sale_products ADD FOREIGN KEY (id_product) REFERENCES product(id) ON DELETE CASCADE;
+
+CREATE INDEX flash_sales_window_idx ON flash_sales (starts_at, ends_at);
+CREATE INDEX flash_sale_products_id_product_idx ON flash_sale_products (id_product);
+
+-- campaign an order line was priced with, per-customer caps are counted from it
+ALTER TABLE product_orders ADD COLUMN id_flash_sale INT;
+ALTER TABLE product_orders ADD FOREIGN KEY (id_flash_sale) REFERENCES flash_sales(id) ON DELETE SET NULL;
+CREATE INDEX product_orders_id_flash_sale_idx ON product_orders (id_flash_sale, id_product) WHERE id_flash_sale IS NOT NULL;
+
+-- flash_sale and priceDiscount are now kept in sync with campaigns by the scheduler
+UPDATE product SET flash_sale = FALSE, pricediscount = 0;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS product_sku_idx;
+DROP INDEX IF EXISTS product_slug_idx;
+
+ALTER TABLE product DROP COLUMN IF EXISTS sku;
+ALTER TABLE product DROP COLUMN IF EXISTS slug;
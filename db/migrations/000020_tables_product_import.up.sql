@@ -0,0 +1,20 @@
+-- slug is derived from the name, sku is the merchant's own product code; both identify a product on import
+ALTER TABLE product ADD COLUMN slug VARCHAR(120);
+ALTER TABLE product ADD COLUMN sku VARCHAR(64);
+
+UPDATE product SET slug = COALESCE(NULLIF(TRIM(BOTH '-' FROM REGEXP_REPLACE(LOWER(name), '[^a-z0-9]+', '-', 'g')), ''), 'product');
+
+-- same name twice keeps the slug on the oldest product, the others get their id appended
+UPDATE product p SET slug = p.slug || '-' || p.id
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY slug ORDER BY id) AS rn
+    FROM product
+    WHERE is_deleted = false
+) d
+WHERE d.id = p.id AND d.rn > 1;
+
+ALTER TABLE product ALTER COLUMN slug SET NOT NULL;
+
+-- a deleted product gives its slug and sku free
+CREATE UNIQUE INDEX product_slug_idx ON product (slug) WHERE is_deleted = false;
+CREATE UNIQUE INDEX product_sku_idx ON product (sku) WHERE is_deleted = false AND sku IS NOT NULL;
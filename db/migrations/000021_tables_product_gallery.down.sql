@@ -0,0 +1,27 @@
+CREATE TABLE product_images (
+    id SERIAL PRIMARY KEY,
+    photos_one VARCHAR(255),
+    photos_two VARCHAR(255),
+    photos_three VARCHAR(255),
+    photos_four VARCHAR(255)
+);
+
+ALTER TABLE product ADD COLUMN id_product_images INT;
+
+-- one row per product, the primary image first and then the first three others by position
+INSERT INTO product_images (id, photos_one, photos_two, photos_three, photos_four)
+SELECT p.id, g.photos[1], g.photos[2], g.photos[3], g.photos[4]
+FROM product p
+LEFT JOIN LATERAL (
+    SELECT ARRAY_AGG(url ORDER BY is_primary DESC, position, id) AS photos
+    FROM product_gallery
+    WHERE id_product = p.id
+) g ON TRUE;
+
+SELECT setval(pg_get_serial_sequence('product_images', 'id'), COALESCE((SELECT MAX(id) FROM product_images), 0) + 1, false);
+
+UPDATE product SET id_product_images = id;
+ALTER TABLE product ALTER COLUMN id_product_images SET NOT NULL;
+ALTER TABLE product ADD FOREIGN KEY (id_product_images) REFERENCES product_images(id);
+
+DROP TABLE IF EXISTS product_gallery;
@@ -0,0 +1,35 @@
+CREATE TABLE product_gallery (
+    id SERIAL PRIMARY KEY,
+    id_product INT NOT NULL,
+    url VARCHAR(255) NOT NULL,
+    alt_text VARCHAR(150) NOT NULL DEFAULT '',
+    position INT NOT NULL DEFAULT 0,
+    is_primary BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE product_gallery ADD FOREIGN KEY (id_product) REFERENCES product(id) ON DELETE CASCADE;
+
+CREATE INDEX product_gallery_product_idx ON product_gallery (id_product, position);
+
+-- lists join the primary image through this index, one per product
+CREATE UNIQUE INDEX product_gallery_primary_idx ON product_gallery (id_product) WHERE is_primary;
+
+-- the first photo becomes the primary image, empty columns are skipped and the rest keep their order
+INSERT INTO product_gallery (id_product, url, position, is_primary)
+SELECT id_product, url, position, position = 0
+FROM (
+    SELECT p.id AS id_product, photo.url,
+           ROW_NUMBER() OVER (PARTITION BY p.id ORDER BY photo.n) - 1 AS position
+    FROM product p
+    JOIN product_images pi ON pi.id = p.id_product_images
+    CROSS JOIN LATERAL (
+        VALUES (1, pi.photos_one), (2, pi.photos_two), (3, pi.photos_three), (4, pi.photos_four)
+    ) AS photo(n, url)
+    WHERE COALESCE(photo.url, '') <> ''
+) photos;
+
+ALTER TABLE product DROP CONSTRAINT IF EXISTS "product_id_product_images_fkey";
+ALTER TABLE product DROP COLUMN id_product_images;
+DROP TABLE product_images;
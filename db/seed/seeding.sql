@@ -31,120 +31,119 @@ insert into variants (name) values ('ice'),('hot');
 INSERT INTO delivery (name, fee) VALUES ('dine_in', 0), ('door_delivery', 15000), ('pickup', 0);
  
 
----  INSERT PRODUCT IMAGES  ---
-INSERT INTO product_images (photos_one) VALUES 
-('https://domf5oio6qrcr.cloudfront.net/medialibrary/8468/Tea.jpg'),
-('https://dcostseafood.id/wp-content/uploads/2021/12/ICED-GREEN-TEA.jpg'),
-('https://shwetainthekitchen.com/wp-content/uploads/2023/07/lemon-iced-tea.jpg'),
-('https://dcostseafood.id/wp-content/uploads/2021/12/LYCHEE-TEA-1.jpg'),
-('https://res.cloudinary.com/dk0z4ums3/image/upload/v1687161948/attached_image/chrysanthemum-tea-inilah-7-manfaatnya-bagi-kesehatan.jpg'),
-('https://www.eatingwell.com/thmb/S4mEBHCn_c3y0U17S9dWEubvW1Y=/1500x0/filters:no_upscale():max_bytes(150000):strip_icc()/peach-iced-tea-hero-1x1-15009_preview_maxWidth_4000_maxHeight_4000_ppi_300_quality_100-0d9f432284a447fc9151868c5acf6c7e.jpg'),
-('https://i.pinimg.com/474x/81/66/d0/8166d03b7c6ad46aebe443ff1a09b79f.jpg'),
-('https://cdn.loveandlemons.com/wp-content/uploads/2023/06/iced-matcha-latte.jpg'),
-('https://www.queensleeappetit.com/wp-content/uploads/2018/02/chocolate-lovers-hot-chocolate-recipe-via-queensleeappetit.com-5.jpg'),
-('https://encrypted-tbn0.gstatic.com/images?q=tbn:ANd9GcR6RJTe4dNR4lrFDmIKIRFj5an4O47KsglEMQ&s'),
-('https://www.siftandsimmer.com/wp-content/uploads/2022/11/milo-dinosaur4.jpg'),
-('https://www.acouplecooks.com/wp-content/uploads/2020/12/Green-Drinks-053.jpg'),
-('https://thetoastykitchen.com/wp-content/uploads/2021/02/tall-glass-of-limeade-with-lime-slice-3.jpg'),
-('https://i0.wp.com/nasgorking.com/wp-content/uploads/2025/06/putra-indonesia-96-scaled.webp?fit=1920%2C2560&ssl=1'),
-('https://img.merahputih.com/media/70/62/2d/70622dde196f15925929048a4f00f1c3.jpg'),
-('https://cdn.yummy.co.id/content-images/images/20210731/Fzd3L5vc5sCw0ssnHjo5gDvbGMfDl3Sp-31363237373138303531d41d8cd98f00b204e9800998ecf8427e.jpg?x-oss-process=image/resize,w_388,h_388,m_fixed,x-oss-process=image/format,webp'),
-('https://encrypted-tbn0.gstatic.com/images?q=tbn:ANd9GcR9rZX-pfVO4EOlhZ20d-hCHpdMnkFy4Q0s_w&s'),
-('https://www.proportionalplate.com/wp-content/uploads/2024/04/Mint-LemonadeMay-07-2025-2.jpg'),
-('https://cdnimg.webstaurantstore.com/uploads/blog/2016/7/regular_mojito.jpg'),
-('https://ameessavorydish.com/wp-content/uploads/2012/03/Kiwi-skinny-mojito-feature.jpg'),
-('https://www.mapleandmango.com/wp-content/uploads/2020/06/strawberry-mojito-mocktail-feature.jpg'),
-('https://images.mrcook.app/recipe-image/01932926-d863-7a09-ab5f-0c1490eefa04?cacheKey=U3VuLCAxMiBKYW4gMjAyNSAwMzozODoyNCBHTVQ='),
-('https://www.lucismorsels.com/wp-content/uploads/2020/02/SQ-Kiwi-Spritzer.jpg'),
-('https://www.thespruceeats.com/thmb/HJrjMfXdLGHbgMhnM0fMkDx9XPQ=/1500x0/filters:no_upscale():max_bytes(150000):strip_icc()/what-is-espresso-765702-hero-03_cropped-ffbc0c7cf45a46ff846843040c8f370c.jpg'),
-('https://myeverydaytable.com/wp-content/uploads/AmericanoHotandIced-3.jpg'),
-('https://d1r9hss9q19p18.cloudfront.net/uploads/2015/07/shutterstock_223511062.jpg'),
-('https://www.sugacoffee.id/wp-content/uploads/2024/04/21.-Cappucino-Pengertian-Proses-dan-Teknik-Peyeduhannya-Suga-Coffee-2.jpg'),
-('https://www.bitkaorigin.com/assets/myback/js/elFinder/files/bitka-mocha-latte-kopi-italia-perpaduan-espresso-susu-dan-cokelat-yang-nikmat-image.jpg'),
-('https://awsimages.detik.net.id/community/media/visual/2025/04/08/penjelasan-espresso-kafe-dan-rumah-beda-rasa-1744100119310_169.webp?w=1200'),
-('https://www.astronauts.id/blog/wp-content/uploads/2023/03/Resep-Es-Cincau-Untuk-Bikin-Buka-Puasa-Jadi-Tambah-Nikmat-1024x683.jpg'),
-('https://img-global.cpcdn.com/recipes/35ef6f9212576405/680x781cq80/vanilla-hazelnut-latte-foto-resep-utama.jpg'),
-('https://images.ctfassets.net/v601h1fyjgba/2L61TpcCFqcNMOtgXklc4s/732a70a58d6dfc25e24378c67900ed16/15697_Keurig_CafeCreations_Salted_Caramel_Latte_Hi.jpg'),
-('https://www.forkinthekitchen.com/wp-content/uploads/2022/06/220518.homemade.caramel.latte-6630.jpg'),
-('https://www.forkinthekitchen.com/wp-content/uploads/2022/08/220629.iced_.latte_.vanilla-9009.jpg'),
-('https://www.bitkaorigin.com/assets/myback/js/elFinder/files/BITKA-Avocado-Coffee-10-Pengertian-Sejarah-Manfaat-dan-Cara-Membuatnya-Img3.jpg'),
-('https://klikwartaku.com/wp-content/uploads/2025/09/Kopi-Es-gula-Aren.jpg'),
-('https://www.folgerscoffee.com/folgers/recipes/_Hero%20Images/Detail%20Pages/5598/image-thumb__5598__schema_image/MochaIced-hero.58f3878d.jpg'),
-('https://takestwoeggs.com/wp-content/uploads/2022/11/Chicken-Katsu-Takestwoeggs-FINAL-Photography-sq.jpg'),
-('https://muchbutter.com/wp-content/uploads/2023/08/Chicken-Steak-16-500x500.jpg'),
-('https://awsimages.detik.net.id/community/media/visual/2021/03/19/spaghetti-aglio-olio_43.jpeg?w=600&q=90'),
-('https://images.services.kitchenstories.io/z_bWPIhhM6qs38B0E46CRaYs81Q=/3840x0/filters:quality(85)/images.kitchenstories.io/wagtailOriginalImages/R2568-photo-final-_0.jpg'),
-('https://www.dapurkobe.co.id/wp-content/uploads/tempe-goreng-kriuk-ala-tepung-kobe.jpg'),
-('https://media.indozone.id/crop/0x0:0x0/images/2025/06/30/6wvBbkRZrLWj0r8GOi3v2Bjz4FsdWBBmCCiTjQAj.jpg'),
-('https://static.promediateknologi.id/crop/0x0:0x0/750x500/webp/photo/p1/1067/2024/11/05/foto-tahu-berontak-411400167.jpg'),
-('https://asset.kompas.com/crops/7-qyrnHe6joJqVavA0xiK7TrDB0=/0x0:1000x667/1200x800/data/photo/2022/08/15/62f997825b557.jpeg'),
-('https://asset.kompas.com/crops/olPvvorGLd5nZkgMNcT_qhVd9Ng=/0x76:1000x743/1200x800/data/photo/2024/05/10/663dbb1fcecb8.jpg'),
-('https://asset-a.grid.id/crop/0x0:0x0/x/photo/2021/02/17/bakwan-crispyjpg-20210217122049.jpg'),
-('https://cdn.rri.co.id/berita/Pusat_Pemberitaan/o/1740911140320-80372-bakwan-sayur-renyah-dan-gurih-pakai-sambal-kacang/pu768dac4chrrhm.jpeg'),
-('https://asset.kompas.com/crops/henUp87hYyOPkQfTpFVrCykP5rg=/0x0:800x533/1200x800/data/photo/2018/08/02/1483887855.jpg'),
-('https://img-global.cpcdn.com/steps/16999565a8178da1/400x400cq80/photo.jpg'),
-('https://image.idn.media/post/20250113/screenshot-2025-0113-2130552-579c18e74dce0f8e75e98443de8e9b79-ebe9761a87d5a310181918a6e331d2db.jpg'),
-('https://media.zcreators.id/crop/0x0:0x0/750x500/photo/indizone/2020/12/10/d5sBOBq/resep-pempek-dos-tanpa-ikan-mudah-dan-dijamin-enak71.jpg'),
-('https://nirvana-store-assets.s3.ap-southeast-1.amazonaws.com/Tortilla_wrap_1_d8e18b338a.png');
-
-
-
 INSERT INTO product
-(name, id_product_images, description, rating, priceOriginal, stock) 
+(name, slug, description, rating, priceOriginal, stock) 
 VALUES
-('tea', 1, 'Teh hitam klasik dengan aroma lembut dan rasa yang menenangkan, cocok untuk dinikmati hangat maupun dingin.', 7.5, 10000, 200),
-('Green Tea', 2, 'Teh hijau segar dengan rasa alami yang khas dan kaya antioksidan, memberikan sensasi sehat di setiap tegukan.', 7.5, 12000, 200),
-('lemon Tea', 3, 'Perpaduan teh hitam dengan perasan lemon segar yang menghasilkan cita rasa asam manis menyegarkan.', 7, 15000, 100),
-('Lychee Tea', 4, 'Teh berpadu rasa leci yang manis dan harum, memberikan sensasi tropis yang nikmat dan ringan.', 7, 18000, 150),
-('Teh Krisan', 5, 'Minuman teh bunga krisan alami dengan aroma khas dan efek menenangkan, cocok untuk relaksasi.', 8, 18000, 100),
-('Peach Tea', 6, 'Teh segar dengan rasa buah persik manis dan lembut, sempurna untuk menemani waktu santai.', 7.6, 20000, 50),
-('Taro', 7, 'Minuman taro lembut dengan warna ungu menggoda dan rasa manis khas umbi talas.', 8, 20000, 100),
-('Matcha', 8, 'Minuman matcha premium dengan rasa teh hijau yang kuat dan creamy, khas Jepang.', 7, 20000, 100),
-('Chocolate', 9, 'Cokelat kental dan lembut dengan perpaduan manis dan pahit yang pas di lidah.', 6, 20000, 200),
-('Red Velvet', 10, 'Minuman red velvet creamy dengan aroma vanila dan cokelat yang elegan.', 7, 20000, 200),
-('Milo', 11, 'Minuman cokelat malt klasik yang disukai semua usia, nikmat disajikan dingin atau hangat.', 8, 20000, 100),
-('Green Lime', 12, 'Minuman jeruk nipis hijau yang segar dan asam, cocok untuk menghilangkan dahaga.', 6, 15000, 100),
-('Honey Lime', 13, 'Perpaduan madu alami dan jeruk nipis yang menciptakan rasa manis-asam menyegarkan.', 7, 15000, 100),
-('Jus Semangka', 14, 'Jus semangka segar dengan rasa manis alami, kaya vitamin dan sangat menyegarkan.', 6, 15000, 150),
-('Jus Jeruk', 15, 'Jus jeruk segar alami kaya vitamin C untuk menjaga daya tahan tubuh.', 6.6, 15000, 100),
-('Melon Squah', 16, 'Minuman soda melon yang manis dan berbuih lembut, menyegarkan di setiap tegukan.', 8, 18000, 100),
-('Strawberry Squash', 17, 'Minuman soda stroberi dengan rasa manis-asam dan warna merah menggoda.', 7, 18000, 120),
-('Mint Lemonade', 18, 'Perpaduan jeruk lemon dan daun mint segar yang memberikan sensasi dingin dan segar.', 7, 18000, 130),
-('Regular Mojito', 19, 'Minuman mojito klasik dengan rasa mint dan lemon yang menyegarkan tanpa alkohol.', 7, 20000, 100),
-('Kiwi Mojito', 20, 'Mojito kiwi dengan rasa manis-asam segar dan aroma buah yang khas.', 7, 20000, 110),
-('Strawberry Mojito', 21, 'Mojito stroberi yang manis dan segar, cocok untuk pecinta rasa buah segar.', 7, 20000, 100),
-('Blast Berry', 22, 'Minuman campuran berbagai buah beri segar dengan rasa manis dan asam seimbang.', 7, 25000, 100),
-('Blast Kiwi', 23, 'Campuran buah kiwi segar dengan soda lembut yang menyegarkan tenggorokan.', 7, 25000, 120),
-('Espresso', 24, 'Kopi espresso dengan cita rasa kuat dan aroma khas biji kopi pilihan.', 7, 13000, 100),
-('Americano', 25, 'Espresso yang dicampur air panas, menghasilkan rasa kopi yang ringan namun berkarakter.', 7, 18000, 200),
-('Coffe Latte', 26, 'Kombinasi espresso dan susu steamed lembut yang menciptakan rasa creamy dan seimbang.', 7, 18000, 100),
-('Cappucino', 27, 'Perpaduan espresso, susu, dan foam lembut dengan cita rasa kopi yang kaya.', 7, 18000, 120),
-('Mochacita', 28, 'Kombinasi kopi, cokelat, dan susu yang menghadirkan rasa manis pahit sempurna.', 7, 18000, 100),
-('Sanger Espresso', 29, 'Espresso khas Aceh dengan tambahan susu kental manis yang menambah kekayaan rasa.', 7, 20000, 100),
-('Sanger Cincau', 30, 'Kreasi kopi sanger dengan tambahan cincau segar, unik dan menyegarkan.', 7.7, 22000, 150),
-('Hazelnut Latte', 31, 'Latte lembut dengan aroma kacang hazelnut yang manis dan harum.', 6.7, 22000, 200),
-('Salted Caramel Latte', 32, 'Perpaduan rasa manis caramel dan gurih asin dalam latte yang creamy.', 8, 22000, 200),
-('Caramel Latte', 33, 'Latte klasik dengan sirup caramel yang manis dan aroma menggoda.', 7, 22000, 200),
-('Vanila Latte', 34, 'Latte lembut dengan sentuhan aroma vanila yang manis dan menenangkan.', 7, 22000, 200),
-('Avocado Coffe', 35, 'Perpaduan unik kopi dan alpukat yang creamy dan lezat.', 7, 20000, 200),
-('Es Kopi Aren', 36, 'Kopi susu dingin dengan gula aren alami yang memberikan rasa manis khas Nusantara.', 7, 20000, 200),
-('Coffe Mocha', 37, 'Kopi dengan campuran cokelat premium yang menghasilkan rasa manis dan pahit seimbang.', 7, 20000, 200),
-('Chicken Katsu', 38, 'Ayam katsu renyah disajikan dengan nasi hangat dan saus khas Jepang.', 7, 55000, 200),
-('Chicken Steak', 39, 'Steak ayam panggang dengan saus lada hitam gurih dan sayuran pelengkap.', 7, 55000, 200),
-('Spaghetti Aglio Alio', 40, 'Spaghetti sederhana dengan bawang putih, minyak zaitun, dan cabai yang menggugah selera.', 7, 35000, 100),
-('Spaghetti Carbonara', 41, 'Spaghetti creamy dengan saus keju, susu, dan potongan daging asap.', 7, 37000, 120),
-('Tempe Goreng', 42, 'Tempe goreng renyah khas Indonesia, gurih dan cocok sebagai camilan.', 7, 15000, 100),
-('Risol Mayo', 43, 'Risol isi daging dan mayones lembut, digoreng hingga keemasan.', 7, 15000, 120),
-('Tahu Isi', 44, 'Tahu goreng berisi sayuran segar yang gurih dan renyah.', 7, 15000, 100),
-('Tahu Cabe Garam', 45, 'Tahu goreng garing dengan taburan cabai dan garam yang pedas gurih.', 7, 17000, 50),
-('Ketoprak', 46, 'Hidangan khas Betawi berisi lontong, tahu, bihun, dan bumbu kacang gurih.', 7, 18000, 100),
-('Bakwan Krispi', 47, 'Bakwan sayur goreng dengan tekstur renyah dan rasa gurih menggoda.', 7, 17000, 100),
-('Bakwan Bumbu Kacang', 48, 'Bakwan disajikan dengan siraman saus kacang pedas manis khas Indonesia.', 7, 20000, 130),
-('Kentang Goreng', 49, 'Kentang goreng renyah di luar dan lembut di dalam, disajikan dengan saus pilihan.', 7, 20000, 100),
-('Rujak Colek', 50, 'Campuran buah segar dengan sambal rujak pedas manis khas tradisional.', 8, 20000, 130),
-('Siomay', 51, 'Siomay ikan kukus disajikan dengan bumbu kacang gurih dan sambal.', 7, 35000, 100),
-('Pempek', 52, 'Pempek Palembang autentik dengan kuah cuko pedas asam manis.', 7, 36000, 100),
-('Tortila', 53, 'Tortila isi ayam dan sayuran segar, disajikan dengan saus spesial.', 8, 37000, 120);
+('tea', 'tea', 'Teh hitam klasik dengan aroma lembut dan rasa yang menenangkan, cocok untuk dinikmati hangat maupun dingin.', 0, 10000, 200),
+('Green Tea', 'green-tea', 'Teh hijau segar dengan rasa alami yang khas dan kaya antioksidan, memberikan sensasi sehat di setiap tegukan.', 0, 12000, 200),
+('lemon Tea', 'lemon-tea', 'Perpaduan teh hitam dengan perasan lemon segar yang menghasilkan cita rasa asam manis menyegarkan.', 0, 15000, 100),
+('Lychee Tea', 'lychee-tea', 'Teh berpadu rasa leci yang manis dan harum, memberikan sensasi tropis yang nikmat dan ringan.', 0, 18000, 150),
+('Teh Krisan', 'teh-krisan', 'Minuman teh bunga krisan alami dengan aroma khas dan efek menenangkan, cocok untuk relaksasi.', 0, 18000, 100),
+('Peach Tea', 'peach-tea', 'Teh segar dengan rasa buah persik manis dan lembut, sempurna untuk menemani waktu santai.', 0, 20000, 50),
+('Taro', 'taro', 'Minuman taro lembut dengan warna ungu menggoda dan rasa manis khas umbi talas.', 0, 20000, 100),
+('Matcha', 'matcha', 'Minuman matcha premium dengan rasa teh hijau yang kuat dan creamy, khas Jepang.', 0, 20000, 100),
+('Chocolate', 'chocolate', 'Cokelat kental dan lembut dengan perpaduan manis dan pahit yang pas di lidah.', 0, 20000, 200),
+('Red Velvet', 'red-velvet', 'Minuman red velvet creamy dengan aroma vanila dan cokelat yang elegan.', 0, 20000, 200),
+('Milo', 'milo', 'Minuman cokelat malt klasik yang disukai semua usia, nikmat disajikan dingin atau hangat.', 0, 20000, 100),
+('Green Lime', 'green-lime', 'Minuman jeruk nipis hijau yang segar dan asam, cocok untuk menghilangkan dahaga.', 0, 15000, 100),
+('Honey Lime', 'honey-lime', 'Perpaduan madu alami dan jeruk nipis yang menciptakan rasa manis-asam menyegarkan.', 0, 15000, 100),
+('Jus Semangka', 'jus-semangka', 'Jus semangka segar dengan rasa manis alami, kaya vitamin dan sangat menyegarkan.', 0, 15000, 150),
+('Jus Jeruk', 'jus-jeruk', 'Jus jeruk segar alami kaya vitamin C untuk menjaga daya tahan tubuh.', 0, 15000, 100),
+('Melon Squah', 'melon-squah', 'Minuman soda melon yang manis dan berbuih lembut, menyegarkan di setiap tegukan.', 0, 18000, 100),
+('Strawberry Squash', 'strawberry-squash', 'Minuman soda stroberi dengan rasa manis-asam dan warna merah menggoda.', 0, 18000, 120),
+('Mint Lemonade', 'mint-lemonade', 'Perpaduan jeruk lemon dan daun mint segar yang memberikan sensasi dingin dan segar.', 0, 18000, 130),
+('Regular Mojito', 'regular-mojito', 'Minuman mojito klasik dengan rasa mint dan lemon yang menyegarkan tanpa alkohol.', 0, 20000, 100),
+('Kiwi Mojito', 'kiwi-mojito', 'Mojito kiwi dengan rasa manis-asam segar dan aroma buah yang khas.', 0, 20000, 110),
+('Strawberry Mojito', 'strawberry-mojito', 'Mojito stroberi yang manis dan segar, cocok untuk pecinta rasa buah segar.', 0, 20000, 100),
+('Blast Berry', 'blast-berry', 'Minuman campuran berbagai buah beri segar dengan rasa manis dan asam seimbang.', 0, 25000, 100),
+('Blast Kiwi', 'blast-kiwi', 'Campuran buah kiwi segar dengan soda lembut yang menyegarkan tenggorokan.', 0, 25000, 120),
+('Espresso', 'espresso', 'Kopi espresso dengan cita rasa kuat dan aroma khas biji kopi pilihan.', 0, 13000, 100),
+('Americano', 'americano', 'Espresso yang dicampur air panas, menghasilkan rasa kopi yang ringan namun berkarakter.', 0, 18000, 200),
+('Coffe Latte', 'coffe-latte', 'Kombinasi espresso dan susu steamed lembut yang menciptakan rasa creamy dan seimbang.', 0, 18000, 100),
+('Cappucino', 'cappucino', 'Perpaduan espresso, susu, dan foam lembut dengan cita rasa kopi yang kaya.', 0, 18000, 120),
+('Mochacita', 'mochacita', 'Kombinasi kopi, cokelat, dan susu yang menghadirkan rasa manis pahit sempurna.', 0, 18000, 100),
+('Sanger Espresso', 'sanger-espresso', 'Espresso khas Aceh dengan tambahan susu kental manis yang menambah kekayaan rasa.', 0, 20000, 100),
+('Sanger Cincau', 'sanger-cincau', 'Kreasi kopi sanger dengan tambahan cincau segar, unik dan menyegarkan.', 0, 22000, 150),
+('Hazelnut Latte', 'hazelnut-latte', 'Latte lembut dengan aroma kacang hazelnut yang manis dan harum.', 0, 22000, 200),
+('Salted Caramel Latte', 'salted-caramel-latte', 'Perpaduan rasa manis caramel dan gurih asin dalam latte yang creamy.', 0, 22000, 200),
+('Caramel Latte', 'caramel-latte', 'Latte klasik dengan sirup caramel yang manis dan aroma menggoda.', 0, 22000, 200),
+('Vanila Latte', 'vanila-latte', 'Latte lembut dengan sentuhan aroma vanila yang manis dan menenangkan.', 0, 22000, 200),
+('Avocado Coffe', 'avocado-coffe', 'Perpaduan unik kopi dan alpukat yang creamy dan lezat.', 0, 20000, 200),
+('Es Kopi Aren', 'es-kopi-aren', 'Kopi susu dingin dengan gula aren alami yang memberikan rasa manis khas Nusantara.', 0, 20000, 200),
+('Coffe Mocha', 'coffe-mocha', 'Kopi dengan campuran cokelat premium yang menghasilkan rasa manis dan pahit seimbang.', 0, 20000, 200),
+('Chicken Katsu', 'chicken-katsu', 'Ayam katsu renyah disajikan dengan nasi hangat dan saus khas Jepang.', 0, 55000, 200),
+('Chicken Steak', 'chicken-steak', 'Steak ayam panggang dengan saus lada hitam gurih dan sayuran pelengkap.', 0, 55000, 200),
+('Spaghetti Aglio Alio', 'spaghetti-aglio-alio', 'Spaghetti sederhana dengan bawang putih, minyak zaitun, dan cabai yang menggugah selera.', 0, 35000, 100),
+('Spaghetti Carbonara', 'spaghetti-carbonara', 'Spaghetti creamy dengan saus keju, susu, dan potongan daging asap.', 0, 37000, 120),
+('Tempe Goreng', 'tempe-goreng', 'Tempe goreng renyah khas Indonesia, gurih dan cocok sebagai camilan.', 0, 15000, 100),
+('Risol Mayo', 'risol-mayo', 'Risol isi daging dan mayones lembut, digoreng hingga keemasan.', 0, 15000, 120),
+('Tahu Isi', 'tahu-isi', 'Tahu goreng berisi sayuran segar yang gurih dan renyah.', 0, 15000, 100),
+('Tahu Cabe Garam', 'tahu-cabe-garam', 'Tahu goreng garing dengan taburan cabai dan garam yang pedas gurih.', 0, 17000, 50),
+('Ketoprak', 'ketoprak', 'Hidangan khas Betawi berisi lontong, tahu, bihun, dan bumbu kacang gurih.', 0, 18000, 100),
+('Bakwan Krispi', 'bakwan-krispi', 'Bakwan sayur goreng dengan tekstur renyah dan rasa gurih menggoda.', 0, 17000, 100),
+('Bakwan Bumbu Kacang', 'bakwan-bumbu-kacang', 'Bakwan disajikan dengan siraman saus kacang pedas manis khas Indonesia.', 0, 20000, 130),
+('Kentang Goreng', 'kentang-goreng', 'Kentang goreng renyah di luar dan lembut di dalam, disajikan dengan saus pilihan.', 0, 20000, 100),
+('Rujak Colek', 'rujak-colek', 'Campuran buah segar dengan sambal rujak pedas manis khas tradisional.', 0, 20000, 130),
+('Siomay', 'siomay', 'Siomay ikan kukus disajikan dengan bumbu kacang gurih dan sambal.', 0, 35000, 100),
+('Pempek', 'pempek', 'Pempek Palembang autentik dengan kuah cuko pedas asam manis.', 0, 36000, 100),
+('Tortila', 'tortila', 'Tortila isi ayam dan sayuran segar, disajikan dengan saus spesial.', 0, 37000, 120);
+
+
+---  INSERT PRODUCT GALLERY  ---
+INSERT INTO product_gallery (id_product, url, position, is_primary) VALUES 
+(1, 'https://domf5oio6qrcr.cloudfront.net/medialibrary/8468/Tea.jpg', 0, TRUE),
+(2, 'https://dcostseafood.id/wp-content/uploads/2021/12/ICED-GREEN-TEA.jpg', 0, TRUE),
+(3, 'https://shwetainthekitchen.com/wp-content/uploads/2023/07/lemon-iced-tea.jpg', 0, TRUE),
+(4, 'https://dcostseafood.id/wp-content/uploads/2021/12/LYCHEE-TEA-1.jpg', 0, TRUE),
+(5, 'https://res.cloudinary.com/dk0z4ums3/image/upload/v1687161948/attached_image/chrysanthemum-tea-inilah-7-manfaatnya-bagi-kesehatan.jpg', 0, TRUE),
+(6, 'https://www.eatingwell.com/thmb/S4mEBHCn_c3y0U17S9dWEubvW1Y=/1500x0/filters:no_upscale():max_bytes(150000):strip_icc()/peach-iced-tea-hero-1x1-15009_preview_maxWidth_4000_maxHeight_4000_ppi_300_quality_100-0d9f432284a447fc9151868c5acf6c7e.jpg', 0, TRUE),
+(7, 'https://i.pinimg.com/474x/81/66/d0/8166d03b7c6ad46aebe443ff1a09b79f.jpg', 0, TRUE),
+(8, 'https://cdn.loveandlemons.com/wp-content/uploads/2023/06/iced-matcha-latte.jpg', 0, TRUE),
+(9, 'https://www.queensleeappetit.com/wp-content/uploads/2018/02/chocolate-lovers-hot-chocolate-recipe-via-queensleeappetit.com-5.jpg', 0, TRUE),
+(10, 'https://encrypted-tbn0.gstatic.com/images?q=tbn:ANd9GcR6RJTe4dNR4lrFDmIKIRFj5an4O47KsglEMQ&s', 0, TRUE),
+(11, 'https://www.siftandsimmer.com/wp-content/uploads/2022/11/milo-dinosaur4.jpg', 0, TRUE),
+(12, 'https://www.acouplecooks.com/wp-content/uploads/2020/12/Green-Drinks-053.jpg', 0, TRUE),
+(13, 'https://thetoastykitchen.com/wp-content/uploads/2021/02/tall-glass-of-limeade-with-lime-slice-3.jpg', 0, TRUE),
+(14, 'https://i0.wp.com/nasgorking.com/wp-content/uploads/2025/06/putra-indonesia-96-scaled.webp?fit=1920%2C2560&ssl=1', 0, TRUE),
+(15, 'https://img.merahputih.com/media/70/62/2d/70622dde196f15925929048a4f00f1c3.jpg', 0, TRUE),
+(16, 'https://cdn.yummy.co.id/content-images/images/20210731/Fzd3L5vc5sCw0ssnHjo5gDvbGMfDl3Sp-31363237373138303531d41d8cd98f00b204e9800998ecf8427e.jpg?x-oss-process=image/resize,w_388,h_388,m_fixed,x-oss-process=image/format,webp', 0, TRUE),
+(17, 'https://encrypted-tbn0.gstatic.com/images?q=tbn:ANd9GcR9rZX-pfVO4EOlhZ20d-hCHpdMnkFy4Q0s_w&s', 0, TRUE),
+(18, 'https://www.proportionalplate.com/wp-content/uploads/2024/04/Mint-LemonadeMay-07-2025-2.jpg', 0, TRUE),
+(19, 'https://cdnimg.webstaurantstore.com/uploads/blog/2016/7/regular_mojito.jpg', 0, TRUE),
+(20, 'https://ameessavorydish.com/wp-content/uploads/2012/03/Kiwi-skinny-mojito-feature.jpg', 0, TRUE),
+(21, 'https://www.mapleandmango.com/wp-content/uploads/2020/06/strawberry-mojito-mocktail-feature.jpg', 0, TRUE),
+(22, 'https://images.mrcook.app/recipe-image/01932926-d863-7a09-ab5f-0c1490eefa04?cacheKey=U3VuLCAxMiBKYW4gMjAyNSAwMzozODoyNCBHTVQ=', 0, TRUE),
+(23, 'https://www.lucismorsels.com/wp-content/uploads/2020/02/SQ-Kiwi-Spritzer.jpg', 0, TRUE),
+(24, 'https://www.thespruceeats.com/thmb/HJrjMfXdLGHbgMhnM0fMkDx9XPQ=/1500x0/filters:no_upscale():max_bytes(150000):strip_icc()/what-is-espresso-765702-hero-03_cropped-ffbc0c7cf45a46ff846843040c8f370c.jpg', 0, TRUE),
+(25, 'https://myeverydaytable.com/wp-content/uploads/AmericanoHotandIced-3.jpg', 0, TRUE),
+(26, 'https://d1r9hss9q19p18.cloudfront.net/uploads/2015/07/shutterstock_223511062.jpg', 0, TRUE),
+(27, 'https://www.sugacoffee.id/wp-content/uploads/2024/04/21.-Cappucino-Pengertian-Proses-dan-Teknik-Peyeduhannya-Suga-Coffee-2.jpg', 0, TRUE),
+(28, 'https://www.bitkaorigin.com/assets/myback/js/elFinder/files/bitka-mocha-latte-kopi-italia-perpaduan-espresso-susu-dan-cokelat-yang-nikmat-image.jpg', 0, TRUE),
+(29, 'https://awsimages.detik.net.id/community/media/visual/2025/04/08/penjelasan-espresso-kafe-dan-rumah-beda-rasa-1744100119310_169.webp?w=1200', 0, TRUE),
+(30, 'https://www.astronauts.id/blog/wp-content/uploads/2023/03/Resep-Es-Cincau-Untuk-Bikin-Buka-Puasa-Jadi-Tambah-Nikmat-1024x683.jpg', 0, TRUE),
+(31, 'https://img-global.cpcdn.com/recipes/35ef6f9212576405/680x781cq80/vanilla-hazelnut-latte-foto-resep-utama.jpg', 0, TRUE),
+(32, 'https://images.ctfassets.net/v601h1fyjgba/2L61TpcCFqcNMOtgXklc4s/732a70a58d6dfc25e24378c67900ed16/15697_Keurig_CafeCreations_Salted_Caramel_Latte_Hi.jpg', 0, TRUE),
+(33, 'https://www.forkinthekitchen.com/wp-content/uploads/2022/06/220518.homemade.caramel.latte-6630.jpg', 0, TRUE),
+(34, 'https://www.forkinthekitchen.com/wp-content/uploads/2022/08/220629.iced_.latte_.vanilla-9009.jpg', 0, TRUE),
+(35, 'https://www.bitkaorigin.com/assets/myback/js/elFinder/files/BITKA-Avocado-Coffee-10-Pengertian-Sejarah-Manfaat-dan-Cara-Membuatnya-Img3.jpg', 0, TRUE),
+(36, 'https://klikwartaku.com/wp-content/uploads/2025/09/Kopi-Es-gula-Aren.jpg', 0, TRUE),
+(37, 'https://www.folgerscoffee.com/folgers/recipes/_Hero%20Images/Detail%20Pages/5598/image-thumb__5598__schema_image/MochaIced-hero.58f3878d.jpg', 0, TRUE),
+(38, 'https://takestwoeggs.com/wp-content/uploads/2022/11/Chicken-Katsu-Takestwoeggs-FINAL-Photography-sq.jpg', 0, TRUE),
+(39, 'https://muchbutter.com/wp-content/uploads/2023/08/Chicken-Steak-16-500x500.jpg', 0, TRUE),
+(40, 'https://awsimages.detik.net.id/community/media/visual/2021/03/19/spaghetti-aglio-olio_43.jpeg?w=600&q=90', 0, TRUE),
+(41, 'https://images.services.kitchenstories.io/z_bWPIhhM6qs38B0E46CRaYs81Q=/3840x0/filters:quality(85)/images.kitchenstories.io/wagtailOriginalImages/R2568-photo-final-_0.jpg', 0, TRUE),
+(42, 'https://www.dapurkobe.co.id/wp-content/uploads/tempe-goreng-kriuk-ala-tepung-kobe.jpg', 0, TRUE),
+(43, 'https://media.indozone.id/crop/0x0:0x0/images/2025/06/30/6wvBbkRZrLWj0r8GOi3v2Bjz4FsdWBBmCCiTjQAj.jpg', 0, TRUE),
+(44, 'https://static.promediateknologi.id/crop/0x0:0x0/750x500/webp/photo/p1/1067/2024/11/05/foto-tahu-berontak-411400167.jpg', 0, TRUE),
+(45, 'https://asset.kompas.com/crops/7-qyrnHe6joJqVavA0xiK7TrDB0=/0x0:1000x667/1200x800/data/photo/2022/08/15/62f997825b557.jpeg', 0, TRUE),
+(46, 'https://asset.kompas.com/crops/olPvvorGLd5nZkgMNcT_qhVd9Ng=/0x76:1000x743/1200x800/data/photo/2024/05/10/663dbb1fcecb8.jpg', 0, TRUE),
+(47, 'https://asset-a.grid.id/crop/0x0:0x0/x/photo/2021/02/17/bakwan-crispyjpg-20210217122049.jpg', 0, TRUE),
+(48, 'https://cdn.rri.co.id/berita/Pusat_Pemberitaan/o/1740911140320-80372-bakwan-sayur-renyah-dan-gurih-pakai-sambal-kacang/pu768dac4chrrhm.jpeg', 0, TRUE),
+(49, 'https://asset.kompas.com/crops/henUp87hYyOPkQfTpFVrCykP5rg=/0x0:800x533/1200x800/data/photo/2018/08/02/1483887855.jpg', 0, TRUE),
+(50, 'https://img-global.cpcdn.com/steps/16999565a8178da1/400x400cq80/photo.jpg', 0, TRUE),
+(51, 'https://image.idn.media/post/20250113/screenshot-2025-0113-2130552-579c18e74dce0f8e75e98443de8e9b79-ebe9761a87d5a310181918a6e331d2db.jpg', 0, TRUE),
+(52, 'https://media.zcreators.id/crop/0x0:0x0/750x500/photo/indizone/2020/12/10/d5sBOBq/resep-pempek-dos-tanpa-ikan-mudah-dan-dijamin-enak71.jpg', 0, TRUE),
+(53, 'https://nirvana-store-assets.s3.ap-southeast-1.amazonaws.com/Tortilla_wrap_1_d8e18b338a.png', 0, TRUE);
 
 
 INSERT INTO product_categories (id_product, id_categories) 
@@ -302,5 +301,19 @@ INSERT INTO size_product (id_product, id_size) VALUES
 (52, 1),(52, 2),(52, 3),
 (53, 1),(53, 2),(53, 3);
 
+---  INSERT PRODUCT SKUS  ---
+-- one sku per size x variant combination of every product, same split of stock as migration 000016
+INSERT INTO product_skus (id_product, id_size, id_variant, stock)
+SELECT c.id_product, c.id_size, c.id_variant,
+       c.stock / c.total + CASE WHEN c.rn <= c.stock % c.total THEN 1 ELSE 0 END
+FROM (
+    SELECT p.id AS id_product, sp.id_size, vp.id_variant, GREATEST(p.stock, 0) AS stock,
+           COUNT(*) OVER (PARTITION BY p.id) AS total,
+           ROW_NUMBER() OVER (PARTITION BY p.id ORDER BY sp.id_size NULLS FIRST, vp.id_variant NULLS FIRST) AS rn
+    FROM product p
+    LEFT JOIN (SELECT DISTINCT id_product, id_size FROM size_product) sp ON sp.id_product = p.id
+    LEFT JOIN (SELECT DISTINCT id_product, id_variant FROM variant_product) vp ON vp.id_product = p.id
+) c
+ON CONFLICT (id_product, COALESCE(id_size, 0), COALESCE(id_variant, 0)) DO NOTHING;
 
 INSERT INTO status (name) VALUES ('on progres'),('pending'),('done');
\ No newline at end of file
@@ -0,0 +1,249 @@
+package controllers
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/federus1105/koda-b4-backend/internals/models"
+	"github.com/federus1105/koda-b4-backend/internals/pkg/libs"
+	"github.com/federus1105/koda-b4-backend/internals/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+)
+
+// ExportAccount godoc
+// @Summary 	Export personal data
+// @Description Profile, addresses, cart and order history of the logged-in user. Use format=zip to download a bundle with one JSON file per section
+// @Tags 		Profile
+// @Produce 	json
+// @Produce 	application/zip
+// @Param 		format 	query 	string 	false 	"json (default) or zip"
+// @Success 	200 {object} 	models.ResponseSucces{result=models.AccountExport}
+// @Failure 	400 {object} 	models.Response
+// @Security 	BearerAuth
+// @Router 		/profile/export [get]
+func ExportAccount(ctx *gin.Context, db *pgxpool.Pool) {
+	format := ctx.DefaultQuery("format", "json")
+	if format != "json" && format != "zip" {
+		ctx.JSON(400, models.Response{
+			Success: false,
+			Message: "format must be json or zip",
+		})
+		return
+	}
+
+	user, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	// --- ORDER DETAIL IS READ ONE BY ONE, SO GIVE IT MORE TIME ---
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	export, err := models.ExportAccount(ctxTimeout, db, user.ID)
+	if err != nil {
+		respondAccountError(ctx, err)
+		return
+	}
+
+	if format == "json" {
+		ctx.JSON(200, models.ResponseSucces{
+			Success: true,
+			Message: "Success",
+			Result:  export,
+		})
+		return
+	}
+
+	bundle, err := accountExportZip(export)
+	if err != nil {
+		respondAccountError(ctx, err)
+		return
+	}
+	filename := fmt.Sprintf("account-%d-%s.zip", user.ID, export.ExportedAt.Format("20060102"))
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	ctx.Data(200, "application/zip", bundle)
+}
+
+// --- ONE JSON FILE PER SECTION ---
+func accountExportZip(export models.AccountExport) ([]byte, error) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+
+	files := []struct {
+		name string
+		data any
+	}{
+		{"profile.json", export.Profile},
+		{"addresses.json", export.Addresses},
+		{"cart.json", export.Cart},
+		{"orders.json", export.Orders},
+	}
+	for _, file := range files {
+		content, err := json.MarshalIndent(file.data, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		w, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     file.name,
+			Method:   zip.Deflate,
+			Modified: export.ExportedAt,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(content); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// DeleteAccount godoc
+// @Summary 	Delete own account
+// @Description Anonymises the account of the logged-in user and logs out every device. Orders are kept for accounting without personal data. Accounts created with Google sign-in set a password with forgot password first
+// @Tags 		Profile
+// @Param 		input 	body 	models.ReqDeleteAccount 	true 	"Current password"
+// @Success 	200 {object} 	models.Response
+// @Failure 	400 {object} 	models.Response
+// @Failure 	401 {object} 	models.Response "Wrong password"
+// @Security 	BearerAuth
+// @Router 		/profile [delete]
+func DeleteAccount(ctx *gin.Context, db *pgxpool.Pool, rdb *redis.Client) {
+	var input models.ReqDeleteAccount
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(400, models.Response{
+			Success: false,
+			Message: "password is required",
+		})
+		return
+	}
+
+	user, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// --- DELETION CAN'T BE UNDONE, CONFIRM WITH PASSWORD ---
+	hashed, err := models.GetAccountPassword(ctxTimeout, db, user.ID)
+	if err != nil {
+		respondAccountError(ctx, err)
+		return
+	}
+	if match, err := libs.VerifyPassword(input.Password, hashed); err != nil || !match {
+		ctx.JSON(401, models.Response{
+			Success: false,
+			Message: "wrong password",
+		})
+		return
+	}
+
+	if err := deleteAccount(ctxTimeout, db, rdb, user.ID); err != nil {
+		respondAccountError(ctx, err)
+		return
+	}
+
+	ctx.JSON(200, models.Response{
+		Success: true,
+		Message: "account deleted",
+	})
+}
+
+// AdminDeleteUser godoc
+// @Summary 	Delete user account
+// @Description Anonymises the account and logs out every device of the user. Orders are kept for accounting without personal data
+// @Tags 		Users
+// @Param 		id 	path 	int 	true 	"User ID"
+// @Success 	200 {object} 	models.Response
+// @Failure 	400 {object} 	models.Response "Own account must be deleted from profile"
+// @Failure 	404 {object} 	models.Response
+// @Failure 	409 {object} 	models.Response "Account is already deleted"
+// @Security 	BearerAuth
+// @Security 	ApiKeyAuth
+// @Router 		/admin/user/{id} [delete]
+func AdminDeleteUser(ctx *gin.Context, db *pgxpool.Pool, rdb *redis.Client) {
+	userID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(404, models.Response{
+			Success: false,
+			Message: "Invalid user id",
+		})
+		return
+	}
+
+	user, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+	if !user.IsAPIKey() && user.ID == userID {
+		ctx.JSON(400, models.Response{
+			Success: false,
+			Message: "use DELETE /profile to delete your own account",
+		})
+		return
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := deleteAccount(ctxTimeout, db, rdb, userID); err != nil {
+		respondAccountError(ctx, err)
+		return
+	}
+
+	ctx.JSON(200, models.Response{
+		Success: true,
+		Message: "account deleted",
+	})
+}
+
+// --- ANONYMISE, THEN MAKE SURE NOTHING ISSUED BEFORE CAN STILL LOG IN ---
+func deleteAccount(ctx context.Context, db *pgxpool.Pool, rdb *redis.Client, userID int) error {
+	secret, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return err
+	}
+	unusable, err := libs.HashPassword(secret)
+	if err != nil {
+		return err
+	}
+
+	if err := models.DeleteAccount(ctx, db, rdb, userID, unusable); err != nil {
+		return err
+	}
+
+	if _, err := models.RevokeOtherSessions(ctx, rdb, userID, ""); err != nil {
+		fmt.Println("Failed to revoke sessions of deleted account.\nCause: ", err)
+	}
+	if err := models.RevokeUserTokens(ctx, rdb, userID); err != nil {
+		fmt.Println("Failed to revoke pending links of deleted account.\nCause: ", err)
+	}
+	return nil
+}
+
+func respondAccountError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, models.ErrAccountNotFound):
+		ctx.JSON(404, models.Response{Success: false, Message: err.Error()})
+	case errors.Is(err, models.ErrAccountDeleted):
+		ctx.JSON(409, models.Response{Success: false, Message: err.Error()})
+	default:
+		fmt.Println("Internal Server Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{Success: false, Message: "internal server error"})
+	}
+}
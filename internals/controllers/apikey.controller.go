@@ -0,0 +1,140 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/federus1105/koda-b4-backend/internals/models"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+)
+
+// GetAPIKeys godoc
+// @Summary 	List API keys
+// @Tags 		Admin API Keys
+// @Success 	200 {object} 	models.ResponseSucces{result=[]models.APIKey}
+// @Security 	BearerAuth
+// @Router 		/admin/api-keys [get]
+func GetAPIKeys(ctx *gin.Context, db *pgxpool.Pool) {
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	keys, err := models.GetAPIKeys(ctxTimeout, db)
+	if err != nil {
+		fmt.Println("Internal Server Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{
+			Success: false,
+			Message: "internal server error",
+		})
+		return
+	}
+
+	ctx.JSON(200, models.ResponseSucces{
+		Success: true,
+		Message: "Success",
+		Result:  keys,
+	})
+}
+
+// CreateAPIKey godoc
+// @Summary 	Create API key
+// @Description Scopes are permission codes, e.g. orders:read. The key is shown only once, send it in the X-API-Key header
+// @Tags 		Admin API Keys
+// @Param 		request body 	models.ReqCreateAPIKey  true 	"API key"
+// @Success 	201 {object} 	models.ResponseSucces
+// @Failure 	403 {object} 	models.Response "Scope is not granted to your role"
+// @Security 	BearerAuth
+// @Router 		/admin/api-keys [post]
+func CreateAPIKey(ctx *gin.Context, db *pgxpool.Pool, rdb *redis.Client) {
+	var req models.ReqCreateAPIKey
+	if !bindJSON(ctx, &req) {
+		return
+	}
+
+	user, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	granted, err := models.GetRolePermissions(ctxTimeout, db, rdb, user.Role)
+	if err != nil {
+		fmt.Println("Internal Server Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{
+			Success: false,
+			Message: "internal server error",
+		})
+		return
+	}
+
+	key, raw, err := models.CreateAPIKey(ctxTimeout, db, user.ID, granted, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrAPIKeyScope):
+			ctx.JSON(403, models.Response{Success: false, Message: err.Error()})
+		case errors.Is(err, models.ErrPermissionNotFound):
+			ctx.JSON(400, models.Response{Success: false, Message: err.Error()})
+		default:
+			ctx.JSON(500, models.Response{Success: false, Message: "internal server error"})
+		}
+		return
+	}
+
+	ctx.JSON(201, models.ResponseSucces{
+		Success: true,
+		Message: "api key created, store it now because it won't be shown again",
+		Result: gin.H{
+			"key":     raw,
+			"api_key": key,
+		},
+	})
+}
+
+// RevokeAPIKey godoc
+// @Summary 	Revoke API key
+// @Tags 		Admin API Keys
+// @Param 		id 	path 	int 	true 	"API key ID"
+// @Success 	200 {object} 	models.Response
+// @Failure 	404 {object} 	models.Response
+// @Security 	BearerAuth
+// @Router 		/admin/api-keys/{id} [delete]
+func RevokeAPIKey(ctx *gin.Context, db *pgxpool.Pool, rdb *redis.Client) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(404, models.Response{
+			Success: false,
+			Message: "Invalid api key id",
+		})
+		return
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := models.RevokeAPIKey(ctxTimeout, db, rdb, id); err != nil {
+		if errors.Is(err, models.ErrAPIKeyNotFound) {
+			ctx.JSON(404, models.Response{
+				Success: false,
+				Message: err.Error(),
+			})
+			return
+		}
+		fmt.Println("Internal Server Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{
+			Success: false,
+			Message: "internal server error",
+		})
+		return
+	}
+
+	ctx.JSON(200, models.Response{
+		Success: true,
+		Message: "api key revoked",
+	})
+}
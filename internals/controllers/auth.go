@@ -4,12 +4,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/federus1105/koda-b4-backend/internals/models"
+	"github.com/federus1105/koda-b4-backend/internals/pkg/auth"
 	"github.com/federus1105/koda-b4-backend/internals/pkg/libs"
 	"github.com/federus1105/koda-b4-backend/internals/pkg/utils"
 	"github.com/gin-gonic/gin"
@@ -24,8 +27,9 @@ import (
 // @Tags 		Auth
 // @Param 		Register body 	utils.RegisterRequest  true 	"Register Info"
 // @Success 	200 {object} 	models.ResponseSucces
+// @Failure 	409 {object} 	models.Response "Email is already registered"
 // @Router 		/auth/register [post]
-func Register(ctx *gin.Context, db *pgxpool.Pool) {
+func Register(ctx *gin.Context, db *pgxpool.Pool, rdb *redis.Client) {
 	var req models.AuthRegister
 	// --- VALIDATION ---
 	if err := ctx.ShouldBindJSON(&req); err != nil {
@@ -64,6 +68,13 @@ func Register(ctx *gin.Context, db *pgxpool.Pool) {
 	defer cancel()
 	newUser, err := models.Register(ctxTimeout, db, hashed, req)
 	if err != nil {
+		if errors.Is(err, models.ErrEmailTaken) {
+			ctx.JSON(409, models.Response{
+				Success: false,
+				Message: err.Error(),
+			})
+			return
+		}
 		ctx.JSON(500, models.Response{
 			Success: false,
 			Message: "internal server error",
@@ -71,9 +82,14 @@ func Register(ctx *gin.Context, db *pgxpool.Pool) {
 		return
 	}
 
+	// --- SEND VERIFICATION EMAIL ---
+	if err := sendVerificationEmail(ctxTimeout, rdb, newUser.Id, newUser.Email); err != nil {
+		fmt.Println("Failed to send verification email:", err)
+	}
+
 	ctx.JSON(200, models.ResponseSucces{
 		Success: true,
-		Message: "Register Succesfully",
+		Message: "Register Succesfully, please check your email to verify your account",
 		Result: gin.H{
 			"id":       newUser.Id,
 			"fullname": newUser.Fullname,
@@ -84,12 +100,14 @@ func Register(ctx *gin.Context, db *pgxpool.Pool) {
 
 // Login godoc
 // @Summary 	Login user
-// @Description Login user and get JWT token
+// @Description Login user and get JWT token.
+// @Description When two-factor authentication is enabled or required, a challenge token is returned instead, exchange it on /auth/login/2fa
 // @Tags 		Auth
 // @Param 		login body 		utils.LoginRequest  true 	"Login Info"
 // @Success 	200 {object} 	models.ResponseSucces
+// @Failure 	429 {object} 	models.Response "Too many failed attempts, see Retry-After header"
 // @Router 		/auth/login [post]
-func Login(ctx *gin.Context, db *pgxpool.Pool) {
+func Login(ctx *gin.Context, db *pgxpool.Pool, rdb *redis.Client) {
 	var req models.AuthLogin
 	// --- VALIDATION ---
 	if err := ctx.ShouldBindJSON(&req); err != nil {
@@ -116,9 +134,31 @@ func Login(ctx *gin.Context, db *pgxpool.Pool) {
 	// ---- LIMITS QUERY EXECUTION TIME ---
 	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
+
+	// --- CHECK LOCKOUT EMAIL AND IP ---
+	emailSubject := models.EmailSubject(req.Email)
+	ipSubject := models.IPSubject(ctx.ClientIP())
+	locked, err := models.GetLockout(ctxTimeout, rdb, models.ScopeLogin, emailSubject, ipSubject)
+	if err != nil {
+		fmt.Println("Redis Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{
+			Success: false,
+			Message: "internal server error",
+		})
+		return
+	}
+	if locked > 0 {
+		respondTooManyAttempts(ctx, locked)
+		return
+	}
+
 	user, err := models.Login(ctxTimeout, db, req.Email)
 	if err != nil {
 		if strings.Contains(err.Error(), "user not found") {
+			if locked := registerFailedLogin(ctxTimeout, rdb, emailSubject, ipSubject); locked > 0 {
+				respondTooManyAttempts(ctx, locked)
+				return
+			}
 			ctx.JSON(401, models.Response{
 				Success: false,
 				Message: "Nama atau Password salah",
@@ -135,6 +175,10 @@ func Login(ctx *gin.Context, db *pgxpool.Pool) {
 	// --- VERIFICATION HASH PASSWORD
 	ok, err := libs.VerifyPassword(req.Password, user.Password)
 	if err != nil || !ok {
+		if locked := registerFailedLogin(ctxTimeout, rdb, emailSubject, ipSubject); locked > 0 {
+			respondTooManyAttempts(ctx, locked)
+			return
+		}
 		ctx.JSON(401, models.Response{
 			Success: false,
 			Message: "invalid email or password",
@@ -142,9 +186,47 @@ func Login(ctx *gin.Context, db *pgxpool.Pool) {
 		return
 	}
 
-	// --- GENERATE JWT TOKEN
-	claims := libs.NewJWTClaims(user.Id, user.Role)
-	jwtToken, err := claims.GenToken()
+	// --- PASSWORD CORRECT, CLEAR FAILED ATTEMPTS OF THE EMAIL ---
+	if err := models.ResetAttempts(ctxTimeout, rdb, models.ScopeLogin, emailSubject); err != nil {
+		fmt.Println("Failed to reset login attempts:", err)
+	}
+
+	// --- EMAIL MUST BE VERIFIED ---
+	if user.VerifiedAt == nil {
+		ctx.JSON(403, models.Response{
+			Success: false,
+			Message: "email not verified, please check your inbox or request a new verification link",
+		})
+		return
+	}
+
+	finishLogin(ctx, ctxTimeout, db, rdb, user)
+}
+
+// --- ASK SECOND FACTOR OR ISSUE TOKENS FOR AUTHENTICATED USER ---
+func finishLogin(ctx *gin.Context, ctxTimeout context.Context, db *pgxpool.Pool, rdb *redis.Client, user models.AuthLogin) {
+	// --- SECOND FACTOR WHEN ENABLED OR REQUIRED FOR ROLE ---
+	required, err := models.IsTwoFactorRequired(ctxTimeout, db, user.Role)
+	if err != nil {
+		fmt.Println("Internal Server Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{
+			Success: false,
+			Message: "internal server error",
+		})
+		return
+	}
+	if user.TotpEnabled || required {
+		startTwoFactorChallenge(ctx, ctxTimeout, rdb, user)
+		return
+	}
+
+	issueLogin(ctx, ctxTimeout, rdb, user)
+}
+
+// --- ALL FACTORS ARE VERIFIED, RESPOND WITH TOKENS ---
+func issueLogin(ctx *gin.Context, ctxTimeout context.Context, rdb *redis.Client, user models.AuthLogin) {
+	// --- GENERATE JWT AND REFRESH TOKEN
+	tokens, err := generateTokens(ctx, ctxTimeout, rdb, user.Id, user.Role)
 	if err != nil {
 		fmt.Println("Internal Server Error.\nCause: ", err)
 		ctx.JSON(500, models.Response{
@@ -157,18 +239,382 @@ func Login(ctx *gin.Context, db *pgxpool.Pool) {
 	ctx.JSON(200, models.ResponseSucces{
 		Success: true,
 		Message: "login successful",
+		Result:  tokens,
+	})
+}
+
+// --- START SESSION, GENERATE ACCESS TOKEN AND REFRESH TOKEN OF THE SESSION ---
+func generateTokens(ctx *gin.Context, ctxTimeout context.Context, rdb *redis.Client, userID int, role string) (gin.H, error) {
+	sid, err := models.CreateSession(ctxTimeout, rdb, userID, sessionInfo(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := libs.NewJWTClaims(userID, role)
+	if err != nil {
+		return nil, err
+	}
+	claims.SessionID = sid
+	jwtToken, err := claims.GenToken()
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := models.IssueRefreshToken(ctxTimeout, rdb, userID, sid)
+	if err != nil {
+		return nil, err
+	}
+
+	return gin.H{
+		"token":         jwtToken,
+		"refresh_token": refreshToken,
+		"expires_in":    int(libs.AccessTokenTTL.Seconds()),
+	}, nil
+}
+
+// --- DEVICE NAME CAN BE SENT BY MOBILE APP ---
+func sessionInfo(ctx *gin.Context) models.SessionInfo {
+	userAgent := ctx.GetHeader("User-Agent")
+	device := strings.TrimSpace(ctx.GetHeader("X-Device-Name"))
+	if device == "" {
+		device = models.DeviceFromUserAgent(userAgent)
+	}
+	if len(device) > 100 {
+		device = device[:100]
+	}
+	if len(userAgent) > 255 {
+		userAgent = userAgent[:255]
+	}
+	return models.SessionInfo{
+		Device:    device,
+		UserAgent: userAgent,
+		IP:        ctx.ClientIP(),
+	}
+}
+
+// --- GET PRINCIPAL SET BY AUTHENTICATION MIDDLEWARE ---
+func currentUser(ctx *gin.Context) (auth.Identity, bool) {
+	user, ok := auth.Principal(ctx)
+	if !ok {
+		ctx.AbortWithStatusJSON(401, models.Response{
+			Success: false,
+			Message: "Please log in first",
+		})
+	}
+	return user, ok
+}
+
+// VerifyEmail godoc
+// @Summary Verify user email
+// @Description Confirm ownership of the email address using the token sent after registration
+// @Tags Auth
+// @Param input body models.ReqVerifyEmail true "Verification token"
+// @Success 200 {object} models.Response "Email verified successfully"
+// @Failure 400 {object} models.Response "Invalid or expired token"
+// @Router /auth/verify-email [post]
+func VerifyEmail(ctx *gin.Context, db *pgxpool.Pool, rdb *redis.Client) {
+	var input models.ReqVerifyEmail
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(400, models.Response{
+			Success: false,
+			Message: "token is required",
+		})
+		return
+	}
+
+	// --- GET USER ID FROM TOKEN ---
+	userIDStr, err := models.GetUserIDFromToken(ctx, rdb, models.VerifyEmailPrefix+input.Token)
+	if err != nil {
+		ctx.JSON(400, models.Response{
+			Success: false,
+			Message: "invalid or expired token",
+		})
+		return
+	}
+	userID, _ := strconv.Atoi(userIDStr)
+
+	// ---- LIMITS QUERY EXECUTION TIME ---
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := models.MarkEmailVerified(ctxTimeout, db, userID); err != nil {
+		fmt.Println("Failed to verify email:", err)
+		ctx.JSON(500, models.Response{
+			Success: false,
+			Message: "failed to verify email",
+		})
+		return
+	}
+
+	// --- DELETE TOKEN AFTER USED ---
+	models.DeleteVerifyToken(ctxTimeout, rdb, userID, input.Token)
+
+	ctx.JSON(200, models.Response{
+		Success: true,
+		Message: "email verified successfully",
+	})
+}
+
+// ResendVerification godoc
+// @Summary Resend verification email
+// @Description Send a new verification link, the previous link stops working. The response is the same whether or not the email is registered
+// @Tags Auth
+// @Param input body models.ReqResendVerification true "Email user"
+// @Success 200 {object} models.Response
+// @Router /auth/resend-verification [post]
+func ResendVerification(ctx *gin.Context, db *pgxpool.Pool, rdb *redis.Client) {
+	var input models.ReqResendVerification
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(400, models.Response{
+			Success: false,
+			Message: "invalid email",
+		})
+		return
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// --- ONLY UNVERIFIED ACCOUNT GET A NEW LINK ---
+	user, err := models.GetUserByEmail(ctxTimeout, db, input.Email)
+	if err == nil && user.VerifiedAt == nil {
+		if err := sendVerificationEmail(ctxTimeout, rdb, user.Id, user.Email); err != nil {
+			fmt.Println("Failed to send verification email:", err)
+		}
+	}
+
+	ctx.JSON(200, models.Response{
+		Success: true,
+		Message: "If the account exists and is not verified yet, a new verification link has been sent",
+	})
+}
+
+// --- GENERATE TOKEN AND SEND VERIFICATION EMAIL ---
+func sendVerificationEmail(ctx context.Context, rdb *redis.Client, userID int, email string) error {
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return err
+	}
+
+	if err := models.SaveVerifyToken(ctx, rdb, userID, token, 24*time.Hour); err != nil {
+		return err
+	}
+
+	verifyLink := fmt.Sprintf("%s?token=%s", os.Getenv("FRONTEND_VERIFY_URL"), url.QueryEscape(token))
+
+	// --- MESSAGE EMAIL ---
+	emailBody := fmt.Sprintf(`
+    <div style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
+        <h2 style="color:  #8B4513;">Verifikasi Email</h2>
+        <p>Halo,</p>
+        <p>Terima kasih telah mendaftar. Klik tombol berikut untuk memverifikasi email Anda:</p>
+        <p>
+            <a href="%s" style="display: inline-block; padding: 10px 20px; background-color:  #8B4513; color: #fff; text-decoration: none; border-radius: 5px;">
+                Verifikasi Email
+            </a>
+        </p>
+        <p>Link ini berlaku selama 24 jam. Jika Anda tidak merasa mendaftar, abaikan email ini.</p>
+        <p>Salam,<br/>Tim Senja Kopi kiri</p>
+    </div>
+`, verifyLink)
+
+	return utils.Send(utils.SendOptions{
+		To:         []string{email},
+		Subject:    "Verifikasi Email",
+		Body:       emailBody,
+		BodyIsHTML: true,
+	})
+}
+
+// --- COUNT FAILED LOGIN FOR EMAIL AND IP, RETURN LOCKOUT IF ANY ---
+func registerFailedLogin(ctx context.Context, rdb *redis.Client, emailSubject, ipSubject string) time.Duration {
+	lockEmail, err := models.RegisterAttempt(ctx, rdb, models.LoginEmailPolicy, emailSubject)
+	if err != nil {
+		fmt.Println("Failed to register attempt:", err)
+	}
+	lockIP, err := models.RegisterAttempt(ctx, rdb, models.LoginIPPolicy, ipSubject)
+	if err != nil {
+		fmt.Println("Failed to register attempt:", err)
+	}
+	return max(lockEmail, lockIP)
+}
+
+// --- RESPONSE 429 WITH RETRY-AFTER ---
+func respondTooManyAttempts(ctx *gin.Context, retryAfter time.Duration) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	ctx.Header("Retry-After", strconv.Itoa(seconds))
+	ctx.JSON(429, models.Response{
+		Success: false,
+		Message: fmt.Sprintf("too many attempts, please try again in %d seconds", seconds),
+	})
+}
+
+// RefreshToken godoc
+// @Summary Refresh access token
+// @Description Exchange a refresh token for a new access token. The refresh token is rotated on every call, reusing an old one revokes all tokens of that login
+// @Tags Auth
+// @Param input body models.ReqRefreshToken true "Refresh token"
+// @Success 200 {object} models.ResponseSucces
+// @Failure 401 {object} models.Response "Invalid, expired or reused refresh token"
+// @Router /auth/refresh [post]
+func RefreshToken(ctx *gin.Context, db *pgxpool.Pool, rdb *redis.Client) {
+	var input models.ReqRefreshToken
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(400, models.Response{
+			Success: false,
+			Message: "refresh_token is required",
+		})
+		return
+	}
+
+	// ---- LIMITS QUERY EXECUTION TIME ---
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// --- ROTATE REFRESH TOKEN ---
+	session, refreshToken, err := models.RotateRefreshToken(ctxTimeout, rdb, input.RefreshToken)
+	if err != nil {
+		if errors.Is(err, models.ErrRefreshTokenInvalid) || errors.Is(err, models.ErrRefreshTokenReused) {
+			ctx.JSON(401, models.Response{
+				Success: false,
+				Message: "Session expired, please log in again",
+			})
+			return
+		}
+		fmt.Println("Internal Server Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{
+			Success: false,
+			Message: "internal server error",
+		})
+		return
+	}
+
+	// --- ROLE MAY HAVE CHANGED SINCE LOGIN ---
+	user, err := models.GetUserByID(ctxTimeout, db, session.UserID)
+	if err != nil {
+		if strings.Contains(err.Error(), "user not found") {
+			models.RevokeRefreshFamily(ctxTimeout, rdb, session.Family)
+			ctx.JSON(401, models.Response{
+				Success: false,
+				Message: "Session expired, please log in again",
+			})
+			return
+		}
+		ctx.JSON(500, models.Response{
+			Success: false,
+			Message: "internal server error",
+		})
+		return
+	}
+
+	// --- SESSION STAYS ALIVE WHILE IT IS REFRESHED ---
+	if err := models.ExtendSession(ctxTimeout, rdb, user.Id, session.Family, sessionInfo(ctx)); err != nil {
+		if errors.Is(err, models.ErrSessionNotFound) {
+			models.RevokeRefreshFamily(ctxTimeout, rdb, session.Family)
+			ctx.JSON(401, models.Response{
+				Success: false,
+				Message: "Session expired, please log in again",
+			})
+			return
+		}
+		fmt.Println("Redis Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{
+			Success: false,
+			Message: "internal server error",
+		})
+		return
+	}
+
+	// --- GENERATE JWT TOKEN
+	claims, err := libs.NewJWTClaims(user.Id, user.Role)
+	if err != nil {
+		fmt.Println("Internal Server Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{
+			Success: false,
+			Message: "internal server error",
+		})
+		return
+	}
+	claims.SessionID = session.Family
+	jwtToken, err := claims.GenToken()
+	if err != nil {
+		fmt.Println("Internal Server Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{
+			Success: false,
+			Message: "internal server error",
+		})
+		return
+	}
+
+	ctx.JSON(200, models.ResponseSucces{
+		Success: true,
+		Message: "token refreshed",
 		Result: gin.H{
-			"token": jwtToken},
+			"token":         jwtToken,
+			"refresh_token": refreshToken,
+			"expires_in":    int(libs.AccessTokenTTL.Seconds()),
+		},
 	})
+}
+
+// Logout godoc
+// @Summary Logout user
+// @Description Revoke the current access token and, when given, the refresh token of this login
+// @Tags Auth
+// @Param input body models.ReqLogout false "Refresh token to revoke"
+// @Success 200 {object} models.Response
+// @Router /auth/logout [post]
+// @Security BearerAuth
+func Logout(ctx *gin.Context, rdb *redis.Client) {
+	var input models.ReqLogout
+	// --- BODY IS OPTIONAL ---
+	_ = ctx.ShouldBindJSON(&input)
+
+	// --- CHECKING CLAIMS TOKEN ---
+	user, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 
+	// --- DENYLIST ACCESS TOKEN ---
+	if err := models.DenyAccessToken(ctxTimeout, rdb, user.TokenID, user.TTL()); err != nil {
+		fmt.Println("Internal Server Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{
+			Success: false,
+			Message: "internal server error",
+		})
+		return
+	}
+
+	// --- END SESSION OF THIS LOGIN, ALSO REVOKES ITS REFRESH TOKEN ---
+	if user.SessionID != "" {
+		if err := models.RevokeSession(ctxTimeout, rdb, user.ID, user.SessionID); err != nil && !errors.Is(err, models.ErrSessionNotFound) {
+			fmt.Println("Failed to revoke session:", err)
+		}
+	}
+
+	// --- REVOKE REFRESH TOKEN ---
+	if input.RefreshToken != "" {
+		if err := models.RevokeRefreshToken(ctxTimeout, rdb, user.ID, input.RefreshToken); err != nil {
+			fmt.Println("Failed to revoke refresh token:", err)
+		}
+	}
+
+	ctx.JSON(200, models.Response{
+		Success: true,
+		Message: "logout successful",
+	})
 }
 
 // ForgotPassword godoc
 // @Summary Send password reset link to user email
-// @Description Receive user email, create password reset token, store it in Redis, and send email containing password reset link
+// @Description Send a reset link to the email, the previous link stops working. The response is the same whether or not the email is registered
 // @Tags Auth
 // @Param input body models.ReqForgot true "Email user"
 // @Success 200 {object} models.Response "Reset link successfully sent"
+// @Failure 429 {object} models.Response "Too many requests, see Retry-After header"
 // @Router /auth/forgot-password [post]
 func ForgotPassword(ctx *gin.Context, db *pgxpool.Pool, rdb *redis.Client) {
 	var input models.ReqForgot
@@ -181,79 +627,116 @@ func ForgotPassword(ctx *gin.Context, db *pgxpool.Pool, rdb *redis.Client) {
 		return
 	}
 
-	// --- GET USER BY EMAIL ---
-	user, err := models.GetUserByEmail(ctx, db, input.Email)
+	// --- CHECK LOCKOUT EMAIL AND IP ---
+	emailSubject := models.EmailSubject(input.Email)
+	ipSubject := models.IPSubject(ctx.ClientIP())
+	locked, err := models.GetLockout(ctx, rdb, models.ScopeForgot, emailSubject, ipSubject)
 	if err != nil {
-		ctx.JSON(400, models.Response{
+		fmt.Println("Redis Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{
 			Success: false,
-			Message: "user not found",
+			Message: "internal server error",
 		})
 		return
 	}
+	if locked > 0 {
+		respondTooManyAttempts(ctx, locked)
+		return
+	}
 
-	// --- GENEATE RANDOM TOKEN ---
-	token, _ := utils.GenerateRandomToken(32)
-	key := "reset:pwd:" + token
-
-	// --- SAVER RANDOM TOKEN IN REDIS --
-	if err := models.SaveResetToken(ctx.Request.Context(), rdb, key, fmt.Sprintf("%d", user.Id), 15*time.Minute); err != nil {
-		ctx.JSON(500, models.Response{
-			Success: false,
-			Message: "failed to save token",
-		})
-		fmt.Println(err)
+	// --- EVERY REQUEST COUNTS, SO THE ENDPOINT CAN'T SPAM EMAILS ---
+	lockEmail, err := models.RegisterAttempt(ctx, rdb, models.ForgotEmailPolicy, emailSubject)
+	if err != nil {
+		fmt.Println("Failed to register attempt:", err)
+	}
+	lockIP, err := models.RegisterAttempt(ctx, rdb, models.ForgotIPPolicy, ipSubject)
+	if err != nil {
+		fmt.Println("Failed to register attempt:", err)
+	}
+	if locked := max(lockEmail, lockIP); locked > 0 {
+		respondTooManyAttempts(ctx, locked)
 		return
 	}
 
-	// --- URL ---
-	frontendURL := os.Getenv("FRONTEND_RESET_URL")
+	// --- ONLY REGISTERED EMAIL GET A LINK, RESPONSE IS THE SAME SO ACCOUNT CAN'T BE ENUMERATED ---
+	user, err := models.GetUserByEmail(ctx, db, input.Email)
+	if err == nil {
+		if err := sendResetPasswordEmail(ctx, rdb, user.Id, user.Email); err != nil {
+			fmt.Println("Failed to send reset password email:", err)
+		}
+	}
+
+	ctx.JSON(200, models.Response{
+		Success: true,
+		Message: "If the account exists, a reset link has been sent to the email",
+	})
+}
+
+func sendResetPasswordEmail(ctx context.Context, rdb *redis.Client, userID int, email string) error {
+	// --- GENEATE RANDOM TOKEN ---
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return err
+	}
+
+	// --- SAVE HASH OF TOKEN IN REDIS, OLDER LINK OF USER IS INVALIDATED ---
+	if err := models.SaveResetToken(ctx, rdb, userID, token, models.ResetPasswordTTL); err != nil {
+		return err
+	}
 
-	resetLink := frontendURL
+	resetLink := fmt.Sprintf("%s?token=%s", os.Getenv("FRONTEND_RESET_URL"), url.QueryEscape(token))
 
 	// --- MESSAGE EMAIL ---
 	emailBody := fmt.Sprintf(`
     <div style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
         <h2 style="color:  #8B4513;">Reset Password</h2>
         <p>Halo,</p>
-        <p>Anda atau seseorang telah meminta reset password untuk akun Anda. Gunakan token berikut untuk melakukan reset password:</p>
-        <p style="font-size: 20px; font-weight: bold; color: #000;">%s</p>
-        <p>Atau klik tombol berikut untuk langsung ke halaman reset password:</p>
+        <p>Anda atau seseorang telah meminta reset password untuk akun Anda. Klik tombol berikut untuk membuat password baru:</p>
         <p>
             <a href="%s" style="display: inline-block; padding: 10px 20px; background-color:  #8B4513; color: #fff; text-decoration: none; border-radius: 5px;">
                 Reset Password
             </a>
         </p>
-        <p>Jika Anda tidak meminta reset password, abaikan email ini.</p>
+        <p>Link ini hanya bisa dipakai sekali dan berlaku selama %d menit. Jika Anda tidak meminta reset password, abaikan email ini.</p>
         <p>Salam,<br/>Tim Senja Kopi kiri</p>
     </div>
-`, token, resetLink)
+`, resetLink, int(models.ResetPasswordTTL.Minutes()))
 
-	err = utils.Send(utils.SendOptions{
-		To:         []string{input.Email},
+	return utils.Send(utils.SendOptions{
+		To:         []string{email},
 		Subject:    "Reset Password",
 		Body:       emailBody,
 		BodyIsHTML: true,
 	})
+}
 
-	// --- ERROR HANDLING ---
-	if err != nil {
-		fmt.Println("SMTP ERROR:", err)
-	} else {
-		fmt.Println("Email sent to:", input.Email)
-	}
+func sendPasswordChangedEmail(email string) error {
+	// --- MESSAGE EMAIL ---
+	emailBody := fmt.Sprintf(`
+    <div style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
+        <h2 style="color:  #8B4513;">Password Telah Diubah</h2>
+        <p>Halo,</p>
+        <p>Password akun Anda baru saja diubah pada %s dan semua perangkat telah dikeluarkan dari akun.</p>
+        <p>Jika bukan Anda yang melakukannya, segera reset password Anda dan hubungi kami.</p>
+        <p>Salam,<br/>Tim Senja Kopi kiri</p>
+    </div>
+`, time.Now().Format("02 Jan 2006 15:04 MST"))
 
-	ctx.JSON(200, gin.H{
-		"success":    true,
-		"message":    "Reset link sent to email",
+	return utils.Send(utils.SendOptions{
+		To:         []string{email},
+		Subject:    "Password Anda Telah Diubah",
+		Body:       emailBody,
+		BodyIsHTML: true,
 	})
 }
 
 // ResetPassword godoc
 // @Summary Reset user password
-// @Description Resets the password for a user using a valid token. The token must have been issued during the forgot password process.
+// @Description Resets the password using the token from the reset link. The token can only be used once, and every session of the user is logged out
 // @Tags Auth
 // @Param input body models.ReqResetPassword true "Reset password request"
 // @Success 200 {object} models.ResponseSucces "Password updated successfully"
+// @Failure 400 {object} models.Response "Invalid token, weak, breached or recently used password"
 // @Router /auth/reset-password [post]
 func ResetPassword(ctx *gin.Context, db *pgxpool.Pool, rdb *redis.Client) {
 	var input models.ReqResetPassword
@@ -281,8 +764,7 @@ func ResetPassword(ctx *gin.Context, db *pgxpool.Pool, rdb *redis.Client) {
 	}
 
 	// --- GET USER ID FROM TOKEN ---
-	key := "reset:pwd:" + input.Token
-	userIDStr, err := models.GetUserIDFromToken(ctx, rdb, key)
+	userID, err := models.GetResetTokenUserID(ctx, rdb, input.Token)
 	if err != nil {
 		ctx.JSON(400, models.Response{
 			Success: false,
@@ -291,7 +773,22 @@ func ResetPassword(ctx *gin.Context, db *pgxpool.Pool, rdb *redis.Client) {
 		return
 	}
 
-	userID, _ := strconv.Atoi(userIDStr)
+	// --- NEW PASSWORD CAN'T BE ONE OF THE RECENT PASSWORD, TOKEN STAYS VALID TO TRY AGAIN ---
+	if err := models.CheckPasswordReuse(ctx, db, userID, input.NewPassword); err != nil {
+		if errors.Is(err, models.ErrPasswordReused) {
+			ctx.JSON(400, models.Response{
+				Success: false,
+				Message: err.Error(),
+			})
+			return
+		}
+		fmt.Println("Internal Server Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{
+			Success: false,
+			Message: "internal server error",
+		})
+		return
+	}
 
 	// --- HASH NEW PASSWORD ---
 	hashed, err := libs.HashPassword(input.NewPassword)
@@ -303,6 +800,15 @@ func ResetPassword(ctx *gin.Context, db *pgxpool.Pool, rdb *redis.Client) {
 		return
 	}
 
+	// --- TOKEN IS DELETED ON READ, SO IT CAN'T BE USED TWICE ---
+	if consumed, err := models.ConsumeResetToken(ctx, rdb, input.Token); err != nil || consumed != userID {
+		ctx.JSON(400, models.Response{
+			Success: false,
+			Message: "invalid or expired token",
+		})
+		return
+	}
+
 	// --- UPDATE PASSWORD BY USER ID ---
 	if err := models.UpdatePasswordByID(ctx, db, userID, hashed); err != nil {
 		ctx.JSON(500, models.Response{
@@ -312,8 +818,16 @@ func ResetPassword(ctx *gin.Context, db *pgxpool.Pool, rdb *redis.Client) {
 		return
 	}
 
-	// --- DELETE TOKEN AFTER USED ---
-	rdb.Del(ctx, key)
+	// --- WHOEVER KNEW THE OLD PASSWORD IS LOGGED OUT ---
+	if _, err := models.RevokeOtherSessions(ctx, rdb, userID, ""); err != nil {
+		fmt.Println("Failed to revoke sessions:", err)
+	}
+
+	if user, err := models.GetUserByID(ctx, db, userID); err == nil {
+		if err := sendPasswordChangedEmail(user.Email); err != nil {
+			fmt.Println("Failed to send password changed email:", err)
+		}
+	}
 
 	ctx.JSON(200, models.ResponseSucces{
 		Success: true,
@@ -323,3 +837,28 @@ func ResetPassword(ctx *gin.Context, db *pgxpool.Pool, rdb *redis.Client) {
 		},
 	})
 }
+
+// --- BIND JSON BODY AND WRITE VALIDATION ERROR ---
+func bindJSON(ctx *gin.Context, req any) bool {
+	if err := ctx.ShouldBindJSON(req); err != nil {
+		var ve validator.ValidationErrors
+		if errors.As(err, &ve) {
+			var msgs []string
+			for _, fe := range ve {
+				msgs = append(msgs, utils.ErrorMessage(fe))
+			}
+			ctx.JSON(400, models.Response{
+				Success: false,
+				Message: strings.Join(msgs, ", "),
+			})
+			return false
+		}
+
+		ctx.JSON(400, models.Response{
+			Success: false,
+			Message: "invalid JSON format",
+		})
+		return false
+	}
+	return true
+}
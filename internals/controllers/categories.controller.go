@@ -23,20 +23,20 @@ import (
 // @Description  Retrieve a paginated list of categories, optionally filtered by name.
 // @Tags         Categories
 // @Param        page  query     int     false  "Page number for pagination (default: 1)"
+// @Param        cursor  query   string  false  "Keyset mode, empty for the first page then nextCursor of the previous page"
+// @Param        count  query    bool    false  "Also return total in keyset mode"
 // @Param        name  query     string  false  "Filter categories by name"
 // @Success      200   {object}  models.ResponseSucces  "Get data successfully"
 // @Router       /admin/categories [get]
 // @Security BearerAuth
+// @Security ApiKeyAuth
 func GetListCategories(ctx *gin.Context, db *pgxpool.Pool) {
 	// --- GET QUERY PARAMS ---
-	pageStr := ctx.Query("page")
-	page, err := strconv.Atoi(pageStr)
-	if err != nil || page < 1 {
-		page = 1
+	lp, ok := bindPage(ctx, "category", 10)
+	if !ok {
+		return
 	}
-
-	limit := 10
-	offset := (page - 1) * limit
+	page, limit := lp.Number, lp.Limit
 	name := ctx.Query("name")
 
 	// ---- LIMITS QUERY EXECUTION TIME ---
@@ -44,16 +44,20 @@ func GetListCategories(ctx *gin.Context, db *pgxpool.Pool) {
 	defer cancel()
 
 	// --- GET TOTAL CATEGORIES ---
-	total, err := models.GetCountCategories(ctxTimeout, db)
-	if err != nil {
-		ctx.JSON(500, models.Response{
-			Success: false,
-			Message: "Failed to get total categories count",
-		})
-		return
+	var total int64
+	if lp.WithCount {
+		count, err := models.GetCountCategories(ctxTimeout, db)
+		if err != nil {
+			ctx.JSON(500, models.Response{
+				Success: false,
+				Message: "Failed to get total categories count",
+			})
+			return
+		}
+		total = count
 	}
 
-	categories, err := models.GetListCategories(ctxTimeout, db, name, limit, offset)
+	categories, next, err := models.GetListCategories(ctxTimeout, db, name, lp.Page)
 	if err != nil {
 		ctx.JSON(500, models.Response{
 			Success: false,
@@ -93,11 +97,15 @@ func GetListCategories(ctx *gin.Context, db *pgxpool.Pool) {
 		})
 		return
 	}
+	if lp.Keyset {
+		ctx.JSON(200, keysetResponse(ctx, lp, total, categories, next))
+		return
+	}
 	ctx.JSON(200, models.PaginatedResponse[models.Categories]{
-		Page:       page,
+		Page:       &page,
 		Limit:      limit,
-		Total:      total,
-		TotalPages: totalPages,
+		Total:      &total,
+		TotalPages: &totalPages,
 		PrevURL:    prevURL,
 		NextURL:    nextURL,
 		Result:     categories,
@@ -112,6 +120,7 @@ func GetListCategories(ctx *gin.Context, db *pgxpool.Pool) {
 // @Success      200  {object}  models.ResponseSucces  "Create Categories Successfully"
 // @Router       /admin/categories [post]
 // @Security BearerAuth
+// @Security ApiKeyAuth
 func CreateCategory(ctx *gin.Context, db *pgxpool.Pool) {
 	var input models.Categories
 
@@ -165,6 +174,7 @@ func CreateCategory(ctx *gin.Context, db *pgxpool.Pool) {
 // @Success      200        {object}  models.ResponseSucces    "Update Categories Successfully"
 // @Router       /admin/categories/{id} [put]
 // @Security BearerAuth
+// @Security ApiKeyAuth
 func UpdateCategories(ctx *gin.Context, db *pgxpool.Pool) {
 	var body models.Categories
 	// --- GET CATEGORIES ID ---
@@ -235,6 +245,7 @@ func UpdateCategories(ctx *gin.Context, db *pgxpool.Pool) {
 // @Success      200  {object}  models.ResponseSucces  "Delete categories successfully"
 // @Router       /admin/categories/{id} [delete]
 // @Security BearerAuth
+// @Security ApiKeyAuth
 func DeleteCategories(ctx *gin.Context, db *pgxpool.Pool) {
 	categoryIDstr := ctx.Param("id")
 	categoryID, err := strconv.Atoi(categoryIDstr)
@@ -0,0 +1,131 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/federus1105/koda-b4-backend/internals/models"
+	"github.com/federus1105/koda-b4-backend/internals/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+)
+
+// --- PUT EMAIL IN PENDING STATE, LINK TO THE NEW ADDRESS AND NOTICE TO THE OLD ONE ---
+// Returns false without error when the email is not changed.
+func requestEmailChange(ctx context.Context, db *pgxpool.Pool, rdb *redis.Client, userID int, email string) (bool, error) {
+	oldEmail, err := models.RequestEmailChange(ctx, db, userID, email)
+	if err != nil {
+		if errors.Is(err, models.ErrEmailUnchanged) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return false, err
+	}
+	if err := models.SaveEmailChangeToken(ctx, rdb, userID, token, models.EmailChangeTTL); err != nil {
+		return false, err
+	}
+
+	confirmLink := fmt.Sprintf("%s?token=%s", os.Getenv("FRONTEND_CONFIRM_EMAIL_URL"), url.QueryEscape(token))
+
+	// --- MESSAGE EMAIL ---
+	confirmBody := fmt.Sprintf(`
+    <div style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
+        <h2 style="color:  #8B4513;">Konfirmasi Email Baru</h2>
+        <p>Halo,</p>
+        <p>Klik tombol berikut untuk memakai alamat email ini di akun Anda:</p>
+        <p>
+            <a href="%s" style="display: inline-block; padding: 10px 20px; background-color:  #8B4513; color: #fff; text-decoration: none; border-radius: 5px;">
+                Konfirmasi Email
+            </a>
+        </p>
+        <p>Link ini berlaku selama 24 jam. Jika Anda tidak meminta perubahan email, abaikan email ini.</p>
+        <p>Salam,<br/>Tim Senja Kopi kiri</p>
+    </div>
+`, html.EscapeString(confirmLink))
+
+	if err := utils.Send(utils.SendOptions{
+		To:         []string{email},
+		Subject:    "Konfirmasi Email Baru",
+		Body:       confirmBody,
+		BodyIsHTML: true,
+	}); err != nil {
+		fmt.Println("SMTP ERROR:", err)
+	}
+
+	noticeBody := fmt.Sprintf(`
+    <div style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
+        <h2 style="color:  #8B4513;">Permintaan Perubahan Email</h2>
+        <p>Halo,</p>
+        <p>Ada permintaan untuk mengganti email akun Anda menjadi <b>%s</b>. Email baru berlaku setelah dikonfirmasi dari alamat tersebut.</p>
+        <p>Jika bukan Anda yang memintanya, segera ganti password Anda dan hubungi kami.</p>
+        <p>Salam,<br/>Tim Senja Kopi kiri</p>
+    </div>
+`, html.EscapeString(email))
+
+	if err := utils.Send(utils.SendOptions{
+		To:         []string{oldEmail},
+		Subject:    "Permintaan Perubahan Email",
+		Body:       noticeBody,
+		BodyIsHTML: true,
+	}); err != nil {
+		fmt.Println("SMTP ERROR:", err)
+	}
+
+	return true, nil
+}
+
+// ConfirmEmailChange godoc
+// @Summary Confirm email change
+// @Description Confirm the new email address using the token sent to it, the new address becomes the login email
+// @Tags Auth
+// @Param input body models.ReqConfirmEmailChange true "Confirmation token"
+// @Success 200 {object} models.ResponseSucces
+// @Failure 400 {object} models.Response "Invalid or expired token"
+// @Failure 409 {object} models.Response "Email is already registered"
+// @Router /auth/confirm-email-change [post]
+func ConfirmEmailChange(ctx *gin.Context, db *pgxpool.Pool, rdb *redis.Client) {
+	var input models.ReqConfirmEmailChange
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(400, models.Response{
+			Success: false,
+			Message: "token is required",
+		})
+		return
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	userID, email, err := models.ConfirmEmailChange(ctxTimeout, db, rdb, input.Token)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrEmailChangeInvalid):
+			ctx.JSON(400, models.Response{Success: false, Message: err.Error()})
+		case errors.Is(err, models.ErrEmailTaken):
+			ctx.JSON(409, models.Response{Success: false, Message: err.Error()})
+		default:
+			fmt.Println("Internal Server Error.\nCause: ", err)
+			ctx.JSON(500, models.Response{Success: false, Message: "internal server error"})
+		}
+		return
+	}
+
+	ctx.JSON(200, models.ResponseSucces{
+		Success: true,
+		Message: "email changed successfully",
+		Result: gin.H{
+			"id":    userID,
+			"email": email,
+		},
+	})
+}
@@ -0,0 +1,281 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/federus1105/koda-b4-backend/internals/models"
+	"github.com/federus1105/koda-b4-backend/internals/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// GetActiveFlashSales godoc
+// @Summary 	Running flash sales
+// @Description Campaigns running now with their products and sale price, ending soonest first
+// @Tags 		Flash Sales
+// @Success 	200 {object} 	models.ResponseSucces{result=[]models.FlashSale}
+// @Router 		/flash-sales [get]
+func GetActiveFlashSales(ctx *gin.Context, db *pgxpool.Pool) {
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	sales, err := models.GetActiveFlashSales(ctxTimeout, db)
+	if err != nil {
+		fmt.Println("Internal Server Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{Success: false, Message: "internal server error"})
+		return
+	}
+
+	ctx.JSON(200, models.ResponseSucces{
+		Success: true,
+		Message: "Success",
+		Result:  sales,
+	})
+}
+
+// GetFlashSales godoc
+// @Summary 	List flash sales
+// @Description Latest start first, 10 per page
+// @Tags 		Flash Sales
+// @Param 		status 	query 	string 	false 	"upcoming, active or ended, empty for all"
+// @Param 		page 	query 	int 	false 	"Page number (default: 1)"
+// @Success 	200 {object} 	models.ResponseSucces{result=[]models.FlashSale}
+// @Failure 	400 {object} 	models.Response
+// @Security 	BearerAuth
+// @Security 	ApiKeyAuth
+// @Router 		/admin/flash-sales [get]
+func GetFlashSales(ctx *gin.Context, db *pgxpool.Pool) {
+	status := ctx.Query("status")
+	if status != "" && status != "upcoming" && status != "active" && status != "ended" {
+		ctx.JSON(400, models.Response{
+			Success: false,
+			Message: "status must be upcoming, active or ended",
+		})
+		return
+	}
+	page, err := strconv.Atoi(ctx.Query("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	sales, err := models.GetFlashSales(ctxTimeout, db, status, page)
+	if err != nil {
+		fmt.Println("Internal Server Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{Success: false, Message: "internal server error"})
+		return
+	}
+
+	ctx.JSON(200, models.ResponseSucces{
+		Success: true,
+		Message: "Success",
+		Result:  sales,
+	})
+}
+
+// GetFlashSale godoc
+// @Summary 	Detail flash sale
+// @Description Products with their sale price and quantity sold in the campaign
+// @Tags 		Flash Sales
+// @Param 		id 	path 	int 	true 	"Flash sale ID"
+// @Success 	200 {object} 	models.ResponseSucces{result=models.FlashSale}
+// @Failure 	404 {object} 	models.Response
+// @Security 	BearerAuth
+// @Security 	ApiKeyAuth
+// @Router 		/admin/flash-sales/{id} [get]
+func GetFlashSale(ctx *gin.Context, db *pgxpool.Pool) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(404, models.Response{
+			Success: false,
+			Message: "Invalid flash sale id",
+		})
+		return
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	sale, err := models.GetFlashSale(ctxTimeout, db, id)
+	if err != nil {
+		if errors.Is(err, models.ErrFlashSaleNotFound) {
+			ctx.JSON(404, models.Response{Success: false, Message: err.Error()})
+			return
+		}
+		fmt.Println("Internal Server Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{Success: false, Message: "internal server error"})
+		return
+	}
+
+	ctx.JSON(200, models.ResponseSucces{
+		Success: true,
+		Message: "Success",
+		Result:  sale,
+	})
+}
+
+// CreateFlashSale godoc
+// @Summary 	Create flash sale
+// @Description Each product gets a sale_price or a discount_percent and an optional max_per_customer. Price changes and cache invalidation happen at starts_at and ends_at
+// @Tags 		Flash Sales
+// @Param 		input 	body 	models.ReqFlashSale 	true 	"Campaign"
+// @Success 	201 {object} 	models.ResponseSucces{result=models.FlashSale}
+// @Failure 	400 {object} 	models.Response
+// @Security 	BearerAuth
+// @Security 	ApiKeyAuth
+// @Router 		/admin/flash-sales [post]
+func CreateFlashSale(ctx *gin.Context, db *pgxpool.Pool) {
+	user, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+	input, ok := bindFlashSale(ctx)
+	if !ok {
+		return
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// --- API KEY HAS NO USER, created_by STAYS EMPTY ---
+	var createdBy *int
+	if user.APIKeyID == 0 {
+		createdBy = &user.ID
+	}
+	sale, err := models.CreateFlashSale(ctxTimeout, db, createdBy, input)
+	if err != nil {
+		flashSaleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(201, models.ResponseSucces{
+		Success: true,
+		Message: "Flash sale created",
+		Result:  sale,
+	})
+}
+
+// UpdateFlashSale godoc
+// @Summary 	Update flash sale
+// @Description Replaces the window and product list, an ended campaign can't be changed
+// @Tags 		Flash Sales
+// @Param 		id 		path 	int 				true 	"Flash sale ID"
+// @Param 		input 	body 	models.ReqFlashSale 	true 	"Campaign"
+// @Success 	200 {object} 	models.ResponseSucces{result=models.FlashSale}
+// @Failure 	400 {object} 	models.Response
+// @Failure 	404 {object} 	models.Response
+// @Failure 	409 {object} 	models.Response "Flash sale has already ended"
+// @Security 	BearerAuth
+// @Security 	ApiKeyAuth
+// @Router 		/admin/flash-sales/{id} [put]
+func UpdateFlashSale(ctx *gin.Context, db *pgxpool.Pool) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(404, models.Response{
+			Success: false,
+			Message: "Invalid flash sale id",
+		})
+		return
+	}
+	input, ok := bindFlashSale(ctx)
+	if !ok {
+		return
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	sale, err := models.UpdateFlashSale(ctxTimeout, db, id, input)
+	if err != nil {
+		flashSaleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(200, models.ResponseSucces{
+		Success: true,
+		Message: "Flash sale updated",
+		Result:  sale,
+	})
+}
+
+// DeleteFlashSale godoc
+// @Summary 	Delete flash sale
+// @Description A running campaign ends immediately, orders keep the price they were placed with
+// @Tags 		Flash Sales
+// @Param 		id 	path 	int 	true 	"Flash sale ID"
+// @Success 	200 {object} 	models.Response
+// @Failure 	404 {object} 	models.Response
+// @Security 	BearerAuth
+// @Security 	ApiKeyAuth
+// @Router 		/admin/flash-sales/{id} [delete]
+func DeleteFlashSale(ctx *gin.Context, db *pgxpool.Pool) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(404, models.Response{
+			Success: false,
+			Message: "Invalid flash sale id",
+		})
+		return
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := models.DeleteFlashSale(ctxTimeout, db, id); err != nil {
+		flashSaleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(200, models.Response{
+		Success: true,
+		Message: "Flash sale deleted",
+	})
+}
+
+func bindFlashSale(ctx *gin.Context) (models.ReqFlashSale, bool) {
+	var input models.ReqFlashSale
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		var ve validator.ValidationErrors
+		if errors.As(err, &ve) {
+			var msgs []string
+			for _, fe := range ve {
+				msgs = append(msgs, utils.ErrorMessage(fe))
+			}
+			ctx.JSON(400, models.Response{
+				Success: false,
+				Message: strings.Join(msgs, ", "),
+			})
+			return input, false
+		}
+
+		ctx.JSON(400, models.Response{
+			Success: false,
+			Message: "invalid JSON format",
+		})
+		return input, false
+	}
+	return input, true
+}
+
+func flashSaleError(ctx *gin.Context, err error) {
+	var ve utils.ValidationError
+	switch {
+	case errors.As(err, &ve):
+		ctx.JSON(400, models.Response{Success: false, Message: ve.Error()})
+	case errors.Is(err, models.ErrFlashSaleNotFound):
+		ctx.JSON(404, models.Response{Success: false, Message: err.Error()})
+	case errors.Is(err, models.ErrFlashSaleEnded):
+		ctx.JSON(409, models.Response{Success: false, Message: err.Error()})
+	default:
+		fmt.Println("Internal Server Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{Success: false, Message: "internal server error"})
+	}
+}
@@ -11,7 +11,6 @@ import (
 	"strconv"
 	"time"
 
-	"github.com/federus1105/koda-b4-backend/internals/middlewares"
 	"github.com/federus1105/koda-b4-backend/internals/models"
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -24,6 +23,8 @@ import (
 // @Param month query int false "Filter bulan (1-12)"
 // @Param status query int false "Filter status history"
 // @Param page query int false "Page number (default: 1)"
+// @Param cursor query string false "Keyset mode, empty for the first page then nextCursor of the previous page"
+// @Param count query bool false "Also return total in keyset mode"
 // @Success 200 {object} models.ResponseSucces
 // @Failure 401 {object} models.Response
 // @Failure 500 {object} models.Response
@@ -32,28 +33,11 @@ import (
 func GetHistory(ctx *gin.Context, db *pgxpool.Pool) {
 
 	// --- GET USER IN CONTEXT ---
-	userIDInterface, exists := ctx.Get(middlewares.UserIDKey)
-	if !exists {
-		ctx.JSON(401, models.Response{
-			Success: false,
-			Message: "Unauthorized: user not logged in",
-		})
-		return
-	}
-
-	var userID int
-	switch v := userIDInterface.(type) {
-	case int:
-		userID = v
-	case float64:
-		userID = int(v)
-	default:
-		ctx.JSON(401, models.Response{
-			Success: false,
-			Message: "Invalid user ID type in context",
-		})
+	user, ok := currentUser(ctx)
+	if !ok {
 		return
 	}
+	userID := user.ID
 
 	// --- FILTER MONTH ---
 	monthStr := ctx.Query("month")
@@ -64,30 +48,31 @@ func GetHistory(ctx *gin.Context, db *pgxpool.Pool) {
 	status, _ := strconv.Atoi(statusStr)
 
 	// --- GET QUERY PARAMS ---
-	pageStr := ctx.Query("page")
-	page, err := strconv.Atoi(pageStr)
-	if err != nil || page < 1 {
-		page = 1
+	lp, ok := bindPage(ctx, "history", 5)
+	if !ok {
+		return
 	}
-
-	limit := 5
-	offset := (page - 1) * limit
+	page, limit := lp.Number, lp.Limit
 
 	// --- LIMITS QUERY EXECUTION TIME ---
 	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	// --- GET TOTAL HISTORY ---
-	total, err := models.GetCountHistory(ctxTimeout, db, userID, month, status)
-	if err != nil {
-		ctx.JSON(500, models.Response{
-			Success: false,
-			Message: "Failed to get total history count",
-		})
-		return
+	var total int64
+	if lp.WithCount {
+		count, err := models.GetCountHistory(ctxTimeout, db, userID, month, status)
+		if err != nil {
+			ctx.JSON(500, models.Response{
+				Success: false,
+				Message: "Failed to get total history count",
+			})
+			return
+		}
+		total = count
 	}
 
-	histories, err := models.GetHistory(ctxTimeout, db, userID, month, status, limit, offset)
+	histories, next, err := models.GetHistory(ctxTimeout, db, userID, month, status, lp.Page)
 	if err != nil {
 		ctx.JSON(500, models.Response{
 			Success: false,
@@ -137,11 +122,15 @@ func GetHistory(ctx *gin.Context, db *pgxpool.Pool) {
 		})
 		return
 	}
+	if lp.Keyset {
+		ctx.JSON(200, keysetResponse(ctx, lp, total, histories, next))
+		return
+	}
 	ctx.JSON(200, models.PaginatedResponse[models.History]{
-		Page:       page,
+		Page:       &page,
 		Limit:      limit,
-		Total:      total,
-		TotalPages: totalPages,
+		Total:      &total,
+		TotalPages: &totalPages,
 		PrevURL:    prevURL,
 		NextURL:    nextURL,
 		Result:     histories,
@@ -172,28 +161,11 @@ func DetailHistory(ctx *gin.Context, db *pgxpool.Pool) {
 	}
 
 	// --- GET USER IN CONTEXT ---
-	userIDInterface, exists := ctx.Get(middlewares.UserIDKey)
-	if !exists {
-		ctx.JSON(401, models.Response{
-			Success: false,
-			Message: "Unauthorized: user not logged in",
-		})
-		return
-	}
-
-	var userID int
-	switch v := userIDInterface.(type) {
-	case int:
-		userID = v
-	case float64:
-		userID = int(v)
-	default:
-		ctx.JSON(401, models.Response{
-			Success: false,
-			Message: "Invalid user ID type in context",
-		})
+	user, ok := currentUser(ctx)
+	if !ok {
 		return
 	}
+	userID := user.ID
 
 	// ---- LIMITS QUERY EXECUTION TIME --
 	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -2,28 +2,42 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
 	"mime/multipart"
-	"net/http"
+	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/cloudinary/cloudinary-go/v2"
+	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
 	"github.com/federus1105/koda-b4-backend/internals/models"
-	"github.com/federus1105/koda-b4-backend/internals/pkg/libs"
 	"github.com/federus1105/koda-b4-backend/internals/pkg/utils"
 	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
 )
 
+// GetListImageById godoc
+// @Summary 	Product gallery
+// @Description Images in display order, the primary one is the product thumbnail
+// @Tags 		Products
+// @Param 		id 	path 	int 	true 	"Product ID"
+// @Success 	200 {object} 	models.ResponseSucces{result=[]models.ProductImage}
+// @Failure 	404 {object} 	models.Response
+// @Security 	BearerAuth
+// @Security 	ApiKeyAuth
+// @Router 		/admin/product/{id}/images [get]
 func GetListImageById(ctx *gin.Context, db *pgxpool.Pool) {
 	// --- GET PORDUCT ID ---
-	productIDstr := ctx.Param("id")
-	productID, err := strconv.Atoi(productIDstr)
+	productID, err := strconv.Atoi(ctx.Param("id"))
 	if err != nil {
 		ctx.JSON(404, models.Response{
 			Success: false,
-			Message: "Invalid images id",
+			Message: "Invalid product id",
 		})
 		return
 	}
@@ -33,21 +47,7 @@ func GetListImageById(ctx *gin.Context, db *pgxpool.Pool) {
 	defer cancel()
 	images, err := models.GetListImageById(ctxTimeout, db, productID)
 	if err != nil {
-		ctx.JSON(500, models.Response{
-			Success: false,
-			Message: "Failed Get list data images",
-		})
-		fmt.Println("Error : ", err.Error())
-		return
-	}
-
-	// --- VALIDATION FOR LIST PRODUCT ---
-	if len(images) == 0 {
-		ctx.JSON(http.StatusOK, gin.H{
-			"success": true,
-			"data":    []string{},
-			"message": "Not found list images",
-		})
+		productImageError(ctx, err)
 		return
 	}
 
@@ -58,11 +58,23 @@ func GetListImageById(ctx *gin.Context, db *pgxpool.Pool) {
 	})
 }
 
-func CreateImagesbyId(ctx *gin.Context, db *pgxpool.Pool) {
-	var body models.ImagesBody
-	// --- GET PORDUCT ID ---
-	productIDstr := ctx.Param("id")
-	productID, err := strconv.Atoi(productIDstr)
+// AddProductImages godoc
+// @Summary 	Add product images
+// @Description Appended to the end of the gallery. The first uploaded image becomes primary when primary=true or when the product has no image yet
+// @Tags 		Products
+// @Accept 		multipart/form-data
+// @Param 		id 			path 		int 		true 	"Product ID"
+// @Param 		images 		formData 	file 		true 	"Send the field once per file, at most 10"
+// @Param 		alt_text 	formData 	[]string 	false 	"Alt text of the image at the same index" collectionFormat(multi)
+// @Param 		primary 	formData 	bool 		false 	"Make the first uploaded image primary"
+// @Success 	200 {object} 	models.ResponseSucces{result=[]models.ProductImage}
+// @Failure 	400 {object} 	models.Response
+// @Failure 	404 {object} 	models.Response
+// @Security 	BearerAuth
+// @Security 	ApiKeyAuth
+// @Router 		/admin/product/{id}/images [post]
+func AddProductImages(ctx *gin.Context, db *pgxpool.Pool, rd *redis.Client, cld *cloudinary.Cloudinary) {
+	productID, err := strconv.Atoi(ctx.Param("id"))
 	if err != nil {
 		ctx.JSON(404, models.Response{
 			Success: false,
@@ -71,98 +83,253 @@ func CreateImagesbyId(ctx *gin.Context, db *pgxpool.Pool) {
 		return
 	}
 
-	// --- CHECKING CLAIMS TOKEN ---
-	claims, exists := ctx.Get("claims")
-	if !exists {
-		fmt.Println("ERROR :", !exists)
-		ctx.AbortWithStatusJSON(403, models.Response{
+	user, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var body models.ReqAddProductImages
+	if !bindProductImageRequest(ctx, &body) {
+		return
+	}
+
+	urls, ok := uploadProductImages(ctx, cld, body.Images, user.ID)
+	if !ok {
+		return
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	images, err := models.AddProductImages(ctxTimeout, db, rd, productID, models.NewProductImages(urls, body.AltText), body.Primary)
+	if err != nil {
+		productImageError(ctx, err)
+		return
+	}
+
+	ctx.JSON(200, models.ResponseSucces{
+		Success: true,
+		Message: "Images added",
+		Result:  images,
+	})
+}
+
+// UpdateProductImage godoc
+// @Summary 	Update product image
+// @Description Change the alt text or make the image primary, the previous primary image stops being one
+// @Tags 		Products
+// @Param 		id 			path 	int 							true 	"Product ID"
+// @Param 		imageId 	path 	int 							true 	"Image ID"
+// @Param 		input 		body 	models.ReqUpdateProductImage 	true 	"Fields to change"
+// @Success 	200 {object} 	models.ResponseSucces{result=[]models.ProductImage}
+// @Failure 	400 {object} 	models.Response
+// @Failure 	404 {object} 	models.Response
+// @Security 	BearerAuth
+// @Security 	ApiKeyAuth
+// @Router 		/admin/product/{id}/images/{imageId} [patch]
+func UpdateProductImage(ctx *gin.Context, db *pgxpool.Pool, rd *redis.Client) {
+	productID, imageID, ok := productImageParams(ctx)
+	if !ok {
+		return
+	}
+
+	var body models.ReqUpdateProductImage
+	if !bindProductImageRequest(ctx, &body) {
+		return
+	}
+	if body.AltText == nil && body.IsPrimary == nil {
+		ctx.JSON(400, models.Response{
 			Success: false,
-			Message: "Please log in again",
+			Message: "alt_text or is_primary is required",
 		})
 		return
 	}
-	user, ok := claims.(libs.Claims)
+
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	images, err := models.UpdateProductImage(ctxTimeout, db, rd, productID, imageID, body)
+	if err != nil {
+		productImageError(ctx, err)
+		return
+	}
+
+	ctx.JSON(200, models.ResponseSucces{
+		Success: true,
+		Message: "Image updated",
+		Result:  images,
+	})
+}
+
+// DeleteProductImage godoc
+// @Summary 	Delete product image
+// @Description The remaining images close the gap, the first one becomes primary when the primary image is deleted
+// @Tags 		Products
+// @Param 		id 			path 	int 	true 	"Product ID"
+// @Param 		imageId 	path 	int 	true 	"Image ID"
+// @Success 	200 {object} 	models.ResponseSucces{result=[]models.ProductImage}
+// @Failure 	404 {object} 	models.Response
+// @Security 	BearerAuth
+// @Security 	ApiKeyAuth
+// @Router 		/admin/product/{id}/images/{imageId} [delete]
+func DeleteProductImage(ctx *gin.Context, db *pgxpool.Pool, rd *redis.Client) {
+	productID, imageID, ok := productImageParams(ctx)
 	if !ok {
-		fmt.Println("ERROR", !ok)
-		ctx.AbortWithStatusJSON(500, models.Response{
+		return
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	images, err := models.DeleteProductImage(ctxTimeout, db, rd, productID, imageID)
+	if err != nil {
+		productImageError(ctx, err)
+		return
+	}
+
+	ctx.JSON(200, models.ResponseSucces{
+		Success: true,
+		Message: "Image deleted",
+		Result:  images,
+	})
+}
+
+// ReorderProductImages godoc
+// @Summary 	Reorder product images
+// @Description ids lists every image of the product once, in the new display order. The primary image doesn't change
+// @Tags 		Products
+// @Param 		id 		path 	int 								true 	"Product ID"
+// @Param 		input 	body 	models.ReqReorderProductImages 	true 	"Image ids in order"
+// @Success 	200 {object} 	models.ResponseSucces{result=[]models.ProductImage}
+// @Failure 	400 {object} 	models.Response
+// @Failure 	404 {object} 	models.Response
+// @Security 	BearerAuth
+// @Security 	ApiKeyAuth
+// @Router 		/admin/product/{id}/images/order [put]
+func ReorderProductImages(ctx *gin.Context, db *pgxpool.Pool, rd *redis.Client) {
+	productID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(404, models.Response{
 			Success: false,
-			Message: "An error occurred!, please try again.",
+			Message: "Invalid product id",
 		})
 		return
 	}
 
-	// --- UPLOAD IMAGES ---
-	imageFiles := map[string]*multipart.FileHeader{
-		"photos_one":   body.ImagesOne,
-		"photos_two":   body.ImagesTwo,
-		"photos_three": body.ImagesThree,
-		"photos_four":  body.ImagesFour,
+	var body models.ReqReorderProductImages
+	if !bindProductImageRequest(ctx, &body) {
+		return
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	images, err := models.ReorderProductImages(ctxTimeout, db, rd, productID, body.Ids)
+	if err != nil {
+		productImageError(ctx, err)
+		return
 	}
 
-	imageStrs := map[string]string{}
+	ctx.JSON(200, models.ResponseSucces{
+		Success: true,
+		Message: "Images reordered",
+		Result:  images,
+	})
+}
 
-	// --- MULTIPLE UPLOAD IMAGES ---
-	for key, file := range imageFiles {
-		if file == nil {
-			continue
-		}
-		savePath, generatedFilename, err := utils.UploadImageFile(ctx, file, "public", fmt.Sprintf("%s_%d", key, user.ID))
+// --- SAVES EACH FILE LOCALLY OR ON CLOUDINARY, URLS COME BACK IN THE ORDER OF files ---
+func uploadProductImages(ctx *gin.Context, cld *cloudinary.Cloudinary, files []*multipart.FileHeader, userID int) ([]string, bool) {
+	useCloudinary := os.Getenv("CLOUDINARY_URL") != ""
+	urls := make([]string, 0, len(files))
+	for i, file := range files {
+		savePath, generatedFilename, err := utils.UploadImageFile(ctx, file, "public", fmt.Sprintf("product_%d_%d", userID, i))
 		if err != nil {
 			ctx.JSON(400, models.Response{
 				Success: false,
 				Message: err.Error(),
 			})
-			return
+			return nil, false
 		}
 
-		if err := ctx.SaveUploadedFile(file, savePath); err != nil {
+		if !useCloudinary {
+			if err := ctx.SaveUploadedFile(file, savePath); err != nil {
+				ctx.JSON(500, models.Response{
+					Success: false,
+					Message: "Failed to save images",
+				})
+				return nil, false
+			}
+			urls = append(urls, generatedFilename)
+			continue
+		}
+
+		// --- UPLOAD CLOUDINARY, THE GENERATED NAME KEEPS EVERY IMAGE ITS OWN PUBLIC ID ---
+		uploadResp, err := cld.Upload.Upload(ctx, file, uploader.UploadParams{
+			Folder:   "assets/product",
+			PublicID: strings.TrimSuffix(generatedFilename, filepath.Ext(generatedFilename)),
+		})
+		if err != nil {
 			ctx.JSON(500, models.Response{
 				Success: false,
-				Message: fmt.Sprintf("Failed to save %s", key),
+				Message: "Failed to upload images to cloudinary",
 			})
-			return
+			return nil, false
 		}
-
-		imageStrs[key] = generatedFilename
+		urls = append(urls, uploadResp.SecureURL)
 	}
+	return urls, true
+}
 
-	// --- ASSIGN TO BODY ---
-	body.ImagesOneStr = imageStrs["photos_one"]
-	body.ImagesTwoStr = imageStrs["photos_two"]
-	body.ImagesThreeStr = imageStrs["photos_three"]
-	body.ImagesFourStr = imageStrs["photos_four"]
-
-	// ---- LIMITS QUERY EXECUTION TIME ---
-	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	images, err := models.CreateImagesbyId(ctxTimeout, db, body, productID)
+func productImageParams(ctx *gin.Context) (int, int, bool) {
+	productID, err := strconv.Atoi(ctx.Param("id"))
 	if err != nil {
-		log.Println("ERROR : ", err)
-		ctx.JSON(500, models.Response{
+		ctx.JSON(404, models.Response{
 			Success: false,
-			Message: "An error occurred while saving data",
+			Message: "Invalid product id",
 		})
-		return
+		return 0, 0, false
 	}
-
-	//  ---- ASIGN LIST IMAGE ---
-	image := make(map[string]string)
-	if images.ImagesOneStr != "" {
-		image["image_one"] = images.ImagesOneStr
-	}
-	if images.ImagesTwoStr != "" {
-		image["image_two"] = images.ImagesTwoStr
-	}
-	if images.ImagesThreeStr != "" {
-		image["image_three"] = images.ImagesThreeStr
+	imageID, err := strconv.Atoi(ctx.Param("imageId"))
+	if err != nil {
+		ctx.JSON(404, models.Response{
+			Success: false,
+			Message: "Invalid image id",
+		})
+		return 0, 0, false
 	}
-	if images.ImagesFourStr != "" {
-		image["image_four"] = images.ImagesFourStr
+	return productID, imageID, true
+}
+
+// --- FORM OR JSON BY CONTENT TYPE ---
+func bindProductImageRequest(ctx *gin.Context, obj any) bool {
+	if err := ctx.ShouldBind(obj); err != nil {
+		var ve validator.ValidationErrors
+		if errors.As(err, &ve) {
+			var msgs []string
+			for _, fe := range ve {
+				msgs = append(msgs, utils.ErrorMessage(fe))
+			}
+			ctx.JSON(400, models.Response{
+				Success: false,
+				Message: strings.Join(msgs, ", "),
+			})
+			return false
+		}
+
+		ctx.JSON(400, models.Response{
+			Success: false,
+			Message: "invalid request format",
+		})
+		return false
 	}
+	return true
+}
 
-	ctx.JSON(200, models.ResponseSucces{
-		Success: true,
-		Message: "Created Images Succesfully",
-		Result:  images,
-	})
+func productImageError(ctx *gin.Context, err error) {
+	var ve utils.ValidationError
+	switch {
+	case errors.As(err, &ve):
+		ctx.JSON(400, models.Response{Success: false, Message: ve.Error()})
+	case errors.Is(err, models.ErrProductNotFound), errors.Is(err, models.ErrProductImageNotFound):
+		ctx.JSON(404, models.Response{Success: false, Message: err.Error()})
+	default:
+		fmt.Println("Internal Server Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{Success: false, Message: "internal server error"})
+	}
 }
@@ -0,0 +1,187 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/federus1105/koda-b4-backend/internals/models"
+	"github.com/federus1105/koda-b4-backend/internals/pkg/libs"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Impersonate godoc
+// @Summary 	Impersonate customer
+// @Description Issues a 10 minute access token of the customer, without refresh token. The token carries an act claim naming the admin, every request made with it is written to the audit log, and changes are blocked unless IMPERSONATION_ALLOW_WRITE=true
+// @Tags 		Users
+// @Param 		id 		path 	int 					true 	"User ID"
+// @Param 		request body 	models.ReqImpersonate 	true 	"Reason, e.g. support ticket"
+// @Success 	201 {object} 	models.ResponseSucces
+// @Failure 	403 {object} 	models.Response "Only customer accounts can be impersonated"
+// @Failure 	404 {object} 	models.Response
+// @Security 	BearerAuth
+// @Router 		/admin/user/{id}/impersonate [post]
+func Impersonate(ctx *gin.Context, db *pgxpool.Pool) {
+	userID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(404, models.Response{
+			Success: false,
+			Message: "Invalid user id",
+		})
+		return
+	}
+
+	var req models.ReqImpersonate
+	if !bindJSON(ctx, &req) {
+		return
+	}
+
+	admin, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+	if admin.ID == userID {
+		ctx.JSON(400, models.Response{
+			Success: false,
+			Message: "you can't impersonate yourself",
+		})
+		return
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	target, err := models.GetImpersonationTarget(ctxTimeout, db, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrAccountNotFound):
+			ctx.JSON(404, models.Response{Success: false, Message: err.Error()})
+		case errors.Is(err, models.ErrAccountDeleted), errors.Is(err, models.ErrImpersonationTarget):
+			ctx.JSON(403, models.Response{Success: false, Message: err.Error()})
+		default:
+			fmt.Println("Internal Server Error.\nCause: ", err)
+			ctx.JSON(500, models.Response{Success: false, Message: "internal server error"})
+		}
+		return
+	}
+
+	actor, err := models.GetUserByID(ctxTimeout, db, admin.ID)
+	if err != nil {
+		fmt.Println("Internal Server Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{Success: false, Message: "internal server error"})
+		return
+	}
+
+	claims, err := libs.NewImpersonationClaims(target.Id, target.Role, libs.Actor{ID: actor.Id, Email: actor.Email})
+	if err != nil {
+		fmt.Println("Internal Server Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{Success: false, Message: "internal server error"})
+		return
+	}
+	expiresAt := claims.ExpiresAt.Time
+
+	// --- RECORD FIRST, A TOKEN THAT CAN'T BE AUDITED IS NEVER HANDED OUT ---
+	impersonationID, err := models.CreateImpersonation(ctxTimeout, db, claims.RegisteredClaims.ID, actor.Id, target.Id, req.Reason, expiresAt)
+	if err != nil {
+		ctx.JSON(500, models.Response{Success: false, Message: "internal server error"})
+		return
+	}
+
+	token, err := claims.GenToken()
+	if err != nil {
+		fmt.Println("Internal Server Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{Success: false, Message: "internal server error"})
+		return
+	}
+
+	ctx.JSON(201, models.ResponseSucces{
+		Success: true,
+		Message: "impersonation started",
+		Result: gin.H{
+			"impersonation_id": impersonationID,
+			"token":            token,
+			"expires_at":       expiresAt,
+			"read_only":        !libs.ImpersonationAllowWrite(),
+			"user": gin.H{
+				"id":    target.Id,
+				"email": target.Email,
+			},
+		},
+	})
+}
+
+// GetImpersonations godoc
+// @Summary 	List impersonations
+// @Description Newest first, 20 per page, with the number of requests made during each one
+// @Tags 		Users
+// @Param 		user_id query 	int false "Only impersonations of this user"
+// @Param 		page 	query 	int false "Page number (default: 1)"
+// @Success 	200 {object} 	models.ResponseSucces{result=[]models.Impersonation}
+// @Security 	BearerAuth
+// @Router 		/admin/impersonations [get]
+func GetImpersonations(ctx *gin.Context, db *pgxpool.Pool) {
+	userID, _ := strconv.Atoi(ctx.Query("user_id"))
+	page, err := strconv.Atoi(ctx.Query("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	impersonations, err := models.GetImpersonations(ctxTimeout, db, userID, page)
+	if err != nil {
+		fmt.Println("Internal Server Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{Success: false, Message: "internal server error"})
+		return
+	}
+
+	ctx.JSON(200, models.ResponseSucces{
+		Success: true,
+		Message: "Success",
+		Result:  impersonations,
+	})
+}
+
+// GetImpersonationAudit godoc
+// @Summary 	Impersonation audit log
+// @Description Every request made with the impersonation token, including blocked ones
+// @Tags 		Users
+// @Param 		id 	path 	int 	true 	"Impersonation ID"
+// @Success 	200 {object} 	models.ResponseSucces{result=[]models.ImpersonationAudit}
+// @Failure 	404 {object} 	models.Response
+// @Security 	BearerAuth
+// @Router 		/admin/impersonations/{id} [get]
+func GetImpersonationAudit(ctx *gin.Context, db *pgxpool.Pool) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(404, models.Response{
+			Success: false,
+			Message: "Invalid impersonation id",
+		})
+		return
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	audit, err := models.GetImpersonationAudit(ctxTimeout, db, id)
+	if err != nil {
+		if errors.Is(err, models.ErrImpersonationNotFound) {
+			ctx.JSON(404, models.Response{Success: false, Message: err.Error()})
+			return
+		}
+		fmt.Println("Internal Server Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{Success: false, Message: "internal server error"})
+		return
+	}
+
+	ctx.JSON(200, models.ResponseSucces{
+		Success: true,
+		Message: "Success",
+		Result:  audit,
+	})
+}
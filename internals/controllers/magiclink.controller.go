@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/federus1105/koda-b4-backend/internals/models"
+	"github.com/federus1105/koda-b4-backend/internals/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+)
+
+// RequestMagicLink godoc
+// @Summary Request magic login link
+// @Description Send a single-use login link to the email, the previous unused link stops working. The response is the same whether or not the email is registered
+// @Tags Auth
+// @Param input body models.ReqMagicLink true "Email user"
+// @Success 200 {object} models.Response
+// @Failure 429 {object} models.Response "Too many requests"
+// @Router /auth/magic-link [post]
+func RequestMagicLink(ctx *gin.Context, db *pgxpool.Pool, rdb *redis.Client) {
+	var input models.ReqMagicLink
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(400, models.Response{
+			Success: false,
+			Message: "invalid email",
+		})
+		return
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// --- CHECK LOCKOUT EMAIL AND IP ---
+	emailSubject := models.EmailSubject(input.Email)
+	ipSubject := models.IPSubject(ctx.ClientIP())
+	locked, err := models.GetLockout(ctxTimeout, rdb, models.ScopeMagic, emailSubject, ipSubject)
+	if err != nil {
+		fmt.Println("Redis Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{
+			Success: false,
+			Message: "internal server error",
+		})
+		return
+	}
+	if locked > 0 {
+		respondTooManyAttempts(ctx, locked)
+		return
+	}
+
+	// --- EVERY REQUEST COUNTS, SO THE ENDPOINT CAN'T SPAM EMAILS ---
+	lockEmail, err := models.RegisterAttempt(ctxTimeout, rdb, models.MagicEmailPolicy, emailSubject)
+	if err != nil {
+		fmt.Println("Failed to register attempt:", err)
+	}
+	lockIP, err := models.RegisterAttempt(ctxTimeout, rdb, models.MagicIPPolicy, ipSubject)
+	if err != nil {
+		fmt.Println("Failed to register attempt:", err)
+	}
+	if locked := max(lockEmail, lockIP); locked > 0 {
+		respondTooManyAttempts(ctx, locked)
+		return
+	}
+
+	user, err := models.GetUserByEmail(ctxTimeout, db, input.Email)
+	if err == nil {
+		if err := sendMagicLinkEmail(ctxTimeout, rdb, user.Id, user.Email); err != nil {
+			fmt.Println("Failed to send magic link:", err)
+		}
+	}
+
+	ctx.JSON(200, models.Response{
+		Success: true,
+		Message: "If the account exists, a login link has been sent to the email",
+	})
+}
+
+func sendMagicLinkEmail(ctx context.Context, rdb *redis.Client, userID int, email string) error {
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return err
+	}
+
+	if err := models.SaveMagicLinkToken(ctx, rdb, userID, token, models.MagicLinkTTL); err != nil {
+		return err
+	}
+
+	loginLink := fmt.Sprintf("%s?token=%s", os.Getenv("FRONTEND_MAGIC_LINK_URL"), url.QueryEscape(token))
+
+	// --- MESSAGE EMAIL ---
+	emailBody := fmt.Sprintf(`
+    <div style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
+        <h2 style="color:  #8B4513;">Masuk ke Akun</h2>
+        <p>Halo,</p>
+        <p>Klik tombol berikut untuk masuk ke akun Anda tanpa password:</p>
+        <p>
+            <a href="%s" style="display: inline-block; padding: 10px 20px; background-color:  #8B4513; color: #fff; text-decoration: none; border-radius: 5px;">
+                Masuk Sekarang
+            </a>
+        </p>
+        <p>Link ini hanya bisa dipakai sekali dan berlaku selama %d menit. Jika Anda tidak meminta link ini, abaikan email ini.</p>
+        <p>Salam,<br/>Tim Senja Kopi kiri</p>
+    </div>
+`, loginLink, int(models.MagicLinkTTL.Minutes()))
+
+	return utils.Send(utils.SendOptions{
+		To:         []string{email},
+		Subject:    "Link Masuk",
+		Body:       emailBody,
+		BodyIsHTML: true,
+	})
+}
+
+// MagicLinkLogin godoc
+// @Summary Login with magic link
+// @Description Exchange the token from the magic link for an access token and refresh token. When two-factor authentication is required, a challenge is returned instead, finish it at /auth/login/2fa
+// @Tags Auth
+// @Param input body models.ReqMagicLinkLogin true "Magic link token"
+// @Success 200 {object} models.ResponseSucces
+// @Failure 400 {object} models.Response "Invalid or expired link"
+// @Router /auth/magic-link/verify [post]
+func MagicLinkLogin(ctx *gin.Context, db *pgxpool.Pool, rdb *redis.Client) {
+	var input models.ReqMagicLinkLogin
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(400, models.Response{
+			Success: false,
+			Message: "token is required",
+		})
+		return
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// --- TOKEN IS DELETED ON READ, SO IT CAN'T BE USED TWICE ---
+	userID, err := models.ConsumeMagicLinkToken(ctxTimeout, rdb, input.Token)
+	if err != nil {
+		if errors.Is(err, models.ErrMagicLinkInvalid) {
+			ctx.JSON(400, models.Response{
+				Success: false,
+				Message: err.Error(),
+			})
+			return
+		}
+		fmt.Println("Redis Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{
+			Success: false,
+			Message: "internal server error",
+		})
+		return
+	}
+
+	user, err := models.GetUserByID(ctxTimeout, db, userID)
+	if err != nil {
+		ctx.JSON(400, models.Response{
+			Success: false,
+			Message: models.ErrMagicLinkInvalid.Error(),
+		})
+		return
+	}
+
+	// --- OPENING THE LINK PROVES OWNERSHIP OF THE EMAIL ---
+	if user.VerifiedAt == nil {
+		if err := models.MarkEmailVerified(ctxTimeout, db, user.Id); err != nil {
+			fmt.Println("Failed to verify email:", err)
+		}
+	}
+
+	if err := models.ResetAttempts(ctxTimeout, rdb, models.ScopeLogin, models.EmailSubject(user.Email)); err != nil {
+		fmt.Println("Failed to reset login attempts:", err)
+	}
+
+	finishLogin(ctx, ctxTimeout, db, rdb, user)
+}
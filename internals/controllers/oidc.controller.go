@@ -0,0 +1,172 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/federus1105/koda-b4-backend/internals/models"
+	"github.com/federus1105/koda-b4-backend/internals/pkg/libs"
+	"github.com/federus1105/koda-b4-backend/internals/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+)
+
+// OIDCLogin godoc
+// @Summary 	Start social login
+// @Description Returns the provider authorization URL, the provider redirects back with code and state for /auth/oidc/callback
+// @Tags 		Auth
+// @Success 	200 {object} 	models.ResponseSucces
+// @Failure 	503 {object} 	models.Response
+// @Router 		/auth/oidc/login [get]
+func OIDCLogin(ctx *gin.Context, rdb *redis.Client, provider *libs.OIDCProvider) {
+	if !provider.Enabled() {
+		ctx.JSON(503, models.Response{
+			Success: false,
+			Message: libs.ErrOIDCDisabled.Error(),
+		})
+		return
+	}
+
+	state, errState := utils.GenerateRandomToken(32)
+	nonce, errNonce := utils.GenerateRandomToken(32)
+	verifier, errVerifier := utils.GenerateRandomToken(32)
+	if err := errors.Join(errState, errNonce, errVerifier); err != nil {
+		fmt.Println("Internal Server Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{
+			Success: false,
+			Message: "internal server error",
+		})
+		return
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := models.SaveOIDCState(ctxTimeout, rdb, state, models.OIDCState{
+		Nonce:    nonce,
+		Verifier: verifier,
+	}); err != nil {
+		fmt.Println("Redis Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{
+			Success: false,
+			Message: "internal server error",
+		})
+		return
+	}
+
+	ctx.JSON(200, models.ResponseSucces{
+		Success: true,
+		Message: "redirect to provider",
+		Result: gin.H{
+			"provider":          provider.Name(),
+			"authorization_url": provider.AuthCodeURL(state, nonce, verifier),
+			"expires_in":        int(models.OIDCStateTTL.Seconds()),
+		},
+	})
+}
+
+// OIDCCallback godoc
+// @Summary 	Finish social login
+// @Description Exchange the authorization code, validate the ID token and log in. The account is linked by verified email or created when it does not exist
+// @Tags 		Auth
+// @Param 		request body 	models.ReqOIDCCallback  true 	"Code and state from provider redirect"
+// @Success 	200 {object} 	models.ResponseSucces
+// @Failure 	401 {object} 	models.Response
+// @Router 		/auth/oidc/callback [post]
+func OIDCCallback(ctx *gin.Context, db *pgxpool.Pool, rdb *redis.Client, provider *libs.OIDCProvider) {
+	if !provider.Enabled() {
+		ctx.JSON(503, models.Response{
+			Success: false,
+			Message: libs.ErrOIDCDisabled.Error(),
+		})
+		return
+	}
+
+	var req models.ReqOIDCCallback
+	// --- VALIDATION ---
+	if !bindJSON(ctx, &req) {
+		return
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	// --- STATE MUST COME FROM OUR OWN REDIRECT ---
+	state, err := models.TakeOIDCState(ctxTimeout, rdb, req.State)
+	if err != nil {
+		fmt.Println("Redis Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{
+			Success: false,
+			Message: "internal server error",
+		})
+		return
+	}
+	if state == nil {
+		ctx.JSON(401, models.Response{
+			Success: false,
+			Message: "invalid or expired login state, please try again",
+		})
+		return
+	}
+
+	rawIDToken, err := provider.Exchange(ctxTimeout, req.Code, state.Verifier)
+	if err != nil {
+		fmt.Println("OIDC exchange failed.\nCause: ", err)
+		ctx.JSON(401, models.Response{
+			Success: false,
+			Message: "failed to sign in with provider",
+		})
+		return
+	}
+
+	identity, err := provider.VerifyIDToken(ctxTimeout, rawIDToken, state.Nonce)
+	if err != nil {
+		fmt.Println("OIDC id token rejected.\nCause: ", err)
+		ctx.JSON(401, models.Response{
+			Success: false,
+			Message: "failed to sign in with provider",
+		})
+		return
+	}
+
+	// --- RANDOM PASSWORD, USER CAN SET ONE WITH FORGOT PASSWORD ---
+	randomPassword, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		fmt.Println("Internal Server Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{
+			Success: false,
+			Message: "internal server error",
+		})
+		return
+	}
+	hashed, err := libs.HashPassword(randomPassword)
+	if err != nil {
+		fmt.Println("Internal Server Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{
+			Success: false,
+			Message: "internal server error",
+		})
+		return
+	}
+
+	user, err := models.LoginWithIdentity(ctxTimeout, db, provider.Name(), identity, hashed)
+	if err != nil {
+		if errors.Is(err, models.ErrOIDCEmailNotVerified) {
+			ctx.JSON(403, models.Response{
+				Success: false,
+				Message: "your email at the provider is not verified",
+			})
+			return
+		}
+		ctx.JSON(500, models.Response{
+			Success: false,
+			Message: "internal server error",
+		})
+		return
+	}
+
+	finishLogin(ctx, ctxTimeout, db, rdb, user)
+}
@@ -10,12 +10,12 @@ import (
 	"strings"
 	"time"
 
-	"github.com/federus1105/koda-b4-backend/internals/middlewares"
 	"github.com/federus1105/koda-b4-backend/internals/models"
 	"github.com/federus1105/koda-b4-backend/internals/pkg/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
 )
 
 // CreateCartProduct godoc
@@ -24,7 +24,7 @@ import (
 // @Tags Cart
 // @Param request body models.CartItemRequest true "Request Body"
 // @Success 200 {object} models.ResponseSucces "Product added to cart successfully"
-// @Failure 400 {object} models.Response "Invalid JSON, validation error, out of stock, or insufficient stock"
+// @Failure 400 {object} models.Response "Invalid JSON, validation error, size and variant combination not available, out of stock, or insufficient stock"
 // @Failure 401 {object} models.Response "Unauthorized: user not logged in"
 // @Failure 404 {object} models.Response "Product not found"
 // @Failure 500 {object} models.Response "Internal server error"
@@ -56,28 +56,11 @@ func CreateCartProduct(ctx *gin.Context, db *pgxpool.Pool) {
 	}
 
 	// --- GET USER IN CONTEXT ---
-	userIDInterface, exists := ctx.Get(middlewares.UserIDKey)
-	if !exists {
-		ctx.JSON(401, models.Response{
-			Success: false,
-			Message: "Unauthorized: user not logged in",
-		})
-		return
-	}
-
-	var userID int
-	switch v := userIDInterface.(type) {
-	case int:
-		userID = v
-	case float64:
-		userID = int(v)
-	default:
-		ctx.JSON(401, models.Response{
-			Success: false,
-			Message: "Invalid user ID type in context",
-		})
+	user, ok := currentUser(ctx)
+	if !ok {
 		return
 	}
+	userID := user.ID
 
 	// --- LIMIT EXECUTION TIME ---
 	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -100,6 +83,11 @@ func CreateCartProduct(ctx *gin.Context, db *pgxpool.Pool) {
 				Success: false,
 				Message: errMsg,
 			})
+		case strings.Contains(errMsg, "size and variant combination is not available"):
+			ctx.JSON(400, models.Response{
+				Success: false,
+				Message: errMsg,
+			})
 		case strings.Contains(errMsg, "product Not found"):
 			ctx.JSON(404, models.Response{
 				Success: false,
@@ -134,24 +122,12 @@ func CreateCartProduct(ctx *gin.Context, db *pgxpool.Pool) {
 // @Router /cart [get]
 // @Security BearerAuth
 func GetCartProduct(ctx *gin.Context, db *pgxpool.Pool) {
-	userIDRaw, exists := ctx.Get(middlewares.UserIDKey)
-	// --- CHECKING IN CONTEXT ---
-	if !exists {
-		ctx.JSON(401, models.Response{
-			Success: false,
-			Message: "User ID not found in context",
-		})
-		return
-	}
-
-	userID, ok := userIDRaw.(int)
+	// --- GET USER IN CONTEXT ---
+	user, ok := currentUser(ctx)
 	if !ok {
-		ctx.JSON(500, models.Response{
-			Success: false,
-			Message: "User ID in context is invalid",
-		})
 		return
 	}
+	userID := user.ID
 
 	// --- LIMIT EXECUTION TIME ---
 	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -184,7 +160,7 @@ func GetCartProduct(ctx *gin.Context, db *pgxpool.Pool) {
 // @Failure 500 {object} models.Response "Internal server error"
 // @Router /transactions [post]
 // @Security BearerAuth
-func Transactions(ctx *gin.Context, db *pgxpool.Pool) {
+func Transactions(ctx *gin.Context, db *pgxpool.Pool, rdb *redis.Client) {
 	var input models.TransactionsInput
 
 	// --- VALIDATION ---
@@ -210,35 +186,18 @@ func Transactions(ctx *gin.Context, db *pgxpool.Pool) {
 	}
 
 	// --- GET USER IN CONTEXT ---
-	userIDInterface, exists := ctx.Get(middlewares.UserIDKey)
-	if !exists {
-		ctx.JSON(401, models.Response{
-			Success: false,
-			Message: "Unauthorized: user not logged in",
-		})
-		return
-	}
-
-	var userID int
-	switch v := userIDInterface.(type) {
-	case int:
-		userID = v
-	case float64:
-		userID = int(v)
-	default:
-		ctx.JSON(401, models.Response{
-			Success: false,
-			Message: "Invalid user ID type in context",
-		})
+	user, ok := currentUser(ctx)
+	if !ok {
 		return
 	}
+	userID := user.ID
 
 	// --- LIMIT EXECUTION TIME ---
 	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	// --- CALL MODEL FUNCTION ---
-	result, err := models.Transactions(ctxTimeout, db, input, userID)
+	result, err := models.Transactions(ctxTimeout, db, rdb, input, userID)
 	if err != nil {
 		var ve utils.ValidationError
 		if errors.As(err, &ve) {
@@ -287,28 +246,11 @@ func DeleteCart(ctx *gin.Context, db *pgxpool.Pool) {
 	}
 
 	// --- GET USER IN CONTEXT ---
-	userIDInterface, exists := ctx.Get(middlewares.UserIDKey)
-	if !exists {
-		ctx.JSON(401, models.Response{
-			Success: false,
-			Message: "Unauthorized: user not logged in",
-		})
-		return
-	}
-
-	var userID int
-	switch v := userIDInterface.(type) {
-	case int:
-		userID = v
-	case float64:
-		userID = int(v)
-	default:
-		ctx.JSON(401, models.Response{
-			Success: false,
-			Message: "Invalid user ID type in context",
-		})
+	user, ok := currentUser(ctx)
+	if !ok {
 		return
 	}
+	userID := user.ID
 
 	// ---- LIMITS QUERY EXECUTION TIME ---
 	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
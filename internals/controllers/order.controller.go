@@ -20,22 +20,23 @@ import (
 // @Description 	Get paginated list of orders with optional filters
 // @Tags 		Orders
 // @Param 		page 		query 	int 	false 	"Page number" 	default(1)
+// @Param 		cursor 		query 	string 	false 	"Keyset mode, empty for the first page then nextCursor of the previous page"
+// @Param 		count 		query 	bool 	false 	"Also return total in keyset mode"
 // @Param 		ordernumber query 	string 	false 	"Filter by order number"
 // @Param 		status 		query 	string 	false 	"Filter by status"
 // @Success 200 {object} models.ResponseSucces
 // @Router /admin/order [get]
 // @Security BearerAuth
+// @Security ApiKeyAuth
 func GetListOrder(ctx *gin.Context, db *pgxpool.Pool) {
 	// --- GET QUERY PARAMS ---
-	pageStr := ctx.Query("page")
-	page, err := strconv.Atoi(pageStr)
-	if err != nil || page < 1 {
-		page = 1
+	lp, ok := bindPage(ctx, "order", 10)
+	if !ok {
+		return
 	}
+	page, limit := lp.Number, lp.Limit
 
 	// --- ARGUMEN ---
-	limit := 10
-	offset := (page - 1) * limit
 	orderNumber := ctx.Query("ordernumber")
 
 	// --- FILTER STATUS ---
@@ -47,15 +48,19 @@ func GetListOrder(ctx *gin.Context, db *pgxpool.Pool) {
 	defer cancel()
 
 	// --- TOTAL ORDER ---
-	total, err := models.GetCountOrder(ctxTimeout, db, orderNumber, status)
-	if err != nil {
-		ctx.JSON(500, models.Response{
-			Success: false,
-			Message: "Failed to get total orders",
-		})
-		return
+	var total int64
+	if lp.WithCount {
+		count, err := models.GetCountOrder(ctxTimeout, db, orderNumber, status)
+		if err != nil {
+			ctx.JSON(500, models.Response{
+				Success: false,
+				Message: "Failed to get total orders",
+			})
+			return
+		}
+		total = count
 	}
-	order, err := models.GetListOrder(ctxTimeout, db, orderNumber, status, limit, offset)
+	order, next, err := models.GetListOrder(ctxTimeout, db, orderNumber, status, lp.Page)
 	if err != nil {
 		ctx.JSON(500, models.Response{
 			Success: false,
@@ -103,11 +108,15 @@ func GetListOrder(ctx *gin.Context, db *pgxpool.Pool) {
 		return
 	}
 
+	if lp.Keyset {
+		ctx.JSON(200, keysetResponse(ctx, lp, total, order, next))
+		return
+	}
 	ctx.JSON(200, models.PaginatedResponse[models.OrderList]{
-		Page:       page,
+		Page:       &page,
 		Limit:      limit,
-		Total:      total,
-		TotalPages: totalPages,
+		Total:      &total,
+		TotalPages: &totalPages,
 		PrevURL:    prevURL,
 		NextURL:    nextURL,
 		Result:     order,
@@ -122,6 +131,7 @@ func GetListOrder(ctx *gin.Context, db *pgxpool.Pool) {
 // @Success 200 {object} models.ResponseSucces
 // @Router /admin/order/{id} [get]
 // @Security BearerAuth
+// @Security ApiKeyAuth
 func GetDetailOrder(ctx *gin.Context, db *pgxpool.Pool) {
 	// --- GET ORDER ID ---
 	orderIDStr := ctx.Param("id")
@@ -171,6 +181,7 @@ func GetDetailOrder(ctx *gin.Context, db *pgxpool.Pool) {
 // @Success 200 {object} models.ResponseSucces
 // @Router /admin/order/{id} [put]
 // @Security BearerAuth
+// @Security ApiKeyAuth
 func UpdateOrderStatus(ctx *gin.Context, db *pgxpool.Pool) {
 	// --- GET ORDER ID ---
 	orderIDStr := ctx.Param("id")
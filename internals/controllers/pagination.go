@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/federus1105/koda-b4-backend/internals/models"
+	"github.com/federus1105/koda-b4-backend/internals/pkg/libs"
+	"github.com/gin-gonic/gin"
+)
+
+type listPage struct {
+	models.Page
+	// --- PAGE NUMBER OF OFFSET MODE ---
+	Number int
+	// --- OFFSET MODE ALWAYS COUNTS, KEYSET ONLY WITH ?count=true ---
+	WithCount bool
+}
+
+// --- ?cursor SWITCHES TO KEYSET MODE, AN EMPTY cursor ASKS FOR THE FIRST PAGE ---
+func bindPage(ctx *gin.Context, list string, limit int) (listPage, bool) {
+	token, keyset := ctx.GetQuery("cursor")
+	if !keyset {
+		page, err := strconv.Atoi(ctx.Query("page"))
+		if err != nil || page < 1 {
+			page = 1
+		}
+		return listPage{
+			Page:      models.Page{Limit: limit, Offset: (page - 1) * limit},
+			Number:    page,
+			WithCount: true,
+		}, true
+	}
+
+	lp := listPage{
+		Page:      models.Page{Limit: limit, Keyset: true},
+		WithCount: ctx.Query("count") == "true",
+	}
+	if token != "" {
+		after, err := libs.DecodeCursor(token, list)
+		if err != nil {
+			ctx.JSON(400, models.Response{
+				Success: false,
+				Message: "invalid cursor",
+			})
+			return listPage{}, false
+		}
+		lp.After = after
+	}
+	return lp, true
+}
+
+// --- NEXT URL KEEPS THE CURRENT FILTERS, THERE IS NO PREVIOUS PAGE IN KEYSET MODE ---
+func keysetResponse[T any](ctx *gin.Context, lp listPage, total int64, items []T, next *libs.Cursor) models.PaginatedResponse[T] {
+	res := models.PaginatedResponse[T]{
+		Limit:  lp.Limit,
+		Result: items,
+	}
+	if lp.WithCount {
+		totalPages := int(math.Ceil(float64(total) / float64(lp.Limit)))
+		res.Total = &total
+		res.TotalPages = &totalPages
+	}
+	if next != nil {
+		token := next.Encode()
+		q := ctx.Request.URL.Query()
+		q.Del("page")
+		q.Set("cursor", token)
+		u := ctx.Request.URL.Path + "?" + q.Encode()
+		res.NextCursor = &token
+		res.NextURL = &u
+	}
+	return res
+}
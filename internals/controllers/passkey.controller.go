@@ -0,0 +1,340 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/federus1105/koda-b4-backend/internals/models"
+	"github.com/federus1105/koda-b4-backend/internals/pkg/libs"
+	"github.com/federus1105/koda-b4-backend/internals/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+)
+
+// --- MAP PASSKEY ERROR TO RESPONSE ---
+func respondPasskeyError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, models.ErrPasskeyNotFound):
+		ctx.JSON(404, models.Response{Success: false, Message: err.Error()})
+	case errors.Is(err, models.ErrPasskeyExists):
+		ctx.JSON(409, models.Response{Success: false, Message: err.Error()})
+	case errors.Is(err, models.ErrPasskeyLimit), errors.Is(err, models.ErrPasskeyChallenge),
+		errors.Is(err, libs.ErrWebAuthnInvalid), errors.Is(err, libs.ErrWebAuthnAlgorithm):
+		ctx.JSON(400, models.Response{Success: false, Message: err.Error()})
+	default:
+		fmt.Println("Internal Server Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{Success: false, Message: "internal server error"})
+	}
+}
+
+// --- RANDOM CHALLENGE, BASE64URL LIKE EVERY BINARY FIELD OF WEBAUTHN JSON ---
+func newPasskeyChallenge() (string, error) {
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+	return libs.EncodeBase64URL([]byte(token)), nil
+}
+
+// PasskeyRegisterOptions godoc
+// @Summary 	Start passkey registration
+// @Description Returns PublicKeyCredentialCreationOptions for navigator.credentials.create(), valid for 5 minutes
+// @Tags 		Profile
+// @Success 	200 {object} 	models.ResponseSucces{result=libs.WebAuthnCreationOptions}
+// @Security 	BearerAuth
+// @Router 		/profile/passkeys/options [post]
+func PasskeyRegisterOptions(ctx *gin.Context, db *pgxpool.Pool, rdb *redis.Client, webauthn libs.WebAuthnConfig) {
+	user, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	email, fullname, err := models.GetPasskeyUser(ctxTimeout, db, user.ID)
+	if err != nil {
+		respondPasskeyError(ctx, err)
+		return
+	}
+	exclude, err := models.GetPasskeyExcludes(ctxTimeout, db, user.ID)
+	if err != nil {
+		respondPasskeyError(ctx, err)
+		return
+	}
+
+	challenge, err := newPasskeyChallenge()
+	if err == nil {
+		err = models.SavePasskeyRegisterChallenge(ctxTimeout, rdb, user.ID, challenge)
+	}
+	if err != nil {
+		respondPasskeyError(ctx, err)
+		return
+	}
+
+	ctx.JSON(200, models.ResponseSucces{
+		Success: true,
+		Message: "Success",
+		Result:  webauthn.CreationOptions(challenge, models.PasskeyUserHandle(user.ID), email, fullname, exclude),
+	})
+}
+
+// RegisterPasskey godoc
+// @Summary 	Finish passkey registration
+// @Description Send the credential returned by navigator.credentials.create(), encoded with PublicKeyCredential.toJSON()
+// @Tags 		Profile
+// @Param 		request body 	models.ReqRegisterPasskey  true 	"Passkey"
+// @Success 	201 {object} 	models.ResponseSucces{result=models.Passkey}
+// @Failure 	400 {object} 	models.Response
+// @Failure 	409 {object} 	models.Response "Passkey is already registered"
+// @Security 	BearerAuth
+// @Router 		/profile/passkeys [post]
+func RegisterPasskey(ctx *gin.Context, db *pgxpool.Pool, rdb *redis.Client, webauthn libs.WebAuthnConfig) {
+	var req models.ReqRegisterPasskey
+	if !bindJSON(ctx, &req) {
+		return
+	}
+
+	user, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	credentialID, err := libs.DecodeBase64URL(req.Credential.ID)
+	if err != nil {
+		respondPasskeyError(ctx, libs.ErrWebAuthnInvalid)
+		return
+	}
+	clientData, err := libs.DecodeBase64URL(req.Credential.Response.ClientDataJSON)
+	if err != nil {
+		respondPasskeyError(ctx, libs.ErrWebAuthnInvalid)
+		return
+	}
+	attestation, err := libs.DecodeBase64URL(req.Credential.Response.AttestationObject)
+	if err != nil {
+		respondPasskeyError(ctx, libs.ErrWebAuthnInvalid)
+		return
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	challenge, err := models.TakePasskeyRegisterChallenge(ctxTimeout, rdb, user.ID)
+	if err != nil {
+		respondPasskeyError(ctx, err)
+		return
+	}
+
+	credential, err := webauthn.VerifyRegistration(challenge, clientData, attestation)
+	if err != nil {
+		respondPasskeyError(ctx, err)
+		return
+	}
+	if !bytes.Equal(credential.ID, credentialID) {
+		respondPasskeyError(ctx, libs.ErrWebAuthnInvalid)
+		return
+	}
+
+	passkey, err := models.CreatePasskey(ctxTimeout, db, user.ID, req.Name, req.Credential.Response.Transports, credential)
+	if err != nil {
+		respondPasskeyError(ctx, err)
+		return
+	}
+
+	ctx.JSON(201, models.ResponseSucces{
+		Success: true,
+		Message: "passkey registered",
+		Result:  passkey,
+	})
+}
+
+// GetPasskeys godoc
+// @Summary 	List passkeys
+// @Tags 		Profile
+// @Success 	200 {object} 	models.ResponseSucces{result=[]models.Passkey}
+// @Security 	BearerAuth
+// @Router 		/profile/passkeys [get]
+func GetPasskeys(ctx *gin.Context, db *pgxpool.Pool) {
+	user, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	passkeys, err := models.GetPasskeys(ctxTimeout, db, user.ID)
+	if err != nil {
+		respondPasskeyError(ctx, err)
+		return
+	}
+
+	ctx.JSON(200, models.ResponseSucces{
+		Success: true,
+		Message: "Success",
+		Result:  passkeys,
+	})
+}
+
+// DeletePasskey godoc
+// @Summary 	Remove passkey
+// @Description The passkey can't be used to log in anymore, remove it from the device as well
+// @Tags 		Profile
+// @Param 		id 	path 	int 	true 	"Passkey ID"
+// @Success 	200 {object} 	models.Response
+// @Failure 	404 {object} 	models.Response
+// @Security 	BearerAuth
+// @Router 		/profile/passkeys/{id} [delete]
+func DeletePasskey(ctx *gin.Context, db *pgxpool.Pool) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(404, models.Response{
+			Success: false,
+			Message: "Invalid passkey id",
+		})
+		return
+	}
+
+	user, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := models.DeletePasskey(ctxTimeout, db, user.ID, id); err != nil {
+		respondPasskeyError(ctx, err)
+		return
+	}
+
+	ctx.JSON(200, models.Response{
+		Success: true,
+		Message: "passkey removed",
+	})
+}
+
+// PasskeyLoginOptions godoc
+// @Summary 	Start passkey login
+// @Description Returns PublicKeyCredentialRequestOptions for navigator.credentials.get(), valid for 5 minutes
+// @Tags 		Auth
+// @Success 	200 {object} 	models.ResponseSucces{result=libs.WebAuthnRequestOptions}
+// @Router 		/auth/passkey/options [post]
+func PasskeyLoginOptions(ctx *gin.Context, rdb *redis.Client, webauthn libs.WebAuthnConfig) {
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	challenge, err := newPasskeyChallenge()
+	if err == nil {
+		err = models.SavePasskeyLoginChallenge(ctxTimeout, rdb, challenge)
+	}
+	if err != nil {
+		respondPasskeyError(ctx, err)
+		return
+	}
+
+	ctx.JSON(200, models.ResponseSucces{
+		Success: true,
+		Message: "Success",
+		Result:  webauthn.RequestOptions(challenge),
+	})
+}
+
+// PasskeyLogin godoc
+// @Summary 	Login with passkey
+// @Description Send the credential returned by navigator.credentials.get(), encoded with PublicKeyCredential.toJSON(). A passkey with user verification counts as two factors, otherwise the two-factor challenge of the account still applies
+// @Tags 		Auth
+// @Param 		request body 	models.ReqPasskeyLogin  true 	"Passkey assertion"
+// @Success 	200 {object} 	models.ResponseSucces
+// @Failure 	401 {object} 	models.Response
+// @Router 		/auth/passkey/login [post]
+func PasskeyLogin(ctx *gin.Context, db *pgxpool.Pool, rdb *redis.Client, webauthn libs.WebAuthnConfig) {
+	var req models.ReqPasskeyLogin
+	if !bindJSON(ctx, &req) {
+		return
+	}
+
+	credentialID, err1 := libs.DecodeBase64URL(req.ID)
+	clientData, err2 := libs.DecodeBase64URL(req.Response.ClientDataJSON)
+	authData, err3 := libs.DecodeBase64URL(req.Response.AuthenticatorData)
+	signature, err4 := libs.DecodeBase64URL(req.Response.Signature)
+	userHandle, err5 := libs.DecodeBase64URL(req.Response.UserHandle)
+	if err := errors.Join(err1, err2, err3, err4, err5); err != nil {
+		respondPasskeyError(ctx, libs.ErrWebAuthnInvalid)
+		return
+	}
+	challenge, err := libs.WebAuthnChallenge(clientData)
+	if err != nil {
+		respondPasskeyError(ctx, err)
+		return
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// --- CHALLENGE IS DELETED ON USE, SO A RESPONSE CAN'T BE REPLAYED ---
+	if err := models.TakePasskeyLoginChallenge(ctxTimeout, rdb, challenge); err != nil {
+		respondPasskeyError(ctx, err)
+		return
+	}
+
+	passkey, err := models.GetPasskeyByCredentialID(ctxTimeout, db, credentialID)
+	if err != nil && !errors.Is(err, models.ErrPasskeyNotFound) {
+		respondPasskeyError(ctx, err)
+		return
+	}
+	if err != nil || (len(userHandle) > 0 && !bytes.Equal(userHandle, models.PasskeyUserHandle(passkey.UserID))) {
+		ctx.JSON(401, models.Response{
+			Success: false,
+			Message: "passkey is not registered",
+		})
+		return
+	}
+
+	assertion, err := webauthn.VerifyAssertion(challenge, passkey.PublicKey, passkey.SignCount, clientData, authData, signature)
+	if err != nil {
+		if errors.Is(err, libs.ErrWebAuthnInvalid) || errors.Is(err, libs.ErrWebAuthnCounter) || errors.Is(err, libs.ErrWebAuthnAlgorithm) {
+			ctx.JSON(401, models.Response{
+				Success: false,
+				Message: err.Error(),
+			})
+			return
+		}
+		respondPasskeyError(ctx, err)
+		return
+	}
+	if err := models.UpdatePasskeyUsage(ctxTimeout, db, passkey.ID, assertion); err != nil {
+		fmt.Println("Failed to update passkey usage:", err)
+	}
+
+	user, err := models.GetUserByID(ctxTimeout, db, passkey.UserID)
+	if err != nil {
+		respondPasskeyError(ctx, err)
+		return
+	}
+
+	// --- EMAIL MUST BE VERIFIED ---
+	if user.VerifiedAt == nil {
+		ctx.JSON(403, models.Response{
+			Success: false,
+			Message: "email not verified, please check your inbox or request a new verification link",
+		})
+		return
+	}
+
+	if err := models.ResetAttempts(ctxTimeout, rdb, models.ScopeLogin, models.EmailSubject(user.Email)); err != nil {
+		fmt.Println("Failed to reset login attempts:", err)
+	}
+
+	// --- DEVICE UNLOCKED WITH BIOMETRIC OR PIN, ALREADY TWO FACTORS ---
+	if assertion.UserVerified {
+		issueLogin(ctx, ctxTimeout, rdb, user)
+		return
+	}
+	finishLogin(ctx, ctxTimeout, db, rdb, user)
+}
@@ -91,10 +91,10 @@ func GetListFavoriteProduct(ctx *gin.Context, db *pgxpool.Pool) {
 		return
 	}
 	ctx.JSON(200, models.PaginatedResponse[models.FavoriteProduct]{
-		Page:       page,
+		Page:       &page,
 		Limit:      limit,
-		Total:      total,
-		TotalPages: totalPages,
+		Total:      &total,
+		TotalPages: &totalPages,
 		PrevURL:    prevURL,
 		NextURL:    nextURL,
 		Result:     products,
@@ -106,12 +106,12 @@ func GetListFavoriteProduct(ctx *gin.Context, db *pgxpool.Pool) {
 // @Description Retrieves a list of products using filters such as name, category, price range, sorting, and pagination.
 // @Tags Products
 // @Param page query int false "Page number (default: 1)"
-// @Param name query string false "Search by product name"
+// @Param name query string false "Search name, description and category, tolerates typos. Results are ordered by relevance and carry a highlighted snippet"
 // @Param category query []int false "Filter by category IDs (can be multiple)"
 // @Param min_price query number false "Minimum price"
 // @Param max_price query number false "Maximum price"
-// @Param sort_by query string false "Sort by criteria (price_asc, price_desc, latest, oldest)"
-// @Success 200 {object} models.ResponseSucces "Successful response with product list"
+// @Param sort_by query string false "Sort by criteria (relevance, priceOriginal, name, rating), relevance is the default while searching"
+// @Success 200 {object} models.ProductFilterResponse "Product list with category, price, size and variant facets"
 // @Failure 500 {object} models.Response "Failed to retrieve product list"
 // @Router /product [get]
 // @Security BearerAuth
@@ -175,6 +175,17 @@ func GetListProductFilter(ctx *gin.Context, db *pgxpool.Pool, rd *redis.Client)
 		return
 	}
 
+	// --- FACETS FOR THE FILTER SIDEBAR ---
+	facets, err := models.GetProductFacets(ctxTimeout, db, rd, name, categoryIDs, minPrice, maxPrice)
+	if err != nil {
+		ctx.JSON(500, models.Response{
+			Success: false,
+			Message: "Failed Get product facets",
+		})
+		fmt.Println("Error : ", err.Error())
+		return
+	}
+
 	// --- TOTAL PAGES ---
 	totalPages := int(math.Ceil(float64(total) / float64(limit)))
 
@@ -219,27 +230,32 @@ func GetListProductFilter(ctx *gin.Context, db *pgxpool.Pool, rd *redis.Client)
 		ctx.JSON(http.StatusOK, gin.H{
 			"success": true,
 			"data":    []string{},
+			"facets":  facets,
 			"message": "Not found list product",
 		})
 		return
 	}
-	ctx.JSON(200, models.PaginatedResponse[models.FavoriteProduct]{
-		Page:       page,
-		Limit:      limit,
-		Total:      total,
-		TotalPages: totalPages,
-		PrevURL:    prevURL,
-		NextURL:    nextURL,
-		Result:     products,
+	ctx.JSON(200, models.ProductFilterResponse{
+		PaginatedResponse: models.PaginatedResponse[models.FavoriteProduct]{
+			Page:       &page,
+			Limit:      limit,
+			Total:      &total,
+			TotalPages: &totalPages,
+			PrevURL:    prevURL,
+			NextURL:    nextURL,
+			Result:     products,
+		},
+		Facets: facets,
 	})
 }
 
 // GetProductById godoc
 // @Summary Get product by ID
-// @Description Retrieves detailed information about a product using its ID.
+// @Description Retrieves detailed information about a product using its ID. Login is optional, an invalid token is still rejected.
 // @Tags Products
 // @Param id path int true "Product ID"
 // @Success 200 {object} models.ResponseSucces "Product retrieved successfully"
+// @Failure 401 {object} models.Response "Invalid or expired token"
 // @Failure 404 {object} models.Response "Product not found or invalid product ID"
 // @Failure 500 {object} models.Response "Failed to retrieve product"
 // @Router /product/{id} [get]
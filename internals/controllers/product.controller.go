@@ -3,24 +3,23 @@ package controllers
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
 	"math"
-	"mime/multipart"
 	"net/http"
 	"net/url"
-	"os"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/cloudinary/cloudinary-go/v2"
-	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
 	"github.com/federus1105/koda-b4-backend/internals/models"
 	"github.com/federus1105/koda-b4-backend/internals/pkg/libs"
 	"github.com/federus1105/koda-b4-backend/internals/pkg/utils"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/joho/godotenv"
@@ -32,20 +31,20 @@ import (
 // @Description Get paginated list of products with optional name filter
 // @Tags Products
 // @Param page query int false "Page number" default(1)
-// @Param name query string false "Filter by product name"
+// @Param cursor query string false "Keyset mode, empty for the first page then nextCursor of the previous page"
+// @Param count query bool false "Also return total in keyset mode"
+// @Param name query string false "Search name, description and category, tolerates typos. Results are ordered by relevance"
 // @Success 200 {object} models.ResponseSucces
 // @Router /admin/product [get]
 // @Security BearerAuth
+// @Security ApiKeyAuth
 func GetListProduct(ctx *gin.Context, db *pgxpool.Pool, rd *redis.Client) {
 	// --- GET QUERY PARAMS ---
-	pageStr := ctx.Query("page")
-	page, err := strconv.Atoi(pageStr)
-	if err != nil || page < 1 {
-		page = 1
+	lp, ok := bindPage(ctx, "product", 10)
+	if !ok {
+		return
 	}
-
-	limit := 10
-	offset := (page - 1) * limit
+	page, limit := lp.Number, lp.Limit
 	name := ctx.Query("name")
 
 	// ---- LIMITS QUERY EXECUTION TIME ---
@@ -53,16 +52,20 @@ func GetListProduct(ctx *gin.Context, db *pgxpool.Pool, rd *redis.Client) {
 	defer cancel()
 
 	// --- GET TOTAL COUNT ---
-	total, err := models.GetCountProduct(ctxTimeout, db, name)
-	if err != nil {
-		ctx.JSON(500, gin.H{
-			"success": false,
-			"message": "Failed to get total product count",
-		})
-		return
+	var total int64
+	if lp.WithCount {
+		count, err := models.GetCountProduct(ctxTimeout, db, name)
+		if err != nil {
+			ctx.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to get total product count",
+			})
+			return
+		}
+		total = count
 	}
 
-	products, err := models.GetListProduct(ctxTimeout, db, rd, name, limit, offset)
+	products, next, err := models.GetListProduct(ctxTimeout, db, rd, name, lp.Page)
 	if err != nil {
 		ctx.JSON(500, models.Response{
 			Success: false,
@@ -116,11 +119,15 @@ func GetListProduct(ctx *gin.Context, db *pgxpool.Pool, rd *redis.Client) {
 		})
 		return
 	}
+	if lp.Keyset {
+		ctx.JSON(200, keysetResponse(ctx, lp, total, products, next))
+		return
+	}
 	ctx.JSON(200, models.PaginatedResponse[models.Product]{
-		Page:       page,
+		Page:       &page,
 		Limit:      limit,
-		Total:      total,
-		TotalPages: totalPages,
+		Total:      &total,
+		TotalPages: &totalPages,
 		PrevURL:    prevURL,
 		NextURL:    nextURL,
 		Result:     products,
@@ -135,16 +142,18 @@ func GetListProduct(ctx *gin.Context, db *pgxpool.Pool, rd *redis.Client) {
 // @Accept multipart/form-data
 // @Param name formData string true "Product name"
 // @Param description formData string true "Product description"
-// @Param rating formData number true "Product rating"
 // @Param price formData number true "Product price"
-// @Param stock formData int true "Product stock"
-// @Param image_one formData file true "Primary product image"
-// @Param image_two formData file false "Secondary image"
-// @Param image_three formData file false "Third image"
-// @Param image_four formData file false "Fourth image"
-// @Success 200 {object} models.ResponseSucces
+// @Param stock formData int false "Stock of every size and variant combination, ignored when skus is sent"
+// @Param size formData []int false "Size IDs" collectionFormat(multi)
+// @Param variant formData []int false "Variant IDs" collectionFormat(multi)
+// @Param skus formData string false "JSON array of models.SkuInput, replaces size, variant and stock"
+// @Param images formData file false "Gallery images in order, the first one is primary. Send the field once per file, at most 10"
+// @Param alt_text formData []string false "Alt text of the image at the same index" collectionFormat(multi)
+// @Success 200 {object} models.ResponseSucces{result=models.ProductResponse}
+// @Failure 400 {object} models.Response
 // @Router /admin/product [post]
 // @Security BearerAuth
+// @Security ApiKeyAuth
 func CreateProduct(ctx *gin.Context, db *pgxpool.Pool, rd *redis.Client, cld *cloudinary.Cloudinary) {
 	var body models.CreateProducts
 	godotenv.Load()
@@ -171,93 +180,38 @@ func CreateProduct(ctx *gin.Context, db *pgxpool.Pool, rd *redis.Client, cld *cl
 		return
 	}
 
-	// --- CHECKING CLAIMS TOKEN ---
-	claims, exists := ctx.Get("claims")
-	if !exists {
-		fmt.Println("ERROR :", !exists)
-		ctx.AbortWithStatusJSON(403, models.Response{
-			Success: false,
-			Message: "Please log in again",
-		})
-		return
-	}
-	user, ok := claims.(libs.Claims)
+	skus, ok := bindSkus(ctx, body.Skus)
 	if !ok {
-		fmt.Println("ERROR", !ok)
-		ctx.AbortWithStatusJSON(500, models.Response{
-			Success: false,
-			Message: "An error occurred!, please try again.",
-		})
 		return
 	}
+	body.SkuItems = skus
 
-	// --- UPLOAD IMAGES ---
-	imageFiles := map[string]*multipart.FileHeader{
-		"photos_one":   body.Image_one,
-		"photos_two":   body.Image_two,
-		"photos_three": body.Image_three,
-		"photos_four":  body.Image_four,
+	// --- GET USER IN CONTEXT ---
+	user, ok := currentUser(ctx)
+	if !ok {
+		return
 	}
 
-	imageStrs := map[string]string{}
-	useCloudinary := os.Getenv("CLOUDINARY_URL") != ""
-
-	// --- MULTIPLE UPLOAD IMAGES ---
-	for key, file := range imageFiles {
-		if file == nil {
-			continue
-		}
-		if !useCloudinary {
-
-			savePath, generatedFilename, err := utils.UploadImageFile(ctx, file, "public", fmt.Sprintf("%s_%d", key, user.ID))
-			if err != nil {
-				ctx.JSON(400, models.Response{
-					Success: false,
-					Message: err.Error(),
-				})
-				return
-			}
-
-			if err := ctx.SaveUploadedFile(file, savePath); err != nil {
-				ctx.JSON(500, models.Response{
-					Success: false,
-					Message: fmt.Sprintf("Failed to save %s", key),
-				})
-				return
-			}
-
-			imageStrs[key] = generatedFilename
-		} else {
-			// --- UPLOAD CLOUDINARY ---
-			overwrite := true
-			uploadResp, err := cld.Upload.Upload(ctx, file, uploader.UploadParams{
-				Folder:    "assets/product",
-				PublicID:  fmt.Sprintf("%s_%d", key, user.ID),
-				Overwrite: &overwrite,
-			})
-
-			if err != nil {
-				ctx.JSON(500, gin.H{
-					"success": false,
-					"message": fmt.Sprintf("Failed to upload %s to cloudinary", key),
-				})
-				return
-			}
-			imageStrs[key] = uploadResp.SecureURL
-		}
+	// --- UPLOAD IMAGES, GALLERY KEEPS THE ORDER THEY WERE SENT IN ---
+	urls, ok := uploadProductImages(ctx, cld, body.Images, user.ID)
+	if !ok {
+		return
 	}
-
-	// --- ASSIGN TO BODY ---
-	body.Image_oneStr = imageStrs["photos_one"]
-	body.Image_twoStr = imageStrs["photos_two"]
-	body.Image_threeStr = imageStrs["photos_three"]
-	body.Image_fourStr = imageStrs["photos_four"]
+	body.ImageItems = models.NewProductImages(urls, body.AltText)
 
 	// ---- LIMITS QUERY EXECUTION TIME ---
 	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	product, err := models.CreateProduct(ctxTimeout, db, rd, body)
 	if err != nil {
+		var ve utils.ValidationError
+		if errors.As(err, &ve) {
+			ctx.JSON(400, models.Response{
+				Success: false,
+				Message: ve.Error(),
+			})
+			return
+		}
 		log.Println("ERROR : ", err)
 		ctx.JSON(500, models.Response{
 			Success: false,
@@ -266,27 +220,21 @@ func CreateProduct(ctx *gin.Context, db *pgxpool.Pool, rd *redis.Client, cld *cl
 		return
 	}
 
-	//  ---- ASIGN LIST IMAGE ---
-	images := make(map[string]string)
-	if product.Image_oneStr != "" {
-		images["image_one"] = product.Image_oneStr
-	}
-	if product.Image_twoStr != "" {
-		images["image_two"] = product.Image_twoStr
-	}
-	if product.Image_threeStr != "" {
-		images["image_three"] = product.Image_threeStr
-	}
-	if product.Image_fourStr != "" {
-		images["image_four"] = product.Image_fourStr
+	skuList, err := models.GetProductSkus(ctxTimeout, db, product.Id, false)
+	if err != nil {
+		log.Println("ERROR : ", err)
+		ctx.JSON(500, models.Response{
+			Success: false,
+			Message: "An error occurred while saving data",
+		})
+		return
 	}
 
 	// --- ASSIGN TO STRUCT RESPONSE ---
 	response := models.ProductResponse{
 		ID:          product.Id,
 		Name:        product.Name,
-		ImageID:     product.ImageId,
-		Images:      images,
+		Images:      product.Gallery,
 		Price:       product.Price,
 		Rating:      product.Rating,
 		Description: product.Description,
@@ -294,6 +242,7 @@ func CreateProduct(ctx *gin.Context, db *pgxpool.Pool, rd *redis.Client, cld *cl
 		Size:        product.Size,
 		Variant:     product.Variant,
 		Category:    product.Category,
+		Skus:        skuList,
 	}
 
 	ctx.JSON(200, models.ResponseSucces{
@@ -306,22 +255,25 @@ func CreateProduct(ctx *gin.Context, db *pgxpool.Pool, rd *redis.Client, cld *cl
 
 // EditProduct godoc
 // @Summary Edit an existing product
-// @Description Update product details and optionally update images
+// @Description Update product details and optionally add images, use the image endpoints to delete or reorder them
 // @Tags Products
 // @Accept multipart/form-data
 // @Param id path int true "Product ID"
 // @Param name formData string false "Product name"
 // @Param description formData string false "Product description"
-// @Param rating formData number false "Product rating"
 // @Param price formData number false "Product price"
-// @Param stock formData int false "Product stock"
-// @Param image_one formData file false "Primary product image"
-// @Param image_two formData file false "Secondary image"
-// @Param image_three formData file false "Third image"
-// @Param image_four formData file false "Fourth image"
+// @Param stock formData int false "Product stock, only for a product with a single size and variant combination"
+// @Param size formData []int false "Size IDs, combinations that already exist keep their price and stock" collectionFormat(multi)
+// @Param variant formData []int false "Variant IDs, combinations that already exist keep their price and stock" collectionFormat(multi)
+// @Param skus formData string false "JSON array of models.SkuInput, replaces every size and variant combination"
+// @Param images formData file false "Images appended to the end of the gallery. Send the field once per file, at most 10"
+// @Param alt_text formData []string false "Alt text of the image at the same index" collectionFormat(multi)
 // @Success 200 {object} models.ResponseSucces
+// @Failure 400 {object} models.Response
+// @Failure 404 {object} models.Response
 // @Router /admin/product/{id} [patch]
 // @Security BearerAuth
+// @Security ApiKeyAuth
 func EditProduct(ctx *gin.Context, db *pgxpool.Pool, rd *redis.Client, cld *cloudinary.Cloudinary) {
 	// --- GET PORDUCT ID ---
 	productIDstr := ctx.Param("id")
@@ -358,88 +310,27 @@ func EditProduct(ctx *gin.Context, db *pgxpool.Pool, rd *redis.Client, cld *clou
 
 	body.Id = productID
 
-	// --- CHECKING CLAIMS TOKEN ---
-	claims, exists := ctx.Get("claims")
-	if !exists {
-		fmt.Println("ERROR :", !exists)
-		ctx.AbortWithStatusJSON(403, models.Response{
-			Success: false,
-			Message: "Please log in again",
-		})
-		return
-	}
-	user, ok := claims.(libs.Claims)
+	skus, ok := bindSkus(ctx, body.Skus)
 	if !ok {
-		fmt.Println("ERROR", !ok)
-		ctx.AbortWithStatusJSON(500, models.Response{
-			Success: false,
-			Message: "An error occurred!, please try again.",
-		})
 		return
 	}
+	body.SkuItems = skus
 
-	// --- HANDLE IMAGE UPLOADS ---
-	imageFiles := map[string]*multipart.FileHeader{
-		"photos_one":   body.Image_one,
-		"photos_two":   body.Image_two,
-		"photos_three": body.Image_three,
-		"photos_four":  body.Image_four,
+	// --- GET USER IN CONTEXT ---
+	user, ok := currentUser(ctx)
+	if !ok {
+		return
 	}
 
-	imageStrs := map[string]*string{}
-	useCloudinary := os.Getenv("CLOUDINARY_URL") != ""
-
-	// --- MULTIPLE UPLOAD IMAGES ---
-	for key, file := range imageFiles {
-		if file == nil {
-			continue
-		}
-
-		if !useCloudinary {
-			// --- LOCAL UPLOAD ---
-			savePath, generatedFilename, err := utils.UploadImageFile(ctx, file, "public", fmt.Sprintf("%s_%d", key, user.ID))
-			if err != nil {
-				ctx.JSON(400, models.Response{
-					Success: false,
-					Message: err.Error(),
-				})
-				return
-			}
-
-			if err := ctx.SaveUploadedFile(file, savePath); err != nil {
-				ctx.JSON(500, models.Response{
-					Success: false,
-					Message: fmt.Sprintf("Failed to save %s", key),
-				})
-				return
-			}
-
-			imageStrs[key] = &generatedFilename
-
-		} else {
-			// --- CLOUDINARY UPLOAD ---
-			overwrite := true
-			uploadResp, err := cld.Upload.Upload(ctx, file, uploader.UploadParams{
-				Folder:    "assets/product",
-				PublicID:  fmt.Sprintf("%s_%d", key, user.ID),
-				Overwrite: &overwrite,
-			})
-
-			if err != nil {
-				ctx.JSON(500, gin.H{
-					"success": false,
-					"message": fmt.Sprintf("Failed to upload %s to Cloudinary", key),
-				})
-				return
-			}
-
-			url := uploadResp.SecureURL
-			imageStrs[key] = &url
-		}
+	// --- NEW IMAGES ARE APPENDED TO THE GALLERY ---
+	urls, ok := uploadProductImages(ctx, cld, body.Images, user.ID)
+	if !ok {
+		return
 	}
+	body.ImageItems = models.NewProductImages(urls, body.AltText)
 
 	// --- CHECKING ROWS UPDATE ---
-	if libs.IsStructEmptyExcept(body, "Id") && len(imageStrs) == 0 {
+	if libs.IsStructEmptyExcept(body, "Id") {
 		ctx.JSON(400, models.Response{
 			Success: false,
 			Message: "No data to update",
@@ -450,15 +341,33 @@ func EditProduct(ctx *gin.Context, db *pgxpool.Pool, rd *redis.Client, cld *clou
 	// ---- LIMITS QUERY EXECUTION TIME ---
 	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
-	product, err := models.EditProduct(ctxTimeout, db, rd, body, imageStrs)
+	product, err := models.EditProduct(ctxTimeout, db, rd, body)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
+		if errors.Is(err, sql.ErrNoRows) || errors.Is(err, models.ErrProductNotFound) {
 			ctx.JSON(404, models.Response{
 				Success: false,
 				Message: "Product not found",
 			})
 			return
 		}
+		var ve utils.ValidationError
+		if errors.As(err, &ve) {
+			ctx.JSON(400, models.Response{
+				Success: false,
+				Message: ve.Error(),
+			})
+			return
+		}
+		fmt.Println("error :", err)
+		ctx.JSON(500, models.Response{
+			Success: false,
+			Message: "Failed to update product",
+		})
+		return
+	}
+
+	skuList, err := models.GetProductSkus(ctxTimeout, db, product.Id, false)
+	if err != nil {
 		fmt.Println("error :", err)
 		ctx.JSON(500, models.Response{
 			Success: false,
@@ -475,27 +384,11 @@ func EditProduct(ctx *gin.Context, db *pgxpool.Pool, rd *redis.Client, cld *clou
 		"rating":      product.Rating,
 		"description": product.Description,
 		"stock":       product.Stock,
-		"images":      map[string]string{},
+		"images":      product.Gallery,
 		"size":        product.Size,
 		"variant":     product.Variant,
 		"category":    product.Category,
-	}
-
-	images := map[string]string{}
-	if product.Image_oneStr != "" {
-		images["image_one"] = product.Image_oneStr
-	}
-	if product.Image_twoStr != "" {
-		images["image_two"] = product.Image_twoStr
-	}
-	if product.Image_threeStr != "" {
-		images["image_three"] = product.Image_threeStr
-	}
-	if product.Image_fourStr != "" {
-		images["image_four"] = product.Image_fourStr
-	}
-	if len(images) > 0 {
-		response["images"] = images
+		"skus":        skuList,
 	}
 
 	ctx.JSON(http.StatusOK, models.ResponseSucces{
@@ -505,6 +398,45 @@ func EditProduct(ctx *gin.Context, db *pgxpool.Pool, rd *redis.Client, cld *clou
 	})
 }
 
+// --- skus ARRIVES AS A JSON STRING IN THE MULTIPART FORM, NIL WHEN NOT SENT ---
+func bindSkus(ctx *gin.Context, raw string) ([]models.SkuInput, bool) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, true
+	}
+
+	skus := []models.SkuInput{}
+	if err := json.Unmarshal([]byte(raw), &skus); err != nil || skus == nil {
+		ctx.JSON(400, models.Response{
+			Success: false,
+			Message: "skus must be a JSON array",
+		})
+		return nil, false
+	}
+
+	for _, sku := range skus {
+		if err := binding.Validator.ValidateStruct(sku); err != nil {
+			var ve validator.ValidationErrors
+			if errors.As(err, &ve) {
+				var msgs []string
+				for _, fe := range ve {
+					msgs = append(msgs, "skus "+utils.ErrorMessage(fe))
+				}
+				ctx.JSON(400, models.Response{
+					Success: false,
+					Message: strings.Join(msgs, ", "),
+				})
+				return nil, false
+			}
+			ctx.JSON(400, models.Response{
+				Success: false,
+				Message: "skus must be a JSON array",
+			})
+			return nil, false
+		}
+	}
+	return skus, true
+}
+
 // DeleteProduct godoc
 // @Summary Delete a product
 // @Description Delete a product by its ID
@@ -513,6 +445,7 @@ func EditProduct(ctx *gin.Context, db *pgxpool.Pool, rd *redis.Client, cld *clou
 // @Success 200 {object} models.ResponseSucces
 // @Router /admin/product/delete/{id} [post]
 // @Security BearerAuth
+// @Security ApiKeyAuth
 func DeleteProduct(ctx *gin.Context, db *pgxpool.Pool, rd *redis.Client) {
 	productIDstr := ctx.Param("id")
 	productId, err := strconv.Atoi(productIDstr)
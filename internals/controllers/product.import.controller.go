@@ -0,0 +1,276 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/federus1105/koda-b4-backend/internals/models"
+	"github.com/federus1105/koda-b4-backend/internals/pkg/libs"
+	"github.com/federus1105/koda-b4-backend/internals/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+)
+
+const productImportMaxSize = 5 << 20
+
+// ImportProducts godoc
+// @Summary 	Import products from CSV or XLSX
+// @Description Header row with sku, slug, name, description, price, stock, categories, sizes, variants and images. categories, sizes, variants and images take several values separated by "|", categories and sizes by name or id. stock is the total, split over the size and variant combinations. A row matches an existing product by sku, then by slug (or the slug of its name when upserting)
+// @Tags 		Products
+// @Accept 		multipart/form-data
+// @Param 		file 		formData 	file 	true 	"CSV or XLSX, at most 1000 rows and 5 MB"
+// @Param 		dry_run 	query 		bool 	false 	"Validate every row and report what would happen, nothing is saved"
+// @Param 		upsert 		query 		bool 	false 	"Update products that already exist instead of reporting them"
+// @Param 		batch_size 	query 		int 	false 	"0 (default) saves everything or nothing, otherwise each batch of rows is saved without its failed rows"
+// @Success 	200 {object} 	models.ResponseSucces{result=models.ProductImportResult}
+// @Failure 	400 {object} 	models.Response
+// @Failure 	422 {object} 	models.ResponseSucces{result=models.ProductImportResult} "Some rows failed, nothing was saved"
+// @Security 	BearerAuth
+// @Security 	ApiKeyAuth
+// @Router 		/admin/product/import [post]
+func ImportProducts(ctx *gin.Context, db *pgxpool.Pool, rd *redis.Client) {
+	opts := models.ProductImportOptions{
+		DryRun: ctx.Query("dry_run") == "true",
+		Upsert: ctx.Query("upsert") == "true",
+	}
+	if v := ctx.Query("batch_size"); v != "" {
+		size, err := strconv.Atoi(v)
+		if err != nil || size < 0 || size > models.ProductImportMaxBatch {
+			ctx.JSON(400, models.Response{
+				Success: false,
+				Message: fmt.Sprintf("batch_size must be between 0 and %d", models.ProductImportMaxBatch),
+			})
+			return
+		}
+		opts.BatchSize = size
+	}
+
+	records, err := readProductImportFile(ctx)
+	if err != nil {
+		ctx.JSON(400, models.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	rows, failed, err := models.ParseProductImport(records)
+	if err != nil {
+		ctx.JSON(400, models.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// --- SAME MESSAGES AS THE PRODUCT FORM ---
+	valid := make([]models.ProductImportRow, 0, len(rows))
+	for _, row := range rows {
+		if err := binding.Validator.ValidateStruct(row); err != nil {
+			var ve validator.ValidationErrors
+			if !errors.As(err, &ve) {
+				failed = append(failed, models.ProductImportError{Row: row.Row, Errors: []string{err.Error()}})
+				continue
+			}
+			var msgs []string
+			for _, fe := range ve {
+				msgs = append(msgs, utils.ErrorMessage(fe))
+			}
+			failed = append(failed, models.ProductImportError{Row: row.Row, Errors: msgs})
+			continue
+		}
+		valid = append(valid, row)
+	}
+
+	// --- ONE ROW AT A TIME IN SAVEPOINTS, SO GIVE IT MORE TIME ---
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	result, err := models.ImportProducts(ctxTimeout, db, rd, valid, failed, opts)
+	if err != nil {
+		fmt.Println("Internal Server Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{Success: false, Message: "internal server error"})
+		return
+	}
+
+	switch {
+	case opts.DryRun:
+		ctx.JSON(200, models.ResponseSucces{
+			Success: result.Failed == 0,
+			Message: fmt.Sprintf("Dry run: %d to create, %d to update, %d failed", result.Created, result.Updated, result.Failed),
+			Result:  result,
+		})
+	case !result.Saved && result.Failed > 0 && opts.BatchSize == 0:
+		ctx.JSON(422, models.ResponseSucces{
+			Success: false,
+			Message: fmt.Sprintf("%d rows failed, nothing was saved", result.Failed),
+			Result:  result,
+		})
+	default:
+		ctx.JSON(200, models.ResponseSucces{
+			Success: true,
+			Message: fmt.Sprintf("Imported: %d created, %d updated, %d failed", result.Created, result.Updated, result.Failed),
+			Result:  result,
+		})
+	}
+}
+
+// --- FILE TYPE BY EXTENSION, CSV MAY USE , OR ; AS SEPARATOR ---
+func readProductImportFile(ctx *gin.Context) ([][]string, error) {
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		return nil, errors.New("file is required")
+	}
+	if file.Size > productImportMaxSize {
+		return nil, errors.New("file can be at most 5 MB")
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		return nil, errors.New("failed to read file")
+	}
+	defer f.Close()
+	content, err := io.ReadAll(io.LimitReader(f, productImportMaxSize+1))
+	if err != nil {
+		return nil, errors.New("failed to read file")
+	}
+
+	switch strings.ToLower(filepath.Ext(file.Filename)) {
+	case ".csv":
+		reader := csv.NewReader(bytes.NewReader(content))
+		reader.FieldsPerRecord = -1
+		if firstLine, _, _ := bytes.Cut(content, []byte("\n")); bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+			reader.Comma = ';'
+		}
+		records, err := reader.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("invalid csv: %v", err)
+		}
+		for _, record := range records {
+			for i, cell := range record {
+				record[i] = csvUnescapeCell(cell)
+			}
+		}
+		return records, nil
+	case ".xlsx":
+		// --- HEADER PLUS THE PRODUCT ROWS ---
+		records, err := libs.ReadXLSX(bytes.NewReader(content), int64(len(content)), models.ProductImportMaxRows+1)
+		if errors.Is(err, libs.ErrXLSXTooManyRows) {
+			return nil, fmt.Errorf("file can have at most %d product rows", models.ProductImportMaxRows)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return records, nil
+	}
+	return nil, errors.New("file must be .csv or .xlsx")
+}
+
+// ExportProducts godoc
+// @Summary 	Export products as CSV or XLSX
+// @Description Same columns as the import, an edited export can be imported again with upsert=true
+// @Tags 		Products
+// @Produce 	text/csv
+// @Produce 	application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param 		format 	query 	string 	false 	"csv (default) or xlsx"
+// @Success 	200 {file} 		file
+// @Failure 	400 {object} 	models.Response
+// @Security 	BearerAuth
+// @Security 	ApiKeyAuth
+// @Router 		/admin/product/export [get]
+func ExportProducts(ctx *gin.Context, db *pgxpool.Pool) {
+	format := ctx.DefaultQuery("format", "csv")
+	if format != "csv" && format != "xlsx" {
+		ctx.JSON(400, models.Response{
+			Success: false,
+			Message: "format must be csv or xlsx",
+		})
+		return
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	products, err := models.ExportProducts(ctxTimeout, db)
+	if err != nil {
+		fmt.Println("Internal Server Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{Success: false, Message: "internal server error"})
+		return
+	}
+
+	header := make([]any, len(models.ProductImportColumns))
+	for i, column := range models.ProductImportColumns {
+		header[i] = column
+	}
+	records := [][]any{header}
+	for _, product := range products {
+		records = append(records, product.Record())
+	}
+
+	var buf bytes.Buffer
+	contentType := "text/csv"
+	if format == "xlsx" {
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+		err = libs.WriteXLSX(&buf, "products", records)
+	} else {
+		w := csv.NewWriter(&buf)
+		for _, record := range records {
+			row := make([]string, len(record))
+			for i, v := range record {
+				if f, ok := v.(float64); ok {
+					row[i] = strconv.FormatFloat(f, 'f', -1, 64)
+					continue
+				}
+				row[i] = csvEscapeCell(fmt.Sprint(v))
+			}
+			w.Write(row)
+		}
+		w.Flush()
+		err = w.Error()
+	}
+	if err != nil {
+		fmt.Println("Internal Server Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{Success: false, Message: "internal server error"})
+		return
+	}
+
+	filename := fmt.Sprintf("products-%s.%s", time.Now().Format("20060102"), format)
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	ctx.Data(200, contentType, buf.Bytes())
+}
+
+// --- A SPREADSHEET RUNS A CELL STARTING WITH ONE OF THESE AS A FORMULA ---
+const csvFormulaPrefixes = "=+-@\t\r"
+
+// --- QUOTES ALREADY IN FRONT OF ONE COUNT TOO, SO THE IMPORT CAN TAKE OFF EXACTLY THE QUOTE ADDED HERE ---
+func csvFormulaCell(cell string) bool {
+	rest := strings.TrimLeft(cell, "'")
+	return rest != "" && strings.ContainsRune(csvFormulaPrefixes, rune(rest[0]))
+}
+
+// --- THE QUOTE KEEPS SUCH A CELL TEXT ---
+func csvEscapeCell(cell string) string {
+	if csvFormulaCell(cell) {
+		return "'" + cell
+	}
+	return cell
+}
+
+// --- UNDOES csvEscapeCell SO AN EXPORT IMPORTS UNCHANGED ---
+func csvUnescapeCell(cell string) string {
+	if strings.HasPrefix(cell, "'") && csvFormulaCell(cell) {
+		return cell[1:]
+	}
+	return cell
+}
@@ -0,0 +1,60 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+)
+
+func TestCSVEscapeCell(t *testing.T) {
+	tests := []struct {
+		cell string
+		want string
+	}{
+		{"", ""},
+		{"Kopi Susu", "Kopi Susu"},
+		{"a=b", "a=b"},
+		{"=HYPERLINK(\"http://evil.example\")", "'=HYPERLINK(\"http://evil.example\")"},
+		{"+1", "'+1"},
+		{"-1", "'-1"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\t=1", "'\t=1"},
+		{"\r=1", "'\r=1"},
+		{"'quoted", "'quoted"},
+		{"'=1", "''=1"},
+	}
+	for _, tt := range tests {
+		if got := csvEscapeCell(tt.cell); got != tt.want {
+			t.Errorf("csvEscapeCell(%q) = %q, want %q", tt.cell, got, tt.want)
+		}
+	}
+}
+
+// --- WHAT THE EXPORT WRITES, THE IMPORT READS BACK UNCHANGED ---
+func TestCSVEscapeRoundTrip(t *testing.T) {
+	record := []string{"", "Kopi Susu", "=1+1", "+62812", "-5", "@user", "\tindented", "\rcarriage", "'=kept", "''-kept", "'plain", "'"}
+
+	escaped := make([]string, len(record))
+	for i, cell := range record {
+		escaped[i] = csvEscapeCell(cell)
+	}
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(escaped); err != nil {
+		t.Fatal(err)
+	}
+	w.Flush()
+
+	read, err := csv.NewReader(&buf).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read) != len(record) {
+		t.Fatalf("read %d cells, want %d", len(read), len(record))
+	}
+	for i, cell := range read {
+		if got := csvUnescapeCell(cell); got != record[i] {
+			t.Errorf("cell %d = %q, want %q", i, got, record[i])
+		}
+	}
+}
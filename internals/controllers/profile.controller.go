@@ -11,18 +11,19 @@ import (
 
 	"github.com/cloudinary/cloudinary-go/v2"
 	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
-	"github.com/federus1105/koda-b4-backend/internals/middlewares"
 	"github.com/federus1105/koda-b4-backend/internals/models"
 	"github.com/federus1105/koda-b4-backend/internals/pkg/libs"
 	"github.com/federus1105/koda-b4-backend/internals/pkg/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
 )
 
 // ProfileUpdate godoc
 // @Summary      Update user profile
-// @Description  Update user profile including fullname, phone, address, email, and photo
+// @Description  Update user profile including fullname, phone, address, email, and photo. A new email stays pending until it is confirmed from the link sent to it
+// @Failure      409  {object}  models.Response "Email is already registered"
 // @Tags         Profile
 // @Param        fullname  formData  string  false  "Full name of the user"
 // @Param        phone     formData  string  false  "Phone number of the user"
@@ -32,31 +33,14 @@ import (
 // @Success      200  {object}  models.ResponseSucces
 // @Router       /profile [patch]
 // @Security BearerAuth
-func ProfileUpdate(ctx *gin.Context, db *pgxpool.Pool, cld *cloudinary.Cloudinary) {
+func ProfileUpdate(ctx *gin.Context, db *pgxpool.Pool, rdb *redis.Client, cld *cloudinary.Cloudinary) {
 	var input models.ProfileUpdate
 	// --- GET USER IN CONTEXT ---
-	userIDInterface, exists := ctx.Get(middlewares.UserIDKey)
-	if !exists {
-		ctx.JSON(401, models.Response{
-			Success: false,
-			Message: "Unauthorized: user not logged in",
-		})
-		return
-	}
-
-	var userID int
-	switch v := userIDInterface.(type) {
-	case int:
-		userID = v
-	case float64:
-		userID = int(v)
-	default:
-		ctx.JSON(401, models.Response{
-			Success: false,
-			Message: "Invalid user ID type in context",
-		})
+	user, ok := currentUser(ctx)
+	if !ok {
 		return
 	}
+	userID := user.ID
 
 	// --- VALIDATION ---
 	if err := ctx.ShouldBind(&input); err != nil {
@@ -80,26 +64,6 @@ func ProfileUpdate(ctx *gin.Context, db *pgxpool.Pool, cld *cloudinary.Cloudinar
 		return
 	}
 
-	// --- CHECKING CLAIMS TOKEN ---
-	claims, exists := ctx.Get("claims")
-	if !exists {
-		fmt.Println("ERROR :", !exists)
-		ctx.AbortWithStatusJSON(403, models.Response{
-			Success: false,
-			Message: "Please log in again",
-		})
-		return
-	}
-	user, ok := claims.(libs.Claims)
-	if !ok {
-		fmt.Println("ERROR", !ok)
-		ctx.AbortWithStatusJSON(500, models.Response{
-			Success: false,
-			Message: "An error occurred!, please try again.",
-		})
-		return
-	}
-
 	useCloudinary := os.Getenv("CLOUDINARY_URL") != ""
 	// --- UPLOAD PHOTO ---
 	if input.Photos != nil {
@@ -165,6 +129,29 @@ func ProfileUpdate(ctx *gin.Context, db *pgxpool.Pool, cld *cloudinary.Cloudinar
 	// ---- LIMITS QUERY EXECUTION TIME ---
 	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
+
+	// --- NEW EMAIL MUST BE CONFIRMED BEFORE IT IS USED ---
+	var emailPending bool
+	if input.Email != nil && *input.Email != "" {
+		pending, err := requestEmailChange(ctxTimeout, db, rdb, userID, *input.Email)
+		if err != nil {
+			if errors.Is(err, models.ErrEmailTaken) {
+				ctx.JSON(409, models.Response{
+					Success: false,
+					Message: err.Error(),
+				})
+				return
+			}
+			fmt.Println("error :", err)
+			ctx.JSON(500, models.Response{
+				Success: false,
+				Message: "Failed to update user",
+			})
+			return
+		}
+		emailPending = pending
+	}
+
 	users, err := models.UpdateProfile(ctxTimeout, db, input, userID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -191,9 +178,14 @@ func ProfileUpdate(ctx *gin.Context, db *pgxpool.Pool, cld *cloudinary.Cloudinar
 		"address":  users.Address,
 		"phone":    users.Phone,
 	}
+	message := "Update Profile Succesfully"
+	if emailPending {
+		response["pending_email"] = *input.Email
+		message = "Update Profile Succesfully, please confirm the new email from the link we sent"
+	}
 	ctx.JSON(200, models.ResponseSucces{
 		Success: true,
-		Message: "Update Profile Succesfully",
+		Message: message,
 		Result:  response,
 	})
 
@@ -205,6 +197,7 @@ func ProfileUpdate(ctx *gin.Context, db *pgxpool.Pool, cld *cloudinary.Cloudinar
 // @Tags         Profile
 // @Param        body  body      models.ReqUpdatePassword  true  "Password update data"
 // @Success      200   {object}  models.ResponseSucces
+// @Failure      400   {object}  models.Response  "Weak, breached or recently used password"
 // @Router       /profile [put]
 // @Security BearerAuth
 func UpdatePassword(ctx *gin.Context, db *pgxpool.Pool) {
@@ -233,28 +226,11 @@ func UpdatePassword(ctx *gin.Context, db *pgxpool.Pool) {
 	}
 
 	// --- GET USER IN CONTEXT ---
-	userIDInterface, exists := ctx.Get(middlewares.UserIDKey)
-	if !exists {
-		ctx.JSON(401, models.Response{
-			Success: false,
-			Message: "Unauthorized: user not logged in",
-		})
-		return
-	}
-
-	var userID int
-	switch v := userIDInterface.(type) {
-	case int:
-		userID = v
-	case float64:
-		userID = int(v)
-	default:
-		ctx.JSON(401, models.Response{
-			Success: false,
-			Message: "Invalid user ID type in context",
-		})
+	user, ok := currentUser(ctx)
+	if !ok {
 		return
 	}
+	userID := user.ID
 
 	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -297,28 +273,11 @@ func UpdatePassword(ctx *gin.Context, db *pgxpool.Pool) {
 // @Router /profile [get]
 func Profile(ctx *gin.Context, db *pgxpool.Pool) {
 	// --- GET USER IN CONTEXT ---
-	userIDInterface, exists := ctx.Get(middlewares.UserIDKey)
-	if !exists {
-		ctx.JSON(401, models.Response{
-			Success: false,
-			Message: "Unauthorized: user not logged in",
-		})
-		return
-	}
-
-	var userID int
-	switch v := userIDInterface.(type) {
-	case int:
-		userID = v
-	case float64:
-		userID = int(v)
-	default:
-		ctx.JSON(401, models.Response{
-			Success: false,
-			Message: "Invalid user ID type in context",
-		})
+	user, ok := currentUser(ctx)
+	if !ok {
 		return
 	}
+	userID := user.ID
 
 	// ---- LIMITS QUERY EXECUTION TIME ---
 	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
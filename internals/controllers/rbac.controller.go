@@ -0,0 +1,221 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/federus1105/koda-b4-backend/internals/models"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+)
+
+// --- MAP ROLE ERROR TO RESPONSE ---
+func respondRoleError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, models.ErrRoleNotFound):
+		ctx.JSON(404, models.Response{Success: false, Message: err.Error()})
+	case errors.Is(err, models.ErrPermissionNotFound):
+		ctx.JSON(400, models.Response{Success: false, Message: err.Error()})
+	case errors.Is(err, models.ErrRoleExists), errors.Is(err, models.ErrRoleInUse):
+		ctx.JSON(409, models.Response{Success: false, Message: err.Error()})
+	case errors.Is(err, models.ErrRoleSystem):
+		ctx.JSON(403, models.Response{Success: false, Message: err.Error()})
+	default:
+		fmt.Println("Internal Server Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{Success: false, Message: "internal server error"})
+	}
+}
+
+// GetRoles godoc
+// @Summary 	List roles
+// @Tags 		Admin Roles
+// @Success 	200 {object} 	models.ResponseSucces{result=[]models.Role}
+// @Security 	BearerAuth
+// @Router 		/admin/roles [get]
+func GetRoles(ctx *gin.Context, db *pgxpool.Pool) {
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	roles, err := models.GetRoles(ctxTimeout, db)
+	if err != nil {
+		respondRoleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(200, models.ResponseSucces{
+		Success: true,
+		Message: "Success",
+		Result:  roles,
+	})
+}
+
+// GetPermissions godoc
+// @Summary 	List permissions
+// @Tags 		Admin Roles
+// @Success 	200 {object} 	models.ResponseSucces{result=[]models.Permission}
+// @Security 	BearerAuth
+// @Router 		/admin/permissions [get]
+func GetPermissions(ctx *gin.Context, db *pgxpool.Pool) {
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	permissions, err := models.GetPermissions(ctxTimeout, db)
+	if err != nil {
+		respondRoleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(200, models.ResponseSucces{
+		Success: true,
+		Message: "Success",
+		Result:  permissions,
+	})
+}
+
+// CreateRole godoc
+// @Summary 	Create role
+// @Description Create a staff role, e.g. barista, with a set of permissions
+// @Tags 		Admin Roles
+// @Param 		request body 	models.ReqCreateRole  true 	"Role"
+// @Success 	201 {object} 	models.Response
+// @Failure 	409 {object} 	models.Response
+// @Security 	BearerAuth
+// @Router 		/admin/roles [post]
+func CreateRole(ctx *gin.Context, db *pgxpool.Pool) {
+	var req models.ReqCreateRole
+	if !bindJSON(ctx, &req) {
+		return
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := models.CreateRole(ctxTimeout, db, req); err != nil {
+		respondRoleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(201, models.Response{
+		Success: true,
+		Message: "role created",
+	})
+}
+
+// UpdateRole godoc
+// @Summary 	Update role
+// @Description Replace the permissions of a role, the admin role can't be changed
+// @Tags 		Admin Roles
+// @Param 		name 	path 	string 	true 	"Role name"
+// @Param 		request body 	models.ReqUpdateRole  true 	"Role"
+// @Success 	200 {object} 	models.Response
+// @Security 	BearerAuth
+// @Router 		/admin/roles/{name} [put]
+func UpdateRole(ctx *gin.Context, db *pgxpool.Pool, rdb *redis.Client) {
+	var req models.ReqUpdateRole
+	if !bindJSON(ctx, &req) {
+		return
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := models.UpdateRole(ctxTimeout, db, rdb, ctx.Param("name"), req); err != nil {
+		respondRoleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(200, models.Response{
+		Success: true,
+		Message: "role updated",
+	})
+}
+
+// DeleteRole godoc
+// @Summary 	Delete role
+// @Description Only custom roles that are not assigned to any user can be deleted
+// @Tags 		Admin Roles
+// @Param 		name 	path 	string 	true 	"Role name"
+// @Success 	200 {object} 	models.Response
+// @Failure 	409 {object} 	models.Response
+// @Security 	BearerAuth
+// @Router 		/admin/roles/{name} [delete]
+func DeleteRole(ctx *gin.Context, db *pgxpool.Pool, rdb *redis.Client) {
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := models.DeleteRole(ctxTimeout, db, rdb, ctx.Param("name")); err != nil {
+		respondRoleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(200, models.Response{
+		Success: true,
+		Message: "role deleted",
+	})
+}
+
+// AssignUserRole godoc
+// @Summary 	Assign role to user
+// @Description Existing sessions of the user are revoked so the new role applies immediately, API keys they created keep only the scopes the new role grants
+// @Tags 		Admin Roles
+// @Param 		id 	path 	int 	true 	"User ID"
+// @Param 		request body 	models.ReqAssignRole  true 	"Role"
+// @Success 	200 {object} 	models.Response
+// @Security 	BearerAuth
+// @Router 		/admin/user/{id}/role [put]
+func AssignUserRole(ctx *gin.Context, db *pgxpool.Pool, rdb *redis.Client) {
+	userID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(404, models.Response{
+			Success: false,
+			Message: "Invalid user id",
+		})
+		return
+	}
+
+	var req models.ReqAssignRole
+	if !bindJSON(ctx, &req) {
+		return
+	}
+
+	user, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+	if user.ID == userID {
+		ctx.JSON(403, models.Response{
+			Success: false,
+			Message: "you can't change your own role",
+		})
+		return
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := models.AssignRole(ctxTimeout, db, rdb, userID, req.Role); err != nil {
+		if strings.Contains(err.Error(), "user not found") {
+			ctx.JSON(404, models.Response{
+				Success: false,
+				Message: "user not found",
+			})
+			return
+		}
+		respondRoleError(ctx, err)
+		return
+	}
+
+	if _, err := models.RevokeOtherSessions(ctxTimeout, rdb, userID, ""); err != nil {
+		fmt.Println("Failed to revoke sessions:", err)
+	}
+
+	ctx.JSON(200, models.Response{
+		Success: true,
+		Message: fmt.Sprintf("role %s assigned", req.Role),
+	})
+}
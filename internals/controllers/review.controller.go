@@ -0,0 +1,389 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudinary/cloudinary-go/v2"
+	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
+	"github.com/federus1105/koda-b4-backend/internals/models"
+	"github.com/federus1105/koda-b4-backend/internals/pkg/auth"
+	"github.com/federus1105/koda-b4-backend/internals/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+)
+
+// GetProductReviews godoc
+// @Summary 	List reviews of a product
+// @Description Approved reviews, 10 per page, newest first or most helpful first
+// @Tags 		Reviews
+// @Param 		id 		path 	int 	true 	"Product ID"
+// @Param 		sort 	query 	string 	false 	"newest (default) or helpful"
+// @Param 		page 	query 	int 	false 	"Page number (default: 1)"
+// @Success 	200 {object} 	models.ResponseSucces{result=[]models.Review}
+// @Router 		/product/{id}/reviews [get]
+func GetProductReviews(ctx *gin.Context, db *pgxpool.Pool) {
+	productID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(404, models.Response{
+			Success: false,
+			Message: "Invalid product id",
+		})
+		return
+	}
+	page, err := strconv.Atoi(ctx.Query("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	// --- LOGGED IN VIEWER SEES WHICH REVIEWS THEY VOTED ---
+	viewerID := 0
+	if user, ok := auth.Principal(ctx); ok {
+		viewerID = user.ID
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	reviews, err := models.GetProductReviews(ctxTimeout, db, productID, viewerID, ctx.Query("sort"), page)
+	if err != nil {
+		fmt.Println("Internal Server Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{Success: false, Message: "internal server error"})
+		return
+	}
+
+	ctx.JSON(200, models.ResponseSucces{
+		Success: true,
+		Message: "Success",
+		Result:  reviews,
+	})
+}
+
+// CreateReview godoc
+// @Summary 	Review a product
+// @Description Only a customer with a completed order of the product can review it, once. The review is shown after a moderator approves it
+// @Tags 		Reviews
+// @Accept 		multipart/form-data
+// @Param 		id 		path 		int 	true 	"Product ID"
+// @Param 		rating 	formData 	int 	true 	"Rating 1 - 5"
+// @Param 		content formData 	string 	false 	"Review text, max 1000 characters"
+// @Param 		photos 	formData 	file 	false 	"Up to 4 photos"
+// @Success 	201 {object} 	models.ResponseSucces{result=models.Review}
+// @Failure 	400 {object} 	models.Response
+// @Failure 	403 {object} 	models.Response "No completed order of this product"
+// @Failure 	409 {object} 	models.Response "Already reviewed"
+// @Security 	BearerAuth
+// @Router 		/product/{id}/reviews [post]
+func CreateReview(ctx *gin.Context, db *pgxpool.Pool, cld *cloudinary.Cloudinary) {
+	user, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+	productID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(404, models.Response{
+			Success: false,
+			Message: "Invalid product id",
+		})
+		return
+	}
+
+	var body models.ReqCreateReview
+	if err := ctx.ShouldBind(&body); err != nil {
+		var ve validator.ValidationErrors
+		if errors.As(err, &ve) {
+			var msgs []string
+			for _, fe := range ve {
+				msgs = append(msgs, utils.ErrorMessage(fe))
+			}
+			ctx.JSON(400, models.Response{
+				Success: false,
+				Message: strings.Join(msgs, ", "),
+			})
+			return
+		}
+
+		ctx.JSON(400, models.Response{
+			Success: false,
+			Message: "invalid Form format",
+		})
+		return
+	}
+
+	// --- NOTHING IS UPLOADED FOR A REVIEW THAT WILL BE REJECTED ---
+	checkCtx, checkCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer checkCancel()
+	if err := models.CanReview(checkCtx, db, user.ID, productID); err != nil {
+		reviewCreateError(ctx, err)
+		return
+	}
+
+	// --- UPLOAD PHOTOS, THE TIMESTAMP KEEPS EVERY UPLOAD ITS OWN PUBLIC ID ---
+	useCloudinary := os.Getenv("CLOUDINARY_URL") != ""
+	uploadedAt := time.Now().UnixNano()
+	for i, file := range body.Photos {
+		key := fmt.Sprintf("review_%d_%d_%d_%d", productID, user.ID, uploadedAt, i)
+		if !useCloudinary {
+			savePath, generatedFilename, err := utils.UploadImageFile(ctx, file, "public", key)
+			if err != nil {
+				ctx.JSON(400, models.Response{
+					Success: false,
+					Message: err.Error(),
+				})
+				return
+			}
+
+			if err := ctx.SaveUploadedFile(file, savePath); err != nil {
+				ctx.JSON(500, models.Response{
+					Success: false,
+					Message: "Failed to save photos",
+				})
+				return
+			}
+			body.PhotoStrs = append(body.PhotoStrs, generatedFilename)
+		} else {
+			// --- SAME CHECKS AS LOCAL UPLOAD, ONLY THE PATH IS UNUSED ---
+			if _, _, err := utils.UploadImageFile(ctx, file, "", key); err != nil {
+				ctx.JSON(400, models.Response{
+					Success: false,
+					Message: err.Error(),
+				})
+				return
+			}
+
+			uploadResp, err := cld.Upload.Upload(ctx, file, uploader.UploadParams{
+				Folder:   "assets/review",
+				PublicID: key,
+			})
+			if err != nil {
+				ctx.JSON(500, models.Response{
+					Success: false,
+					Message: "Failed to upload photos to cloudinary",
+				})
+				return
+			}
+			body.PhotoStrs = append(body.PhotoStrs, uploadResp.SecureURL)
+		}
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	review, err := models.CreateReview(ctxTimeout, db, user.ID, productID, body)
+	if err != nil {
+		reviewCreateError(ctx, err)
+		return
+	}
+
+	ctx.JSON(201, models.ResponseSucces{
+		Success: true,
+		Message: "Review submitted, it will be shown after moderation",
+		Result:  review,
+	})
+}
+
+func reviewCreateError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, models.ErrReviewProduct):
+		ctx.JSON(404, models.Response{Success: false, Message: err.Error()})
+	case errors.Is(err, models.ErrReviewNotPurchased):
+		ctx.JSON(403, models.Response{Success: false, Message: err.Error()})
+	case errors.Is(err, models.ErrReviewExists):
+		ctx.JSON(409, models.Response{Success: false, Message: err.Error()})
+	default:
+		log.Println("ERROR : ", err)
+		ctx.JSON(500, models.Response{Success: false, Message: "internal server error"})
+	}
+}
+
+// VoteReview godoc
+// @Summary 	Mark a review helpful
+// @Description POST adds the vote, DELETE takes it back. The author can't vote on their own review
+// @Tags 		Reviews
+// @Param 		id 	path 	int 	true 	"Review ID"
+// @Success 	200 {object} 	models.ResponseSucces
+// @Failure 	403 {object} 	models.Response
+// @Failure 	404 {object} 	models.Response
+// @Security 	BearerAuth
+// @Router 		/reviews/{id}/helpful [post]
+// @Router 		/reviews/{id}/helpful [delete]
+func VoteReview(ctx *gin.Context, db *pgxpool.Pool) {
+	user, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+	reviewID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(404, models.Response{
+			Success: false,
+			Message: "Invalid review id",
+		})
+		return
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	helpful := ctx.Request.Method == "POST"
+	count, err := models.VoteReview(ctxTimeout, db, reviewID, user.ID, helpful)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrReviewNotFound):
+			ctx.JSON(404, models.Response{Success: false, Message: err.Error()})
+		case errors.Is(err, models.ErrReviewOwnVote):
+			ctx.JSON(403, models.Response{Success: false, Message: err.Error()})
+		default:
+			fmt.Println("Internal Server Error.\nCause: ", err)
+			ctx.JSON(500, models.Response{Success: false, Message: "internal server error"})
+		}
+		return
+	}
+
+	ctx.JSON(200, models.ResponseSucces{
+		Success: true,
+		Message: "Success",
+		Result: gin.H{
+			"helpful": count,
+			"voted":   helpful,
+		},
+	})
+}
+
+// GetReviewsForModeration godoc
+// @Summary 	List reviews by status
+// @Description Moderation queue, oldest first, 10 per page
+// @Tags 		Reviews
+// @Param 		status 	query 	string 	false 	"pending (default), approved or rejected"
+// @Param 		page 	query 	int 	false 	"Page number (default: 1)"
+// @Success 	200 {object} 	models.ResponseSucces{result=[]models.Review}
+// @Failure 	400 {object} 	models.Response
+// @Security 	BearerAuth
+// @Router 		/admin/reviews [get]
+func GetReviewsForModeration(ctx *gin.Context, db *pgxpool.Pool) {
+	status := ctx.DefaultQuery("status", "pending")
+	if status != "pending" && status != "approved" && status != "rejected" {
+		ctx.JSON(400, models.Response{
+			Success: false,
+			Message: "status must be pending, approved or rejected",
+		})
+		return
+	}
+	page, err := strconv.Atoi(ctx.Query("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	reviews, err := models.GetReviewsByStatus(ctxTimeout, db, status, page)
+	if err != nil {
+		fmt.Println("Internal Server Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{Success: false, Message: "internal server error"})
+		return
+	}
+
+	ctx.JSON(200, models.ResponseSucces{
+		Success: true,
+		Message: "Success",
+		Result:  reviews,
+	})
+}
+
+// ModerateReview godoc
+// @Summary 	Approve or reject a review
+// @Description Only approved reviews are shown and count toward the product rating
+// @Tags 		Reviews
+// @Param 		id 		path 	int 						true 	"Review ID"
+// @Param 		input 	body 	models.ReqModerateReview 	true 	"New status"
+// @Success 	200 {object} 	models.Response
+// @Failure 	400 {object} 	models.Response
+// @Failure 	404 {object} 	models.Response
+// @Security 	BearerAuth
+// @Router 		/admin/reviews/{id} [patch]
+func ModerateReview(ctx *gin.Context, db *pgxpool.Pool, rd *redis.Client) {
+	user, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+	reviewID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(404, models.Response{
+			Success: false,
+			Message: "Invalid review id",
+		})
+		return
+	}
+
+	var input models.ReqModerateReview
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(400, models.Response{
+			Success: false,
+			Message: "status must be approved or rejected",
+		})
+		return
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := models.ModerateReview(ctxTimeout, db, rd, reviewID, user.ID, input.Status); err != nil {
+		if errors.Is(err, models.ErrReviewNotFound) {
+			ctx.JSON(404, models.Response{Success: false, Message: err.Error()})
+			return
+		}
+		fmt.Println("Internal Server Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{Success: false, Message: "internal server error"})
+		return
+	}
+
+	ctx.JSON(200, models.Response{
+		Success: true,
+		Message: "Review " + input.Status,
+	})
+}
+
+// DeleteReview godoc
+// @Summary 	Delete a review
+// @Tags 		Reviews
+// @Param 		id 	path 	int 	true 	"Review ID"
+// @Success 	200 {object} 	models.Response
+// @Failure 	404 {object} 	models.Response
+// @Security 	BearerAuth
+// @Router 		/admin/reviews/{id} [delete]
+func DeleteReview(ctx *gin.Context, db *pgxpool.Pool, rd *redis.Client) {
+	reviewID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(404, models.Response{
+			Success: false,
+			Message: "Invalid review id",
+		})
+		return
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := models.DeleteReview(ctxTimeout, db, rd, reviewID); err != nil {
+		if errors.Is(err, models.ErrReviewNotFound) {
+			ctx.JSON(404, models.Response{Success: false, Message: err.Error()})
+			return
+		}
+		fmt.Println("Internal Server Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{Success: false, Message: "internal server error"})
+		return
+	}
+
+	ctx.JSON(200, models.Response{
+		Success: true,
+		Message: "Review deleted",
+	})
+}
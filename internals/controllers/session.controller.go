@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/federus1105/koda-b4-backend/internals/models"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// GetSessions godoc
+// @Summary 	List active sessions
+// @Description List devices logged in to this account, the session of the current token is marked as current
+// @Tags 		Profile
+// @Success 	200 {object} 	models.ResponseSucces{result=[]models.Session}
+// @Security 	BearerAuth
+// @Router 		/profile/sessions [get]
+func GetSessions(ctx *gin.Context, rdb *redis.Client) {
+	user, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	sessions, err := models.GetSessions(ctxTimeout, rdb, user.ID, user.SessionID)
+	if err != nil {
+		fmt.Println("Redis Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{
+			Success: false,
+			Message: "internal server error",
+		})
+		return
+	}
+
+	ctx.JSON(200, models.ResponseSucces{
+		Success: true,
+		Message: "Success",
+		Result:  sessions,
+	})
+}
+
+// RevokeSession godoc
+// @Summary 	Revoke a session
+// @Description Log out one device, its access and refresh token stop working immediately
+// @Tags 		Profile
+// @Param 		id 	path 	string 	true 	"Session ID"
+// @Success 	200 {object} 	models.Response
+// @Failure 	404 {object} 	models.Response
+// @Security 	BearerAuth
+// @Router 		/profile/sessions/{id} [delete]
+func RevokeSession(ctx *gin.Context, rdb *redis.Client) {
+	user, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := models.RevokeSession(ctxTimeout, rdb, user.ID, ctx.Param("id")); err != nil {
+		if errors.Is(err, models.ErrSessionNotFound) {
+			ctx.JSON(404, models.Response{
+				Success: false,
+				Message: "session not found",
+			})
+			return
+		}
+		fmt.Println("Redis Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{
+			Success: false,
+			Message: "internal server error",
+		})
+		return
+	}
+
+	ctx.JSON(200, models.Response{
+		Success: true,
+		Message: "session revoked",
+	})
+}
+
+// RevokeOtherSessions godoc
+// @Summary 	Revoke all other sessions
+// @Description Log out every device except the one making this request
+// @Tags 		Profile
+// @Success 	200 {object} 	models.ResponseSucces
+// @Security 	BearerAuth
+// @Router 		/profile/sessions [delete]
+func RevokeOtherSessions(ctx *gin.Context, rdb *redis.Client) {
+	user, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	revoked, err := models.RevokeOtherSessions(ctxTimeout, rdb, user.ID, user.SessionID)
+	if err != nil {
+		fmt.Println("Redis Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{
+			Success: false,
+			Message: "internal server error",
+		})
+		return
+	}
+
+	ctx.JSON(200, models.ResponseSucces{
+		Success: true,
+		Message: "other sessions revoked",
+		Result:  gin.H{"revoked": revoked},
+	})
+}
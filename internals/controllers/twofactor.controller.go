@@ -0,0 +1,642 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/federus1105/koda-b4-backend/internals/models"
+	"github.com/federus1105/koda-b4-backend/internals/pkg/libs"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+)
+
+// --- ISSUER SHOWN IN AUTHENTICATOR APP ---
+func totpIssuer() string {
+	if issuer := os.Getenv("TOTP_ISSUER"); issuer != "" {
+		return issuer
+	}
+	return "Senja Kopi Kiri"
+}
+
+// --- PASSWORD IS VERIFIED, ASK FOR SECOND FACTOR ---
+func startTwoFactorChallenge(ctx *gin.Context, ctxTimeout context.Context, rdb *redis.Client, user models.AuthLogin) {
+	// --- TOO MANY WRONG CODES LOCK THE ACCOUNT, NOT ONLY THE CHALLENGE ---
+	locked, err := models.GetLockout(ctxTimeout, rdb, models.Scope2FA, models.UserSubject(user.Id))
+	if err != nil {
+		fmt.Println("Redis Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{
+			Success: false,
+			Message: "internal server error",
+		})
+		return
+	}
+	if locked > 0 {
+		respondTooManyAttempts(ctx, locked)
+		return
+	}
+
+	challenge := models.LoginChallenge{
+		UserID: user.Id,
+		Role:   user.Role,
+		Setup:  !user.TotpEnabled,
+	}
+	token, err := models.CreateLoginChallenge(ctxTimeout, rdb, challenge)
+	if err != nil {
+		fmt.Println("Internal Server Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{
+			Success: false,
+			Message: "internal server error",
+		})
+		return
+	}
+
+	result := gin.H{
+		"two_factor_required": true,
+		"challenge_token":     token,
+		"expires_in":          int(models.LoginChallengeTTL.Seconds()),
+	}
+
+	// --- ROLE REQUIRES 2FA BUT USER NOT ENROLLED YET, ENROLL DURING LOGIN ---
+	if challenge.Setup {
+		secret, err := libs.GenerateTOTPSecret()
+		if err == nil {
+			err = models.SavePendingTOTPSecret(ctxTimeout, rdb, user.Id, secret)
+		}
+		if err != nil {
+			fmt.Println("Internal Server Error.\nCause: ", err)
+			ctx.JSON(500, models.Response{
+				Success: false,
+				Message: "internal server error",
+			})
+			return
+		}
+		result["setup_required"] = true
+		result["secret"] = secret
+		result["otpauth_url"] = libs.TOTPProvisioningURI(totpIssuer(), user.Email, secret)
+	}
+
+	ctx.JSON(200, models.ResponseSucces{
+		Success: true,
+		Message: "two-factor authentication required",
+		Result:  result,
+	})
+}
+
+// --- COUNT THE TRY BEFORE THE CODE IS CHECKED SO PARALLEL REQUESTS CAN'T PASS THE LIMIT, 429 WHEN LOCKED ---
+func registerSecondFactorAttempt(ctx *gin.Context, ctxTimeout context.Context, rdb *redis.Client, userID int) bool {
+	subject := models.UserSubject(userID)
+	locked, err := models.GetLockout(ctxTimeout, rdb, models.Scope2FA, subject)
+	if err == nil && locked == 0 {
+		locked, err = models.RegisterAttempt(ctxTimeout, rdb, models.TwoFactorUserPolicy, subject)
+	}
+	if err != nil {
+		fmt.Println("Redis Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{
+			Success: false,
+			Message: "internal server error",
+		})
+		return false
+	}
+	if locked > 0 {
+		respondTooManyAttempts(ctx, locked)
+		return false
+	}
+	return true
+}
+
+// --- CHECK TOTP CODE OR RECOVERY CODE ---
+func verifySecondFactor(ctx context.Context, db *pgxpool.Pool, rdb *redis.Client, userID int, secret, code, recoveryCode string) (bool, error) {
+	if code != "" {
+		step, ok := libs.ValidateTOTP(secret, code, time.Now())
+		if !ok {
+			return false, nil
+		}
+		return models.MarkTOTPStepUsed(ctx, rdb, userID, step)
+	}
+	return models.UseRecoveryCode(ctx, db, userID, recoveryCode)
+}
+
+// LoginTwoFactor godoc
+// @Summary 	Complete login with two-factor code
+// @Description Exchange the challenge token from /auth/login and a TOTP code or recovery code for JWT token.
+// @Description When the challenge requires setup, the code must come from the secret returned by /auth/login and recovery codes are returned once
+// @Tags 		Auth
+// @Param 		request body 	models.ReqLoginTwoFactor  true 	"Challenge token and code"
+// @Success 	200 {object} 	models.ResponseSucces
+// @Failure 	401 {object} 	models.Response
+// @Failure 	429 {object} 	models.Response "Too many wrong codes for the account"
+// @Router 		/auth/login/2fa [post]
+func LoginTwoFactor(ctx *gin.Context, db *pgxpool.Pool, rdb *redis.Client) {
+	var req models.ReqLoginTwoFactor
+	// --- VALIDATION ---
+	if !bindJSON(ctx, &req) {
+		return
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	challenge, err := models.GetLoginChallenge(ctxTimeout, rdb, req.ChallengeToken)
+	if err != nil {
+		if errors.Is(err, models.ErrLoginChallengeInvalid) {
+			ctx.JSON(401, models.Response{
+				Success: false,
+				Message: "login session expired, please log in again",
+			})
+			return
+		}
+		fmt.Println("Redis Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{
+			Success: false,
+			Message: "internal server error",
+		})
+		return
+	}
+
+	// --- GET SECRET, PENDING ONE WHEN ENROLLING ---
+	var secret string
+	if challenge.Setup {
+		secret, err = models.GetPendingTOTPSecret(ctxTimeout, rdb, challenge.UserID)
+	} else {
+		secret, err = models.GetTOTPSecret(ctxTimeout, db, challenge.UserID)
+	}
+	if err != nil {
+		fmt.Println("Internal Server Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{
+			Success: false,
+			Message: "internal server error",
+		})
+		return
+	}
+	if secret == "" {
+		models.DeleteLoginChallenge(ctxTimeout, rdb, req.ChallengeToken)
+		ctx.JSON(401, models.Response{
+			Success: false,
+			Message: "login session expired, please log in again",
+		})
+		return
+	}
+
+	// --- RECOVERY CODE CAN'T BE USED BEFORE ENROLLMENT ---
+	code, recoveryCode := req.Code, req.RecoveryCode
+	if challenge.Setup {
+		recoveryCode = ""
+	}
+
+	if !registerSecondFactorAttempt(ctx, ctxTimeout, rdb, challenge.UserID) {
+		models.DeleteLoginChallenge(ctxTimeout, rdb, req.ChallengeToken)
+		return
+	}
+
+	ok := false
+	if code != "" || recoveryCode != "" {
+		ok, err = verifySecondFactor(ctxTimeout, db, rdb, challenge.UserID, secret, code, recoveryCode)
+		if err != nil {
+			fmt.Println("Internal Server Error.\nCause: ", err)
+			ctx.JSON(500, models.Response{
+				Success: false,
+				Message: "internal server error",
+			})
+			return
+		}
+	}
+	if !ok {
+		if err := models.FailLoginChallenge(ctxTimeout, rdb, req.ChallengeToken); err != nil {
+			fmt.Println("Redis Error.\nCause: ", err)
+		}
+		ctx.JSON(401, models.Response{
+			Success: false,
+			Message: "invalid two-factor code",
+		})
+		return
+	}
+
+	if err := models.DeleteLoginChallenge(ctxTimeout, rdb, req.ChallengeToken); err != nil {
+		fmt.Println("Redis Error.\nCause: ", err)
+	}
+	if err := models.ResetAttempts(ctxTimeout, rdb, models.Scope2FA, models.UserSubject(challenge.UserID)); err != nil {
+		fmt.Println("Failed to reset two-factor attempts:", err)
+	}
+
+	// --- FINISH ENROLLMENT ---
+	var recoveryCodes []string
+	if challenge.Setup {
+		recoveryCodes, err = models.EnableTwoFactor(ctxTimeout, db, rdb, challenge.UserID, secret)
+		if err != nil {
+			ctx.JSON(500, models.Response{
+				Success: false,
+				Message: "internal server error",
+			})
+			return
+		}
+	}
+
+	tokens, err := generateTokens(ctx, ctxTimeout, rdb, challenge.UserID, challenge.Role)
+	if err != nil {
+		fmt.Println("Internal Server Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{
+			Success: false,
+			Message: "internal server error",
+		})
+		return
+	}
+	if recoveryCodes != nil {
+		tokens["recovery_codes"] = recoveryCodes
+	}
+
+	ctx.JSON(200, models.ResponseSucces{
+		Success: true,
+		Message: "login successful",
+		Result:  tokens,
+	})
+}
+
+// GetTwoFactorStatus godoc
+// @Summary 	Get two-factor status
+// @Tags 		Profile
+// @Success 	200 {object} 	models.ResponseSucces{result=models.TwoFactorStatus}
+// @Security 	BearerAuth
+// @Router 		/profile/2fa [get]
+func GetTwoFactorStatus(ctx *gin.Context, db *pgxpool.Pool) {
+	user, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	status, err := models.GetTwoFactorStatus(ctxTimeout, db, user.ID)
+	if err != nil {
+		fmt.Println("Internal Server Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{
+			Success: false,
+			Message: "internal server error",
+		})
+		return
+	}
+
+	ctx.JSON(200, models.ResponseSucces{
+		Success: true,
+		Message: "Success",
+		Result:  status,
+	})
+}
+
+// SetupTwoFactor godoc
+// @Summary 	Start two-factor enrollment
+// @Description Generate a new TOTP secret and provisioning URI, confirm it with /profile/2fa/enable
+// @Tags 		Profile
+// @Success 	200 {object} 	models.ResponseSucces
+// @Failure 	409 {object} 	models.Response
+// @Security 	BearerAuth
+// @Router 		/profile/2fa/setup [post]
+func SetupTwoFactor(ctx *gin.Context, db *pgxpool.Pool, rdb *redis.Client) {
+	user, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	status, err := models.GetTwoFactorStatus(ctxTimeout, db, user.ID)
+	if err != nil {
+		fmt.Println("Internal Server Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{
+			Success: false,
+			Message: "internal server error",
+		})
+		return
+	}
+	if status.Enabled {
+		ctx.JSON(409, models.Response{
+			Success: false,
+			Message: "two-factor authentication is already enabled",
+		})
+		return
+	}
+
+	account, err := models.GetUserByID(ctxTimeout, db, user.ID)
+	if err != nil {
+		ctx.JSON(404, models.Response{
+			Success: false,
+			Message: "user not found",
+		})
+		return
+	}
+
+	secret, err := libs.GenerateTOTPSecret()
+	if err == nil {
+		err = models.SavePendingTOTPSecret(ctxTimeout, rdb, user.ID, secret)
+	}
+	if err != nil {
+		fmt.Println("Internal Server Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{
+			Success: false,
+			Message: "internal server error",
+		})
+		return
+	}
+
+	ctx.JSON(200, models.ResponseSucces{
+		Success: true,
+		Message: "scan the QR code and confirm with a code from your authenticator app",
+		Result: gin.H{
+			"secret":      secret,
+			"otpauth_url": libs.TOTPProvisioningURI(totpIssuer(), account.Email, secret),
+			"expires_in":  int(models.TOTPSetupTTL.Seconds()),
+		},
+	})
+}
+
+// EnableTwoFactor godoc
+// @Summary 	Confirm two-factor enrollment
+// @Description Confirm the pending secret with a TOTP code, recovery codes are returned only once
+// @Tags 		Profile
+// @Param 		request body 	models.ReqTOTPCode  true 	"TOTP code"
+// @Success 	200 {object} 	models.ResponseSucces
+// @Security 	BearerAuth
+// @Router 		/profile/2fa/enable [post]
+func EnableTwoFactor(ctx *gin.Context, db *pgxpool.Pool, rdb *redis.Client) {
+	user, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req models.ReqTOTPCode
+	if !bindJSON(ctx, &req) {
+		return
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	secret, err := models.GetPendingTOTPSecret(ctxTimeout, rdb, user.ID)
+	if err != nil {
+		fmt.Println("Redis Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{
+			Success: false,
+			Message: "internal server error",
+		})
+		return
+	}
+	if secret == "" {
+		ctx.JSON(400, models.Response{
+			Success: false,
+			Message: "setup expired, please start two-factor setup again",
+		})
+		return
+	}
+
+	valid, err := verifySecondFactor(ctxTimeout, db, rdb, user.ID, secret, req.Code, "")
+	if err != nil {
+		fmt.Println("Internal Server Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{
+			Success: false,
+			Message: "internal server error",
+		})
+		return
+	}
+	if !valid {
+		ctx.JSON(400, models.Response{
+			Success: false,
+			Message: "invalid two-factor code",
+		})
+		return
+	}
+
+	codes, err := models.EnableTwoFactor(ctxTimeout, db, rdb, user.ID, secret)
+	if err != nil {
+		ctx.JSON(500, models.Response{
+			Success: false,
+			Message: "internal server error",
+		})
+		return
+	}
+
+	ctx.JSON(200, models.ResponseSucces{
+		Success: true,
+		Message: "two-factor authentication enabled, store your recovery codes in a safe place",
+		Result:  gin.H{"recovery_codes": codes},
+	})
+}
+
+// DisableTwoFactor godoc
+// @Summary 	Disable two-factor authentication
+// @Tags 		Profile
+// @Param 		request body 	models.ReqDisableTwoFactor  true 	"TOTP code or recovery code"
+// @Success 	200 {object} 	models.Response
+// @Failure 	403 {object} 	models.Response "Two-factor is mandatory for the role"
+// @Security 	BearerAuth
+// @Router 		/profile/2fa/disable [post]
+func DisableTwoFactor(ctx *gin.Context, db *pgxpool.Pool, rdb *redis.Client) {
+	user, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req models.ReqDisableTwoFactor
+	if !bindJSON(ctx, &req) {
+		return
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	status, err := models.GetTwoFactorStatus(ctxTimeout, db, user.ID)
+	if err != nil {
+		fmt.Println("Internal Server Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{
+			Success: false,
+			Message: "internal server error",
+		})
+		return
+	}
+	if !status.Enabled {
+		ctx.JSON(400, models.Response{
+			Success: false,
+			Message: "two-factor authentication is not enabled",
+		})
+		return
+	}
+	if status.Required {
+		ctx.JSON(403, models.Response{
+			Success: false,
+			Message: "two-factor authentication is mandatory for your role",
+		})
+		return
+	}
+
+	if !checkCurrentSecondFactor(ctx, ctxTimeout, db, rdb, user.ID, req.Code, req.RecoveryCode) {
+		return
+	}
+
+	if err := models.DisableTwoFactor(ctxTimeout, db, user.ID); err != nil {
+		fmt.Println("Internal Server Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{
+			Success: false,
+			Message: "internal server error",
+		})
+		return
+	}
+
+	ctx.JSON(200, models.Response{
+		Success: true,
+		Message: "two-factor authentication disabled",
+	})
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary 	Regenerate recovery codes
+// @Description Old recovery codes stop working
+// @Tags 		Profile
+// @Param 		request body 	models.ReqTOTPCode  true 	"TOTP code"
+// @Success 	200 {object} 	models.ResponseSucces
+// @Security 	BearerAuth
+// @Router 		/profile/2fa/recovery-codes [post]
+func RegenerateRecoveryCodes(ctx *gin.Context, db *pgxpool.Pool, rdb *redis.Client) {
+	user, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req models.ReqTOTPCode
+	if !bindJSON(ctx, &req) {
+		return
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if !checkCurrentSecondFactor(ctx, ctxTimeout, db, rdb, user.ID, req.Code, "") {
+		return
+	}
+
+	codes, err := models.RegenerateRecoveryCodes(ctxTimeout, db, user.ID)
+	if err != nil {
+		fmt.Println("Internal Server Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{
+			Success: false,
+			Message: "internal server error",
+		})
+		return
+	}
+
+	ctx.JSON(200, models.ResponseSucces{
+		Success: true,
+		Message: "recovery codes regenerated",
+		Result:  gin.H{"recovery_codes": codes},
+	})
+}
+
+// --- CHECK CODE AGAINST ENABLED SECRET, WRITE RESPONSE ON FAILURE ---
+func checkCurrentSecondFactor(ctx *gin.Context, ctxTimeout context.Context, db *pgxpool.Pool, rdb *redis.Client, userID int, code, recoveryCode string) bool {
+	secret, err := models.GetTOTPSecret(ctxTimeout, db, userID)
+	if err != nil {
+		fmt.Println("Internal Server Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{
+			Success: false,
+			Message: "internal server error",
+		})
+		return false
+	}
+	if secret == "" {
+		ctx.JSON(400, models.Response{
+			Success: false,
+			Message: "two-factor authentication is not enabled",
+		})
+		return false
+	}
+	if !registerSecondFactorAttempt(ctx, ctxTimeout, rdb, userID) {
+		return false
+	}
+
+	valid, err := verifySecondFactor(ctxTimeout, db, rdb, userID, secret, code, recoveryCode)
+	if err != nil {
+		fmt.Println("Internal Server Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{
+			Success: false,
+			Message: "internal server error",
+		})
+		return false
+	}
+	if !valid {
+		ctx.JSON(400, models.Response{
+			Success: false,
+			Message: "invalid two-factor code",
+		})
+		return false
+	}
+	return true
+}
+
+// GetTwoFactorPolicy godoc
+// @Summary 	Get two-factor policy per role
+// @Tags 		Admin Security
+// @Success 	200 {object} 	models.ResponseSucces
+// @Security 	BearerAuth
+// @Router 		/admin/security/2fa [get]
+func GetTwoFactorPolicy(ctx *gin.Context, db *pgxpool.Pool) {
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	policy, err := models.GetTwoFactorPolicies(ctxTimeout, db)
+	if err != nil {
+		fmt.Println("Internal Server Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{
+			Success: false,
+			Message: "internal server error",
+		})
+		return
+	}
+
+	ctx.JSON(200, models.ResponseSucces{
+		Success: true,
+		Message: "Success",
+		Result:  policy,
+	})
+}
+
+// UpdateTwoFactorPolicy godoc
+// @Summary 	Make two-factor mandatory for a role
+// @Description Users of the role without 2FA are asked to enroll on their next login
+// @Tags 		Admin Security
+// @Param 		request body 	models.ReqTwoFactorPolicy  true 	"Role policy"
+// @Success 	200 {object} 	models.Response
+// @Security 	BearerAuth
+// @Router 		/admin/security/2fa [put]
+func UpdateTwoFactorPolicy(ctx *gin.Context, db *pgxpool.Pool) {
+	var req models.ReqTwoFactorPolicy
+	if !bindJSON(ctx, &req) {
+		return
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := models.SetTwoFactorRequired(ctxTimeout, db, req.Role, *req.Required); err != nil {
+		if errors.Is(err, models.ErrRoleNotFound) {
+			ctx.JSON(404, models.Response{
+				Success: false,
+				Message: err.Error(),
+			})
+			return
+		}
+		fmt.Println("Internal Server Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{
+			Success: false,
+			Message: "internal server error",
+		})
+		return
+	}
+
+	ctx.JSON(200, models.Response{
+		Success: true,
+		Message: fmt.Sprintf("two-factor policy for role %s updated", req.Role),
+	})
+}
@@ -9,6 +9,7 @@ import (
 	"math"
 	"net/http"
 	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -19,6 +20,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
 )
 
 // GetListUser godoc
@@ -26,20 +28,20 @@ import (
 // @Description Get paginated list of users with optional search by name
 // @Tags Users
 // @Param page query int false "Page number" default(1)
+// @Param cursor query string false "Keyset mode, empty for the first page then nextCursor of the previous page"
+// @Param count query bool false "Also return total in keyset mode"
 // @Param name query string false "Filter by name"
 // @Success 200 {object} models.ResponseSucces
 // @Router /admin/user [get]
 // @Security BearerAuth
+// @Security ApiKeyAuth
 func GetListUser(ctx *gin.Context, db *pgxpool.Pool) {
 	// --- GET QUERY PARAMS ---
-	pageStr := ctx.Query("page")
-	page, err := strconv.Atoi(pageStr)
-	if err != nil || page < 1 {
-		page = 1
+	lp, ok := bindPage(ctx, "user", 10)
+	if !ok {
+		return
 	}
-
-	limit := 10
-	offset := (page - 1) * limit
+	page, limit := lp.Number, lp.Limit
 	name := ctx.Query("name")
 
 	// ---- LIMITS QUERY EXECUTION TIME ---
@@ -47,16 +49,20 @@ func GetListUser(ctx *gin.Context, db *pgxpool.Pool) {
 	defer cancel()
 
 	// --- TOTAL USERS  ---
-	total, err := models.GetCountUser(ctxTimeout, db, name)
-	if err != nil {
-		ctx.JSON(500, models.Response{
-			Success: false,
-			Message: "Failed to get total users",
-		})
-		return
+	var total int64
+	if lp.WithCount {
+		count, err := models.GetCountUser(ctxTimeout, db, name)
+		if err != nil {
+			ctx.JSON(500, models.Response{
+				Success: false,
+				Message: "Failed to get total users",
+			})
+			return
+		}
+		total = count
 	}
 
-	users, err := models.GetListUser(ctxTimeout, db, name, limit, offset)
+	users, next, err := models.GetListUser(ctxTimeout, db, name, lp.Page)
 	if err != nil {
 		ctx.JSON(500, models.Response{
 			Success: false,
@@ -99,11 +105,15 @@ func GetListUser(ctx *gin.Context, db *pgxpool.Pool) {
 		return
 	}
 
+	if lp.Keyset {
+		ctx.JSON(200, keysetResponse(ctx, lp, total, users, next))
+		return
+	}
 	ctx.JSON(200, models.PaginatedResponse[models.UserList]{
-		Page:       page,
+		Page:       &page,
 		Limit:      limit,
-		Total:      total,
-		TotalPages: totalPages,
+		Total:      &total,
+		TotalPages: &totalPages,
 		PrevURL:    prevURL,
 		NextURL:    nextURL,
 		Result:     users,
@@ -118,13 +128,16 @@ func GetListUser(ctx *gin.Context, db *pgxpool.Pool) {
 // @Param        fullname  formData  string  true   "Full name of the user"
 // @Param        email     formData  string  true   "Email of the user"
 // @Param        password  formData  string  true   "Password"
-// @Param        role      formData  string  true   "Role (admin, user)"
+// @Param        role      formData  string  false  "Role name, see /admin/roles. Defaults to user, any other role needs roles:manage"
 // @Param        phone     formData  string  true  "Phone number"
 // @Param        address   formData  string  true  "Address"
 // @Param        photos    formData  file    true  "User photo"
 // @Success      200 {object} models.ResponseSucces
+// @Failure      403 {object} models.Response "Role other than user without roles:manage"
+// @Failure      409 {object} models.Response "Email is already registered"
 // @Router       /admin/user [post]
 // @Security     BearerAuth
+// @Security     ApiKeyAuth
 func CreateUser(ctx *gin.Context, db *pgxpool.Pool) {
 	var body models.UserBody
 
@@ -150,22 +163,20 @@ func CreateUser(ctx *gin.Context, db *pgxpool.Pool) {
 		return
 	}
 
-	// --- CHECKING CLAIMS TOKEN ---
-	claims, exists := ctx.Get("claims")
-	if !exists {
-		fmt.Println("ERROR :", !exists)
-		ctx.AbortWithStatusJSON(403, models.Response{
-			Success: false,
-			Message: "Please log in again",
-		})
+	// --- GET USER IN CONTEXT ---
+	user, ok := currentUser(ctx)
+	if !ok {
 		return
 	}
-	user, ok := claims.(libs.Claims)
-	if !ok {
-		fmt.Println("ERROR", !ok)
-		ctx.AbortWithStatusJSON(500, models.Response{
+
+	// --- users:write ONLY CREATES PLAIN USERS, OTHER ROLES GO THROUGH roles:manage ---
+	if body.Role == "" {
+		body.Role = models.DefaultUserRole
+	}
+	if body.Role != models.DefaultUserRole && !slices.Contains(ctx.GetStringSlice("permissions"), "roles:manage") {
+		ctx.JSON(403, models.Response{
 			Success: false,
-			Message: "An error occurred!, please try again.",
+			Message: "Assigning a role other than user requires the roles:manage permission",
 		})
 		return
 	}
@@ -207,6 +218,20 @@ func CreateUser(ctx *gin.Context, db *pgxpool.Pool) {
 	defer cancel()
 	newUser, err := models.CreateUser(ctxTimeout, db, hashed, body)
 	if err != nil {
+		if errors.Is(err, models.ErrRoleNotFound) {
+			ctx.JSON(400, models.Response{
+				Success: false,
+				Message: err.Error(),
+			})
+			return
+		}
+		if errors.Is(err, models.ErrEmailTaken) {
+			ctx.JSON(409, models.Response{
+				Success: false,
+				Message: err.Error(),
+			})
+			return
+		}
 		ctx.JSON(500, models.Response{
 			Success: false,
 			Message: "internal server error",
@@ -234,7 +259,7 @@ func CreateUser(ctx *gin.Context, db *pgxpool.Pool) {
 
 // EditUser godoc
 // @Summary      Edit an existing user
-// @Description  Update user details with optional photo upload
+// @Description  Update user details with optional photo upload. A new email stays pending until the user confirms it from the link sent to it
 // @Tags         Users
 // @Accept       multipart/form-data
 // @Param        id        path      int     true   "User ID"
@@ -245,9 +270,11 @@ func CreateUser(ctx *gin.Context, db *pgxpool.Pool) {
 // @Param        address   formData  string  false  "Address"
 // @Param        photos    formData  file    false  "User photo"
 // @Success      200 {object} models.ResponseSucces
+// @Failure      409 {object} models.Response "Email is already registered"
 // @Router       /admin/user/{id} [patch]
 // @Security     BearerAuth
-func EditUser(ctx *gin.Context, db *pgxpool.Pool) {
+// @Security     ApiKeyAuth
+func EditUser(ctx *gin.Context, db *pgxpool.Pool, rdb *redis.Client) {
 	var body models.UserUpdateBody
 
 	// --- GET PORDUCT ID ---
@@ -285,23 +312,9 @@ func EditUser(ctx *gin.Context, db *pgxpool.Pool) {
 
 	body.Id = userID
 
-	// --- CHECKING CLAIMS TOKEN ---
-	claims, exists := ctx.Get("claims")
-	if !exists {
-		fmt.Println("ERROR :", !exists)
-		ctx.AbortWithStatusJSON(403, models.Response{
-			Success: false,
-			Message: "Please log in again",
-		})
-		return
-	}
-	user, ok := claims.(libs.Claims)
+	// --- GET USER IN CONTEXT ---
+	user, ok := currentUser(ctx)
 	if !ok {
-		fmt.Println("ERROR", !ok)
-		ctx.AbortWithStatusJSON(500, models.Response{
-			Success: false,
-			Message: "An error occurred!, please try again.",
-		})
 		return
 	}
 
@@ -339,6 +352,36 @@ func EditUser(ctx *gin.Context, db *pgxpool.Pool) {
 	// ---- LIMITS QUERY EXECUTION TIME ---
 	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
+
+	// --- NEW EMAIL MUST BE CONFIRMED BY THE USER BEFORE IT IS USED ---
+	var emailPending bool
+	if body.Email != nil && *body.Email != "" {
+		pending, err := requestEmailChange(ctxTimeout, db, rdb, userID, *body.Email)
+		if err != nil {
+			if errors.Is(err, models.ErrEmailTaken) {
+				ctx.JSON(409, models.Response{
+					Success: false,
+					Message: err.Error(),
+				})
+				return
+			}
+			if strings.Contains(err.Error(), "user not found") {
+				ctx.JSON(404, models.Response{
+					Success: false,
+					Message: "user not found",
+				})
+				return
+			}
+			fmt.Println("error :", err)
+			ctx.JSON(500, models.Response{
+				Success: false,
+				Message: "Failed to update user",
+			})
+			return
+		}
+		emailPending = pending
+	}
+
 	users, err := models.EditUser(ctxTimeout, db, body, userID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -365,9 +408,14 @@ func EditUser(ctx *gin.Context, db *pgxpool.Pool) {
 		"phone":    users.Phone,
 		"email":    users.Email,
 	}
+	message := "Update User Succesfully"
+	if emailPending {
+		response["pending_email"] = *body.Email
+		message = "Update User Succesfully, the new email is used after the user confirms it"
+	}
 	ctx.JSON(200, models.ResponseSucces{
 		Success: true,
-		Message: "Update User Succesfully",
+		Message: message,
 		Result:  response,
 	})
 
@@ -0,0 +1,32 @@
+package controllers
+
+import (
+	"fmt"
+
+	"github.com/federus1105/koda-b4-backend/internals/models"
+	"github.com/federus1105/koda-b4-backend/internals/pkg/libs"
+	"github.com/gin-gonic/gin"
+)
+
+// JWKS godoc
+// @Summary 	JSON Web Key Set
+// @Description Public keys to verify access token, select the key by the kid header of the token. Empty when the server still signs with a shared secret
+// @Tags 		Auth
+// @Produce 	json
+// @Success 	200 {object} 	map[string]any
+// @Router 		/.well-known/jwks.json [get]
+func JWKS(ctx *gin.Context) {
+	jwks, err := libs.JWKS()
+	if err != nil {
+		fmt.Println("Internal Server Error.\nCause: ", err)
+		ctx.JSON(500, models.Response{
+			Success: false,
+			Message: "internal server error",
+		})
+		return
+	}
+
+	// --- VERIFIER CAN CACHE KEY SET FOR A FEW MINUTES ---
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(200, jwks)
+}
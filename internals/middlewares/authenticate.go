@@ -0,0 +1,178 @@
+package middlewares
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/federus1105/koda-b4-backend/internals/models"
+	"github.com/federus1105/koda-b4-backend/internals/pkg/auth"
+	"github.com/federus1105/koda-b4-backend/internals/pkg/libs"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+)
+
+// --- REASON A REQUEST IS REJECTED, STATUS IS 401 UNLESS SET ---
+type authError struct {
+	status  int
+	message string
+}
+
+var errNoCredentials = &authError{message: "Please log in first"}
+
+// --- BEARER TOKEN IS REQUIRED, PRINCIPAL IS AVAILABLE WITH auth.Principal ---
+func Authenticate(rdb *redis.Client) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		identity, authErr := resolveBearer(ctx, rdb)
+		if authErr != nil {
+			abortAuth(ctx, authErr)
+			return
+		}
+		authorize(ctx, identity)
+	}
+}
+
+// --- PUBLIC ROUTE, ANONYMOUS REQUEST GOES THROUGH WITHOUT PRINCIPAL BUT A BAD TOKEN IS STILL REJECTED ---
+func OptionalAuthenticate(rdb *redis.Client) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if ctx.GetHeader("Authorization") == "" {
+			ctx.Next()
+			return
+		}
+		identity, authErr := resolveBearer(ctx, rdb)
+		if authErr != nil {
+			abortAuth(ctx, authErr)
+			return
+		}
+		authorize(ctx, identity)
+	}
+}
+
+// --- ACCEPT X-API-Key AS ALTERNATIVE TO BEARER TOKEN, USE WITH RequirePermission ---
+func AuthenticateOrAPIKey(db *pgxpool.Pool, rdb *redis.Client) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		rawKey := ctx.GetHeader("X-API-Key")
+		if rawKey == "" {
+			identity, authErr := resolveBearer(ctx, rdb)
+			if authErr != nil {
+				abortAuth(ctx, authErr)
+				return
+			}
+			authorize(ctx, identity)
+			return
+		}
+
+		keyID, scopes, err := models.AuthenticateAPIKey(ctx.Request.Context(), db, rdb, rawKey)
+		if err != nil {
+			if errors.Is(err, models.ErrAPIKeyInvalid) {
+				abortAuth(ctx, &authError{message: "Invalid API key"})
+				return
+			}
+			log.Println("Internal Server Error.\nCause: ", err.Error())
+			abortAuth(ctx, &authError{status: 500, message: "Internal Server Error"})
+			return
+		}
+
+		authorize(ctx, auth.FromAPIKey(keyID, scopes))
+	}
+}
+
+// --- "Bearer <token>", SCHEME IS CASE-INSENSITIVE ---
+func bearerToken(header string) (string, bool) {
+	scheme, token, found := strings.Cut(strings.TrimSpace(header), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// --- VERIFY SIGNATURE, DENYLIST AND SESSION OF ACCESS TOKEN ---
+func resolveBearer(ctx *gin.Context, rdb *redis.Client) (auth.Identity, *authError) {
+	token, ok := bearerToken(ctx.GetHeader("Authorization"))
+	if !ok {
+		return auth.Identity{}, errNoCredentials
+	}
+
+	var claims libs.Claims
+	if err := claims.VerifyToken(token); err != nil {
+		if errors.Is(err, libs.ErrJWTKeysNotLoaded) {
+			log.Println("Internal Server Error.\nCause: ", err.Error())
+			return auth.Identity{}, &authError{status: 500, message: "Internal Server Error"}
+		}
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return auth.Identity{}, &authError{message: "Token expired, please log in again!"}
+		}
+		log.Println("JWT Error.\nCause: ", err.Error())
+		return auth.Identity{}, &authError{message: "Please log in again"}
+	}
+
+	// --- TOKEN REVOKED BY LOGOUT ---
+	denied, err := models.IsAccessTokenDenied(ctx.Request.Context(), rdb, claims.RegisteredClaims.ID)
+	if err != nil {
+		log.Println("Redis Error.\nCause: ", err.Error())
+		return auth.Identity{}, &authError{status: 500, message: "Internal Server Error"}
+	}
+	if denied {
+		return auth.Identity{}, &authError{message: "Token has been revoked, please log in again"}
+	}
+
+	// --- SESSION REVOKED FROM ANOTHER DEVICE ---
+	if claims.SessionID != "" {
+		alive, err := models.TouchSession(ctx.Request.Context(), rdb, claims.SessionID, ctx.ClientIP())
+		if err != nil {
+			log.Println("Redis Error.\nCause: ", err.Error())
+			return auth.Identity{}, &authError{status: 500, message: "Internal Server Error"}
+		}
+		if !alive {
+			return auth.Identity{}, &authError{message: "Session has been revoked, please log in again"}
+		}
+	}
+
+	return auth.FromClaims(claims), nil
+}
+
+// --- SAVE PRINCIPAL, THEN CHECK WHAT IT MAY DO ON THIS ROUTE ---
+func authorize(ctx *gin.Context, identity auth.Identity) {
+	auth.SetPrincipal(ctx, identity)
+
+	// --- SUPPORT STAFF CAN LOOK BUT NOT CHANGE ANYTHING, ATTEMPT IS STILL AUDITED ---
+	if identity.IsImpersonated() && blockedWhileImpersonating(ctx) {
+		abortForbidden(ctx, "This action is not allowed while impersonating a user")
+		return
+	}
+
+	ctx.Next()
+}
+
+func abortAuth(ctx *gin.Context, authErr *authError) {
+	status := authErr.status
+	if status == 0 {
+		status = http.StatusUnauthorized
+		ctx.Header("WWW-Authenticate", "Bearer")
+	}
+	ctx.AbortWithStatusJSON(status, models.Response{
+		Success: false,
+		Message: authErr.message,
+	})
+}
+
+// --- LOGGED IN, BUT NOT ALLOWED ---
+func abortForbidden(ctx *gin.Context, message string) {
+	ctx.AbortWithStatusJSON(http.StatusForbidden, models.Response{
+		Success: false,
+		Message: message,
+	})
+}
+
+// --- ROUTE NEEDS A PRINCIPAL BUT NO AUTHENTICATION MIDDLEWARE RAN BEFORE ---
+func principalOrAbort(ctx *gin.Context) (auth.Identity, bool) {
+	identity, ok := auth.Principal(ctx)
+	if !ok {
+		abortAuth(ctx, errNoCredentials)
+	}
+	return identity, ok
+}
@@ -0,0 +1,51 @@
+package middlewares
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/federus1105/koda-b4-backend/internals/models"
+	"github.com/federus1105/koda-b4-backend/internals/pkg/auth"
+	"github.com/federus1105/koda-b4-backend/internals/pkg/libs"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// --- LOGOUT ENDS IMPERSONATION, SO IT'S ALWAYS ALLOWED ---
+// Password, email, 2FA, passkey, session and account deletion under /profile stay with the user even when writes are allowed.
+func blockedWhileImpersonating(ctx *gin.Context) bool {
+	switch ctx.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	path := ctx.FullPath()
+	if path == "/auth/logout" {
+		return false
+	}
+	if path == "/profile" || strings.HasPrefix(path, "/profile/") {
+		return true
+	}
+	return !libs.ImpersonationAllowWrite()
+}
+
+// --- WRITE EVERY REQUEST MADE WITH AN IMPERSONATION TOKEN TO THE AUDIT LOG, REGISTER BEFORE ROUTES ---
+func ImpersonationAudit(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Next()
+
+		user, ok := auth.Principal(ctx)
+		if !ok || !user.IsImpersonated() {
+			return
+		}
+
+		// --- REQUEST CONTEXT MAY BE CANCELLED ALREADY ---
+		ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := models.LogImpersonatedRequest(ctxTimeout, db, user.TokenID, ctx.Request.Method, ctx.Request.URL.RequestURI(), ctx.Writer.Status(), ctx.ClientIP()); err != nil {
+			log.Println("Failed to write impersonation audit.\nCause: ", err.Error())
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package middlewares
+
+import (
+	"log"
+	"slices"
+
+	"github.com/federus1105/koda-b4-backend/internals/models"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+)
+
+// --- ROLE OF TOKEN MUST HAVE ALL GIVEN PERMISSION, e.g. RequirePermission(db, rdb, "orders:update") ---
+func RequirePermission(db *pgxpool.Pool, rdb *redis.Client, permissions ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		user, ok := principalOrAbort(ctx)
+		if !ok {
+			return
+		}
+
+		// --- API KEY ONLY HAS ITS OWN SCOPES ---
+		granted := user.Scopes
+		var err error
+		if !user.IsAPIKey() {
+			granted, err = models.GetRolePermissions(ctx.Request.Context(), db, rdb, user.Role)
+		}
+		if err != nil {
+			log.Println("Internal Server Error.\nCause: ", err.Error())
+			ctx.AbortWithStatusJSON(500, models.Response{
+				Success: false,
+				Message: "Internal server error",
+			})
+			return
+		}
+
+		for _, permission := range permissions {
+			if !slices.Contains(granted, permission) {
+				abortForbidden(ctx, "You do not have access rights to this resource.")
+				return
+			}
+		}
+
+		ctx.Set("permissions", granted)
+		ctx.Next()
+	}
+}
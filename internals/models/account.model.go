@@ -0,0 +1,245 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+)
+
+// --- NAME SHOWN ON ORDERS OF A DELETED ACCOUNT ---
+const DeletedAccountName = "Deleted User"
+
+// --- ORDER HISTORY IS READ PAGE BY PAGE WITH GetHistory ---
+const exportHistoryPageSize = 100
+
+var (
+	ErrAccountNotFound = errors.New("user not found")
+	ErrAccountDeleted  = errors.New("account is already deleted")
+)
+
+type ReqDeleteAccount struct {
+	Password string `json:"password" binding:"required"`
+}
+
+type AccountExport struct {
+	Profile    Profiles          `json:"profile"`
+	Addresses  []string          `json:"addresses"`
+	Cart       []Card            `json:"cart"`
+	Orders     []DetailHistories `json:"orders"`
+	Reviews    []Review          `json:"reviews"`
+	ExportedAt time.Time         `json:"exported_at"`
+}
+
+// --- EVERYTHING WE STORE ABOUT THE USER, FOR DATA EXPORT REQUEST ---
+func ExportAccount(ctx context.Context, db *pgxpool.Pool, userID int) (AccountExport, error) {
+	profile, err := Profile(ctx, db, userID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return AccountExport{}, ErrAccountNotFound
+		}
+		return AccountExport{}, err
+	}
+
+	cart, err := GetCartProduct(ctx, db, userID)
+	if err != nil {
+		return AccountExport{}, err
+	}
+
+	orders := []DetailHistories{}
+	page := Page{Limit: exportHistoryPageSize, Keyset: true}
+	for {
+		histories, next, err := GetHistory(ctx, db, userID, 0, 0, page)
+		if err != nil {
+			return AccountExport{}, err
+		}
+		for _, h := range histories {
+			detail, err := DetailHistory(ctx, db, userID, h.Id)
+			if err != nil {
+				// --- ORDER WITHOUT PRODUCT HAS NO DETAIL, KEEP THE SUMMARY ---
+				if err == pgx.ErrNoRows {
+					orders = append(orders, DetailHistories{Id: h.Id, OrderNumber: h.OrderNumber, Status: h.Status, Total: h.Total, CreatedAt: h.Date, Items: []Items{}})
+					continue
+				}
+				return AccountExport{}, err
+			}
+			orders = append(orders, detail)
+		}
+		if next == nil {
+			break
+		}
+		page.After = next
+	}
+
+	reviews, err := GetUserReviews(ctx, db, userID)
+	if err != nil {
+		return AccountExport{}, err
+	}
+
+	// --- ADDRESS FROM PROFILE AND EVERY ADDRESS USED ON AN ORDER ---
+	addresses := []string{}
+	seen := map[string]bool{}
+	addAddress := func(address string) {
+		if address == "" || seen[address] {
+			return
+		}
+		seen[address] = true
+		addresses = append(addresses, address)
+	}
+	if profile.Address != nil {
+		addAddress(*profile.Address)
+	}
+	for _, order := range orders {
+		addAddress(order.Addres)
+	}
+
+	return AccountExport{
+		Profile:    profile,
+		Addresses:  addresses,
+		Cart:       cart,
+		Orders:     orders,
+		Reviews:    reviews,
+		ExportedAt: time.Now(),
+	}, nil
+}
+
+// --- PASSWORD HASH TO CONFIRM SELF DELETION ---
+func GetAccountPassword(ctx context.Context, db *pgxpool.Pool, userID int) (string, error) {
+	var password string
+	var deletedAt *time.Time
+	err := db.QueryRow(ctx, `SELECT password, deleted_at FROM users WHERE id = $1`, userID).Scan(&password, &deletedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return "", ErrAccountNotFound
+		}
+		return "", err
+	}
+	if deletedAt != nil {
+		return "", ErrAccountDeleted
+	}
+	return password, nil
+}
+
+// --- ANONYMISE ACCOUNT, ORDERS ARE KEPT FOR ACCOUNTING WITHOUT PERSONAL DATA ---
+// unusablePassword is a hash of a random secret, so the password can't be guessed or reset into use.
+func DeleteAccount(ctx context.Context, db *pgxpool.Pool, rdb *redis.Client, userID int, unusablePassword string) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		log.Println("Failed to begin transaction : ", err)
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var deletedAt *time.Time
+	if err := tx.QueryRow(ctx, `SELECT deleted_at FROM users WHERE id = $1 FOR UPDATE`, userID).Scan(&deletedAt); err != nil {
+		if err == pgx.ErrNoRows {
+			return ErrAccountNotFound
+		}
+		return err
+	}
+	if deletedAt != nil {
+		return ErrAccountDeleted
+	}
+
+	// --- EMAIL STAYS UNIQUE AND CAN'T RECEIVE MAIL ---
+	anonymousEmail := fmt.Sprintf("deleted-%d@deleted.invalid", userID)
+	if _, err := tx.Exec(ctx, `
+		UPDATE users SET email = $2, password = $3, pending_email = NULL,
+			totp_secret = NULL, totp_enabled_at = NULL, role = 'user', deleted_at = NOW()
+		WHERE id = $1
+	`, userID, anonymousEmail, unusablePassword); err != nil {
+		log.Println("Failed to anonymise user :", err)
+		return err
+	}
+
+	var accountID int
+	err = tx.QueryRow(ctx, `
+		UPDATE account SET fullname = $2, phonenumber = NULL, address = NULL, photos = NULL, updatedat = NOW()
+		WHERE id_users = $1
+		RETURNING id
+	`, userID, DeletedAccountName).Scan(&accountID)
+	if err != nil && err != pgx.ErrNoRows {
+		log.Println("Failed to anonymise account :", err)
+		return err
+	}
+
+	if accountID != 0 {
+		if _, err := tx.Exec(ctx, `DELETE FROM cart WHERE account_id = $1`, accountID); err != nil {
+			log.Println("Failed to delete cart :", err)
+			return err
+		}
+		// --- ORDER NUMBER, ITEMS AND AMOUNT STAY, CONTACT DETAIL OF THE BUYER IS REMOVED ---
+		if _, err := tx.Exec(ctx, `
+			UPDATE orders SET fullname = $2, email = $3, phonenumber = '-', address = '-'
+			WHERE id_account = $1
+		`, accountID, DeletedAccountName, anonymousEmail); err != nil {
+			log.Println("Failed to anonymise orders :", err)
+			return err
+		}
+	}
+
+	// --- REVIEWS STAY UNDER THE ANONYMISED NAME, HELPFUL VOTES GO ---
+	if _, err := tx.Exec(ctx, `DELETE FROM product_review_votes WHERE id_users = $1`, userID); err != nil {
+		log.Println("Failed to delete review votes :", err)
+		return err
+	}
+
+	for _, query := range []string{
+		`DELETE FROM recovery_codes WHERE id_users = $1`,
+		`DELETE FROM user_identities WHERE id_users = $1`,
+		`DELETE FROM passkeys WHERE id_users = $1`,
+		`DELETE FROM password_history WHERE id_users = $1`,
+	} {
+		if _, err := tx.Exec(ctx, query, userID); err != nil {
+			log.Println("Failed to delete login data :", err)
+			return err
+		}
+	}
+
+	// --- KEYS OF A DELETED ADMIN STOP WORKING WITH THE ACCOUNT ---
+	prefixes, err := revokeUserAPIKeys(ctx, tx, userID)
+	if err != nil {
+		log.Println("Failed to revoke api keys :", err)
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Println("Failed to commit Transaction : ", err)
+		return err
+	}
+
+	for _, prefix := range prefixes {
+		if err := rdb.Del(ctx, apiKeyCachePrefix+prefix).Err(); err != nil {
+			log.Println("Redis Error.\nCause: ", err)
+		}
+	}
+	return nil
+}
+
+// --- PENDING LINK (RESET, VERIFY, MAGIC LINK, EMAIL CHANGE) CAN'T BE USED AFTER DELETION ---
+func RevokeUserTokens(ctx context.Context, rdb *redis.Client, userID int) error {
+	for _, p := range [][2]string{
+		{resetPasswordPrefix, resetPasswordUserPrefix},
+		{VerifyEmailPrefix, verifyEmailUserPrefix},
+		{magicLinkPrefix, magicLinkUserPrefix},
+		{emailChangePrefix, emailChangeUserPrefix},
+	} {
+		userKey := fmt.Sprintf("%s%d", p[1], userID)
+		token, err := rdb.Get(ctx, userKey).Result()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := rdb.Del(ctx, p[0]+token, userKey).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,246 @@
+package models
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/federus1105/koda-b4-backend/internals/pkg/libs"
+	"github.com/federus1105/koda-b4-backend/internals/pkg/utils"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+)
+
+// --- REDIS KEY PREFIX ---
+const (
+	apiKeyCachePrefix = "apikey:"
+	apiKeyUsedPrefix  = "apikey:used:"
+)
+
+const (
+	apiKeyTokenPrefix     = "skk_"
+	apiKeyCacheTTL        = time.Minute
+	apiKeyTouchInterval   = time.Minute
+	DefaultAPIKeyLifetime = 90
+)
+
+var (
+	ErrAPIKeyInvalid  = errors.New("invalid, expired or revoked api key")
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	ErrAPIKeyScope    = errors.New("scope is not granted to your role")
+)
+
+type APIKey struct {
+	ID         int        `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedBy  *int       `json:"created_by"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type ReqCreateAPIKey struct {
+	Name          string   `json:"name" binding:"required,max=100"`
+	Scopes        []string `json:"scopes" binding:"required,min=1,dive,required"`
+	ExpiresInDays *int     `json:"expires_in_days" binding:"omitempty,min=1,max=365"`
+}
+
+// --- DATA NEEDED TO AUTHENTICATE, CACHED IN REDIS ---
+type apiKeyRecord struct {
+	ID          int        `json:"id"`
+	Hash        string     `json:"hash"`
+	Scopes      []string   `json:"scopes"`
+	CreatorRole string     `json:"creator_role"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+}
+
+func hashAPIKey(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+// --- CREATE API KEY, PLAIN KEY IS RETURNED ONCE ---
+func CreateAPIKey(ctx context.Context, db *pgxpool.Pool, createdBy int, granted []string, req ReqCreateAPIKey) (APIKey, string, error) {
+	scopes := slices.Compact(slices.Sorted(slices.Values(req.Scopes)))
+
+	// --- CREATOR CAN'T GIVE MORE THAN THEIR ROLE HAS ---
+	for _, scope := range scopes {
+		if !slices.Contains(granted, scope) {
+			return APIKey{}, "", fmt.Errorf("%w: %s", ErrAPIKeyScope, scope)
+		}
+	}
+	var known int
+	if err := db.QueryRow(ctx, `SELECT COUNT(*) FROM permissions WHERE code = ANY($1)`, scopes).Scan(&known); err != nil {
+		return APIKey{}, "", err
+	}
+	if known != len(scopes) {
+		return APIKey{}, "", ErrPermissionNotFound
+	}
+
+	prefix, err := utils.GenerateRandomToken(4)
+	if err != nil {
+		return APIKey{}, "", err
+	}
+	secret, err := utils.GenerateRandomToken(24)
+	if err != nil {
+		return APIKey{}, "", err
+	}
+	raw := apiKeyTokenPrefix + prefix + "_" + secret
+
+	days := DefaultAPIKeyLifetime
+	if req.ExpiresInDays != nil {
+		days = *req.ExpiresInDays
+	}
+	expiresAt := time.Now().Add(time.Duration(days) * 24 * time.Hour)
+
+	key := APIKey{Name: req.Name, Prefix: prefix, Scopes: scopes, CreatedBy: &createdBy, ExpiresAt: &expiresAt}
+	err = db.QueryRow(ctx, `
+		INSERT INTO api_keys (name, prefix, key_hash, scopes, created_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`, req.Name, prefix, hashAPIKey(raw), scopes, createdBy, expiresAt).Scan(&key.ID, &key.CreatedAt)
+	if err != nil {
+		log.Println("Failed to insert api key :", err)
+		return APIKey{}, "", err
+	}
+	return key, raw, nil
+}
+
+// --- LIST API KEY, NEWEST FIRST ---
+func GetAPIKeys(ctx context.Context, db *pgxpool.Pool) ([]APIKey, error) {
+	rows, err := db.Query(ctx, `
+		SELECT id, name, prefix, scopes, created_by, expires_at, last_used_at, revoked_at, created_at
+		FROM api_keys
+		ORDER BY created_at DESC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByPos[APIKey])
+}
+
+// --- REVOKE API KEY, TAKES EFFECT IMMEDIATELY ---
+func RevokeAPIKey(ctx context.Context, db *pgxpool.Pool, rdb *redis.Client, id int) error {
+	var prefix string
+	err := db.QueryRow(ctx, `
+		UPDATE api_keys SET revoked_at = COALESCE(revoked_at, NOW())
+		WHERE id = $1
+		RETURNING prefix
+	`, id).Scan(&prefix)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return ErrAPIKeyNotFound
+		}
+		return err
+	}
+	return rdb.Del(ctx, apiKeyCachePrefix+prefix).Err()
+}
+
+// --- REVOKE EVERY KEY CREATED BY THE USER, RETURN PREFIXES TO CLEAR FROM CACHE AFTER COMMIT ---
+func revokeUserAPIKeys(ctx context.Context, tx pgx.Tx, userID int) ([]string, error) {
+	rows, err := tx.Query(ctx, `
+		UPDATE api_keys SET revoked_at = NOW()
+		WHERE created_by = $1 AND revoked_at IS NULL
+		RETURNING prefix
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+// --- DROP CACHED KEYS OF THE USER, THEIR SCOPES FOLLOW THE NEW ROLE ON THE NEXT REQUEST ---
+func invalidateUserAPIKeys(ctx context.Context, db *pgxpool.Pool, rdb *redis.Client, userID int) error {
+	rows, err := db.Query(ctx, `SELECT prefix FROM api_keys WHERE created_by = $1 AND revoked_at IS NULL`, userID)
+	if err != nil {
+		return err
+	}
+	prefixes, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil || len(prefixes) == 0 {
+		return err
+	}
+	keys := make([]string, len(prefixes))
+	for i, prefix := range prefixes {
+		keys[i] = apiKeyCachePrefix + prefix
+	}
+	return rdb.Del(ctx, keys...).Err()
+}
+
+// --- AUTHENTICATE RAW KEY FROM X-API-Key HEADER, RETURN ID AND SCOPES ---
+// --- SCOPES ARE LIMITED TO WHAT THE CREATOR'S ROLE GRANTS NOW, A DEMOTION OR A REMOVED PERMISSION APPLIES TO THEIR KEYS ---
+func AuthenticateAPIKey(ctx context.Context, db *pgxpool.Pool, rdb *redis.Client, raw string) (int, []string, error) {
+	rest, ok := strings.CutPrefix(raw, apiKeyTokenPrefix)
+	if !ok {
+		return 0, nil, ErrAPIKeyInvalid
+	}
+	prefix, _, ok := strings.Cut(rest, "_")
+	if !ok || prefix == "" {
+		return 0, nil, ErrAPIKeyInvalid
+	}
+
+	record, err := libs.GetFromCache[apiKeyRecord](ctx, rdb, apiKeyCachePrefix+prefix)
+	if err != nil {
+		log.Println("Redis Error.\nCause: ", err)
+	}
+	if record == nil {
+		var r apiKeyRecord
+		err := db.QueryRow(ctx, `
+			SELECT k.id, k.key_hash, k.scopes, COALESCE(u.role, ''), k.expires_at
+			FROM api_keys k
+			LEFT JOIN users u ON u.id = k.created_by AND u.deleted_at IS NULL
+			WHERE k.prefix = $1 AND k.revoked_at IS NULL
+		`, prefix).Scan(&r.ID, &r.Hash, &r.Scopes, &r.CreatorRole, &r.ExpiresAt)
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				return 0, nil, ErrAPIKeyInvalid
+			}
+			return 0, nil, err
+		}
+		record = &r
+		if err := libs.SetToCache(ctx, rdb, apiKeyCachePrefix+prefix, r, apiKeyCacheTTL); err != nil {
+			log.Println("Redis Error.\nCause: ", err)
+		}
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashAPIKey(raw)), []byte(record.Hash)) != 1 {
+		return 0, nil, ErrAPIKeyInvalid
+	}
+	if record.ExpiresAt != nil && time.Now().After(*record.ExpiresAt) {
+		return 0, nil, ErrAPIKeyInvalid
+	}
+
+	// --- UPDATE LAST USED AT MOST ONCE A MINUTE ---
+	first, err := rdb.SetNX(ctx, fmt.Sprintf("%s%d", apiKeyUsedPrefix, record.ID), 1, apiKeyTouchInterval).Result()
+	if err == nil && first {
+		if _, err := db.Exec(ctx, `UPDATE api_keys SET last_used_at = NOW() WHERE id = $1`, record.ID); err != nil {
+			log.Println("Failed to update api key last used :", err)
+		}
+	}
+
+	// --- A KEY WITHOUT CREATOR HAS NO ROLE TO GRANT ITS SCOPES ---
+	scopes := []string{}
+	if record.CreatorRole != "" {
+		granted, err := GetRolePermissions(ctx, db, rdb, record.CreatorRole)
+		if err != nil {
+			return 0, nil, err
+		}
+		for _, scope := range record.Scopes {
+			if slices.Contains(granted, scope) {
+				scopes = append(scopes, scope)
+			}
+		}
+	}
+
+	return record.ID, scopes, nil
+}
@@ -0,0 +1,139 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/federus1105/koda-b4-backend/internals/pkg/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+// --- REDIS KEY PREFIX ---
+const (
+	attemptPrefix   = "auth:attempts:"
+	lockPrefix      = "auth:lock:"
+	lockLevelPrefix = "auth:lock:level:"
+)
+
+// --- LOCKOUT GROWS FROM BASE, DOUBLE ON EVERY REPEAT ---
+const (
+	lockoutBase     = time.Minute
+	lockoutMax      = time.Hour
+	lockoutLevelTTL = 24 * time.Hour
+)
+
+// --- EACH SCOPE HAS ITS OWN LOCK, FAILING ONE ENDPOINT NEVER LOCKS ANOTHER ---
+const (
+	ScopeLogin  = "login"
+	ScopeForgot = "forgot"
+	ScopeMagic  = "magic"
+	Scope2FA    = "2fa"
+)
+
+type AttemptPolicy struct {
+	Scope  string
+	Limit  int
+	Window time.Duration
+}
+
+var (
+	LoginEmailPolicy  = AttemptPolicy{Scope: ScopeLogin, Limit: 5, Window: 15 * time.Minute}
+	LoginIPPolicy     = AttemptPolicy{Scope: ScopeLogin, Limit: 20, Window: 15 * time.Minute}
+	ForgotEmailPolicy = AttemptPolicy{Scope: ScopeForgot, Limit: 3, Window: 15 * time.Minute}
+	ForgotIPPolicy    = AttemptPolicy{Scope: ScopeForgot, Limit: 10, Window: 15 * time.Minute}
+	MagicEmailPolicy  = AttemptPolicy{Scope: ScopeMagic, Limit: 3, Window: 15 * time.Minute}
+	MagicIPPolicy     = AttemptPolicy{Scope: ScopeMagic, Limit: 10, Window: 15 * time.Minute}
+	// --- PER USER, NOT PER CHALLENGE, AND COUNTED BEFORE THE CODE IS CHECKED ---
+	TwoFactorUserPolicy = AttemptPolicy{Scope: Scope2FA, Limit: 10, Window: 15 * time.Minute}
+)
+
+// --- SUBJECT KEY FOR EMAIL AND IP ---
+func EmailSubject(email string) string {
+	return "email:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func IPSubject(ip string) string {
+	return "ip:" + ip
+}
+
+func UserSubject(userID int) string {
+	return "user:" + strconv.Itoa(userID)
+}
+
+func lockKey(scope, subject string) string {
+	return lockPrefix + scope + ":" + subject
+}
+
+func lockLevelKey(scope, subject string) string {
+	return lockLevelPrefix + scope + ":" + subject
+}
+
+// --- GET LONGEST REMAINING LOCKOUT OF SUBJECTS IN scope ---
+func GetLockout(ctx context.Context, rdb *redis.Client, scope string, subjects ...string) (time.Duration, error) {
+	var longest time.Duration
+	for _, subject := range subjects {
+		ttl, err := rdb.PTTL(ctx, lockKey(scope, subject)).Result()
+		if err != nil {
+			return 0, err
+		}
+		if ttl > longest {
+			longest = ttl
+		}
+	}
+	return longest, nil
+}
+
+// --- RECORD ATTEMPT IN SLIDING WINDOW, RETURN LOCKOUT WHEN LIMIT IS REACHED ---
+func RegisterAttempt(ctx context.Context, rdb *redis.Client, policy AttemptPolicy, subject string) (time.Duration, error) {
+	key := fmt.Sprintf("%s%s:%s", attemptPrefix, policy.Scope, subject)
+	now := time.Now()
+
+	// --- RANDOM SUFFIX, CONCURRENT ATTEMPTS IN THE SAME NANOSECOND ARE STILL COUNTED ---
+	suffix, err := utils.GenerateRandomToken(8)
+	if err != nil {
+		return 0, err
+	}
+	member := fmt.Sprintf("%d:%s", now.UnixNano(), suffix)
+
+	pipe := rdb.TxPipeline()
+	pipe.ZRemRangeByScore(ctx, key, "-inf", strconv.FormatInt(now.Add(-policy.Window).UnixNano(), 10))
+	pipe.ZAdd(ctx, key, redis.Z{Score: float64(now.UnixNano()), Member: member})
+	count := pipe.ZCard(ctx, key)
+	pipe.Expire(ctx, key, policy.Window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+
+	if count.Val() < int64(policy.Limit) {
+		return 0, nil
+	}
+
+	// --- ESCALATE LOCKOUT ---
+	levelKey := lockLevelKey(policy.Scope, subject)
+	level, err := rdb.Incr(ctx, levelKey).Result()
+	if err != nil {
+		return 0, err
+	}
+	rdb.Expire(ctx, levelKey, lockoutLevelTTL)
+
+	duration := lockoutMax
+	if level < 8 {
+		duration = min(lockoutBase<<(level-1), lockoutMax)
+	}
+
+	pipe = rdb.TxPipeline()
+	pipe.Set(ctx, lockKey(policy.Scope, subject), level, duration)
+	pipe.Del(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return duration, nil
+}
+
+// --- CLEAR ATTEMPTS OF AN EMAIL OR USER AFTER SUCCESS, THE IP WINDOW EXPIRES ON ITS OWN SO A LOGIN CAN'T RESET IT ---
+func ResetAttempts(ctx context.Context, rdb *redis.Client, scope, subject string) error {
+	return rdb.Del(ctx, fmt.Sprintf("%s%s:%s", attemptPrefix, scope, subject), lockLevelKey(scope, subject)).Err()
+}
@@ -2,8 +2,12 @@ package models
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"log"
+	"strconv"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -20,31 +24,56 @@ type AuthRegister struct {
 	Id       int    `json:"id"`
 	Fullname string `json:"fullname" binding:"required,max=20"`
 	Email    string `json:"email"  binding:"required,email"`
-	Password string `json:"password" binding:"required,password_complex"`
+	Password string `json:"password" binding:"required,password_length,password_complex,password_breached"`
 }
 
 type AuthLogin struct {
-	Id       int    `json:"id"`
-	Email    string `json:"email"  binding:"required,email"`
-	Password string `json:"password" binding:"required,password_complex"`
-	Role     string `json:"role,omitempty"`
+	Id          int        `json:"id"`
+	Email       string     `json:"email"  binding:"required,email"`
+	Password    string     `json:"password" binding:"required,password_complex"`
+	Role        string     `json:"role,omitempty"`
+	VerifiedAt  *time.Time `json:"-"`
+	TotpEnabled bool       `json:"-"`
 }
 
 type Users struct {
-	Id       int    `json:"id"`
-	Email    string `json:"email" binding:"email"`
-	Password string `json:"password" binding:"password_complex"`
+	Id         int        `json:"id"`
+	Email      string     `json:"email" binding:"email"`
+	Password   string     `json:"password" binding:"password_complex"`
+	VerifiedAt *time.Time `json:"-"`
 }
 
 type ReqResetPassword struct {
 	Token       string `json:"token" binding:"required"`
-	NewPassword string `json:"new_password" binding:"password_complex"`
+	NewPassword string `json:"new_password" binding:"required,password_length,password_complex,password_breached"`
 }
 
 type ReqForgot struct {
 	Email string `json:"email" binding:"required,email"`
 }
 
+type ReqVerifyEmail struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type ReqResendVerification struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// --- REDIS KEY PREFIX EMAIL VERIFICATION ---
+const (
+	VerifyEmailPrefix     = "verify:email:"
+	verifyEmailUserPrefix = "verify:email:user:"
+)
+
+// --- REDIS KEY PREFIX RESET PASSWORD ---
+const (
+	resetPasswordPrefix     = "reset:pwd:"
+	resetPasswordUserPrefix = "reset:pwd:user:"
+)
+
+const ResetPasswordTTL = 15 * time.Minute
+
 func Register(ctx context.Context, db *pgxpool.Pool, hashed string, user AuthRegister) (AuthRegister, error) {
 	// --- START TRANSACTION ---
 	tx, err := db.Begin(ctx)
@@ -60,6 +89,9 @@ func Register(ctx context.Context, db *pgxpool.Pool, hashed string, user AuthReg
 		`INSERT INTO users (email, password) VALUES ($1, $2) 
 		RETURNING id`, user.Email, hashed).Scan(&userID)
 	if err != nil {
+		if IsUniqueViolation(err) {
+			return AuthRegister{}, ErrEmailTaken
+		}
 		log.Println("Failed to insert user :", err)
 		return AuthRegister{}, err
 	}
@@ -87,9 +119,9 @@ func Register(ctx context.Context, db *pgxpool.Pool, hashed string, user AuthReg
 }
 
 func Login(ctx context.Context, db *pgxpool.Pool, email string) (AuthLogin, error) {
-	sql := `SELECT id, email, password, role FROM users WHERE email = $1`
+	sql := `SELECT id, email, password, role, verified_at, totp_enabled_at IS NOT NULL FROM users WHERE LOWER(email) = LOWER($1)`
 	var user AuthLogin
-	if err := db.QueryRow(ctx, sql, email).Scan(&user.Id, &user.Email, &user.Password, &user.Role); err != nil {
+	if err := db.QueryRow(ctx, sql, email).Scan(&user.Id, &user.Email, &user.Password, &user.Role, &user.VerifiedAt, &user.TotpEnabled); err != nil {
 		if err == pgx.ErrNoRows {
 			return AuthLogin{}, errors.New("user not found")
 		}
@@ -99,19 +131,88 @@ func Login(ctx context.Context, db *pgxpool.Pool, email string) (AuthLogin, erro
 	return user, nil
 }
 
+// ----- GET USER BY ID, WITH WHAT IS NEEDED TO FINISH LOGIN -----
+func GetUserByID(ctx context.Context, db *pgxpool.Pool, id int) (AuthLogin, error) {
+	sql := `SELECT id, email, role, verified_at, totp_enabled_at IS NOT NULL FROM users WHERE id = $1`
+	var user AuthLogin
+	if err := db.QueryRow(ctx, sql, id).Scan(&user.Id, &user.Email, &user.Role, &user.VerifiedAt, &user.TotpEnabled); err != nil {
+		if err == pgx.ErrNoRows {
+			return AuthLogin{}, errors.New("user not found")
+		}
+		return AuthLogin{}, err
+	}
+	return user, nil
+}
+
 // ----- GET USER BY EMAIL -----
 func GetUserByEmail(ctx context.Context, db *pgxpool.Pool, email string) (*Users, error) {
-	query := "SELECT id, email, password FROM users WHERE email=$1"
+	query := "SELECT id, email, password, verified_at FROM users WHERE LOWER(email) = LOWER($1)"
 	var user Users
-	if err := db.QueryRow(ctx, query, email).Scan(&user.Id, &user.Email, &user.Password); err != nil {
+	if err := db.QueryRow(ctx, query, email).Scan(&user.Id, &user.Email, &user.Password, &user.VerifiedAt); err != nil {
 		return nil, errors.New("user not found")
 	}
 	return &user, nil
 }
 
-// --- SAVE TOKEN FOR RESET PASSWORD ---
-func SaveResetToken(ctx context.Context, rdb *redis.Client, key, userID string, ttl time.Duration) error {
-	return rdb.Set(ctx, key, userID, ttl).Err()
+// --- SAVE HASH OF RESET TOKEN, OLDER LINK OF USER IS INVALIDATED ---
+func SaveResetToken(ctx context.Context, rdb *redis.Client, userID int, token string, ttl time.Duration) error {
+	return saveUserToken(ctx, rdb, resetPasswordPrefix, resetPasswordUserPrefix, userID, token, ttl)
+}
+
+// --- USER OF RESET TOKEN WITHOUT USING IT, SO THE NEW PASSWORD CAN BE CHECKED FIRST ---
+func GetResetTokenUserID(ctx context.Context, rdb *redis.Client, token string) (int, error) {
+	val, err := GetUserIDFromToken(ctx, rdb, resetPasswordPrefix+hashToken(token))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(val)
+}
+
+// --- CONSUME RESET TOKEN, A TOKEN CAN ONLY BE USED ONCE ---
+func ConsumeResetToken(ctx context.Context, rdb *redis.Client, token string) (int, error) {
+	return consumeUserToken(ctx, rdb, resetPasswordPrefix, resetPasswordUserPrefix, token)
+}
+
+// --- ONE TOKEN PER USER, ONLY HASH IS STORED SO A LEAKED REDIS DUMP IS USELESS ---
+func saveUserToken(ctx context.Context, rdb *redis.Client, prefix, userPrefix string, userID int, token string, ttl time.Duration) error {
+	userKey := fmt.Sprintf("%s%d", userPrefix, userID)
+	old, err := rdb.Get(ctx, userKey).Result()
+	if err != nil && err != redis.Nil {
+		return err
+	}
+
+	hashed := hashToken(token)
+	pipe := rdb.TxPipeline()
+	if old != "" {
+		pipe.Del(ctx, prefix+old)
+	}
+	pipe.Set(ctx, prefix+hashed, strconv.Itoa(userID), ttl)
+	pipe.Set(ctx, userKey, hashed, ttl)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func consumeUserToken(ctx context.Context, rdb *redis.Client, prefix, userPrefix, token string) (int, error) {
+	hashed := hashToken(token)
+	val, err := rdb.GetDel(ctx, prefix+hashed).Result()
+	if err != nil {
+		return 0, errors.New("invalid or expired token")
+	}
+	userID, err := strconv.Atoi(val)
+	if err != nil {
+		return 0, errors.New("invalid or expired token")
+	}
+
+	userKey := fmt.Sprintf("%s%d", userPrefix, userID)
+	if current, err := rdb.Get(ctx, userKey).Result(); err == nil && current == hashed {
+		rdb.Del(ctx, userKey)
+	}
+	return userID, nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
 
 // --- GET USER ID FROM TOKEN REDIS ---
@@ -123,11 +224,65 @@ func GetUserIDFromToken(ctx context.Context, rdb *redis.Client, key string) (str
 	return val, nil
 }
 
-// --- UPDATE PASSWORD ---
+// --- UPDATE PASSWORD, PREVIOUS HASH IS KEPT IN HISTORY ---
 func UpdatePasswordByID(ctx context.Context, db *pgxpool.Pool, userID int, hashedPassword string) error {
-	_, err := db.Exec(ctx,
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		log.Println("Failed to begin transaction : ", err)
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var previous string
+	if err := tx.QueryRow(ctx, "SELECT password FROM users WHERE id = $1 FOR UPDATE", userID).Scan(&previous); err != nil {
+		if err == pgx.ErrNoRows {
+			return errors.New("user not found")
+		}
+		return err
+	}
+
+	if _, err := tx.Exec(ctx,
 		"UPDATE users SET password = $1 WHERE id = $2",
 		hashedPassword, userID,
+	); err != nil {
+		log.Println("Failed to update password :", err)
+		return err
+	}
+
+	if err := savePasswordHistory(ctx, tx, userID, previous); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// --- SAVE VERIFICATION TOKEN, OLDER LINK OF USER IS INVALIDATED ---
+func SaveVerifyToken(ctx context.Context, rdb *redis.Client, userID int, token string, ttl time.Duration) error {
+	userKey := fmt.Sprintf("%s%d", verifyEmailUserPrefix, userID)
+	old, err := rdb.Get(ctx, userKey).Result()
+	if err != nil && err != redis.Nil {
+		return err
+	}
+
+	pipe := rdb.TxPipeline()
+	if old != "" {
+		pipe.Del(ctx, VerifyEmailPrefix+old)
+	}
+	pipe.Set(ctx, VerifyEmailPrefix+token, strconv.Itoa(userID), ttl)
+	pipe.Set(ctx, userKey, token, ttl)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// --- DELETE VERIFICATION TOKEN AFTER USED ---
+func DeleteVerifyToken(ctx context.Context, rdb *redis.Client, userID int, token string) error {
+	return rdb.Del(ctx, VerifyEmailPrefix+token, fmt.Sprintf("%s%d", verifyEmailUserPrefix, userID)).Err()
+}
+
+// --- MARK EMAIL AS VERIFIED ---
+func MarkEmailVerified(ctx context.Context, db *pgxpool.Pool, userID int) error {
+	_, err := db.Exec(ctx,
+		"UPDATE users SET verified_at = NOW() WHERE id = $1 AND verified_at IS NULL",
+		userID,
 	)
 	return err
 }
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/federus1105/koda-b4-backend/internals/pkg/libs"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -17,27 +18,40 @@ type Categories struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
-func GetListCategories(ctx context.Context, db *pgxpool.Pool, name string, limit, offset int) ([]Categories, error) {
+func GetListCategories(ctx context.Context, db *pgxpool.Pool, name string, page Page) ([]Categories, *libs.Cursor, error) {
 	sql := `SELECT id, name, created_at, updated_at FROM categories`
 
+	whereClauses := []string{}
 	args := []interface{}{}
 	argIdx := 1
 
 	// --- SEARCH ---
 	if strings.TrimSpace(name) != "" {
-		sql += fmt.Sprintf(" WHERE name ILIKE $%d", argIdx)
+		whereClauses = append(whereClauses, fmt.Sprintf("name ILIKE $%d", argIdx))
 		args = append(args, "%"+name+"%")
 		argIdx++
 	}
 
+	// --- AFTER CURSOR ---
+	if condition, cursorArgs, next := page.afterCursor("name", "id", "text", false, argIdx); condition != "" {
+		whereClauses = append(whereClauses, condition)
+		args = append(args, cursorArgs...)
+		argIdx = next
+	}
+
+	if len(whereClauses) > 0 {
+		sql += " WHERE " + strings.Join(whereClauses, " AND ")
+	}
+
 	// --- ORDER LIMIT, OFFSET ---
-	sql += fmt.Sprintf(" ORDER BY name ASC LIMIT $%d OFFSET $%d", argIdx, argIdx+1)
-	args = append(args, limit, offset)
+	limitSQL, limitArgs := page.limitClause(argIdx)
+	sql += " ORDER BY name ASC, id ASC" + limitSQL
+	args = append(args, limitArgs...)
 
 	// --- EXECUTE QUERY ---
 	rows, err := db.Query(ctx, sql, args...)
 	if err != nil {
-		fmt.Println(err)
+		return nil, nil, err
 	}
 	defer rows.Close()
 
@@ -45,16 +59,19 @@ func GetListCategories(ctx context.Context, db *pgxpool.Pool, name string, limit
 	for rows.Next() {
 		var c Categories
 		if err := rows.Scan(&c.Id, &c.Name, &c.CreatedAt, &c.UpdatedAt); err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		categories = append(categories, c)
 	}
 
 	if rows.Err() != nil {
-		return nil, rows.Err()
+		return nil, nil, rows.Err()
 	}
 
-	return categories, nil
+	categories, next := keysetPage(page, "category", categories, func(i int) (string, int) {
+		return categories[i].Name, categories[i].Id
+	})
+	return categories, next, nil
 
 }
 
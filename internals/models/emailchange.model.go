@@ -0,0 +1,113 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+)
+
+// --- REDIS KEY PREFIX ---
+const (
+	emailChangePrefix     = "email:change:"
+	emailChangeUserPrefix = "email:change:user:"
+)
+
+const EmailChangeTTL = 24 * time.Hour
+
+var (
+	ErrEmailTaken         = errors.New("email is already registered")
+	ErrEmailUnchanged     = errors.New("new email is the same as the current email")
+	ErrEmailChangeInvalid = errors.New("invalid or expired token")
+)
+
+type ReqConfirmEmailChange struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// --- UNIQUE INDEX ON LOWER(email) ---
+func IsUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// --- EMAIL IS COMPARED CASE-INSENSITIVE ---
+func EmailTaken(ctx context.Context, db *pgxpool.Pool, email string, exceptUserID int) (bool, error) {
+	var taken bool
+	err := db.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM users WHERE LOWER(email) = LOWER($1) AND id <> $2)`,
+		strings.TrimSpace(email), exceptUserID).Scan(&taken)
+	return taken, err
+}
+
+// --- PUT NEW EMAIL IN PENDING STATE, RETURN CURRENT EMAIL ---
+func RequestEmailChange(ctx context.Context, db *pgxpool.Pool, userID int, email string) (string, error) {
+	email = strings.TrimSpace(email)
+
+	var current string
+	if err := db.QueryRow(ctx, `SELECT email FROM users WHERE id = $1`, userID).Scan(&current); err != nil {
+		if err == pgx.ErrNoRows {
+			return "", errors.New("user not found")
+		}
+		return "", err
+	}
+	if strings.EqualFold(current, email) {
+		return current, ErrEmailUnchanged
+	}
+
+	taken, err := EmailTaken(ctx, db, email, userID)
+	if err != nil {
+		return "", err
+	}
+	if taken {
+		return "", ErrEmailTaken
+	}
+
+	if _, err := db.Exec(ctx, `UPDATE users SET pending_email = $2 WHERE id = $1`, userID, email); err != nil {
+		log.Println("Failed to save pending email :", err)
+		return "", err
+	}
+	return current, nil
+}
+
+// --- SAVE HASH OF CONFIRMATION TOKEN, OLDER LINK OF USER IS INVALIDATED ---
+func SaveEmailChangeToken(ctx context.Context, rdb *redis.Client, userID int, token string, ttl time.Duration) error {
+	return saveUserToken(ctx, rdb, emailChangePrefix, emailChangeUserPrefix, userID, token, ttl)
+}
+
+// --- MOVE PENDING EMAIL TO EMAIL, RETURN USER ID AND NEW EMAIL ---
+func ConfirmEmailChange(ctx context.Context, db *pgxpool.Pool, rdb *redis.Client, token string) (int, string, error) {
+	userID, err := consumeUserToken(ctx, rdb, emailChangePrefix, emailChangeUserPrefix, token)
+	if err != nil {
+		return 0, "", ErrEmailChangeInvalid
+	}
+
+	// --- CLICKING THE LINK PROVES OWNERSHIP OF THE NEW EMAIL ---
+	var email string
+	err = db.QueryRow(ctx, `
+		UPDATE users SET email = pending_email, pending_email = NULL, verified_at = COALESCE(verified_at, NOW())
+		WHERE id = $1 AND pending_email IS NOT NULL
+		RETURNING email
+	`, userID).Scan(&email)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return 0, "", ErrEmailChangeInvalid
+		}
+		// --- SOMEONE REGISTERED THE EMAIL WHILE IT WAS PENDING ---
+		if IsUniqueViolation(err) {
+			if _, err := db.Exec(ctx, `UPDATE users SET pending_email = NULL WHERE id = $1`, userID); err != nil {
+				log.Println("Failed to clear pending email :", err)
+			}
+			return 0, "", ErrEmailTaken
+		}
+		log.Println("Failed to confirm email change :", err)
+		return 0, "", err
+	}
+	return userID, email, nil
+}
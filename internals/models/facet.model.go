@@ -0,0 +1,206 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/federus1105/koda-b4-backend/internals/pkg/libs"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+)
+
+// --- WIDTH OF ONE PRICE HISTOGRAM BUCKET ---
+const priceBucketSize = 10000
+
+type FacetCount struct {
+	Id    int    `json:"id"`
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+// --- Min INCLUSIVE, Max EXCLUSIVE ---
+type PriceBucket struct {
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	Count int64   `json:"count"`
+}
+
+// --- EVERY FACET IS COUNTED WITH THE OTHER FILTERS, NOT ITS OWN; SIZE AND VARIANT COUNT PRODUCTS WITH STOCK ---
+type ProductFacets struct {
+	Categories []FacetCount  `json:"categories"`
+	Prices     []PriceBucket `json:"prices"`
+	Sizes      []FacetCount  `json:"sizes"`
+	Variants   []FacetCount  `json:"variants"`
+}
+
+type ProductFilterResponse struct {
+	PaginatedResponse[FavoriteProduct]
+	Facets ProductFacets `json:"facets"`
+}
+
+// --- SHARED PART OF THE product_filter: REDIS KEYS ---
+func productFilterKey(name string, categoryIDs []int, minPrice, maxPrice float64) string {
+	strIDs := make([]string, len(categoryIDs))
+	for i, id := range categoryIDs {
+		strIDs[i] = fmt.Sprintf("%d", id)
+	}
+	return fmt.Sprintf("name=%s&category=%s&min=%.2f&max=%.2f", name, strings.Join(strIDs, ","), minPrice, maxPrice)
+}
+
+// --- SAME ROWS AS GetListProductFilter, skip LEAVES OUT "category" OR "price" ---
+func productFilterConditions(name string, categoryIDs []int, minPrice, maxPrice float64, skip string) (string, []any) {
+	sql := " AND p.is_deleted = false AND EXISTS (SELECT 1 FROM product_categories pcx WHERE pcx.id_product = p.id)"
+	args := []any{}
+	argIdx := 1
+
+	if search := newProductSearch(name, argIdx); search != nil {
+		sql += " AND " + search.condition()
+		args = append(args, name)
+		argIdx++
+	}
+
+	if len(categoryIDs) > 0 && skip != "category" {
+		placeholders := make([]string, len(categoryIDs))
+		for i, id := range categoryIDs {
+			placeholders[i] = fmt.Sprintf("$%d", argIdx)
+			args = append(args, id)
+			argIdx++
+		}
+		sql += fmt.Sprintf(`
+			AND p.id IN (
+				SELECT id_product
+				FROM product_categories
+				WHERE id_categories IN (%s)
+				GROUP BY id_product
+				HAVING COUNT(DISTINCT id_categories) = %d
+			)`, strings.Join(placeholders, ","), len(categoryIDs))
+	}
+
+	if skip != "price" {
+		if minPrice > 0 {
+			sql += fmt.Sprintf(" AND p.priceOriginal >= $%d", argIdx)
+			args = append(args, minPrice)
+			argIdx++
+		}
+		if maxPrice > 0 {
+			sql += fmt.Sprintf(" AND p.priceOriginal <= $%d", argIdx)
+			args = append(args, maxPrice)
+		}
+	}
+
+	return sql, args
+}
+
+func GetProductFacets(ctx context.Context, db *pgxpool.Pool, rd *redis.Client,
+	name string,
+	categoryIDs []int,
+	minPrice, maxPrice float64) (ProductFacets, error) {
+
+	// --- REDIS, SORT AND PAGE DON'T CHANGE FACETS ---
+	redisKey := "product_filter:facets:" + productFilterKey(name, categoryIDs, minPrice, maxPrice)
+
+	if cached, err := libs.GetFromCache[ProductFacets](ctx, rd, redisKey); err != nil {
+		log.Println("Redis Error:", err)
+	} else if cached != nil {
+		log.Printf("Key %s found in cache Served in using Redis 👌", redisKey)
+		return *cached, nil
+	}
+
+	q, done, err := beginProductSearch(ctx, db, newProductSearch(name, 1))
+	if err != nil {
+		return ProductFacets{}, err
+	}
+	defer done()
+
+	var facets ProductFacets
+
+	// --- CATEGORY, EMPTY CATEGORIES ARE LISTED WITH 0 ---
+	conditions, args := productFilterConditions(name, categoryIDs, minPrice, maxPrice, "category")
+	facets.Categories, err = queryFacetCounts(ctx, q, `
+		SELECT c.id, c.name, COUNT(DISTINCT p.id)
+		FROM categories c
+		LEFT JOIN product_categories pc ON pc.id_categories = c.id
+		LEFT JOIN product p ON p.id = pc.id_product`+conditions+`
+		GROUP BY c.id, c.name
+		ORDER BY c.name ASC`, args...)
+	if err != nil {
+		return ProductFacets{}, err
+	}
+
+	// --- PRICE HISTOGRAM, ONLY BUCKETS WITH PRODUCTS ---
+	conditions, args = productFilterConditions(name, categoryIDs, minPrice, maxPrice, "price")
+	rows, err := q.Query(ctx, fmt.Sprintf(`
+		SELECT FLOOR(p.priceOriginal / %[1]d) * %[1]d AS bucket, COUNT(*)
+		FROM product p
+		WHERE TRUE %[2]s
+		GROUP BY bucket
+		ORDER BY bucket ASC`, priceBucketSize, conditions), args...)
+	if err != nil {
+		return ProductFacets{}, err
+	}
+	defer rows.Close()
+
+	facets.Prices = []PriceBucket{}
+	for rows.Next() {
+		var bucket PriceBucket
+		if err := rows.Scan(&bucket.Min, &bucket.Count); err != nil {
+			return ProductFacets{}, err
+		}
+		bucket.Max = bucket.Min + priceBucketSize
+		facets.Prices = append(facets.Prices, bucket)
+	}
+	if err := rows.Err(); err != nil {
+		return ProductFacets{}, err
+	}
+
+	// --- SIZE AND VARIANT, PRODUCTS WITH AN ACTIVE SKU IN STOCK ---
+	conditions, args = productFilterConditions(name, categoryIDs, minPrice, maxPrice, "")
+	facets.Sizes, err = queryFacetCounts(ctx, q, `
+		SELECT s.id, s.name::text, COUNT(DISTINCT p.id)
+		FROM sizes s
+		LEFT JOIN product_skus ps ON ps.id_size = s.id AND ps.is_active AND ps.stock > 0
+		LEFT JOIN product p ON p.id = ps.id_product`+conditions+`
+		GROUP BY s.id, s.name
+		ORDER BY s.id ASC`, args...)
+	if err != nil {
+		return ProductFacets{}, err
+	}
+	facets.Variants, err = queryFacetCounts(ctx, q, `
+		SELECT v.id, v.name::text, COUNT(DISTINCT p.id)
+		FROM variants v
+		LEFT JOIN product_skus ps ON ps.id_variant = v.id AND ps.is_active AND ps.stock > 0
+		LEFT JOIN product p ON p.id = ps.id_product`+conditions+`
+		GROUP BY v.id, v.name
+		ORDER BY v.id ASC`, args...)
+	if err != nil {
+		return ProductFacets{}, err
+	}
+
+	// --- SAVE TO CACHE ---
+	if err := libs.SetToCache(ctx, rd, redisKey, facets, 5*time.Minute); err != nil {
+		log.Println("Redis Error:", err)
+	}
+
+	return facets, nil
+}
+
+func queryFacetCounts(ctx context.Context, db searchQuerier, sql string, args ...any) ([]FacetCount, error) {
+	rows, err := db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := []FacetCount{}
+	for rows.Next() {
+		var count FacetCount
+		if err := rows.Scan(&count.Id, &count.Name, &count.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, count)
+	}
+	return counts, rows.Err()
+}
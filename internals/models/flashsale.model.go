@@ -0,0 +1,438 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/federus1105/koda-b4-backend/internals/pkg/utils"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+)
+
+const FlashSalePageSize = 10
+
+// --- LONGEST SLEEP OF THE SCHEDULER, ALSO PICKS UP CHANGES MADE BY ANOTHER INSTANCE ---
+const flashSaleMaxWait = time.Minute
+
+var (
+	ErrFlashSaleNotFound = errors.New("flash sale not found")
+	ErrFlashSaleEnded    = errors.New("flash sale has already ended")
+)
+
+type FlashSaleProductInput struct {
+	ProductID       int      `json:"product_id" binding:"required,gt=0"`
+	SalePrice       *float64 `json:"sale_price" binding:"omitempty,gt=0"`
+	DiscountPercent *float64 `json:"discount_percent" binding:"omitempty,gt=0,lt=100"`
+	MaxPerCustomer  *int     `json:"max_per_customer" binding:"omitempty,gt=0"`
+}
+
+type ReqFlashSale struct {
+	Name     string                  `json:"name" binding:"required,max=100"`
+	StartsAt time.Time               `json:"starts_at" binding:"required"`
+	EndsAt   time.Time               `json:"ends_at" binding:"required,gtfield=StartsAt"`
+	Products []FlashSaleProductInput `json:"products" binding:"required,min=1,dive"`
+}
+
+type FlashSaleProduct struct {
+	ProductID       int      `json:"product_id"`
+	Name            string   `json:"name"`
+	Image           string   `json:"image"`
+	Price           float64  `json:"price"`
+	FinalPrice      float64  `json:"final_price"`
+	SalePrice       *float64 `json:"sale_price"`
+	DiscountPercent *float64 `json:"discount_percent"`
+	MaxPerCustomer  *int     `json:"max_per_customer"`
+	Sold            int      `json:"sold"`
+}
+
+type FlashSale struct {
+	Id        int                `json:"id"`
+	Name      string             `json:"name"`
+	StartsAt  time.Time          `json:"starts_at"`
+	EndsAt    time.Time          `json:"ends_at"`
+	Status    string             `json:"status"`
+	Products  []FlashSaleProduct `json:"products"`
+	CreatedAt time.Time          `json:"created_at"`
+}
+
+// --- CAMPAIGN RUNNING NOW FOR productExpr, COLUMNS OF ALIAS fs ARE NULL WITHOUT ONE ---
+func activeFlashSaleJoin(productExpr string) string {
+	return `
+		LEFT JOIN LATERAL (
+			SELECT fs.id, fsp.sale_price, fsp.discount_percent, fsp.max_per_customer, fs.ends_at
+			FROM flash_sale_products fsp
+			JOIN flash_sales fs ON fs.id = fsp.id_flash_sale
+			WHERE fsp.id_product = ` + productExpr + ` AND fs.starts_at <= NOW() AND fs.ends_at > NOW()
+			ORDER BY fs.starts_at DESC
+			LIMIT 1
+		) fs ON TRUE`
+}
+
+// --- SALE PRICE TAKES THE SAME AMOUNT OFF EVERY SKU, A PERCENTAGE SCALES EACH ONE, 0 WITHOUT CAMPAIGN ---
+func FlashSalePrice(skuPrice, basePrice float64, salePrice, discountPercent *float64) float64 {
+	switch {
+	case salePrice != nil:
+		return SkuDiscountPrice(skuPrice, basePrice, *salePrice)
+	case discountPercent != nil:
+		return math.Round(skuPrice * (100 - *discountPercent) / 100)
+	}
+	return 0
+}
+
+func flashSaleStatus(startsAt, endsAt, now time.Time) string {
+	switch {
+	case now.Before(startsAt):
+		return "upcoming"
+	case now.Before(endsAt):
+		return "active"
+	}
+	return "ended"
+}
+
+func CreateFlashSale(ctx context.Context, db *pgxpool.Pool, createdBy *int, req ReqFlashSale) (FlashSale, error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		log.Println("Failed to begin transaction:", err)
+		return FlashSale{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	var id int
+	if err := tx.QueryRow(ctx, `
+		INSERT INTO flash_sales (name, starts_at, ends_at, created_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`, req.Name, req.StartsAt, req.EndsAt, createdBy).Scan(&id); err != nil {
+		log.Println("Failed to insert flash sale:", err)
+		return FlashSale{}, err
+	}
+	if err := insertFlashSaleProducts(ctx, tx, id, req); err != nil {
+		return FlashSale{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Println("Failed to commit transaction:", err)
+		return FlashSale{}, err
+	}
+	wakeFlashSaleScheduler()
+	return GetFlashSale(ctx, db, id)
+}
+
+// --- REPLACES WINDOW AND PRODUCT LIST, AN ENDED CAMPAIGN STAYS AS IT WAS ---
+func UpdateFlashSale(ctx context.Context, db *pgxpool.Pool, id int, req ReqFlashSale) (FlashSale, error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		log.Println("Failed to begin transaction:", err)
+		return FlashSale{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	var ended bool
+	if err := tx.QueryRow(ctx, `SELECT ends_at <= NOW() FROM flash_sales WHERE id = $1 FOR UPDATE`, id).Scan(&ended); err != nil {
+		if err == pgx.ErrNoRows {
+			return FlashSale{}, ErrFlashSaleNotFound
+		}
+		return FlashSale{}, err
+	}
+	if ended {
+		return FlashSale{}, ErrFlashSaleEnded
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE flash_sales SET name = $2, starts_at = $3, ends_at = $4, updated_at = NOW()
+		WHERE id = $1
+	`, id, req.Name, req.StartsAt, req.EndsAt); err != nil {
+		log.Println("Failed to update flash sale:", err)
+		return FlashSale{}, err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM flash_sale_products WHERE id_flash_sale = $1`, id); err != nil {
+		log.Println("Failed to delete flash sale products:", err)
+		return FlashSale{}, err
+	}
+	if err := insertFlashSaleProducts(ctx, tx, id, req); err != nil {
+		return FlashSale{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Println("Failed to commit transaction:", err)
+		return FlashSale{}, err
+	}
+	wakeFlashSaleScheduler()
+	return GetFlashSale(ctx, db, id)
+}
+
+// --- DELETING A RUNNING CAMPAIGN ENDS IT NOW, ORDERS KEEP THEIR PRICE ---
+func DeleteFlashSale(ctx context.Context, db *pgxpool.Pool, id int) error {
+	result, err := db.Exec(ctx, `DELETE FROM flash_sales WHERE id = $1`, id)
+	if err != nil {
+		log.Println("Failed to delete flash sale:", err)
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrFlashSaleNotFound
+	}
+	wakeFlashSaleScheduler()
+	return nil
+}
+
+// --- ONE PRICE RULE PER PRODUCT, BELOW ITS PRICE, AND NO OTHER CAMPAIGN OF THE PRODUCT IN THE SAME WINDOW ---
+func insertFlashSaleProducts(ctx context.Context, tx pgx.Tx, id int, req ReqFlashSale) error {
+	// --- LOCKED IN ID ORDER SO TWO CAMPAIGNS SAVED AT ONCE CAN'T BOTH PASS THE OVERLAP CHECK ---
+	productIDs := make([]int, 0, len(req.Products))
+	for _, item := range req.Products {
+		productIDs = append(productIDs, item.ProductID)
+	}
+	if _, err := tx.Exec(ctx, `SELECT id FROM product WHERE id = ANY($1) ORDER BY id FOR UPDATE`, productIDs); err != nil {
+		log.Println("Failed to lock flash sale products:", err)
+		return err
+	}
+
+	seen := map[int]bool{}
+	for i, item := range req.Products {
+		field := fmt.Sprintf("products[%d]", i)
+		if (item.SalePrice == nil) == (item.DiscountPercent == nil) {
+			return utils.ValidationError{Field: field, Message: "set either sale_price or discount_percent"}
+		}
+		if seen[item.ProductID] {
+			return utils.ValidationError{Field: field, Message: fmt.Sprintf("product %d is listed twice", item.ProductID)}
+		}
+		seen[item.ProductID] = true
+
+		var price float64
+		err := tx.QueryRow(ctx, `SELECT priceoriginal FROM product WHERE id = $1 AND is_deleted = false`, item.ProductID).Scan(&price)
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				return utils.ValidationError{Field: field, Message: fmt.Sprintf("product %d not found", item.ProductID)}
+			}
+			return err
+		}
+		if item.SalePrice != nil && *item.SalePrice >= price {
+			return utils.ValidationError{Field: field, Message: fmt.Sprintf("sale price must be below the product price %.0f", price)}
+		}
+
+		var overlap *string
+		err = tx.QueryRow(ctx, `
+			SELECT fs.name
+			FROM flash_sale_products fsp
+			JOIN flash_sales fs ON fs.id = fsp.id_flash_sale
+			WHERE fsp.id_product = $1 AND fs.id <> $2 AND fs.starts_at < $4 AND fs.ends_at > $3
+			LIMIT 1
+		`, item.ProductID, id, req.StartsAt, req.EndsAt).Scan(&overlap)
+		if err != nil && err != pgx.ErrNoRows {
+			return err
+		}
+		if overlap != nil {
+			return utils.ValidationError{Field: field, Message: fmt.Sprintf("product %d is already in flash sale %q at that time", item.ProductID, *overlap)}
+		}
+
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO flash_sale_products (id_flash_sale, id_product, sale_price, discount_percent, max_per_customer)
+			VALUES ($1, $2, $3, $4, $5)
+		`, id, item.ProductID, item.SalePrice, item.DiscountPercent, item.MaxPerCustomer); err != nil {
+			log.Println("Failed to insert flash sale product:", err)
+			return err
+		}
+	}
+	return nil
+}
+
+func GetFlashSale(ctx context.Context, db *pgxpool.Pool, id int) (FlashSale, error) {
+	var sale FlashSale
+	err := db.QueryRow(ctx, `SELECT id, name, starts_at, ends_at, created_at FROM flash_sales WHERE id = $1`, id).
+		Scan(&sale.Id, &sale.Name, &sale.StartsAt, &sale.EndsAt, &sale.CreatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return FlashSale{}, ErrFlashSaleNotFound
+		}
+		return FlashSale{}, err
+	}
+	sale.Status = flashSaleStatus(sale.StartsAt, sale.EndsAt, time.Now())
+	if sale.Products, err = getFlashSaleProducts(ctx, db, id); err != nil {
+		return FlashSale{}, err
+	}
+	return sale, nil
+}
+
+// --- status "upcoming", "active" OR "ended", EMPTY FOR ALL, LATEST START FIRST ---
+func GetFlashSales(ctx context.Context, db *pgxpool.Pool, status string, page int) ([]FlashSale, error) {
+	return queryFlashSales(ctx, db, `
+		WHERE $1 = ''
+			OR ($1 = 'upcoming' AND starts_at > NOW())
+			OR ($1 = 'active' AND starts_at <= NOW() AND ends_at > NOW())
+			OR ($1 = 'ended' AND ends_at <= NOW())
+		ORDER BY starts_at DESC, id DESC
+		LIMIT $2 OFFSET $3`, status, FlashSalePageSize, (page-1)*FlashSalePageSize)
+}
+
+// --- CAMPAIGNS RUNNING NOW, ENDING SOONEST FIRST ---
+func GetActiveFlashSales(ctx context.Context, db *pgxpool.Pool) ([]FlashSale, error) {
+	return queryFlashSales(ctx, db, `
+		WHERE starts_at <= NOW() AND ends_at > NOW()
+		ORDER BY ends_at ASC, id ASC`)
+}
+
+func queryFlashSales(ctx context.Context, db *pgxpool.Pool, where string, args ...any) ([]FlashSale, error) {
+	rows, err := db.Query(ctx, `SELECT id, name, starts_at, ends_at, created_at FROM flash_sales `+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sales := []FlashSale{}
+	for rows.Next() {
+		var sale FlashSale
+		if err := rows.Scan(&sale.Id, &sale.Name, &sale.StartsAt, &sale.EndsAt, &sale.CreatedAt); err != nil {
+			return nil, err
+		}
+		sales = append(sales, sale)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for i := range sales {
+		sales[i].Status = flashSaleStatus(sales[i].StartsAt, sales[i].EndsAt, now)
+		if sales[i].Products, err = getFlashSaleProducts(ctx, db, sales[i].Id); err != nil {
+			return nil, err
+		}
+	}
+	return sales, nil
+}
+
+func getFlashSaleProducts(ctx context.Context, db *pgxpool.Pool, id int) ([]FlashSaleProduct, error) {
+	rows, err := db.Query(ctx, `
+		SELECT p.id, p.name, COALESCE(img.url, ''), p.priceoriginal,
+			fsp.sale_price, fsp.discount_percent, fsp.max_per_customer,
+			COALESCE((SELECT SUM(po.quantity) FROM product_orders po
+				WHERE po.id_flash_sale = fsp.id_flash_sale AND po.id_product = p.id), 0)::INT
+		FROM flash_sale_products fsp
+		JOIN product p ON p.id = fsp.id_product`+primaryImageJoin("p.id")+`
+		WHERE fsp.id_flash_sale = $1
+		ORDER BY p.name ASC`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := []FlashSaleProduct{}
+	for rows.Next() {
+		var p FlashSaleProduct
+		if err := rows.Scan(&p.ProductID, &p.Name, &p.Image, &p.Price, &p.SalePrice, &p.DiscountPercent, &p.MaxPerCustomer, &p.Sold); err != nil {
+			return nil, err
+		}
+		p.FinalPrice = FlashSalePrice(p.Price, p.Price, p.SalePrice, p.DiscountPercent)
+		products = append(products, p)
+	}
+	return products, rows.Err()
+}
+
+// --- PER-CUSTOMER CAP, quantity IS WHAT THIS ORDER ADDS, CALLED INSIDE THE CHECKOUT TRANSACTION ---
+func checkFlashSaleLimit(ctx context.Context, tx pgx.Tx, userID, flashSaleID, productID, limit, quantity int) error {
+	// --- SERIALISE CHECKOUTS OF THE SAME CUSTOMER IN THE SAME CAMPAIGN ---
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1, $2)`, flashSaleID, userID); err != nil {
+		return err
+	}
+
+	var bought int
+	err := tx.QueryRow(ctx, `
+		SELECT COALESCE(SUM(po.quantity), 0)::INT
+		FROM product_orders po
+		JOIN orders o ON o.id = po.id_order
+		WHERE po.id_flash_sale = $1 AND po.id_product = $2 AND o.id_account = $3
+	`, flashSaleID, productID, userID).Scan(&bought)
+	if err != nil {
+		return err
+	}
+	if bought+quantity > limit {
+		return utils.ValidationError{
+			Field:   fmt.Sprintf("product_id_%d", productID),
+			Message: fmt.Sprintf("flash sale limit is %d per customer, %d left", limit, max(limit-bought, 0)),
+		}
+	}
+	return nil
+}
+
+// --- COPY THE RUNNING CAMPAIGN PRICE TO product.flash_sale AND priceDiscount, RETURNS THE NEXT WINDOW BOUNDARY ---
+func SyncFlashSales(ctx context.Context, db *pgxpool.Pool, rd *redis.Client) (time.Time, error) {
+	result, err := db.Exec(ctx, `
+		WITH active AS (
+			SELECT DISTINCT ON (fsp.id_product) fsp.id_product,
+				COALESCE(fsp.sale_price, ROUND((p.priceoriginal * (100 - fsp.discount_percent) / 100)::numeric)::float8) AS price
+			FROM flash_sale_products fsp
+			JOIN flash_sales fs ON fs.id = fsp.id_flash_sale
+			JOIN product p ON p.id = fsp.id_product
+			WHERE fs.starts_at <= NOW() AND fs.ends_at > NOW()
+			ORDER BY fsp.id_product, fs.starts_at DESC
+		)
+		UPDATE product p
+		SET flash_sale = s.on_sale, pricediscount = s.price
+		FROM (
+			SELECT p2.id, a.id_product IS NOT NULL AS on_sale, COALESCE(a.price, 0) AS price
+			FROM product p2
+			LEFT JOIN active a ON a.id_product = p2.id
+		) s
+		WHERE p.id = s.id
+			AND (p.flash_sale IS DISTINCT FROM s.on_sale OR p.pricediscount IS DISTINCT FROM s.price)`)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if result.RowsAffected() > 0 {
+		log.Printf("Flash sale price updated on %d products", result.RowsAffected())
+		invalidateProductCaches(ctx, rd)
+	}
+
+	var next *time.Time
+	err = db.QueryRow(ctx, `
+		SELECT MIN(boundary) FROM (
+			SELECT starts_at AS boundary FROM flash_sales WHERE starts_at > NOW()
+			UNION ALL
+			SELECT ends_at FROM flash_sales WHERE ends_at > NOW()
+		) b`).Scan(&next)
+	if err != nil || next == nil {
+		return time.Time{}, err
+	}
+	return *next, nil
+}
+
+var flashSaleWake = make(chan struct{}, 1)
+
+// --- CAMPAIGN CHANGED, SYNC NOW INSTEAD OF AT THE NEXT TICK ---
+func wakeFlashSaleScheduler() {
+	select {
+	case flashSaleWake <- struct{}{}:
+	default:
+	}
+}
+
+// --- STARTS AND ENDS CAMPAIGNS ON TIME, SLEEPS UNTIL THE NEXT BOUNDARY OR AT MOST flashSaleMaxWait ---
+func StartFlashSaleScheduler(ctx context.Context, db *pgxpool.Pool, rd *redis.Client) {
+	go func() {
+		for {
+			wait := flashSaleMaxWait
+			syncCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+			next, err := SyncFlashSales(syncCtx, db, rd)
+			cancel()
+			if err != nil {
+				log.Println("Failed to sync flash sales:", err)
+			} else if !next.IsZero() && time.Until(next) < wait {
+				wait = max(time.Until(next), 0)
+			}
+
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-flashSaleWake:
+				timer.Stop()
+			case <-timer.C:
+			}
+		}
+	}()
+}
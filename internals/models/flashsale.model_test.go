@@ -0,0 +1,45 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFlashSalePrice(t *testing.T) {
+	tests := []struct {
+		name           string
+		skuPrice, base float64
+		salePrice, pct *float64
+		want           float64
+	}{
+		{"no campaign", 25000, 20000, nil, nil, 0},
+		{"sale price on the base sku", 20000, 20000, ptr(15000.0), nil, 15000},
+		{"sale price keeps the sku difference", 25000, 20000, ptr(15000.0), nil, 20000},
+		{"sale price on a cheaper sku", 18000, 20000, ptr(15000.0), nil, 13000},
+		{"sale price never below zero", 4000, 20000, ptr(15000.0), nil, 0},
+		{"sale price wins over percent", 20000, 20000, ptr(15000.0), ptr(50.0), 15000},
+		{"percent off", 20000, 20000, nil, ptr(25.0), 15000},
+		{"percent off is rounded", 18333, 20000, nil, ptr(10.0), 16500},
+		{"full percent off", 20000, 20000, nil, ptr(100.0), 0},
+	}
+	for _, tt := range tests {
+		if got := FlashSalePrice(tt.skuPrice, tt.base, tt.salePrice, tt.pct); got != tt.want {
+			t.Errorf("%s: FlashSalePrice = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFlashSaleStatus(t *testing.T) {
+	startsAt := time.Date(2026, 10, 18, 8, 0, 0, 0, time.UTC)
+	endsAt := startsAt.Add(2 * time.Hour)
+	for now, want := range map[time.Time]string{
+		startsAt.Add(-time.Second): "upcoming",
+		startsAt:                   "active",
+		endsAt.Add(-time.Second):   "active",
+		endsAt:                     "ended",
+	} {
+		if got := flashSaleStatus(startsAt, endsAt, now); got != want {
+			t.Errorf("flashSaleStatus at %s = %s, want %s", now.Format(time.TimeOnly), got, want)
+		}
+	}
+}
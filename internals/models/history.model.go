@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/federus1105/koda-b4-backend/internals/pkg/libs"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -45,7 +46,7 @@ type DetailHistories struct {
 	Items       []Items   `json:"items"`
 }
 
-func GetHistory(ctx context.Context, db *pgxpool.Pool, IdUser int, month, status, limit, offset int) ([]History, error) {
+func GetHistory(ctx context.Context, db *pgxpool.Pool, IdUser int, month, status int, page Page) ([]History, *libs.Cursor, error) {
 
 	sql := `
 	SELECT
@@ -54,14 +55,12 @@ func GetHistory(ctx context.Context, db *pgxpool.Pool, IdUser int, month, status
 		o.createdat,
 		s.name AS status,
 		o.total,
-		latest.photos_one
+		COALESCE(latest.url, '')
 	FROM orders o
 	LEFT JOIN status s ON s.id = o.id_status
 	LEFT JOIN LATERAL (
-		SELECT pi.photos_one
-		FROM product_orders po
-		JOIN product p ON p.id = po.id_product
-		JOIN product_images pi ON pi.id = p.id_product_images
+		SELECT img.url
+		FROM product_orders po` + primaryImageJoin("po.id_product") + `
 		WHERE po.id_order = o.id
 		ORDER BY po.id_order DESC
 		LIMIT 1
@@ -86,17 +85,25 @@ func GetHistory(ctx context.Context, db *pgxpool.Pool, IdUser int, month, status
 		argIdx++
 	}
 
+	// --- AFTER CURSOR ---
+	if condition, cursorArgs, next := page.afterCursor("o.createdat", "o.id", "timestamp", true, argIdx); condition != "" {
+		sql += " AND " + condition
+		args = append(args, cursorArgs...)
+		argIdx = next
+	}
+
 	// --- SORT BY CREATED AT ---
-	sql += " ORDER BY o.createdat DESC"
+	sql += " ORDER BY o.createdat DESC, o.id DESC"
 
 	// --- LIMIT & OFFSET ---
-	sql += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argIdx, argIdx+1)
-	args = append(args, limit, offset)
+	limitSQL, limitArgs := page.limitClause(argIdx)
+	sql += limitSQL
+	args = append(args, limitArgs...)
 
 	// --- EXECUTE QUERY ---
 	rows, err := db.Query(ctx, sql, args...)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer rows.Close()
 
@@ -104,15 +111,18 @@ func GetHistory(ctx context.Context, db *pgxpool.Pool, IdUser int, month, status
 	for rows.Next() {
 		var h History
 		if err := rows.Scan(&h.Id, &h.OrderNumber, &h.Date, &h.Status, &h.Total, &h.Image); err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		histories = append(histories, h)
 	}
 	if rows.Err() != nil {
-		return nil, rows.Err()
+		return nil, nil, rows.Err()
 	}
 
-	return histories, nil
+	histories, next := keysetPage(page, "history", histories, func(i int) (string, int) {
+		return timeKey(histories[i].Date), histories[i].Id
+	})
+	return histories, next, nil
 }
 
 func DetailHistory(ctx context.Context, db *pgxpool.Pool, idUser, idHistory int) (DetailHistories, error) {
@@ -129,7 +139,7 @@ func DetailHistory(ctx context.Context, db *pgxpool.Pool, idUser, idHistory int)
     json_agg(
         json_build_object(
             'id', p.id,
-            'image', pi.photos_one,
+            'image', COALESCE(img.url, ''),
             'flash_sale', p.flash_sale,
             'name',  p.name,
             'quantity', po.quantity,
@@ -143,8 +153,7 @@ func DetailHistory(ctx context.Context, db *pgxpool.Pool, idUser, idHistory int)
     JOIN payment_method pm ON pm.id = o.id_paymentmethod
     JOIN delivery d ON d.id = o.id_delivery
     JOIN status s ON s.id = o.id_status
-    JOIN product p ON p.id = po.id_product
-    JOIN product_images pi ON pi.id = p.id_product_images
+    JOIN product p ON p.id = po.id_product` + primaryImageJoin("p.id") + `
     WHERE o.id = $1 AND o.id_account = $2
     GROUP BY 
     o.id, o.order_number, o.fullname, o.phonenumber, o.email,
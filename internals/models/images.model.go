@@ -2,80 +2,280 @@ package models
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"mime/multipart"
 
+	"github.com/federus1105/koda-b4-backend/internals/pkg/utils"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
 )
 
-type Images struct {
-	ImagesOne   *string `form:"imagesOne"`
-	ImagesTwo   *string `form:"imagesTwo"`
-	ImagesThree *string `form:"imagesThree"`
-	ImagesFour  *string `form:"imagesFour"`
+// --- FILES PER REQUEST, THE GALLERY ITSELF HAS NO LIMIT ---
+const ProductImageUploadMax = 10
+
+var (
+	ErrProductNotFound      = errors.New("product not found")
+	ErrProductImageNotFound = errors.New("image not found")
+)
+
+type ProductImage struct {
+	Id        int    `json:"id"`
+	Url       string `json:"url"`
+	AltText   string `json:"alt_text"`
+	Position  int    `json:"position"`
+	IsPrimary bool   `json:"is_primary"`
+}
+
+// --- UPLOADED FILE OR IMPORTED URL WAITING FOR ITS GALLERY ROW ---
+type NewProductImage struct {
+	Url     string
+	AltText string
+}
+
+type ReqAddProductImages struct {
+	Images  []*multipart.FileHeader `form:"images" binding:"required,min=1,max=10"`
+	AltText []string                `form:"alt_text" binding:"max=10,dive,max=150"`
+	Primary bool                    `form:"primary"`
+}
+
+type ReqUpdateProductImage struct {
+	AltText   *string `json:"alt_text" binding:"omitempty,max=150"`
+	IsPrimary *bool   `json:"is_primary"`
+}
+
+type ReqReorderProductImages struct {
+	Ids []int `json:"ids" binding:"required,min=1,dive,gt=0"`
 }
 
-type ImagesBody struct {
-	ImagesOne      *multipart.FileHeader `form:"imagesOne"`
-	ImagesTwo      *multipart.FileHeader `form:"imagesTwo"`
-	ImagesThree    *multipart.FileHeader `form:"imagesThree"`
-	ImagesFour     *multipart.FileHeader `form:"imagesFour"`
-	ImagesOneStr   string                `form:"imagesOneStr"`
-	ImagesTwoStr   string                `form:"imagesTwoStr"`
-	ImagesThreeStr string                `form:"imagesThreeStr"`
-	ImagesFourStr  string                `form:"imagesFourStr"`
+// --- ALT TEXT AT THE SAME INDEX AS ITS FILE, MISSING ONES ARE EMPTY ---
+func NewProductImages(urls, altTexts []string) []NewProductImage {
+	images := make([]NewProductImage, len(urls))
+	for i, url := range urls {
+		images[i].Url = url
+		if i < len(altTexts) {
+			images[i].AltText = altTexts[i]
+		}
+	}
+	return images
 }
 
-func GetListImageById(ctx context.Context, db *pgxpool.Pool, idProduct int) ([]Images, error) {
-	sql := `SELECT pi.photos_one, pi.photos_two, pi.photos_three, pi.photos_four
-		FROM product_images pi
-		JOIN product p ON p.id_product_images =  pi.id
-		WHERE p.id = $1;`
+// --- PRIMARY IMAGE OF productExpr THROUGH product_gallery_primary_idx, COLUMNS OF ALIAS img ARE NULL WITHOUT ONE ---
+func primaryImageJoin(productExpr string) string {
+	return `
+		LEFT JOIN product_gallery img ON img.id_product = ` + productExpr + ` AND img.is_primary`
+}
 
-	// --- EXECUTE QUERY ---
-	rows, err := db.Query(ctx, sql, idProduct)
+func GetProductImages(ctx context.Context, db skuQuerier, productID int) ([]ProductImage, error) {
+	rows, err := db.Query(ctx, `
+		SELECT id, url, alt_text, position, is_primary
+		FROM product_gallery
+		WHERE id_product = $1
+		ORDER BY position, id`, productID)
 	if err != nil {
-		fmt.Println(err)
+		return nil, err
+	}
+	images, err := pgx.CollectRows(rows, pgx.RowToStructByPos[ProductImage])
+	if images == nil {
+		images = []ProductImage{}
+	}
+	return images, err
+}
+
+func GetListImageById(ctx context.Context, db *pgxpool.Pool, productID int) ([]ProductImage, error) {
+	var exists bool
+	if err := db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM product WHERE id = $1 AND is_deleted = false)`, productID).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrProductNotFound
+	}
+	return GetProductImages(ctx, db, productID)
+}
+
+// --- APPENDED AFTER THE LAST POSITION, THE FIRST NEW IMAGE BECOMES PRIMARY WHEN ASKED OR WHEN THERE IS NONE ---
+func addProductImages(ctx context.Context, tx pgx.Tx, productID int, images []NewProductImage, primary bool) error {
+	if len(images) == 0 {
+		return nil
+	}
+
+	var next int
+	var hasPrimary bool
+	if err := tx.QueryRow(ctx, `
+		SELECT COALESCE(MAX(position) + 1, 0), COALESCE(BOOL_OR(is_primary), false)
+		FROM product_gallery WHERE id_product = $1`, productID).Scan(&next, &hasPrimary); err != nil {
+		return err
+	}
+	if primary && hasPrimary {
+		if _, err := tx.Exec(ctx, `UPDATE product_gallery SET is_primary = false, updated_at = NOW() WHERE id_product = $1 AND is_primary`, productID); err != nil {
+			return err
+		}
 	}
-	defer rows.Close()
-	var images []Images
 
-	for rows.Next() {
-		var p Images
-		if err := rows.Scan(&p.ImagesOne, &p.ImagesTwo, &p.ImagesThree, &p.ImagesFour); err != nil {
-			return nil, err
+	for i, image := range images {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO product_gallery (id_product, url, alt_text, position, is_primary)
+			VALUES ($1, $2, $3, $4, $5)`,
+			productID, image.Url, image.AltText, next+i, i == 0 && (primary || !hasPrimary)); err != nil {
+			log.Println("Failed to insert product image:", err)
+			return err
 		}
-		images = append(images, p)
 	}
+	return nil
+}
+
+// --- POSITIONS BACK TO 0..n-1 AND THE FIRST IMAGE PROMOTED WHEN THE PRIMARY IS GONE ---
+func normalizeProductImages(ctx context.Context, tx pgx.Tx, productID int) error {
+	if _, err := tx.Exec(ctx, `
+		UPDATE product_gallery g SET position = o.rn - 1
+		FROM (
+			SELECT id, ROW_NUMBER() OVER (ORDER BY position, id) AS rn
+			FROM product_gallery WHERE id_product = $1
+		) o
+		WHERE g.id = o.id AND g.position <> o.rn - 1`, productID); err != nil {
+		return err
+	}
+	_, err := tx.Exec(ctx, `
+		UPDATE product_gallery SET is_primary = true, updated_at = NOW()
+		WHERE id = (SELECT id FROM product_gallery WHERE id_product = $1 ORDER BY position, id LIMIT 1)
+			AND NOT EXISTS (SELECT 1 FROM product_gallery WHERE id_product = $1 AND is_primary)`, productID)
+	return err
+}
 
-	if rows.Err() != nil {
-		return nil, rows.Err()
+// --- WHOLE GALLERY REPLACED IN THE GIVEN ORDER, THE FIRST ONE IS PRIMARY ---
+func replaceProductImages(ctx context.Context, tx pgx.Tx, productID int, images []NewProductImage) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM product_gallery WHERE id_product = $1`, productID); err != nil {
+		return err
 	}
+	return addProductImages(ctx, tx, productID, images, true)
+}
 
-	return images, nil
+// --- LOCKS THE PRODUCT ROW SO CONCURRENT GALLERY EDITS KEEP POSITIONS IN ORDER ---
+func lockGalleryProduct(ctx context.Context, tx pgx.Tx, productID int) error {
+	var id int
+	err := tx.QueryRow(ctx, `SELECT id FROM product WHERE id = $1 AND is_deleted = false FOR UPDATE`, productID).Scan(&id)
+	if err == pgx.ErrNoRows {
+		return ErrProductNotFound
+	}
+	return err
 }
 
-func CreateImagesbyId(ctx context.Context, db *pgxpool.Pool, body ImagesBody, idProduct int) (ImagesBody, error) {
-	sql := `UPDATE product_images (photos_one, photos_two, photos_three, photos_four)
-        VALUES ($1, $2, $3, $4)
-        RETURNING photos_one, photos_two, photos_three, photos_four`
+// --- RUNS change IN A TRANSACTION, RETURNS THE GALLERY AFTER IT ---
+func editProductGallery(ctx context.Context, db *pgxpool.Pool, rd *redis.Client, productID int, change func(tx pgx.Tx) error) ([]ProductImage, error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		log.Println("Failed to begin transaction:", err)
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
 
-	values := []any{
-		body.ImagesOneStr,
-		body.ImagesTwoStr,
-		body.ImagesThreeStr,
-		body.ImagesFourStr,
+	if err := lockGalleryProduct(ctx, tx, productID); err != nil {
+		return nil, err
+	}
+	if err := change(tx); err != nil {
+		return nil, err
 	}
-	var newImages ImagesBody
-	if err := db.QueryRow(ctx, sql, values...).Scan(
-		&newImages.ImagesOneStr,
-		&newImages.ImagesTwoStr,
-		&newImages.ImagesThreeStr,
-		&newImages.ImagesFourStr); err != nil {
-		log.Println("Failed to insert product_images:", err)
-		return ImagesBody{}, err
+	if err := normalizeProductImages(ctx, tx, productID); err != nil {
+		return nil, err
+	}
+	images, err := GetProductImages(ctx, tx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Println("Failed to commit transaction:", err)
+		return nil, err
 	}
-	return newImages, nil
+	invalidateProductCaches(ctx, rd)
+	return images, nil
+}
+
+func AddProductImages(ctx context.Context, db *pgxpool.Pool, rd *redis.Client, productID int, images []NewProductImage, primary bool) ([]ProductImage, error) {
+	return editProductGallery(ctx, db, rd, productID, func(tx pgx.Tx) error {
+		return addProductImages(ctx, tx, productID, images, primary)
+	})
+}
+
+// --- PRIMARY MOVES BY MAKING ANOTHER IMAGE PRIMARY, A PRODUCT WITH IMAGES ALWAYS HAS ONE ---
+func UpdateProductImage(ctx context.Context, db *pgxpool.Pool, rd *redis.Client, productID, imageID int, req ReqUpdateProductImage) ([]ProductImage, error) {
+	return editProductGallery(ctx, db, rd, productID, func(tx pgx.Tx) error {
+		var isPrimary bool
+		err := tx.QueryRow(ctx, `SELECT is_primary FROM product_gallery WHERE id = $1 AND id_product = $2`, imageID, productID).Scan(&isPrimary)
+		if err == pgx.ErrNoRows {
+			return ErrProductImageNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if req.AltText != nil {
+			if _, err := tx.Exec(ctx, `UPDATE product_gallery SET alt_text = $2, updated_at = NOW() WHERE id = $1`, imageID, *req.AltText); err != nil {
+				return err
+			}
+		}
+		if req.IsPrimary == nil || *req.IsPrimary == isPrimary {
+			return nil
+		}
+		if !*req.IsPrimary {
+			return utils.ValidationError{Field: "is_primary", Message: "can't be removed, make another image primary instead"}
+		}
+
+		// --- TWO STATEMENTS, THE UNIQUE INDEX IS CHECKED ROW BY ROW ---
+		if _, err := tx.Exec(ctx, `UPDATE product_gallery SET is_primary = false, updated_at = NOW() WHERE id_product = $1 AND is_primary`, productID); err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, `UPDATE product_gallery SET is_primary = true, updated_at = NOW() WHERE id = $1`, imageID)
+		return err
+	})
+}
+
+func DeleteProductImage(ctx context.Context, db *pgxpool.Pool, rd *redis.Client, productID, imageID int) ([]ProductImage, error) {
+	return editProductGallery(ctx, db, rd, productID, func(tx pgx.Tx) error {
+		result, err := tx.Exec(ctx, `DELETE FROM product_gallery WHERE id = $1 AND id_product = $2`, imageID, productID)
+		if err != nil {
+			log.Println("Failed to delete product image:", err)
+			return err
+		}
+		if result.RowsAffected() == 0 {
+			return ErrProductImageNotFound
+		}
+		return nil
+	})
+}
+
+// --- ids LISTS EVERY IMAGE OF THE PRODUCT ONCE, IN THE NEW ORDER ---
+func ReorderProductImages(ctx context.Context, db *pgxpool.Pool, rd *redis.Client, productID int, ids []int) ([]ProductImage, error) {
+	return editProductGallery(ctx, db, rd, productID, func(tx pgx.Tx) error {
+		current, err := GetProductImages(ctx, tx, productID)
+		if err != nil {
+			return err
+		}
+
+		known := map[int]bool{}
+		for _, image := range current {
+			known[image.Id] = true
+		}
+		seen := map[int]bool{}
+		for _, id := range ids {
+			if !known[id] {
+				return utils.ValidationError{Field: "ids", Message: fmt.Sprintf("image %d doesn't belong to this product", id)}
+			}
+			if seen[id] {
+				return utils.ValidationError{Field: "ids", Message: fmt.Sprintf("image %d is listed twice", id)}
+			}
+			seen[id] = true
+		}
+		if len(ids) != len(current) {
+			return utils.ValidationError{Field: "ids", Message: fmt.Sprintf("must list all %d images of the product", len(current))}
+		}
 
+		_, err = tx.Exec(ctx, `
+			UPDATE product_gallery g SET position = o.position - 1, updated_at = NOW()
+			FROM UNNEST($2::int[]) WITH ORDINALITY AS o(id, position)
+			WHERE g.id = o.id AND g.id_product = $1`, productID, ids)
+		return err
+	})
 }
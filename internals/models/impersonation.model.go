@@ -0,0 +1,143 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const ImpersonationPageSize = 20
+
+var (
+	ErrImpersonationTarget   = errors.New("only customer accounts can be impersonated")
+	ErrImpersonationNotFound = errors.New("impersonation not found")
+)
+
+type ReqImpersonate struct {
+	Reason string `json:"reason" binding:"required,max=255"`
+}
+
+type Impersonation struct {
+	ID         int       `json:"id"`
+	ActorID    *int      `json:"actor_id"`
+	ActorEmail *string   `json:"actor_email"`
+	UserID     int       `json:"user_id"`
+	UserEmail  string    `json:"user_email"`
+	Reason     string    `json:"reason"`
+	Requests   int       `json:"requests"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type ImpersonationAudit struct {
+	ID        int       `json:"id"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	Status    int       `json:"status"`
+	IP        *string   `json:"ip"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// --- ONLY ACTIVE CUSTOMER, SO IMPERSONATION NEVER GIVES MORE ACCESS THAN THE ADMIN HAS ---
+func GetImpersonationTarget(ctx context.Context, db *pgxpool.Pool, userID int) (AuthLogin, error) {
+	var user AuthLogin
+	var deletedAt *time.Time
+	err := db.QueryRow(ctx, `SELECT id, email, role, deleted_at FROM users WHERE id = $1`, userID).
+		Scan(&user.Id, &user.Email, &user.Role, &deletedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return AuthLogin{}, ErrAccountNotFound
+		}
+		return AuthLogin{}, err
+	}
+	if deletedAt != nil {
+		return AuthLogin{}, ErrAccountDeleted
+	}
+	if user.Role != DefaultUserRole {
+		return AuthLogin{}, ErrImpersonationTarget
+	}
+	return user, nil
+}
+
+// --- RECORD WHO STARTED IMPERSONATION AND WHY, TOKEN ID LINKS REQUESTS TO IT ---
+func CreateImpersonation(ctx context.Context, db *pgxpool.Pool, tokenID string, actorID, userID int, reason string, expiresAt time.Time) (int, error) {
+	var id int
+	err := db.QueryRow(ctx, `
+		INSERT INTO impersonations (token_id, actor_id, id_users, reason, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`, tokenID, actorID, userID, reason, expiresAt).Scan(&id)
+	if err != nil {
+		log.Println("Failed to insert impersonation :", err)
+		return 0, err
+	}
+	return id, nil
+}
+
+// --- WRITE REQUEST MADE WITH IMPERSONATION TOKEN TO AUDIT LOG ---
+func LogImpersonatedRequest(ctx context.Context, db *pgxpool.Pool, tokenID, method, path string, status int, ip string) error {
+	result, err := db.Exec(ctx, `
+		INSERT INTO impersonation_audit (id_impersonation, method, path, status, ip)
+		SELECT id, $2, LEFT($3, 255), $4, $5 FROM impersonations WHERE token_id = $1
+	`, tokenID, method, path, status, ip)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrImpersonationNotFound
+	}
+	return nil
+}
+
+// --- LIST IMPERSONATION, NEWEST FIRST, OPTIONALLY OF ONE USER ---
+func GetImpersonations(ctx context.Context, db *pgxpool.Pool, userID, page int) ([]Impersonation, error) {
+	rows, err := db.Query(ctx, `
+		SELECT i.id, i.actor_id, a.email, i.id_users, u.email, i.reason,
+			(SELECT COUNT(*) FROM impersonation_audit ia WHERE ia.id_impersonation = i.id)::INT,
+			i.expires_at, i.created_at
+		FROM impersonations i
+		JOIN users u ON u.id = i.id_users
+		LEFT JOIN users a ON a.id = i.actor_id
+		WHERE $1 = 0 OR i.id_users = $1
+		ORDER BY i.created_at DESC
+		LIMIT $2 OFFSET $3
+	`, userID, ImpersonationPageSize, (page-1)*ImpersonationPageSize)
+	if err != nil {
+		return nil, err
+	}
+	impersonations, err := pgx.CollectRows(rows, pgx.RowToStructByPos[Impersonation])
+	if impersonations == nil {
+		impersonations = []Impersonation{}
+	}
+	return impersonations, err
+}
+
+// --- EVERY REQUEST MADE DURING ONE IMPERSONATION ---
+func GetImpersonationAudit(ctx context.Context, db *pgxpool.Pool, id int) ([]ImpersonationAudit, error) {
+	var exists bool
+	if err := db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM impersonations WHERE id = $1)`, id).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrImpersonationNotFound
+	}
+
+	rows, err := db.Query(ctx, `
+		SELECT id, method, path, status, ip, created_at
+		FROM impersonation_audit
+		WHERE id_impersonation = $1
+		ORDER BY created_at
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	audit, err := pgx.CollectRows(rows, pgx.RowToStructByPos[ImpersonationAudit])
+	if audit == nil {
+		audit = []ImpersonationAudit{}
+	}
+	return audit, err
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// --- REDIS KEY PREFIX ---
+const (
+	magicLinkPrefix     = "magic:link:"
+	magicLinkUserPrefix = "magic:link:user:"
+)
+
+const MagicLinkTTL = 10 * time.Minute
+
+var ErrMagicLinkInvalid = errors.New("invalid or expired link")
+
+type ReqMagicLink struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ReqMagicLinkLogin struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// --- SAVE MAGIC LINK TOKEN, OLDER UNUSED LINK OF USER IS INVALIDATED ---
+func SaveMagicLinkToken(ctx context.Context, rdb *redis.Client, userID int, token string, ttl time.Duration) error {
+	userKey := fmt.Sprintf("%s%d", magicLinkUserPrefix, userID)
+	old, err := rdb.Get(ctx, userKey).Result()
+	if err != nil && err != redis.Nil {
+		return err
+	}
+
+	pipe := rdb.TxPipeline()
+	if old != "" {
+		pipe.Del(ctx, magicLinkPrefix+old)
+	}
+	pipe.Set(ctx, magicLinkPrefix+token, strconv.Itoa(userID), ttl)
+	pipe.Set(ctx, userKey, token, ttl)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// --- CONSUME MAGIC LINK TOKEN, A TOKEN CAN ONLY BE USED ONCE ---
+func ConsumeMagicLinkToken(ctx context.Context, rdb *redis.Client, token string) (int, error) {
+	val, err := rdb.GetDel(ctx, magicLinkPrefix+token).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return 0, ErrMagicLinkInvalid
+		}
+		return 0, err
+	}
+	userID, err := strconv.Atoi(val)
+	if err != nil {
+		return 0, ErrMagicLinkInvalid
+	}
+
+	// --- CLEAR USER POINTER ONLY WHEN IT STILL POINTS TO THIS TOKEN ---
+	userKey := fmt.Sprintf("%s%d", magicLinkUserPrefix, userID)
+	if current, err := rdb.Get(ctx, userKey).Result(); err == nil && current == token {
+		rdb.Del(ctx, userKey)
+	}
+	return userID, nil
+}
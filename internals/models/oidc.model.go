@@ -0,0 +1,138 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/federus1105/koda-b4-backend/internals/pkg/libs"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+)
+
+// --- REDIS KEY PREFIX ---
+const oidcStatePrefix = "oidc:state:"
+
+const OIDCStateTTL = 10 * time.Minute
+
+var ErrOIDCEmailNotVerified = errors.New("email from provider is not verified")
+
+// --- DATA KEPT BETWEEN REDIRECT AND CALLBACK ---
+type OIDCState struct {
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+}
+
+type ReqOIDCCallback struct {
+	Code  string `json:"code" binding:"required"`
+	State string `json:"state" binding:"required"`
+}
+
+func SaveOIDCState(ctx context.Context, rdb *redis.Client, state string, data OIDCState) error {
+	return libs.SetToCache(ctx, rdb, oidcStatePrefix+state, data, OIDCStateTTL)
+}
+
+// --- STATE IS SINGLE USE ---
+func TakeOIDCState(ctx context.Context, rdb *redis.Client, state string) (*OIDCState, error) {
+	data, err := libs.GetFromCache[OIDCState](ctx, rdb, oidcStatePrefix+state)
+	if err != nil || data == nil {
+		return nil, err
+	}
+	deleted, err := rdb.Del(ctx, oidcStatePrefix+state).Result()
+	if err != nil {
+		return nil, err
+	}
+	if deleted == 0 {
+		return nil, nil
+	}
+	return data, nil
+}
+
+// --- FIND USER BY IDENTITY, LINK BY EMAIL OR CREATE LIKE REGISTER ---
+func LoginWithIdentity(ctx context.Context, db *pgxpool.Pool, provider string, identity libs.OIDCIdentity, hashed string) (AuthLogin, error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		log.Println("Failed to begin transaction : ", err)
+		return AuthLogin{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	var userID int
+	err = tx.QueryRow(ctx,
+		`UPDATE user_identities SET last_login_at = NOW(), email = $3
+		WHERE provider = $1 AND subject = $2
+		RETURNING id_users`, provider, identity.Subject, identity.Email).Scan(&userID)
+	if err != nil && err != pgx.ErrNoRows {
+		log.Println("Failed to get identity :", err)
+		return AuthLogin{}, err
+	}
+
+	if err == pgx.ErrNoRows {
+		if !identity.EmailVerified || identity.Email == "" {
+			return AuthLogin{}, ErrOIDCEmailNotVerified
+		}
+
+		// --- LINK TO EXISTING ACCOUNT WITH SAME EMAIL ---
+		err = tx.QueryRow(ctx,
+			`SELECT id FROM users WHERE LOWER(email) = LOWER($1) LIMIT 1`,
+			identity.Email).Scan(&userID)
+		switch {
+		case err == nil:
+			// --- UNVERIFIED ACCOUNT MAY BE PRE-REGISTERED BY SOMEONE ELSE, DROP ITS PASSWORD ---
+			if _, err := tx.Exec(ctx,
+				`UPDATE users SET verified_at = NOW(), password = $2 WHERE id = $1 AND verified_at IS NULL`,
+				userID, hashed); err != nil {
+				log.Println("Failed to verify linked user :", err)
+				return AuthLogin{}, err
+			}
+		case err == pgx.ErrNoRows:
+			// --- INSERT TABLE USER ---
+			if err := tx.QueryRow(ctx,
+				`INSERT INTO users (email, password, verified_at) VALUES ($1, $2, NOW())
+				RETURNING id`, identity.Email, hashed).Scan(&userID); err != nil {
+				log.Println("Failed to insert user :", err)
+				return AuthLogin{}, err
+			}
+
+			// --- INSERT TABLE ACCOUNT ---
+			fullname := identity.Name
+			if len([]rune(fullname)) > 50 {
+				fullname = string([]rune(fullname)[:50])
+			}
+			if _, err := tx.Exec(ctx,
+				`INSERT INTO account (id_users, fullname) VALUES ($1, $2)`,
+				userID, fullname); err != nil {
+				log.Println("Failed to insert account : ", err)
+				return AuthLogin{}, err
+			}
+		default:
+			log.Println("Failed to get user by email :", err)
+			return AuthLogin{}, err
+		}
+
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO user_identities (id_users, provider, subject, email, last_login_at)
+			VALUES ($1, $2, $3, $4, NOW())`,
+			userID, provider, identity.Subject, identity.Email); err != nil {
+			log.Println("Failed to insert identity :", err)
+			return AuthLogin{}, err
+		}
+	}
+
+	var user AuthLogin
+	if err := tx.QueryRow(ctx,
+		`SELECT id, email, role, verified_at, totp_enabled_at IS NOT NULL FROM users WHERE id = $1`,
+		userID).Scan(&user.Id, &user.Email, &user.Role, &user.VerifiedAt, &user.TotpEnabled); err != nil {
+		log.Println("Failed to get user :", err)
+		return AuthLogin{}, err
+	}
+
+	// --- COMMIT TRANSACTION ---
+	if err := tx.Commit(ctx); err != nil {
+		log.Println("Failed to commit Transaction : ", err)
+		return AuthLogin{}, err
+	}
+	return user, nil
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/federus1105/koda-b4-backend/internals/pkg/utils"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
 )
 
 type CartItemRequest struct {
@@ -41,15 +42,19 @@ type Card struct {
 	Price         float64 `json:"price"`
 	PriceDiscount float64 `json:"discount"`
 	FlashSale     bool    `json:"flash_sale"`
-	Subtotal      float64 `json:"subtotal"`
+	// --- MOST A CUSTOMER CAN BUY IN THE RUNNING FLASH SALE ---
+	FlashSaleLimit *int    `json:"flash_sale_limit,omitempty"`
+	Subtotal       float64 `json:"subtotal"`
 }
 
 type TransactionsProduct struct {
-	Id_product int
-	Quantity   int
-	Subtotal   float64
-	Variant    string
-	Size       string
+	Id_product    int
+	Id_sku        int
+	Id_flash_sale *int
+	Quantity      int
+	Subtotal      float64
+	Variant       string
+	Size          string
 }
 
 type TransactionsInput struct {
@@ -69,22 +74,45 @@ type TransactionsInput struct {
 }
 
 func CreateCartProduct(ctx context.Context, db *pgxpool.Pool, accountID int, input CartItemRequest) (*CartItemResponse, error) {
+	var skuID *int
 	var stock int
-	// --- CHECKING STOCK ---
-	err := db.QueryRow(ctx, "SELECT stock FROM product WHERE id = $1", input.ProductID).Scan(&stock)
+	var skuActive bool
+	// --- CHECKING STOCK OF THE SIZE x VARIANT ---
+	err := db.QueryRow(ctx, `
+		SELECT ps.id, COALESCE(ps.stock, 0), COALESCE(ps.is_active, false)
+		FROM product p
+		LEFT JOIN product_skus ps ON ps.id_product = p.id
+			AND ps.id_size IS NOT DISTINCT FROM $2
+			AND ps.id_variant IS NOT DISTINCT FROM $3
+		WHERE p.id = $1`, input.ProductID, input.SizeID, input.VariantID).Scan(&skuID, &stock, &skuActive)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return &CartItemResponse{}, fmt.Errorf("product Not found")
 		}
 		return nil, fmt.Errorf("failed to check product stock %w", err)
 	}
+	if skuID == nil || !skuActive {
+		return nil, fmt.Errorf(skuUnavailable)
+	}
+
+	// --- SAME SIZE x VARIANT ALREADY IN CART COUNTS AGAINST STOCK ---
+	var inCart int
+	err = db.QueryRow(ctx, `
+		SELECT COALESCE(SUM(quantity), 0) FROM cart
+		WHERE account_id = $1 AND product_id = $2
+			AND size_id IS NOT DISTINCT FROM $3
+			AND variant_id IS NOT DISTINCT FROM $4`,
+		accountID, input.ProductID, input.SizeID, input.VariantID).Scan(&inCart)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check cart quantity %w", err)
+	}
 
 	// --- VALIDATION STOCK ---
 	if stock <= 0 {
 		return nil, fmt.Errorf("product is out of stock")
 	}
-	if input.Quantity > stock {
-		return nil, fmt.Errorf("insufficient stock %d", stock)
+	if input.Quantity+inCart > stock {
+		return nil, fmt.Errorf("insufficient stock %d", max(stock-inCart, 0))
 	}
 
 	// --- IF STOCK READY INSERT CART ---
@@ -128,18 +156,22 @@ func GetCartProduct(ctx context.Context, db *pgxpool.Pool, UserID int) ([]Card,
 	p.id as id_product,
     p.name, 
     p.priceoriginal,
-    p.pricediscount,
-    p.flash_sale,
-    pi.photos_one, 
+    COALESCE(img.url, ''),
     c.quantity, 
     s.name AS size, 
     v.name AS variant,
-    (p.priceoriginal * c.quantity) AS subtotal
+    ps.price,
+    COALESCE(ps.price_delta, 0),
+    fs.sale_price,
+    fs.discount_percent,
+    fs.max_per_customer
 FROM cart c
 LEFT JOIN sizes s ON s.id = c.size_id
 LEFT JOIN variants v ON v.id = c.variant_id
-LEFT JOIN product p ON p.id = c.product_id
-LEFT JOIN product_images pi ON p.id_product_images = pi.id
+LEFT JOIN product p ON p.id = c.product_id` + primaryImageJoin("c.product_id") + `
+LEFT JOIN product_skus ps ON ps.id_product = c.product_id
+	AND ps.id_size IS NOT DISTINCT FROM c.size_id
+	AND ps.id_variant IS NOT DISTINCT FROM c.variant_id` + activeFlashSaleJoin("c.product_id") + `
 WHERE c.account_id = $1;`
 
 	rows, err := db.Query(ctx, sql, UserID)
@@ -151,20 +183,34 @@ WHERE c.account_id = $1;`
 	carts := []Card{}
 	for rows.Next() {
 		var c Card
+		var fixedPrice, salePrice, discountPercent *float64
+		var priceDelta float64
 		if err := rows.Scan(
 			&c.Id,
 			&c.Id_product,
 			&c.Name,
 			&c.Price,
-			&c.PriceDiscount,
-			&c.FlashSale,
 			&c.Image,
 			&c.Quantity,
 			&c.Size,
 			&c.Variant,
-			&c.Subtotal); err != nil {
+			&fixedPrice,
+			&priceDelta,
+			&salePrice,
+			&discountPercent,
+			&c.FlashSaleLimit); err != nil {
 			return nil, err
 		}
+
+		// --- PRICE OF THE SIZE x VARIANT, FLASH SALE PRICE WHILE A CAMPAIGN RUNS ---
+		basePrice := c.Price
+		c.Price = SkuPrice(basePrice, fixedPrice, priceDelta)
+		c.PriceDiscount = FlashSalePrice(c.Price, basePrice, salePrice, discountPercent)
+		c.FlashSale = c.PriceDiscount > 0
+		c.Subtotal = c.Price * float64(c.Quantity)
+		if c.FlashSale {
+			c.Subtotal = c.PriceDiscount * float64(c.Quantity)
+		}
 		carts = append(carts, c)
 	}
 
@@ -174,7 +220,7 @@ WHERE c.account_id = $1;`
 	return carts, nil
 }
 
-func Transactions(ctx context.Context, db *pgxpool.Pool, input TransactionsInput, Iduser int) (TransactionsInput, error) {
+func Transactions(ctx context.Context, db *pgxpool.Pool, rd *redis.Client, input TransactionsInput, Iduser int) (TransactionsInput, error) {
 	var result TransactionsInput
 
 	// --- GET DATA USER ---
@@ -198,11 +244,16 @@ func Transactions(ctx context.Context, db *pgxpool.Pool, input TransactionsInput
 
 	// --- GET CART USER ---
 	rows, err := db.Query(ctx, `
-		SELECT c.quantity, p.id as product_id, p.priceoriginal, p.pricediscount, p.flash_sale, s.name AS size, v.name AS variant
+		SELECT c.quantity, p.id as product_id, p.priceoriginal, s.name AS size, v.name AS variant,
+			ps.id, ps.price, COALESCE(ps.price_delta, 0), COALESCE(ps.is_active, false),
+			fs.id, fs.sale_price, fs.discount_percent, fs.max_per_customer
 		FROM cart c
 		JOIN product p ON p.id = c.product_id
 		LEFT JOIN sizes s ON s.id = c.size_id
 		LEFT JOIN variants v ON v.id = c.variant_id
+		LEFT JOIN product_skus ps ON ps.id_product = c.product_id
+			AND ps.id_size IS NOT DISTINCT FROM c.size_id
+			AND ps.id_variant IS NOT DISTINCT FROM c.variant_id`+activeFlashSaleJoin("c.product_id")+`
 		WHERE c.account_id=$1
 	`, Iduser)
 	if err != nil {
@@ -213,30 +264,57 @@ func Transactions(ctx context.Context, db *pgxpool.Pool, input TransactionsInput
 	var products []TransactionsProduct
 	subtotal := 0.0
 
+	// --- QUANTITY PER PRODUCT IN A CAPPED FLASH SALE, EVERY SIZE x VARIANT COUNTS ---
+	type flashSaleLimit struct {
+		flashSaleID, limit, quantity int
+	}
+	limits := map[int]*flashSaleLimit{}
+
 	for rows.Next() {
 		var productID, quantity int
 		var size, variant sql.NullString
-		var priceOriginal, priceDiscount float64
-		var flashSale bool
-
-		if err := rows.Scan(&quantity, &productID, &priceOriginal, &priceDiscount, &flashSale, &size, &variant); err != nil {
+		var priceOriginal float64
+		var skuActive bool
+		var skuID, flashSaleID, maxPerCustomer *int
+		var fixedPrice, salePrice, discountPercent *float64
+		var priceDelta float64
+
+		if err := rows.Scan(&quantity, &productID, &priceOriginal, &size, &variant,
+			&skuID, &fixedPrice, &priceDelta, &skuActive,
+			&flashSaleID, &salePrice, &discountPercent, &maxPerCustomer); err != nil {
 			return result, fmt.Errorf("failed to scan cart items: %v", err)
 		}
 
-		price := priceOriginal
-		if flashSale {
-			price = priceDiscount
+		// --- SIZE x VARIANT REMOVED OR DISABLED SINCE IT WAS ADDED TO CART ---
+		if skuID == nil || !skuActive {
+			return result, utils.ValidationError{
+				Field:   fmt.Sprintf("product_id_%d", productID),
+				Message: skuUnavailable,
+			}
+		}
+
+		price := SkuPrice(priceOriginal, fixedPrice, priceDelta)
+		if salePriceSku := FlashSalePrice(price, priceOriginal, salePrice, discountPercent); salePriceSku > 0 {
+			price = salePriceSku
+		}
+		if flashSaleID != nil && maxPerCustomer != nil {
+			if limits[productID] == nil {
+				limits[productID] = &flashSaleLimit{flashSaleID: *flashSaleID, limit: *maxPerCustomer}
+			}
+			limits[productID].quantity += quantity
 		}
 
 		itemSubtotal := price * float64(quantity)
 		subtotal += itemSubtotal
 
 		products = append(products, TransactionsProduct{
-			Id_product: productID,
-			Quantity:   quantity,
-			Subtotal:   itemSubtotal,
-			Variant:    variant.String,
-			Size:       size.String,
+			Id_product:    productID,
+			Id_sku:        *skuID,
+			Id_flash_sale: flashSaleID,
+			Quantity:      quantity,
+			Subtotal:      itemSubtotal,
+			Variant:       variant.String,
+			Size:          size.String,
 		})
 	}
 
@@ -268,6 +346,13 @@ func Transactions(ctx context.Context, db *pgxpool.Pool, input TransactionsInput
 	}
 	defer tx.Rollback(ctx)
 
+	// --- FLASH SALE CAP, INCLUDING EARLIER ORDERS IN THE SAME CAMPAIGN ---
+	for productID, l := range limits {
+		if err := checkFlashSaleLimit(ctx, tx, Iduser, l.flashSaleID, productID, l.limit, l.quantity); err != nil {
+			return result, err
+		}
+	}
+
 	// --- INSERT ORDERS ---
 	var orderID int
 	var orderNumber string
@@ -301,19 +386,19 @@ func Transactions(ctx context.Context, db *pgxpool.Pool, input TransactionsInput
 	// --- INSERT PRODUCT ORDERS ---
 	for _, p := range products {
 		_, err := tx.Exec(ctx, `
-			INSERT INTO product_orders(id_order, id_product, quantity, variant, size, subtotal)
-			VALUES ($1,$2,$3,$4,$5,$6)
-		`, orderID, p.Id_product, p.Quantity, p.Variant, p.Size, p.Subtotal)
+			INSERT INTO product_orders(id_order, id_product, quantity, variant, size, subtotal, id_flash_sale)
+			VALUES ($1,$2,$3,$4,$5,$6,$7)
+		`, orderID, p.Id_product, p.Quantity, p.Variant, p.Size, p.Subtotal, p.Id_flash_sale)
 		if err != nil {
 			return result, fmt.Errorf("failed insert product orders: %v", err)
 		}
 
 		// --- UPDATE STOCK ---
 		res, err := tx.Exec(ctx, `
-        UPDATE product
-        SET stock = stock - $1
-        WHERE id = $2 AND stock >= $1
-    `, p.Quantity, p.Id_product)
+        UPDATE product_skus
+        SET stock = stock - $1, updated_at = NOW()
+        WHERE id = $2 AND is_active AND stock >= $1
+    `, p.Quantity, p.Id_sku)
 		if err != nil {
 			return result, fmt.Errorf("failed update stock: %v", err)
 		}
@@ -325,6 +410,9 @@ func Transactions(ctx context.Context, db *pgxpool.Pool, input TransactionsInput
 				Message: "stock not enough",
 			}
 		}
+		if _, err := syncProductStock(ctx, tx, p.Id_product); err != nil {
+			return result, fmt.Errorf("failed update stock: %v", err)
+		}
 	}
 
 	// --- DELETE CART USER ---
@@ -355,6 +443,7 @@ func Transactions(ctx context.Context, db *pgxpool.Pool, input TransactionsInput
 		log.Println("failed commmit transaksi:", err)
 		return TransactionsInput{}, err
 	}
+	invalidateFacetCaches(ctx, rd)
 
 	return result, nil
 }
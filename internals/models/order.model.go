@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/federus1105/koda-b4-backend/internals/pkg/libs"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -18,6 +19,7 @@ type ItemList struct {
 }
 
 type OrderList struct {
+	Id          int        `json:"id"`
 	OrderNumber string     `json:"orderNumber"`
 	Date        time.Time  `json:"date"`
 	Status      string     `json:"status"`
@@ -50,12 +52,13 @@ type UpdateStatusRequest struct {
 	Status int `json:"status"`
 }
 
-func GetListOrder(ctx context.Context, db *pgxpool.Pool, OrderNumber string, status, limit, offset int) ([]OrderList, error) {
+func GetListOrder(ctx context.Context, db *pgxpool.Pool, OrderNumber string, status int, page Page) ([]OrderList, *libs.Cursor, error) {
 	var p OrderList
 	var productsJSON []byte
 
 	sql := `
 SELECT 
+    o.id,
     o.order_number,
     o.createdAt,
     s.name,
@@ -94,6 +97,13 @@ JOIN status s ON s.id = o.id_status
 		argIdx++
 	}
 
+	// --- AFTER CURSOR ---
+	if condition, cursorArgs, next := page.afterCursor("o.createdAt", "o.id", "timestamp", true, argIdx); condition != "" {
+		whereClauses = append(whereClauses, condition)
+		args = append(args, cursorArgs...)
+		argIdx = next
+	}
+
 	// --- APPEND ALL WHERE ---
 	if len(whereClauses) > 0 {
 		sql += " WHERE " + strings.Join(whereClauses, " AND ")
@@ -103,34 +113,39 @@ JOIN status s ON s.id = o.id_status
 	sql += ` GROUP BY o.id, s.name, o.createdAt, o.id_status, o.total`
 
 	// --- ORDER LIMIT, OFFSET ---
-	sql += fmt.Sprintf(" ORDER BY o.createdAt DESC LIMIT $%d OFFSET $%d", argIdx, argIdx+1)
-	args = append(args, limit, offset)
+	limitSQL, limitArgs := page.limitClause(argIdx)
+	sql += " ORDER BY o.createdAt DESC, o.id DESC" + limitSQL
+	args = append(args, limitArgs...)
 
 	// --- EXECUTE QUERY ---
 	rows, err := db.Query(ctx, sql, args...)
 	if err != nil {
-		fmt.Println(err)
+		return nil, nil, err
 	}
 	defer rows.Close()
 
 	var order []OrderList
 	for rows.Next() {
-		if err := rows.Scan(&p.OrderNumber, &p.Date, &p.Status, &p.Total, &productsJSON); err != nil {
-			return nil, err
+		if err := rows.Scan(&p.Id, &p.OrderNumber, &p.Date, &p.Status, &p.Total, &productsJSON); err != nil {
+			return nil, nil, err
 		}
 
-		// --- PARSE JSON TO SLICE OF ITEMSLIST ---
+		// --- PARSE JSON TO SLICE OF ITEMSLIST, FRESH SLICE SO ROWS DON'T SHARE ITEMS ---
+		p.Order = nil
 		if err := json.Unmarshal(productsJSON, &p.Order); err != nil {
-			return nil, fmt.Errorf("failed to parse order items JSON: %w", err)
+			return nil, nil, fmt.Errorf("failed to parse order items JSON: %w", err)
 		}
 		order = append(order, p)
 	}
 
 	if rows.Err() != nil {
-		return nil, rows.Err()
+		return nil, nil, rows.Err()
 	}
 
-	return order, nil
+	order, next := keysetPage(page, "order", order, func(i int) (string, int) {
+		return timeKey(order[i].Date), order[i].Id
+	})
+	return order, next, nil
 
 }
 
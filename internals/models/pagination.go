@@ -0,0 +1,52 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/federus1105/koda-b4-backend/internals/pkg/libs"
+)
+
+// --- OFFSET PAGINATION UNLESS Keyset, After IS NIL ON THE FIRST KEYSET PAGE ---
+type Page struct {
+	Limit  int
+	Offset int
+	Keyset bool
+	After  *libs.Cursor
+}
+
+// --- ROW COMPARISON AGAINST THE CURSOR, e.g. (o.createdat, o.id) < ($3::timestamp, $4) ---
+func (p Page) afterCursor(sortExpr, idExpr, sqlType string, desc bool, argIdx int) (string, []any, int) {
+	if !p.Keyset || p.After == nil {
+		return "", nil, argIdx
+	}
+	op := ">"
+	if desc {
+		op = "<"
+	}
+	condition := fmt.Sprintf("(%s, %s) %s ($%d::%s, $%d)", sortExpr, idExpr, op, argIdx, sqlType, argIdx+1)
+	return condition, []any{p.After.Key, p.After.ID}, argIdx + 2
+}
+
+// --- KEYSET READS ONE EXTRA ROW TO KNOW WHETHER THERE IS A NEXT PAGE ---
+func (p Page) limitClause(argIdx int) (string, []any) {
+	if p.Keyset {
+		return fmt.Sprintf(" LIMIT $%d", argIdx), []any{p.Limit + 1}
+	}
+	return fmt.Sprintf(" LIMIT $%d OFFSET $%d", argIdx, argIdx+1), []any{p.Limit, p.Offset}
+}
+
+// --- DROP THE EXTRA ROW, NEXT CURSOR POINTS AT THE LAST ROW KEPT ---
+func keysetPage[T any](p Page, list string, rows []T, key func(i int) (string, int)) ([]T, *libs.Cursor) {
+	if !p.Keyset || len(rows) <= p.Limit {
+		return rows, nil
+	}
+	rows = rows[:p.Limit]
+	sortKey, id := key(len(rows) - 1)
+	return rows, &libs.Cursor{List: list, Key: sortKey, ID: id}
+}
+
+// --- TIMESTAMP WITHOUT TIME ZONE COMES BACK AS UTC, MICROSECONDS ARE KEPT ---
+func timeKey(t time.Time) string {
+	return t.Format(time.RFC3339Nano)
+}
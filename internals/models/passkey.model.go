@@ -0,0 +1,225 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/federus1105/koda-b4-backend/internals/pkg/libs"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+)
+
+// --- REDIS KEY PREFIX ---
+const (
+	passkeyRegisterPrefix = "webauthn:register:"
+	passkeyLoginPrefix    = "webauthn:login:"
+)
+
+const MaxPasskeysPerUser = 10
+
+var (
+	ErrPasskeyNotFound  = errors.New("passkey not found")
+	ErrPasskeyExists    = errors.New("passkey is already registered")
+	ErrPasskeyLimit     = fmt.Errorf("a maximum of %d passkeys can be registered", MaxPasskeysPerUser)
+	ErrPasskeyChallenge = errors.New("passkey challenge expired, please try again")
+)
+
+type Passkey struct {
+	ID         int        `json:"id"`
+	Name       string     `json:"name"`
+	Transports []string   `json:"transports"`
+	BackedUp   bool       `json:"backed_up"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// --- STORED CREDENTIAL NEEDED TO VERIFY AN ASSERTION ---
+type PasskeyRecord struct {
+	ID        int
+	UserID    int
+	PublicKey []byte
+	SignCount uint32
+}
+
+// --- BODY IS PublicKeyCredential.toJSON() FROM THE BROWSER, SO FIELD NAME FOLLOW WEBAUTHN ---
+type ReqRegisterPasskey struct {
+	Name       string                 `json:"name" binding:"required,max=100"`
+	Credential RegistrationCredential `json:"credential"`
+}
+
+type RegistrationCredential struct {
+	ID       string              `json:"id" binding:"required"`
+	Response AttestationResponse `json:"response"`
+}
+
+type AttestationResponse struct {
+	ClientDataJSON    string   `json:"clientDataJSON" binding:"required"`
+	AttestationObject string   `json:"attestationObject" binding:"required"`
+	Transports        []string `json:"transports" binding:"max=10,dive,max=20"`
+}
+
+type ReqPasskeyLogin struct {
+	ID       string            `json:"id" binding:"required"`
+	Response AssertionResponse `json:"response"`
+}
+
+type AssertionResponse struct {
+	ClientDataJSON    string `json:"clientDataJSON" binding:"required"`
+	AuthenticatorData string `json:"authenticatorData" binding:"required"`
+	Signature         string `json:"signature" binding:"required"`
+	UserHandle        string `json:"userHandle"`
+}
+
+// --- USER HANDLE IS OPAQUE FOR AUTHENTICATOR, USER ID IS ENOUGH ---
+func PasskeyUserHandle(userID int) []byte {
+	return []byte(strconv.Itoa(userID))
+}
+
+// --- SAVE REGISTRATION CHALLENGE, ONE PENDING REGISTRATION PER USER ---
+func SavePasskeyRegisterChallenge(ctx context.Context, rdb *redis.Client, userID int, challenge string) error {
+	return rdb.Set(ctx, fmt.Sprintf("%s%d", passkeyRegisterPrefix, userID), challenge, libs.WebAuthnTimeout).Err()
+}
+
+// --- TAKE REGISTRATION CHALLENGE, A CHALLENGE CAN ONLY BE USED ONCE ---
+func TakePasskeyRegisterChallenge(ctx context.Context, rdb *redis.Client, userID int) (string, error) {
+	challenge, err := rdb.GetDel(ctx, fmt.Sprintf("%s%d", passkeyRegisterPrefix, userID)).Result()
+	if err == redis.Nil {
+		return "", ErrPasskeyChallenge
+	}
+	return challenge, err
+}
+
+// --- LOGIN IS NOT TIED TO A USER YET, SO THE CHALLENGE ITSELF IS THE KEY ---
+func SavePasskeyLoginChallenge(ctx context.Context, rdb *redis.Client, challenge string) error {
+	return rdb.Set(ctx, passkeyLoginPrefix+challenge, 1, libs.WebAuthnTimeout).Err()
+}
+
+func TakePasskeyLoginChallenge(ctx context.Context, rdb *redis.Client, challenge string) error {
+	deleted, err := rdb.Del(ctx, passkeyLoginPrefix+challenge).Result()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrPasskeyChallenge
+	}
+	return nil
+}
+
+// --- NAME SHOWN BY AUTHENTICATOR WHEN CHOOSING AN ACCOUNT ---
+func GetPasskeyUser(ctx context.Context, db *pgxpool.Pool, userID int) (string, string, error) {
+	var email, fullname string
+	err := db.QueryRow(ctx, `
+		SELECT u.email, COALESCE(a.fullname, '')
+		FROM users u
+		LEFT JOIN account a ON a.id_users = u.id
+		WHERE u.id = $1
+	`, userID).Scan(&email, &fullname)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return "", "", errors.New("user not found")
+		}
+		return "", "", err
+	}
+	if fullname == "" {
+		fullname = email
+	}
+	return email, fullname, nil
+}
+
+func GetPasskeyExcludes(ctx context.Context, db *pgxpool.Pool, userID int) ([]libs.WebAuthnExclude, error) {
+	rows, err := db.Query(ctx, `SELECT credential_id, transports FROM passkeys WHERE id_users = $1`, userID)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByPos[libs.WebAuthnExclude])
+}
+
+// --- SAVE VERIFIED CREDENTIAL ---
+func CreatePasskey(ctx context.Context, db *pgxpool.Pool, userID int, name string, transports []string, cred libs.WebAuthnCredential) (Passkey, error) {
+	var count int
+	if err := db.QueryRow(ctx, `SELECT COUNT(*) FROM passkeys WHERE id_users = $1`, userID).Scan(&count); err != nil {
+		return Passkey{}, err
+	}
+	if count >= MaxPasskeysPerUser {
+		return Passkey{}, ErrPasskeyLimit
+	}
+	if transports == nil {
+		transports = []string{}
+	}
+
+	passkey := Passkey{Name: name, Transports: transports, BackedUp: cred.BackedUp}
+	err := db.QueryRow(ctx, `
+		INSERT INTO passkeys (id_users, name, credential_id, public_key, sign_count, transports, aaguid, backup_eligible, backed_up)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at
+	`, userID, name, cred.ID, cred.PublicKey, int64(cred.SignCount), transports, cred.AAGUID, cred.BackupEligible, cred.BackedUp).
+		Scan(&passkey.ID, &passkey.CreatedAt)
+	if err != nil {
+		if IsUniqueViolation(err) {
+			return Passkey{}, ErrPasskeyExists
+		}
+		log.Println("Failed to insert passkey :", err)
+		return Passkey{}, err
+	}
+	return passkey, nil
+}
+
+// --- LIST PASSKEY OF USER ---
+func GetPasskeys(ctx context.Context, db *pgxpool.Pool, userID int) ([]Passkey, error) {
+	rows, err := db.Query(ctx, `
+		SELECT id, name, transports, backed_up, last_used_at, created_at
+		FROM passkeys
+		WHERE id_users = $1
+		ORDER BY created_at DESC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	passkeys, err := pgx.CollectRows(rows, pgx.RowToStructByPos[Passkey])
+	if passkeys == nil {
+		passkeys = []Passkey{}
+	}
+	return passkeys, err
+}
+
+// --- DELETE PASSKEY, ONLY OWNER CAN DELETE ---
+func DeletePasskey(ctx context.Context, db *pgxpool.Pool, userID, id int) error {
+	result, err := db.Exec(ctx, `DELETE FROM passkeys WHERE id = $1 AND id_users = $2`, id, userID)
+	if err != nil {
+		log.Println("Failed to delete passkey :", err)
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrPasskeyNotFound
+	}
+	return nil
+}
+
+func GetPasskeyByCredentialID(ctx context.Context, db *pgxpool.Pool, credentialID []byte) (PasskeyRecord, error) {
+	var record PasskeyRecord
+	var signCount int64
+	err := db.QueryRow(ctx, `
+		SELECT id, id_users, public_key, sign_count FROM passkeys WHERE credential_id = $1
+	`, credentialID).Scan(&record.ID, &record.UserID, &record.PublicKey, &signCount)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return PasskeyRecord{}, ErrPasskeyNotFound
+		}
+		return PasskeyRecord{}, err
+	}
+	record.SignCount = uint32(signCount)
+	return record, nil
+}
+
+// --- SAVE COUNTER AFTER LOGIN SO A CLONED AUTHENTICATOR CAN BE DETECTED ---
+func UpdatePasskeyUsage(ctx context.Context, db *pgxpool.Pool, id int, assertion libs.WebAuthnAssertion) error {
+	_, err := db.Exec(ctx,
+		`UPDATE passkeys SET sign_count = $2, backed_up = $3, last_used_at = NOW() WHERE id = $1`,
+		id, int64(assertion.SignCount), assertion.BackedUp)
+	return err
+}
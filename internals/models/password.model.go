@@ -0,0 +1,65 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/federus1105/koda-b4-backend/internals/pkg/libs"
+	"github.com/federus1105/koda-b4-backend/internals/pkg/utils"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrPasswordReused = errors.New("password was used recently, please choose a different password")
+
+// --- REJECT CURRENT PASSWORD AND THE LAST N PASSWORD OF HISTORY ---
+func CheckPasswordReuse(ctx context.Context, db *pgxpool.Pool, userID int, password string) error {
+	history := utils.GetPasswordPolicy().HistorySize
+	if history == 0 {
+		return nil
+	}
+
+	rows, err := db.Query(ctx, `
+		SELECT password FROM users WHERE id = $1
+		UNION ALL
+		(SELECT password FROM password_history WHERE id_users = $1 ORDER BY created_at DESC, id DESC LIMIT $2)
+	`, userID, history)
+	if err != nil {
+		return err
+	}
+	hashes, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return err
+	}
+
+	for _, hashed := range hashes {
+		if ok, err := libs.VerifyPassword(password, hashed); err == nil && ok {
+			return ErrPasswordReused
+		}
+	}
+	return nil
+}
+
+// --- KEEP ONLY AS MANY HASH AS THE POLICY CHECKS ---
+func savePasswordHistory(ctx context.Context, tx pgx.Tx, userID int, hashed string) error {
+	history := utils.GetPasswordPolicy().HistorySize
+	if history == 0 {
+		return nil
+	}
+
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO password_history (id_users, password) VALUES ($1, $2)`, userID, hashed); err != nil {
+		log.Println("Failed to insert password history :", err)
+		return err
+	}
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM password_history WHERE id_users = $1 AND id NOT IN (
+			SELECT id FROM password_history WHERE id_users = $1 ORDER BY created_at DESC, id DESC LIMIT $2
+		)
+	`, userID, history); err != nil {
+		log.Println("Failed to prune password history :", err)
+		return err
+	}
+	return nil
+}
@@ -20,6 +20,9 @@ type FavoriteProduct struct {
 	Discount    float64 `json:"discount"`
 	Flash_sale  bool    `json:"flash_sale"`
 	Description string  `json:"description"`
+	Rating      float64 `json:"rating"`
+	ReviewCount int     `json:"review_count"`
+	Snippet     string  `json:"snippet,omitempty"`
 }
 
 type Option struct {
@@ -27,32 +30,36 @@ type Option struct {
 	Name string `json:"name"`
 }
 type ProductClient struct {
-	ImageOne      *string  `json:"-"`
-	ImageTwo      *string  `json:"-"`
-	ImageThree    *string  `json:"-"`
-	ImageFour     *string  `json:"-"`
-	Images        []string `json:"images"`
-	Name          string   `json:"name"`
-	Price         float64  `json:"price"`
-	PriceDiscount float64  `json:"priceDiscount"`
-	Rating        string   `json:"rating"`
-	Description   string   `json:"desc"`
-	Stock         int      `json:"stock"`
-	Size          []Option `json:"sizes"`
-	Variant       []Option `json:"variant"`
-	Flash_sale    bool     `json:"flash_sale"`
+	// --- IMAGE URLS, PRIMARY FIRST ---
+	Images        []string       `json:"images"`
+	Gallery       []ProductImage `json:"gallery"`
+	Name          string         `json:"name"`
+	Price         float64        `json:"price"`
+	PriceDiscount float64        `json:"priceDiscount"`
+	Rating        float64        `json:"rating"`
+	ReviewCount   int            `json:"review_count"`
+	Description   string         `json:"desc"`
+	Stock         int            `json:"stock"`
+	Size          []Option       `json:"sizes"`
+	Variant       []Option       `json:"variant"`
+	Flash_sale    bool           `json:"flash_sale"`
+	// --- END OF THE RUNNING FLASH SALE ---
+	FlashSaleEndsAt *time.Time   `json:"flash_sale_ends_at,omitempty"`
+	Skus            []ProductSku `json:"skus"`
 }
 
 func GetListFavoriteProduct(ctx context.Context, db *pgxpool.Pool, limit, offset int) ([]FavoriteProduct, error) {
-	sql := `SELECT pi.photos_one as image,
+	sql := `SELECT COALESCE(img.url, '') as image,
 	p.id,
 	p.name, 
 	p.flash_sale,
 	p.priceoriginal as price,
     p.pricediscount as discount,
-	p.description
+	p.description,
+	p.rating,
+	p.review_count
  	FROM product p
-	JOIN product_images pi ON pi.id = p.id_product_images
+	` + primaryImageJoin("p.id") + `
 	WHERE is_deleted = false AND is_favorite = true
 	LIMIT $1 OFFSET $2`
 
@@ -66,7 +73,7 @@ func GetListFavoriteProduct(ctx context.Context, db *pgxpool.Pool, limit, offset
 	var products []FavoriteProduct
 	for rows.Next() {
 		var fp FavoriteProduct
-		if err := rows.Scan(&fp.Image, &fp.Id, &fp.Name, &fp.Flash_sale, &fp.Price, &fp.Discount, &fp.Description); err != nil {
+		if err := rows.Scan(&fp.Image, &fp.Id, &fp.Name, &fp.Flash_sale, &fp.Price, &fp.Discount, &fp.Description, &fp.Rating, &fp.ReviewCount); err != nil {
 			return nil, err
 		}
 		products = append(products, fp)
@@ -88,16 +95,8 @@ func GetListProductFilter(ctx context.Context, db *pgxpool.Pool, rd *redis.Clien
 	limit, offset int) ([]FavoriteProduct, error) {
 
 	// --- REDIS ----
-	catStr := ""
-	if len(categoryIDs) > 0 {
-		strIDs := make([]string, len(categoryIDs))
-		for i, id := range categoryIDs {
-			strIDs[i] = fmt.Sprintf("%d", id)
-		}
-		catStr = strings.Join(strIDs, ",")
-	}
-	redisKey := fmt.Sprintf("product_filter:name=%s&category=%s&min=%.2f&max=%.2f&sort=%s&limit=%d&offset=%d",
-		name, catStr, minPrice, maxPrice, sortBy, limit, offset)
+	redisKey := fmt.Sprintf("product_filter:%s&sort=%s&limit=%d&offset=%d",
+		productFilterKey(name, categoryIDs, minPrice, maxPrice), sortBy, limit, offset)
 
 	// --- CHECK CACHE ---
 	if cached, err := libs.GetFromCache[[]FavoriteProduct](ctx, rd, redisKey); err != nil {
@@ -114,19 +113,24 @@ SELECT DISTINCT p.id,
 	   p.priceoriginal as price,
        p.pricediscount as discount,
        p.description,
-       pi.photos_one AS image
-FROM product p
-JOIN product_images pi ON pi.id = p.id_product_images
+       p.rating,
+       p.review_count,
+       COALESCE(img.url, '') AS image,
+       %s AS snippet,
+       %s AS relevance
+FROM product p` + primaryImageJoin("p.id") + `
 JOIN product_categories pc ON p.id = pc.id_product
 WHERE p.is_deleted = false
 `
 	args := []interface{}{}
 	argIdx := 1
 
-	// --- SEARCH BY NAME ---
-	if strings.TrimSpace(name) != "" {
-		sql += fmt.Sprintf(" AND p.name ILIKE $%d", argIdx)
-		args = append(args, "%"+name+"%")
+	// --- SEARCH BY NAME, DESCRIPTION AND CATEGORY ---
+	search := newProductSearch(name, argIdx)
+	sql = fmt.Sprintf(sql, search.snippet(), search.rank())
+	if search != nil {
+		sql += " AND " + search.condition()
+		args = append(args, name)
 		argIdx++
 	}
 
@@ -162,18 +166,30 @@ WHERE p.is_deleted = false
 		argIdx++
 	}
 
-	// --- SORT ---
-	if sortBy != "priceOriginal" {
-		sortBy = "name"
+	// --- SORT, A SEARCH IS ORDERED BY RELEVANCE UNLESS ASKED OTHERWISE ---
+	switch {
+	case search != nil && (sortBy == "" || sortBy == "relevance"):
+		sql += " ORDER BY relevance DESC, p.name ASC"
+	case sortBy == "rating":
+		sql += " ORDER BY p.rating DESC, p.review_count DESC, p.name ASC"
+	default:
+		if sortBy != "priceOriginal" {
+			sortBy = "name"
+		}
+		sql += fmt.Sprintf(" ORDER BY p.%s ASC", sortBy)
 	}
-	sql += fmt.Sprintf(" ORDER BY p.%s ASC", sortBy)
 
 	// --- LIMIT & OFFSET ---
 	sql += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argIdx, argIdx+1)
 	args = append(args, limit, offset)
 
 	// --- EXECUTE QUERY ---
-	rows, err := db.Query(ctx, sql, args...)
+	q, done, err := beginProductSearch(ctx, db, search)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	rows, err := q.Query(ctx, sql, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -182,7 +198,8 @@ WHERE p.is_deleted = false
 	var products []FavoriteProduct
 	for rows.Next() {
 		var p FavoriteProduct
-		if err := rows.Scan(&p.Id, &p.Name, &p.Flash_sale, &p.Price, &p.Discount, &p.Description, &p.Image); err != nil {
+		var relevance float64
+		if err := rows.Scan(&p.Id, &p.Name, &p.Flash_sale, &p.Price, &p.Discount, &p.Description, &p.Rating, &p.ReviewCount, &p.Image, &p.Snippet, &relevance); err != nil {
 			return nil, err
 		}
 		products = append(products, p)
@@ -201,44 +218,43 @@ WHERE p.is_deleted = false
 
 func GetProductById(ctx context.Context, db *pgxpool.Pool, productId int) (ProductClient, error) {
 	var product ProductClient
-	var priceDiscount *float64
+	var salePrice, discountPercent *float64
 	// --- QUERY ----
 	err := db.QueryRow(ctx, `
-        SELECT p.name, p.flash_sale, p.priceoriginal, p.priceDiscount, p.rating, p.description, p.stock,
-               pi.photos_one, pi.photos_two, pi.photos_three, pi.photos_four
-        FROM product p
-        JOIN product_images pi ON p.id_product_images = pi.id
+        SELECT p.name, p.priceoriginal, fs.sale_price, fs.discount_percent, fs.ends_at, p.rating, p.review_count, p.description, p.stock
+        FROM product p`+activeFlashSaleJoin("p.id")+`
         WHERE p.id=$1
     `, productId).Scan(
 		&product.Name,
-		&product.Flash_sale,
 		&product.Price,
-		&priceDiscount,
+		&salePrice,
+		&discountPercent,
+		&product.FlashSaleEndsAt,
 		&product.Rating,
+		&product.ReviewCount,
 		&product.Description,
 		&product.Stock,
-		&product.ImageOne,
-		&product.ImageTwo,
-		&product.ImageThree,
-		&product.ImageFour,
 	)
 	if err != nil {
 		return ProductClient{}, err
 	}
 
-	// -- ASSIGN IF NULL IN DB MAKE IT O ---
-	if priceDiscount == nil {
-		product.PriceDiscount = 0.0
-	} else {
-		product.PriceDiscount = *priceDiscount
-	}
+	// --- PRICE OF THE RUNNING FLASH SALE, 0 WITHOUT ONE ---
+	product.PriceDiscount = FlashSalePrice(product.Price, product.Price, salePrice, discountPercent)
+	product.Flash_sale = product.PriceDiscount > 0
 
-	// --- APPEND IMAGES TO SLICE IMAGES ---
-	product.Images = []string{
-		libs.StringOrEmpty(product.ImageOne),
-		libs.StringOrEmpty(product.ImageTwo),
-		libs.StringOrEmpty(product.ImageThree),
-		libs.StringOrEmpty(product.ImageFour),
+	// --- GALLERY IN POSITION ORDER, images KEEPS THE PRIMARY IN FRONT ---
+	product.Gallery, err = GetProductImages(ctx, db, productId)
+	if err != nil {
+		return ProductClient{}, err
+	}
+	product.Images = []string{}
+	for _, image := range product.Gallery {
+		if image.IsPrimary {
+			product.Images = append([]string{image.Url}, product.Images...)
+			continue
+		}
+		product.Images = append(product.Images, image.Url)
 	}
 
 	// --- GET SIZE ---
@@ -282,6 +298,12 @@ func GetProductById(ctx context.Context, db *pgxpool.Pool, productId int) (Produ
 		product.Variant = append(product.Variant, variant)
 	}
 
+	// --- GET SKUS, PRICE AND STOCK PER SIZE x VARIANT ---
+	product.Skus, err = GetProductSkus(ctx, db, productId, true)
+	if err != nil {
+		return ProductClient{}, err
+	}
+
 	return product, nil
 }
 
@@ -324,10 +346,11 @@ func GetCountProductFilter(
 	args := []interface{}{}
 	argIdx := 1
 
-	// --- FILTER NAME ---
-	if strings.TrimSpace(name) != "" {
-		sql += fmt.Sprintf(" AND p.name ILIKE $%d", argIdx)
-		args = append(args, "%"+name+"%")
+	// --- SEARCH BY NAME, DESCRIPTION AND CATEGORY ---
+	search := newProductSearch(name, argIdx)
+	if search != nil {
+		sql += " AND " + search.condition()
+		args = append(args, name)
 		argIdx++
 	}
 
@@ -366,7 +389,12 @@ func GetCountProductFilter(
 	}
 
 	// --- EXEC ---
-	if err := db.QueryRow(ctx, sql, args...).Scan(&total); err != nil {
+	q, done, err := beginProductSearch(ctx, db, search)
+	if err != nil {
+		return 0, err
+	}
+	defer done()
+	if err := q.QueryRow(ctx, sql, args...).Scan(&total); err != nil {
 		return 0, err
 	}
 
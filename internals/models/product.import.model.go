@@ -0,0 +1,580 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/federus1105/koda-b4-backend/internals/pkg/utils"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	ProductImportMaxRows  = 1000
+	ProductImportMaxBatch = 500
+	// --- categories, sizes, variants AND images HOLD SEVERAL VALUES ---
+	ProductImportListSeparator = "|"
+)
+
+// --- HEADER OF THE IMPORT FILE AND THE EXPORT, IN EXPORT ORDER ---
+var ProductImportColumns = []string{"sku", "slug", "name", "description", "price", "stock", "categories", "sizes", "variants", "images"}
+
+// --- FIELD NAMES MATCH CreateProducts SO THE VALIDATOR GIVES THE SAME MESSAGES ---
+type ProductImportRow struct {
+	Row         int      `json:"row"`
+	Sku         string   `json:"sku" binding:"max=64"`
+	Slug        string   `json:"slug" binding:"max=100"`
+	Name        string   `json:"name" binding:"required,max=100"`
+	Description string   `json:"description" binding:"required,max=255"`
+	Price       float64  `json:"price" binding:"required,gte=5000"`
+	Stock       int      `json:"stock" binding:"gte=0"`
+	Category    []string `json:"categories" binding:"required,min=1"`
+	Size        []string `json:"sizes" binding:"max=3"`
+	Variant     []string `json:"variants" binding:"max=2"`
+	Images      []string `json:"images" binding:"dive,url"`
+}
+
+type ProductImportError struct {
+	Row    int      `json:"row"`
+	Errors []string `json:"errors"`
+}
+
+type ProductImportItem struct {
+	Row    int    `json:"row"`
+	Action string `json:"action"`
+	Id     int    `json:"id,omitempty"`
+	Slug   string `json:"slug"`
+}
+
+type ProductImportOptions struct {
+	DryRun bool
+	Upsert bool
+	// --- 0 IMPORTS EVERYTHING OR NOTHING, OTHERWISE EACH BATCH KEEPS ITS VALID ROWS ---
+	BatchSize int
+}
+
+type ProductImportResult struct {
+	DryRun  bool                 `json:"dry_run"`
+	Rows    int                  `json:"rows"`
+	Created int                  `json:"created"`
+	Updated int                  `json:"updated"`
+	Failed  int                  `json:"failed"`
+	Saved   bool                 `json:"saved"`
+	Items   []ProductImportItem  `json:"items"`
+	Errors  []ProductImportError `json:"errors"`
+}
+
+// --- NAMES AND IDS OF CATEGORIES, SIZES AND VARIANTS, READ ONCE PER IMPORT ---
+type productImportLookup struct {
+	categories map[string]int
+	sizes      map[string]int
+	variants   map[string]int
+}
+
+// --- FIRST RECORD IS THE HEADER, COLUMNS MAY COME IN ANY ORDER AND UNKNOWN ONES ARE IGNORED ---
+func ParseProductImport(records [][]string) ([]ProductImportRow, []ProductImportError, error) {
+	if len(records) == 0 {
+		return nil, nil, utils.ValidationError{Field: "file", Message: "is empty"}
+	}
+
+	columns := map[string]int{}
+	for i, name := range records[0] {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if name != "" {
+			columns[name] = i
+		}
+	}
+	for _, required := range []string{"name", "description", "price", "categories"} {
+		if _, ok := columns[required]; !ok {
+			return nil, nil, utils.ValidationError{Field: "file", Message: fmt.Sprintf("is missing the %s column", required)}
+		}
+	}
+
+	body := records[1:]
+	for len(body) > 0 && blankRecord(body[len(body)-1]) {
+		body = body[:len(body)-1]
+	}
+	if len(body) == 0 {
+		return nil, nil, utils.ValidationError{Field: "file", Message: "has no product rows"}
+	}
+	if len(body) > ProductImportMaxRows {
+		return nil, nil, utils.ValidationError{Field: "file", Message: fmt.Sprintf("can have at most %d product rows", ProductImportMaxRows)}
+	}
+
+	rows := make([]ProductImportRow, 0, len(body))
+	var failed []ProductImportError
+	for i, record := range body {
+		cell := func(name string) string {
+			if idx, ok := columns[name]; ok && idx < len(record) {
+				return strings.TrimSpace(record[idx])
+			}
+			return ""
+		}
+
+		if blankRecord(record) {
+			continue
+		}
+
+		// --- LINE NUMBER IN THE FILE, THE HEADER IS LINE 1 ---
+		row := ProductImportRow{
+			Row:         i + 2,
+			Sku:         cell("sku"),
+			Slug:        cell("slug"),
+			Name:        cell("name"),
+			Description: cell("description"),
+			Category:    splitImportList(cell("categories")),
+			Size:        splitImportList(cell("sizes")),
+			Variant:     splitImportList(cell("variants")),
+			Images:      splitImportList(cell("images")),
+		}
+
+		var errs []string
+		if v := cell("price"); v != "" {
+			price, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				errs = append(errs, "price must be a number")
+			}
+			row.Price = price
+		}
+		if v := cell("stock"); v != "" {
+			stock, err := strconv.ParseFloat(v, 64)
+			if err != nil || stock != float64(int(stock)) {
+				errs = append(errs, "stock must be a whole number")
+			}
+			row.Stock = int(stock)
+		}
+		if row.Slug != "" && Slugify(row.Slug) != row.Slug {
+			errs = append(errs, "slug must contain only lowercase letters, numbers and dashes")
+		}
+
+		if len(errs) > 0 {
+			failed = append(failed, ProductImportError{Row: row.Row, Errors: errs})
+			continue
+		}
+		rows = append(rows, row)
+	}
+	return rows, failed, nil
+}
+
+func blankRecord(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func splitImportList(value string) []string {
+	values := []string{}
+	for _, v := range strings.Split(value, ProductImportListSeparator) {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// --- ROWS THAT FAILED PARSING OR VALIDATION ARE PASSED IN failed AND ONLY REPORTED ---
+func ImportProducts(ctx context.Context, db *pgxpool.Pool, rd *redis.Client, rows []ProductImportRow, failed []ProductImportError, opts ProductImportOptions) (ProductImportResult, error) {
+	result := ProductImportResult{
+		DryRun: opts.DryRun,
+		Rows:   len(rows) + len(failed),
+		Items:  []ProductImportItem{},
+		Errors: append([]ProductImportError{}, failed...),
+	}
+
+	lookup, err := loadProductImportLookup(ctx, db)
+	if err != nil {
+		return ProductImportResult{}, err
+	}
+
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = max(len(rows), 1)
+	}
+
+	written := false
+	for start := 0; start < len(rows); start += batchSize {
+		batch := rows[start:min(start+batchSize, len(rows))]
+
+		tx, err := db.Begin(ctx)
+		if err != nil {
+			log.Println("Failed to begin transaction:", err)
+			return ProductImportResult{}, err
+		}
+		items, errs, err := importProductBatch(ctx, tx, lookup, batch, opts.Upsert)
+		if err != nil {
+			tx.Rollback(ctx)
+			return ProductImportResult{}, err
+		}
+		result.Errors = append(result.Errors, errs...)
+
+		// --- ALL OR NOTHING KEEPS THE BATCH ONLY WHEN NO ROW OF THE FILE FAILED ---
+		save := !opts.DryRun && (opts.BatchSize > 0 || len(result.Errors) == 0)
+		if !save {
+			tx.Rollback(ctx)
+		} else if err := tx.Commit(ctx); err != nil {
+			log.Println("Failed to commit transaction:", err)
+			return ProductImportResult{}, err
+		} else {
+			written = written || len(items) > 0
+		}
+
+		if !save && !opts.DryRun {
+			continue
+		}
+		for _, item := range items {
+			// --- A DRY RUN CREATES NOTHING, THE ID WAS ROLLED BACK ---
+			if opts.DryRun && item.Action == "created" {
+				item.Id = 0
+			}
+			if item.Action == "created" {
+				result.Created++
+			} else {
+				result.Updated++
+			}
+			result.Items = append(result.Items, item)
+		}
+	}
+	sort.Slice(result.Errors, func(i, j int) bool { return result.Errors[i].Row < result.Errors[j].Row })
+	result.Failed = len(result.Errors)
+	result.Saved = written
+
+	if written {
+		invalidateProductCaches(ctx, rd)
+		// --- PERCENTAGE FLASH SALE PRICE FOLLOWS AN UPDATED PRICE ---
+		if result.Updated > 0 {
+			wakeFlashSaleScheduler()
+		}
+	}
+	return result, nil
+}
+
+// --- EVERY ROW RUNS IN ITS OWN SAVEPOINT, A FAILED ROW DOESN'T ABORT THE OTHERS ---
+func importProductBatch(ctx context.Context, tx pgx.Tx, lookup productImportLookup, rows []ProductImportRow, upsert bool) ([]ProductImportItem, []ProductImportError, error) {
+	items := []ProductImportItem{}
+	var failed []ProductImportError
+	for _, row := range rows {
+		savepoint, err := tx.Begin(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		item, err := importProductRow(ctx, savepoint, lookup, row, upsert)
+		if err != nil {
+			savepoint.Rollback(ctx)
+
+			var ve utils.ValidationError
+			switch {
+			case errors.As(err, &ve):
+				failed = append(failed, ProductImportError{Row: row.Row, Errors: []string{ve.Error()}})
+			case IsUniqueViolation(err):
+				failed = append(failed, ProductImportError{Row: row.Row, Errors: []string{"sku or slug is already used by another product"}})
+			default:
+				return nil, nil, err
+			}
+			continue
+		}
+		if err := savepoint.Commit(ctx); err != nil {
+			return nil, nil, err
+		}
+		items = append(items, item)
+	}
+	return items, failed, nil
+}
+
+func importProductRow(ctx context.Context, tx pgx.Tx, lookup productImportLookup, row ProductImportRow, upsert bool) (ProductImportItem, error) {
+	categories, err := lookup.ids("categories", lookup.categories, row.Category)
+	if err != nil {
+		return ProductImportItem{}, err
+	}
+	sizes, err := lookup.ids("sizes", lookup.sizes, row.Size)
+	if err != nil {
+		return ProductImportItem{}, err
+	}
+	variants, err := lookup.ids("variants", lookup.variants, row.Variant)
+	if err != nil {
+		return ProductImportItem{}, err
+	}
+
+	var sku *string
+	if row.Sku != "" {
+		sku = &row.Sku
+	}
+
+	// --- SKU WINS OVER SLUG, WITHOUT EITHER AN UPSERT MATCHES THE SLUG OF THE NAME ---
+	key := row.Slug
+	if key == "" && upsert {
+		key = Slugify(row.Name)
+	}
+	var (
+		id          int
+		currentSlug string
+		currentSku  *string
+	)
+	err = tx.QueryRow(ctx, `
+		SELECT id, slug, sku FROM product
+		WHERE is_deleted = false AND ((sku = $1 AND $1 <> '') OR (slug = $2 AND $2 <> ''))
+		ORDER BY (sku = $1 AND $1 <> '') DESC
+		LIMIT 1`, row.Sku, key).Scan(&id, &currentSlug, &currentSku)
+	if err != nil && err != pgx.ErrNoRows {
+		return ProductImportItem{}, err
+	}
+	found := err == nil
+
+	if found && !upsert {
+		return ProductImportItem{}, utils.ValidationError{Field: "product", Message: fmt.Sprintf("%s already exists, import with upsert to update it", currentSlug)}
+	}
+	if found && sku != nil && currentSku != nil && *currentSku != row.Sku {
+		return ProductImportItem{}, utils.ValidationError{Field: "slug", Message: fmt.Sprintf("%s belongs to the product with sku %s", currentSlug, *currentSku)}
+	}
+
+	images := NewProductImages(row.Images, nil)
+
+	if !found {
+		skus := SkusFromOptions(sizes, variants, 0)
+		splitSkuStock(skus, row.Stock)
+		product, err := insertProduct(ctx, tx, CreateProducts{
+			Name:        row.Name,
+			Description: row.Description,
+			Price:       row.Price,
+			Stock:       row.Stock,
+			Category:    categories,
+			SkuItems:    skus,
+			ImageItems:  images,
+		}, row.Slug, sku)
+		if err != nil {
+			return ProductImportItem{}, err
+		}
+
+		slug := row.Slug
+		if slug == "" {
+			if err := tx.QueryRow(ctx, `SELECT slug FROM product WHERE id = $1`, product.Id).Scan(&slug); err != nil {
+				return ProductImportItem{}, err
+			}
+		}
+		return ProductImportItem{Row: row.Row, Action: "created", Id: product.Id, Slug: slug}, nil
+	}
+
+	// --- UPDATE, AN EMPTY slug OR sku KEEPS THE CURRENT ONE ---
+	slug := currentSlug
+	if row.Slug != "" {
+		slug = row.Slug
+	}
+	if sku == nil {
+		sku = currentSku
+	}
+	if _, err := tx.Exec(ctx, `
+		UPDATE product SET name = $2, description = $3, priceoriginal = $4, slug = $5, sku = $6, updatedat = NOW()
+		WHERE id = $1`, id, row.Name, row.Description, row.Price, slug, sku); err != nil {
+		log.Println("Failed to update product:", err)
+		return ProductImportItem{}, err
+	}
+
+	// --- EMPTY images KEEPS THE CURRENT GALLERY, OTHERWISE THE FIRST URL IS PRIMARY ---
+	if len(images) > 0 {
+		if err := replaceProductImages(ctx, tx, id, images); err != nil {
+			return ProductImportItem{}, err
+		}
+	}
+
+	if _, err := tx.Exec(ctx, "DELETE FROM product_categories WHERE id_product=$1", id); err != nil {
+		return ProductImportItem{}, err
+	}
+	for _, categoryID := range categories {
+		if _, err := tx.Exec(ctx, "INSERT INTO product_categories (id_product, id_categories) VALUES ($1, $2)", id, categoryID); err != nil {
+			log.Println("Failed to insert product categories", err)
+			return ProductImportItem{}, err
+		}
+	}
+
+	current, err := GetProductSkus(ctx, tx, id, false)
+	if err != nil {
+		return ProductImportItem{}, err
+	}
+	if _, err := replaceProductSkus(ctx, tx, id, importSkus(current, sizes, variants, row.Stock)); err != nil {
+		return ProductImportItem{}, err
+	}
+
+	return ProductImportItem{Row: row.Row, Action: "updated", Id: id, Slug: slug}, nil
+}
+
+// --- SAME COMBINATIONS AND SAME TOTAL KEEP EVERY SKU AS IT IS, OTHERWISE THE TOTAL IS SPLIT AGAIN ---
+func importSkus(current []ProductSku, sizes, variants []int, stock int) []SkuInput {
+	existing := map[[2]int]ProductSku{}
+	total := 0
+	for _, sku := range current {
+		existing[skuKey(optionID(sku.Size), optionID(sku.Variant))] = sku
+		if sku.Active {
+			total += sku.Stock
+		}
+	}
+
+	skus := SkusFromOptions(sizes, variants, 0)
+	unchanged := len(skus) == len(current) && total == stock
+	for i, sku := range skus {
+		old, ok := existing[skuKey(sku.Size, sku.Variant)]
+		if !ok {
+			unchanged = false
+			continue
+		}
+		active := old.Active
+		skus[i].FixedPrice = old.FixedPrice
+		skus[i].PriceDelta = old.PriceDelta
+		skus[i].Stock = old.Stock
+		skus[i].Active = &active
+	}
+	if !unchanged {
+		// --- A DISABLED SKU STAYS DISABLED AND KEEPS ITS STOCK, THE TOTAL GOES TO THE ACTIVE ONES ---
+		selling := make([]int, 0, len(skus))
+		for i, sku := range skus {
+			if sku.Active == nil || *sku.Active {
+				selling = append(selling, i)
+			}
+		}
+		split := make([]SkuInput, len(selling))
+		splitSkuStock(split, stock)
+		for j, i := range selling {
+			skus[i].Stock = split[j].Stock
+		}
+	}
+	return skus
+}
+
+// --- TOTAL STOCK SPLIT EVENLY, THE REMAINDER GOES TO THE FIRST SKUS ---
+func splitSkuStock(skus []SkuInput, total int) {
+	if len(skus) == 0 {
+		return
+	}
+	for i := range skus {
+		skus[i].Stock = total / len(skus)
+		if i < total%len(skus) {
+			skus[i].Stock++
+		}
+	}
+}
+
+func loadProductImportLookup(ctx context.Context, db *pgxpool.Pool) (productImportLookup, error) {
+	lookup := productImportLookup{}
+	for _, table := range []struct {
+		sql    string
+		target *map[string]int
+	}{
+		{`SELECT id, name FROM categories`, &lookup.categories},
+		{`SELECT id, name::text FROM sizes`, &lookup.sizes},
+		{`SELECT id, name::text FROM variants`, &lookup.variants},
+	} {
+		rows, err := db.Query(ctx, table.sql)
+		if err != nil {
+			return productImportLookup{}, err
+		}
+		names := map[string]int{}
+		for rows.Next() {
+			var (
+				id   int
+				name string
+			)
+			if err := rows.Scan(&id, &name); err != nil {
+				rows.Close()
+				return productImportLookup{}, err
+			}
+			names[strings.ToLower(name)] = id
+			names[strconv.Itoa(id)] = id
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return productImportLookup{}, err
+		}
+		*table.target = names
+	}
+	return lookup, nil
+}
+
+// --- NAME (CASE-INSENSITIVE) OR ID TO ID ---
+func (l productImportLookup) ids(field string, names map[string]int, values []string) ([]int, error) {
+	ids := []int{}
+	seen := map[int]bool{}
+	for _, value := range values {
+		id, ok := names[strings.ToLower(value)]
+		if !ok {
+			return nil, utils.ValidationError{Field: field, Message: fmt.Sprintf("has unknown value %q", value)}
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// --- EVERY PRODUCT IN THE IMPORT FORMAT, SO AN EDITED EXPORT CAN BE IMPORTED WITH UPSERT ---
+func ExportProducts(ctx context.Context, db *pgxpool.Pool) ([]ProductImportRow, error) {
+	rows, err := db.Query(ctx, `
+		SELECT COALESCE(p.sku, ''), p.slug, p.name, p.description, p.priceoriginal, p.stock,
+			COALESCE((
+				SELECT array_agg(c.name ORDER BY c.name)
+				FROM product_categories pc JOIN categories c ON c.id = pc.id_categories
+				WHERE pc.id_product = p.id
+			), '{}'),
+			COALESCE((
+				SELECT array_agg(o.name ORDER BY o.id) FROM (
+					SELECT DISTINCT s.id, s.name::text AS name
+					FROM product_skus ps JOIN sizes s ON s.id = ps.id_size
+					WHERE ps.id_product = p.id AND ps.is_active
+				) o
+			), '{}'),
+			COALESCE((
+				SELECT array_agg(o.name ORDER BY o.id) FROM (
+					SELECT DISTINCT v.id, v.name::text AS name
+					FROM product_skus ps JOIN variants v ON v.id = ps.id_variant
+					WHERE ps.id_product = p.id AND ps.is_active
+				) o
+			), '{}'),
+			COALESCE((
+				SELECT array_agg(g.url ORDER BY g.is_primary DESC, g.position, g.id)
+				FROM product_gallery g
+				WHERE g.id_product = p.id
+			), '{}')
+		FROM product p
+		WHERE p.is_deleted = false
+		ORDER BY p.id`)
+	if err != nil {
+		log.Println("Failed to query products:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := []ProductImportRow{}
+	for rows.Next() {
+		var p ProductImportRow
+		if err := rows.Scan(&p.Sku, &p.Slug, &p.Name, &p.Description, &p.Price, &p.Stock, &p.Category, &p.Size, &p.Variant, &p.Images); err != nil {
+			return nil, err
+		}
+		p.Row = len(products) + 2
+		products = append(products, p)
+	}
+	return products, rows.Err()
+}
+
+// --- CELLS IN ProductImportColumns ORDER ---
+func (p ProductImportRow) Record() []any {
+	return []any{
+		p.Sku,
+		p.Slug,
+		p.Name,
+		p.Description,
+		p.Price,
+		p.Stock,
+		strings.Join(p.Category, ProductImportListSeparator),
+		strings.Join(p.Size, ProductImportListSeparator),
+		strings.Join(p.Variant, ProductImportListSeparator),
+		strings.Join(p.Images, ProductImportListSeparator),
+	}
+}
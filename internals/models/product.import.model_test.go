@@ -0,0 +1,156 @@
+package models
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/federus1105/koda-b4-backend/internals/pkg/utils"
+)
+
+func TestParseProductImport(t *testing.T) {
+	records := [][]string{
+		{"\ufeffName", " PRICE ", "description", "categories", "stock", "sizes", "slug", "unknown"},
+		{"Kopi Susu", "18000", "Kopi dengan susu", "Coffee | Milk |", "10", "1|2", "kopi-susu", "x"},
+		{"", "", "", "", "", "", "", ""},
+		{"Teh", "12.5e3", "Teh hitam", "Tea", "7.0", "", "", ""},
+		{"Bad", "murah", "x", "Tea", "1.5", "", "Bad Slug", ""},
+		{"Short row", "5000"},
+		{" ", "", ""},
+	}
+
+	rows, failed, err := ParseProductImport(records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantRows := []ProductImportRow{
+		{Row: 2, Slug: "kopi-susu", Name: "Kopi Susu", Description: "Kopi dengan susu", Price: 18000, Stock: 10,
+			Category: []string{"Coffee", "Milk"}, Size: []string{"1", "2"}, Variant: []string{}, Images: []string{}},
+		{Row: 4, Name: "Teh", Description: "Teh hitam", Price: 12500, Stock: 7,
+			Category: []string{"Tea"}, Size: []string{}, Variant: []string{}, Images: []string{}},
+		{Row: 6, Name: "Short row", Price: 5000,
+			Category: []string{}, Size: []string{}, Variant: []string{}, Images: []string{}},
+	}
+	if !reflect.DeepEqual(rows, wantRows) {
+		t.Fatalf("rows = %+v\nwant %+v", rows, wantRows)
+	}
+	wantFailed := []ProductImportError{{Row: 5, Errors: []string{
+		"price must be a number",
+		"stock must be a whole number",
+		"slug must contain only lowercase letters, numbers and dashes",
+	}}}
+	if !reflect.DeepEqual(failed, wantFailed) {
+		t.Fatalf("failed = %+v, want %+v", failed, wantFailed)
+	}
+}
+
+func TestParseProductImportFile(t *testing.T) {
+	header := []string{"name", "description", "price", "categories"}
+	tooMany := [][]string{header}
+	for range ProductImportMaxRows + 1 {
+		tooMany = append(tooMany, []string{"Kopi", "x", "5000", "Coffee"})
+	}
+
+	tests := []struct {
+		name    string
+		records [][]string
+	}{
+		{"empty", nil},
+		{"missing column", [][]string{{"name", "description", "price"}, {"Kopi", "x", "5000"}}},
+		{"header only", [][]string{header}},
+		{"blank rows only", [][]string{header, {"", " "}, {}}},
+		{"too many rows", tooMany},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := ParseProductImport(tt.records)
+			var ve utils.ValidationError
+			if !errors.As(err, &ve) || ve.Field != "file" {
+				t.Fatalf("err = %v, want a file validation error", err)
+			}
+		})
+	}
+
+	// --- TRAILING BLANK ROWS DON'T COUNT AGAINST THE LIMIT ---
+	atLimit := append(tooMany[:ProductImportMaxRows+1:ProductImportMaxRows+1], []string{"", ""}, []string{})
+	if rows, _, err := ParseProductImport(atLimit); err != nil || len(rows) != ProductImportMaxRows {
+		t.Fatalf("%d rows at the limit: %d rows, %v", ProductImportMaxRows, len(rows), err)
+	}
+}
+
+func TestSplitSkuStock(t *testing.T) {
+	tests := []struct {
+		total int
+		skus  int
+		want  []int
+	}{
+		{10, 1, []int{10}},
+		{10, 2, []int{5, 5}},
+		{10, 3, []int{4, 3, 3}},
+		{11, 4, []int{3, 3, 3, 2}},
+		{2, 4, []int{1, 1, 0, 0}},
+		{0, 3, []int{0, 0, 0}},
+		{5, 0, []int{}},
+	}
+	for _, tt := range tests {
+		skus := make([]SkuInput, tt.skus)
+		splitSkuStock(skus, tt.total)
+		got := make([]int, len(skus))
+		for i, sku := range skus {
+			got[i] = sku.Stock
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitSkuStock(%d over %d) = %v, want %v", tt.total, tt.skus, got, tt.want)
+		}
+	}
+}
+
+func TestImportSkus(t *testing.T) {
+	current := []ProductSku{
+		{Size: &Option{Id: 1}, PriceDelta: 0, Stock: 4, Active: true},
+		{Size: &Option{Id: 2}, PriceDelta: 3000, Stock: 6, Active: true},
+		{Size: &Option{Id: 3}, FixedPrice: ptr(30000.0), Stock: 2, Active: false},
+	}
+
+	tests := []struct {
+		name  string
+		sizes []int
+		stock int
+		want  []SkuInput
+	}{
+		{
+			// --- SAME SKUS AND THE TOTAL OF THE ACTIVE ONES, THE STOCK OF EACH IS KEPT ---
+			name: "unchanged", sizes: []int{1, 2, 3}, stock: 10,
+			want: []SkuInput{
+				{Size: ptr(1), Stock: 4, Active: ptr(true)},
+				{Size: ptr(2), PriceDelta: 3000, Stock: 6, Active: ptr(true)},
+				{Size: ptr(3), FixedPrice: ptr(30000.0), Stock: 2, Active: ptr(false)},
+			},
+		},
+		{
+			// --- NEW TOTAL GOES TO THE ACTIVE SKUS, THE DISABLED ONE STAYS DISABLED WITH ITS STOCK ---
+			name: "new total", sizes: []int{1, 2, 3}, stock: 9,
+			want: []SkuInput{
+				{Size: ptr(1), Stock: 5, Active: ptr(true)},
+				{Size: ptr(2), PriceDelta: 3000, Stock: 4, Active: ptr(true)},
+				{Size: ptr(3), FixedPrice: ptr(30000.0), Stock: 2, Active: ptr(false)},
+			},
+		},
+		{
+			// --- A NEW SIZE IS ACTIVE AND SHARES THE TOTAL, A DROPPED ONE IS LEFT OUT ---
+			name: "new size", sizes: []int{2, 3, 4}, stock: 7,
+			want: []SkuInput{
+				{Size: ptr(2), PriceDelta: 3000, Stock: 4, Active: ptr(true)},
+				{Size: ptr(3), FixedPrice: ptr(30000.0), Stock: 2, Active: ptr(false)},
+				{Size: ptr(4), Stock: 3},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := importSkus(current, tt.sizes, nil, tt.stock); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("importSkus = %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
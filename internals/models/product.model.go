@@ -5,112 +5,114 @@ import (
 	"fmt"
 	"log"
 	"mime/multipart"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/federus1105/koda-b4-backend/internals/pkg/libs"
+	"github.com/federus1105/koda-b4-backend/internals/pkg/utils"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/redis/go-redis/v9"
 )
 
 type Product struct {
-	Id          int               `json:"id"`
-	Name        string            `json:"name"`
-	Price       string            `json:"price"`
-	Description string            `json:"description"`
-	Stock       string            `json:"stock"`
-	Size        []string          `json:"size"`
-	Variant     []string          `json:"variant"`
-	Images      map[string]string `json:"images"`
-	Rating      float64           `json:"rating"`
+	Id          int      `json:"id"`
+	Name        string   `json:"name"`
+	Price       string   `json:"price"`
+	Description string   `json:"description"`
+	Stock       string   `json:"stock"`
+	Size        []string `json:"size"`
+	Variant     []string `json:"variant"`
+	Image       string   `json:"image"`
+	Rating      float64  `json:"rating"`
+	Snippet     string   `json:"snippet,omitempty"`
 }
 type CreateProducts struct {
-	Id             int                   `form:"id"`
-	Name           string                `form:"name" binding:"required"`
-	ImageId        int                   `form:"imageId"`
-	Image_one      *multipart.FileHeader `form:"image_one"`
-	Image_two      *multipart.FileHeader `form:"image_two"`
-	Image_three    *multipart.FileHeader `form:"image_three"`
-	Image_four     *multipart.FileHeader `form:"image_four"`
-	Image_oneStr   string                `form:"image_oneStr"`
-	Image_twoStr   string                `form:"image_twoStr,omitempty"`
-	Image_threeStr string                `form:"image_threeStr,omitempty"`
-	Image_fourStr  string                `form:"image_fourStr,omitempty"`
-	Price          float64               `form:"price" binding:"required,gte=5000"`
-	Rating         float64               `form:"rating" binding:"required,gte=1,lte=10"`
-	Description    string                `form:"description" binding:"required"`
-	Stock          int                   `form:"stock" binding:"gte=0"`
-	Size           []int                 `form:"size,omitempty" binding:"max=3,dive,gt=0,lte=3"`
-	Variant        []int                 `form:"variant,omitempty" binding:"max=2,dive,gt=0,lte=2"`
-	Category       []int                 `form:"category" binding:"required"`
+	Id          int                     `form:"id"`
+	Name        string                  `form:"name" binding:"required"`
+	Images      []*multipart.FileHeader `form:"images" binding:"max=10"`
+	AltText     []string                `form:"alt_text" binding:"max=10,dive,max=150"`
+	ImageItems  []NewProductImage       `form:"-"`
+	Gallery     []ProductImage          `form:"-"`
+	Price       float64                 `form:"price" binding:"required,gte=5000"`
+	Rating      float64                 `form:"-"`
+	Description string                  `form:"description" binding:"required"`
+	Stock       int                     `form:"stock" binding:"gte=0"`
+	Size        []int                   `form:"size,omitempty" binding:"max=3,dive,gt=0,lte=3"`
+	Variant     []int                   `form:"variant,omitempty" binding:"max=2,dive,gt=0,lte=2"`
+	Category    []int                   `form:"category" binding:"required"`
+	Skus        string                  `form:"skus"`
+	SkuItems    []SkuInput              `form:"-"`
 }
 
 type UpdateProducts struct {
-	Id             int                   `form:"id"`
-	Name           *string               `form:"name"`
-	Image_one      *multipart.FileHeader `form:"image_one"`
-	Image_two      *multipart.FileHeader `form:"image_two"`
-	Image_three    *multipart.FileHeader `form:"image_three"`
-	Image_four     *multipart.FileHeader `form:"image_four"`
-	Image_oneStr   *string               `form:"image_oneStr"`
-	Image_twoStr   *string               `form:"image_twoStr,omitempty"`
-	Image_threeStr *string               `form:"image_threeStr,omitempty"`
-	Image_fourStr  *string               `form:"image_fourStr,omitempty"`
-	Price          *float64              `form:"price" binding:"omitempty,gte=5000"`
-	Rating         *float64              `form:"rating" binding:"omitempty,gte=1,lte=10"`
-	Description    *string               `form:"description"`
-	Stock          *int                  `form:"stock" binding:"omitempty,gte=0"`
-	Size           []int                 `form:"size,omitempty" binding:"max=3,dive,gt=0,lte=3"`
-	Variant        []int                 `form:"variant,omitempty" binding:"max=2,dive,gt=0,lte=2"`
-	Category       []int                 `form:"category,omitempty"`
+	Id          int                     `form:"id"`
+	Name        *string                 `form:"name"`
+	Images      []*multipart.FileHeader `form:"images" binding:"max=10"`
+	AltText     []string                `form:"alt_text" binding:"max=10,dive,max=150"`
+	ImageItems  []NewProductImage       `form:"-"`
+	Price       *float64                `form:"price" binding:"omitempty,gte=5000"`
+	Description *string                 `form:"description"`
+	Stock       *int                    `form:"stock" binding:"omitempty,gte=0"`
+	Size        []int                   `form:"size,omitempty" binding:"max=3,dive,gt=0,lte=3"`
+	Variant     []int                   `form:"variant,omitempty" binding:"max=2,dive,gt=0,lte=2"`
+	Category    []int                   `form:"category,omitempty"`
+	Skus        string                  `form:"skus"`
+	SkuItems    []SkuInput              `form:"-"`
 }
 
 type ProductResponse struct {
-	ID          int               `json:"id"`
-	Name        string            `json:"name"`
-	ImageID     int               `json:"idImage"`
-	Images      map[string]string `json:"images,omitempty"`
-	Price       float64           `json:"price"`
-	Rating      float64           `json:"rating"`
-	Description string            `json:"description"`
-	Stock       int               `json:"stock"`
-	Size        []int             `json:"size,omitempty"`
-	Variant     []int             `json:"variant,omitempty"`
-	Category    []int             `json:"category"`
+	ID          int            `json:"id"`
+	Name        string         `json:"name"`
+	Images      []ProductImage `json:"images"`
+	Price       float64        `json:"price"`
+	Rating      float64        `json:"rating"`
+	Description string         `json:"description"`
+	Stock       int            `json:"stock"`
+	Size        []int          `json:"size,omitempty"`
+	Variant     []int          `json:"variant,omitempty"`
+	Category    []int          `json:"category"`
+	Skus        []ProductSku   `json:"skus"`
 }
 
-func GetListProduct(ctx context.Context, db *pgxpool.Pool, rd *redis.Client, name string, limit, offset int) ([]Product, error) {
+func GetListProduct(ctx context.Context, db *pgxpool.Pool, rd *redis.Client, name string, page Page) ([]Product, *libs.Cursor, error) {
 	// --- REDIS KEY ---
 	redisKey := fmt.Sprintf(
 		"list-product:name=%s:limit=%d:offset=%d",
 		strings.ToLower(name),
-		limit,
-		offset,
+		page.Limit,
+		page.Offset,
 	)
 
-	// --- GET CACHE ---
-	if cached, err := libs.GetFromCache[[]Product](ctx, rd, redisKey); err != nil {
-		log.Println("Redis Error:", err)
-	} else if cached != nil && len(*cached) > 0 {
-		log.Printf("Key %s found in cache Served in using Redis 👌", redisKey)
-		return *cached, nil
+	// --- GET CACHE, KEYSET PAGES READ THE INDEX DIRECTLY ---
+	if !page.Keyset {
+		if cached, err := libs.GetFromCache[[]Product](ctx, rd, redisKey); err != nil {
+			log.Println("Redis Error:", err)
+		} else if cached != nil && len(*cached) > 0 {
+			log.Printf("Key %s found in cache Served in using Redis 👌", redisKey)
+			return *cached, nil, nil
+		}
 	}
 
+	args := []interface{}{}
+	argIdx := 1
+	search := newProductSearch(name, argIdx)
+
 	sql := `SELECT
     p.id,
     p.name,
-    pi.photos_one,
-    pi.photos_two,
-    pi.photos_three,
-    pi.photos_four,
+    COALESCE(img.url, '') AS image,
     p.priceOriginal AS price,
     p.description,
     p.stock,
 	p.rating,
     COALESCE(ARRAY_AGG(DISTINCT s.name) FILTER (WHERE s.name IS NOT NULL), '{}') AS sizes,
-    COALESCE(ARRAY_AGG(DISTINCT v.name) FILTER (WHERE v.name IS NOT NULL), '{}') AS variants
-FROM product p
-LEFT JOIN product_images pi ON pi.id = p.id_product_images
+    COALESCE(ARRAY_AGG(DISTINCT v.name) FILTER (WHERE v.name IS NOT NULL), '{}') AS variants,
+    ` + search.snippet() + ` AS snippet,
+    (` + search.rank() + `)::float8 AS relevance,
+    p.createdat
+FROM product p` + primaryImageJoin("p.id") + `
 LEFT JOIN size_product sp ON sp.id_product = p.id
 LEFT JOIN sizes s ON s.id = sp.id_size
 LEFT JOIN variant_product vp ON vp.id_product = p.id
@@ -118,67 +120,71 @@ LEFT JOIN variants v ON v.id = vp.id_variant
 WHERE p.is_deleted = false
 `
 
-	args := []interface{}{}
-	argIdx := 1
-
 	// --- SEARCH ---
-	if strings.TrimSpace(name) != "" {
-		sql += fmt.Sprintf(" AND p.name ILIKE $%d", argIdx)
-		args = append(args, "%"+name+"%")
+	orderBy := "p.createdat ASC, p.id ASC"
+	if search != nil {
+		sql += " AND " + search.condition()
+		args = append(args, name)
 		argIdx++
+		orderBy = "relevance DESC, " + orderBy
+	}
+
+	// --- AFTER CURSOR, A SEARCH PAGES BY RELEVANCE ---
+	sortExpr, sortType, desc := "p.createdat", "timestamp", false
+	if search != nil && page.Keyset {
+		sortExpr, sortType, desc = "("+search.rank()+")::float8", "float8", true
+		orderBy = "relevance DESC, p.id DESC"
+	}
+	if condition, cursorArgs, next := page.afterCursor(sortExpr, "p.id", sortType, desc, argIdx); condition != "" {
+		sql += " AND " + condition
+		args = append(args, cursorArgs...)
+		argIdx = next
 	}
 
 	// --- GROUP BY & ORDER LIMIT OFFSET ---
+	limitSQL, limitArgs := page.limitClause(argIdx)
 	sql += fmt.Sprintf(`
-	GROUP BY p.id, p.name, pi.photos_one, pi.photos_two, pi.photos_three, pi.photos_four, p.priceOriginal, p.description, p.stock
-	ORDER BY p.createdat ASC
-	LIMIT $%d OFFSET $%d`, argIdx, argIdx+1)
-	args = append(args, limit, offset)
+	GROUP BY p.id, p.name, img.url, p.priceOriginal, p.description, p.stock
+	ORDER BY %s`, orderBy) + limitSQL
+	args = append(args, limitArgs...)
 
 	// --- EXECUTE QUERY ---
-	rows, err := db.Query(ctx, sql, args...)
+	q, done, err := beginProductSearch(ctx, db, search)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer done()
+	rows, err := q.Query(ctx, sql, args...)
 	if err != nil {
-		fmt.Println(err)
+		log.Println("Failed to query products:", err)
+		return nil, nil, err
 	}
 	defer rows.Close()
 
-	products := make([]Product, 0, limit)
+	products := make([]Product, 0, page.Limit+1)
+	keys := make([]string, 0, page.Limit+1)
 	for rows.Next() {
 		var (
 			id          int
 			name        string
-			photosOne   *string
-			photosTwo   *string
-			photosThree *string
-			photosFour  *string
+			image       string
 			price       float64
 			description string
 			stock       int
 			rating      float64
 			sizes       []string
 			variants    []string
+			snippet     string
+			relevance   float64
+			createdAt   time.Time
 		)
-		if err := rows.Scan(&id, &name, &photosOne, &photosTwo, &photosThree, &photosFour, &price, &description, &stock, &rating, &sizes, &variants); err != nil {
-			return nil, err
-		}
-
-		images := map[string]string{
-			"photos_one":   "",
-			"photos_two":   "",
-			"photos_three": "",
-			"photos_four":  "",
-		}
-		if photosOne != nil {
-			images["photos_one"] = *photosOne
+		if err := rows.Scan(&id, &name, &image, &price, &description, &stock, &rating, &sizes, &variants, &snippet, &relevance, &createdAt); err != nil {
+			return nil, nil, err
 		}
-		if photosTwo != nil {
-			images["photos_two"] = *photosTwo
-		}
-		if photosThree != nil {
-			images["photos_three"] = *photosThree
-		}
-		if photosFour != nil {
-			images["photos_four"] = *photosFour
+		if search != nil && page.Keyset {
+			keys = append(keys, strconv.FormatFloat(relevance, 'g', -1, 64))
+		} else {
+			keys = append(keys, timeKey(createdAt))
 		}
 
 		product := Product{
@@ -188,19 +194,31 @@ WHERE p.is_deleted = false
 			Description: description,
 			Stock:       fmt.Sprintf("%d", stock),
 			Rating:      rating,
-			Images:      images,
+			Image:       image,
 			Size:        sizes,
 			Variant:     variants,
+			Snippet:     snippet,
 		}
 
 		products = append(products, product)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	if page.Keyset {
+		items, next := keysetPage(page, "product", products, func(i int) (string, int) {
+			return keys[i], products[i].Id
+		})
+		return items, next, nil
+	}
+
 	// --- SAVING TO CACHE ---
 	if err := libs.SetToCache(ctx, rd, redisKey, products, 5*time.Minute); err != nil {
 		log.Println("Redis Error:", err)
 	}
 
-	return products, nil
+	return products, nil, nil
 }
 
 func CreateProduct(ctx context.Context, db *pgxpool.Pool, rd *redis.Client, body CreateProducts) (CreateProducts, error) {
@@ -212,28 +230,37 @@ func CreateProduct(ctx context.Context, db *pgxpool.Pool, rd *redis.Client, body
 	}
 	defer tx.Rollback(ctx)
 
-	// --- INSERT PRODUCT IMAGES ---
-	imageSQL := `
-        INSERT INTO product_images (photos_one, photos_two, photos_three, photos_four)
-        VALUES ($1, $2, $3, $4)
-        RETURNING id
-    `
-	var imageId int
-	if err := tx.QueryRow(ctx, imageSQL,
-		body.Image_oneStr,
-		body.Image_twoStr,
-		body.Image_threeStr,
-		body.Image_fourStr,
-	).Scan(&imageId); err != nil {
-		log.Println("Failed to insert product_images:", err)
+	newProduct, err := insertProduct(ctx, tx, body, "", nil)
+	if err != nil {
 		return CreateProducts{}, err
 	}
 
-	// --- INSERT PRODUCT
-	productSQL := `INSERT INTO product (name, description, rating, priceoriginal, stock, id_product_images) 
+	// --- COMMIT TRANSACTION ---
+	if err := tx.Commit(ctx); err != nil {
+		log.Println("Failed to commit transaction:", err)
+		return CreateProducts{}, err
+	}
+
+	// --- INVALIDATE ---
+	invalidateProductCaches(ctx, rd)
+
+	return newProduct, nil
+}
+
+// --- EMPTY slug IS DERIVED FROM THE NAME, sku IS THE OPTIONAL MERCHANT PRODUCT CODE ---
+func insertProduct(ctx context.Context, tx pgx.Tx, body CreateProducts, slug string, sku *string) (CreateProducts, error) {
+	var err error
+	if slug == "" {
+		if slug, err = uniqueProductSlug(ctx, tx, body.Name); err != nil {
+			return CreateProducts{}, err
+		}
+	}
+
+	// --- INSERT PRODUCT, RATING STARTS AT 0 AND FOLLOWS APPROVED REVIEWS ---
+	productSQL := `INSERT INTO product (name, description, priceoriginal, stock, slug, sku) 
 	VALUES ($1, $2, $3, $4, $5, $6)
-	RETURNING id, name, description, rating, priceoriginal, stock, id_product_images`
-	values := []any{body.Name, body.Description, body.Rating, body.Price, body.Stock, imageId}
+	RETURNING id, name, description, rating, priceoriginal, stock`
+	values := []any{body.Name, body.Description, body.Price, body.Stock, slug, sku}
 	var newProduct CreateProducts
 	if err := tx.QueryRow(ctx, productSQL, values...).Scan(
 		&newProduct.Id,
@@ -242,34 +269,18 @@ func CreateProduct(ctx context.Context, db *pgxpool.Pool, rd *redis.Client, body
 		&newProduct.Rating,
 		&newProduct.Price,
 		&newProduct.Stock,
-		&newProduct.ImageId,
 	); err != nil {
 		log.Println("Failed to insert product:", err)
 		return CreateProducts{}, err
 	}
 
-	// --- INSERT SIZE PRODUCT ---
-	if len(body.Size) > 0 {
-		sizeSQL := `INSERT INTO size_product (id_product, id_size) VALUES ($1, $2)`
-		for _, sizeID := range body.Size {
-			_, err := tx.Exec(ctx, sizeSQL, newProduct.Id, sizeID)
-			if err != nil {
-				log.Println("Failed to insert size product:", err)
-				return CreateProducts{}, err
-			}
-		}
+	// --- INSERT SKUS, WITHOUT skus EVERY SIZE x VARIANT GETS THE FORM STOCK ---
+	skus := body.SkuItems
+	if skus == nil {
+		skus = SkusFromOptions(body.Size, body.Variant, body.Stock)
 	}
-
-	// --- INSERT VARIANT PRODUCT ---
-	if len(body.Variant) > 0 {
-		VariantSQL := `INSERT INTO variant_product (id_product, id_variant) VALUES ($1, $2)`
-		for _, VariantID := range body.Variant {
-			_, err := tx.Exec(ctx, VariantSQL, newProduct.Id, VariantID)
-			if err != nil {
-				log.Println("Failed to insert variant product:", err)
-				return CreateProducts{}, err
-			}
-		}
+	if newProduct.Stock, err = replaceProductSkus(ctx, tx, newProduct.Id, skus); err != nil {
+		return CreateProducts{}, err
 	}
 
 	// --- INSERT TO CATEGORY PRODUCT ---
@@ -284,30 +295,21 @@ func CreateProduct(ctx context.Context, db *pgxpool.Pool, rd *redis.Client, body
 		}
 	}
 
-	// --- ASIGN IMAGE STR TO RETURN STRUCT RESPONSE---
-	newProduct.Image_oneStr = body.Image_oneStr
-	newProduct.Image_twoStr = body.Image_twoStr
-	newProduct.Image_threeStr = body.Image_threeStr
-	newProduct.Image_fourStr = body.Image_fourStr
-	newProduct.Size = body.Size
-	newProduct.Variant = body.Variant
-	newProduct.Category = body.Category
-
-	// --- COMMIT TRANSACTION ---
-	if err := tx.Commit(ctx); err != nil {
-		log.Println("Failed to commit transaction:", err)
+	// --- INSERT GALLERY, THE FIRST IMAGE IS PRIMARY ---
+	if err := addProductImages(ctx, tx, newProduct.Id, body.ImageItems, true); err != nil {
 		return CreateProducts{}, err
 	}
-
-	// --- INVALIDATE ---
-	if err := libs.InvalidateCacheByPattern(ctx, rd, "list-product*"); err != nil {
-		log.Println("Failed to invalidate product cache:", err)
+	if newProduct.Gallery, err = GetProductImages(ctx, tx, newProduct.Id); err != nil {
+		return CreateProducts{}, err
 	}
 
+	newProduct.Size, newProduct.Variant = skuOptionIDs(skus)
+	newProduct.Category = body.Category
+
 	return newProduct, nil
 }
 
-func EditProduct(ctx context.Context, db *pgxpool.Pool, rd *redis.Client, body UpdateProducts, images map[string]*string) (CreateProducts, error) {
+func EditProduct(ctx context.Context, db *pgxpool.Pool, rd *redis.Client, body UpdateProducts) (CreateProducts, error) {
 	// --- START QUERY TRANSACTION ---
 	tx, err := db.Begin(ctx)
 	if err != nil {
@@ -330,16 +332,6 @@ func EditProduct(ctx context.Context, db *pgxpool.Pool, rd *redis.Client, body U
 		args = append(args, *body.Price)
 		idx++
 	}
-	if body.Rating != nil {
-		setClauses = append(setClauses, fmt.Sprintf("rating=$%d", idx))
-		args = append(args, *body.Rating)
-		idx++
-	}
-	if body.Stock != nil {
-		setClauses = append(setClauses, fmt.Sprintf("stock=$%d", idx))
-		args = append(args, *body.Stock)
-		idx++
-	}
 	if body.Description != nil {
 		setClauses = append(setClauses, fmt.Sprintf("description=$%d", idx))
 		args = append(args, *body.Description)
@@ -355,71 +347,46 @@ func EditProduct(ctx context.Context, db *pgxpool.Pool, rd *redis.Client, body U
 		}
 	}
 
-	// --- TAKE THE ID FIRST ---
-	var imageId int
-	err = tx.QueryRow(ctx, "SELECT id_product_images FROM product WHERE id=$1", body.Id).Scan(&imageId)
-	if err != nil {
+	// --- NEW IMAGES ARE APPENDED TO THE GALLERY ---
+	if err := lockGalleryProduct(ctx, tx, body.Id); err != nil {
 		return CreateProducts{}, err
 	}
-
-	// --- HANDLE IMAGES ---
-	for key, val := range images {
-		var column string
-		switch key {
-		case "photos_one":
-			column = "photos_one"
-		case "photos_two":
-			column = "photos_two"
-		case "photos_three":
-			column = "photos_three"
-		case "photos_four":
-			column = "photos_four"
-		default:
-			continue
-		}
-
-		if val != nil {
-			imgQuery := fmt.Sprintf("UPDATE product_images SET %s=$1 WHERE id=$2", column)
-			_, err := tx.Exec(ctx, imgQuery, *val, imageId)
-			if err != nil {
-				return CreateProducts{}, err
-			}
-		}
+	if err := addProductImages(ctx, tx, body.Id, body.ImageItems, false); err != nil {
+		return CreateProducts{}, err
 	}
 
-	// --- HANDLE SIZE ---
-	if len(body.Size) > 0 {
-		_, err := tx.Exec(ctx, "DELETE FROM size_product WHERE id_product=$1", body.Id)
+	// --- HANDLE SKUS, SIZE AND VARIANT ---
+	skus := body.SkuItems
+	if skus == nil && (len(body.Size) > 0 || len(body.Variant) > 0) {
+		current, err := GetProductSkus(ctx, tx, body.Id, false)
 		if err != nil {
 			return CreateProducts{}, err
 		}
-
-		for _, sizeID := range body.Size {
-			if sizeID > 0 && sizeID <= 3 {
-				_, err := tx.Exec(ctx, "INSERT INTO size_product (id_product, id_size) VALUES ($1, $2)", body.Id, sizeID)
-				if err != nil {
-					return CreateProducts{}, err
-				}
-			}
+		stock := 0
+		if body.Stock != nil {
+			stock = *body.Stock
+		}
+		skus = regenerateSkus(current, body.Size, body.Variant, stock)
+	}
+	if skus == nil && body.Price != nil {
+		// --- A LOWER PRODUCT PRICE MUST KEEP EVERY SKU WITHOUT FIXED PRICE ABOVE 0 ---
+		var tooLow bool
+		if err := tx.QueryRow(ctx, `
+			SELECT EXISTS (SELECT 1 FROM product_skus WHERE id_product = $1 AND price IS NULL AND $2 + price_delta <= 0)
+		`, body.Id, *body.Price).Scan(&tooLow); err != nil {
+			return CreateProducts{}, err
+		}
+		if tooLow {
+			return CreateProducts{}, utils.ValidationError{Field: "price", Message: "would bring the price of a sku to 0 or below"}
 		}
 	}
-
-	// --- HANDLE VARIANT ---
-	if len(body.Variant) > 0 {
-		// Hapus semua variant lama
-		_, err := tx.Exec(ctx, "DELETE FROM variant_product WHERE id_product=$1", body.Id)
-		if err != nil {
+	if skus != nil {
+		if _, err := replaceProductSkus(ctx, tx, body.Id, skus); err != nil {
 			return CreateProducts{}, err
 		}
-
-		// Insert variant baru
-		for _, variantID := range body.Variant {
-			if variantID > 0 && variantID <= 2 {
-				_, err := tx.Exec(ctx, "INSERT INTO variant_product (id_product, id_variant) VALUES ($1, $2)", body.Id, variantID)
-				if err != nil {
-					return CreateProducts{}, err
-				}
-			}
+	} else if body.Stock != nil {
+		if err := setSingleSkuStock(ctx, tx, body.Id, *body.Stock); err != nil {
+			return CreateProducts{}, err
 		}
 	}
 
@@ -442,13 +409,8 @@ func EditProduct(ctx context.Context, db *pgxpool.Pool, rd *redis.Client, body U
 	// ----- GET DATA  ----
 	var product CreateProducts
 	err = tx.QueryRow(ctx, `
-    SELECT p.id, name, p.description, p.rating, p.priceoriginal, p.stock, p.id_product_images,
-       COALESCE(pi.photos_one, '') AS photos_one,
-       COALESCE(pi.photos_two, '') AS photos_two,
-       COALESCE(pi.photos_three, '') AS photos_three,
-       COALESCE(pi.photos_four, '') AS photos_four
+    SELECT p.id, name, p.description, p.rating, p.priceoriginal, p.stock
 	FROM product p
-	JOIN product_images pi ON p.id_product_images = pi.id
 	WHERE p.id=$1
 `, body.Id).Scan(
 		&product.Id,
@@ -457,15 +419,13 @@ func EditProduct(ctx context.Context, db *pgxpool.Pool, rd *redis.Client, body U
 		&product.Rating,
 		&product.Price,
 		&product.Stock,
-		&product.ImageId,
-		&product.Image_oneStr,
-		&product.Image_twoStr,
-		&product.Image_threeStr,
-		&product.Image_fourStr,
 	)
 	if err != nil {
 		return CreateProducts{}, err
 	}
+	if product.Gallery, err = GetProductImages(ctx, tx, body.Id); err != nil {
+		return CreateProducts{}, err
+	}
 
 	// --- GET SIZE LIST ---
 	rowsSize, err := tx.Query(ctx, `
@@ -529,29 +489,17 @@ func EditProduct(ctx context.Context, db *pgxpool.Pool, rd *redis.Client, body U
 	}
 	product.Category = categoryIDs
 
-	// --- ASIGN IMAGE STR TO RETURN STRUCT RESPONSE---
-	if body.Image_oneStr != nil {
-		product.Image_oneStr = *body.Image_oneStr
-	}
-	if body.Image_twoStr != nil {
-		product.Image_twoStr = *body.Image_twoStr
-	}
-
-	if body.Image_threeStr != nil {
-		product.Image_threeStr = *body.Image_threeStr
-	}
-	if body.Image_fourStr != nil {
-		product.Image_fourStr = *body.Image_fourStr
-	}
-
 	err = tx.Commit(ctx)
 	if err != nil {
 		log.Println("Failed to commit transaction:", err)
 		return CreateProducts{}, err
 	}
 	// --- INVALIDATE ---
-	if err := libs.InvalidateCacheByPattern(ctx, rd, "list-product*"); err != nil {
-		log.Println("Failed to invalidate product cache:", err)
+	invalidateProductCaches(ctx, rd)
+
+	// --- PERCENTAGE FLASH SALE PRICE FOLLOWS THE NEW PRICE ---
+	if body.Price != nil {
+		wakeFlashSaleScheduler()
 	}
 
 	return product, nil
@@ -579,9 +527,7 @@ func DeleteProduct(ctx context.Context, db *pgxpool.Pool, rd *redis.Client, id i
 	}
 
 	// --- INVALIDATE ---
-	if err := libs.InvalidateCacheByPattern(ctx, rd, "list-product*"); err != nil {
-		log.Println("Failed to invalidate product cache:", err)
-	}
+	invalidateProductCaches(ctx, rd)
 
 	log.Printf("product with id %d successfully deleted", id)
 	return nil
@@ -590,19 +536,91 @@ func DeleteProduct(ctx context.Context, db *pgxpool.Pool, rd *redis.Client, id i
 func GetCountProduct(ctx context.Context, db *pgxpool.Pool, name string) (int64, error) {
 	var total int64
 
-	query := "SELECT COUNT(*) FROM product WHERE is_deleted = false"
+	query := "SELECT COUNT(*) FROM product p WHERE p.is_deleted = false"
 	args := []interface{}{}
 
-	if name != "" {
-		query += " AND name ILIKE $1"
-		args = append(args, "%"+name+"%")
+	search := newProductSearch(name, 1)
+	if search != nil {
+		query += " AND " + search.condition()
+		args = append(args, name)
 	}
 
 	// --- EXECUTE QUERY ---
-	err := db.QueryRow(ctx, query, args...).Scan(&total)
+	q, done, err := beginProductSearch(ctx, db, search)
+	if err != nil {
+		return 0, err
+	}
+	defer done()
+	err = q.QueryRow(ctx, query, args...).Scan(&total)
 	if err != nil {
 		return 0, err
 	}
 
 	return total, nil
 }
+
+// --- PRODUCT LISTS, FILTER PAGES AND FACETS (SKU STOCK, PRICE, RATING, FLASH SALE) ---
+func invalidateProductCaches(ctx context.Context, rd *redis.Client) {
+	for _, pattern := range []string{"list-product*", "product_filter:*"} {
+		if err := libs.InvalidateCacheByPattern(ctx, rd, pattern); err != nil {
+			log.Println("Redis Error:", err)
+		}
+	}
+}
+
+// --- SIZE AND VARIANT FACETS COUNT PRODUCTS WITH STOCK, A SALE CAN EMPTY A SKU ---
+func invalidateFacetCaches(ctx context.Context, rd *redis.Client) {
+	if err := libs.InvalidateCacheByPattern(ctx, rd, "product_filter:facets:*"); err != nil {
+		log.Println("Redis Error:", err)
+	}
+}
+
+// --- LOWERCASE ASCII LETTERS AND DIGITS JOINED BY "-", SAME RULE AS THE SLUG MIGRATION ---
+func Slugify(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		dash = true
+	}
+	if b.Len() == 0 {
+		return "product"
+	}
+	slug := b.String()
+	if len(slug) > 100 {
+		slug = strings.TrimRight(slug[:100], "-")
+	}
+	return slug
+}
+
+// --- SLUG OF THE NAME, "-2", "-3" ... APPENDED WHILE ANOTHER PRODUCT HAS IT ---
+func uniqueProductSlug(ctx context.Context, tx pgx.Tx, name string) (string, error) {
+	base := Slugify(name)
+	rows, err := tx.Query(ctx, `
+		SELECT slug FROM product
+		WHERE is_deleted = false AND (slug = $1 OR slug LIKE $1 || '-%')`, base)
+	if err != nil {
+		return "", err
+	}
+	taken, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return "", err
+	}
+
+	used := map[string]bool{}
+	for _, slug := range taken {
+		used[slug] = true
+	}
+	slug := base
+	for n := 2; used[slug]; n++ {
+		slug = fmt.Sprintf("%s-%d", base, n)
+	}
+	return slug, nil
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"mime/multipart"
@@ -25,18 +26,19 @@ type ProfileUpdate struct {
 
 type ReqUpdatePassword struct {
 	OldPassword     string `json:"old_password"  binding:"required"`
-	NewPassword     string `json:"new_password"  binding:"required,password_complex"`
+	NewPassword     string `json:"new_password"  binding:"required,password_length,password_complex,password_breached"`
 	ConfirmPassword string `json:"confirm_password" binding:"required,eqfield=NewPassword"`
 }
 
 type Profiles struct {
-	Id        int       `json:"id"`
-	Fullname  string    `json:"fullname"`
-	Phone     *string   `json:"phone"`
-	Address   *string   `json:"address"`
-	Photos    *string   `json:"photos"`
-	Email     string    `json:"email"`
-	CreatedAt time.Time `json:"created_at"`
+	Id           int       `json:"id"`
+	Fullname     string    `json:"fullname"`
+	Phone        *string   `json:"phone"`
+	Address      *string   `json:"address"`
+	Photos       *string   `json:"photos"`
+	Email        string    `json:"email"`
+	PendingEmail *string   `json:"pending_email"`
+	CreatedAt    time.Time `json:"created_at"`
 }
 
 func UpdateProfile(ctx context.Context, db *pgxpool.Pool, input ProfileUpdate, Id int) (ProfileUpdate, error) {
@@ -86,15 +88,7 @@ func UpdateProfile(ctx context.Context, db *pgxpool.Pool, input ProfileUpdate, I
 		}
 	}
 
-	// --- UPDATE EMAIL DI USERS TABLE ---
-	if input.Email != nil {
-		queryEmail := `UPDATE users SET email=$1 WHERE id=$2`
-		_, err := tx.Exec(ctx, queryEmail, *input.Email, Id)
-		if err != nil {
-			log.Println("Failed to update email:", err)
-			return ProfileUpdate{}, err
-		}
-	}
+	// --- EMAIL IS NOT UPDATED HERE, IT STAYS PENDING UNTIL CONFIRMED (SEE RequestEmailChange) ---
 
 	// --- GET UPDATED DATA ---
 	var updated ProfileUpdate
@@ -150,6 +144,15 @@ func UpdatePassword(ctx context.Context, db *pgxpool.Pool, userID int, oldPasswo
 		return fmt.Errorf("%w the old password doesn't match", utils.ErrValidation)
 	}
 
+	// --- NEW PASSWORD CAN'T BE ONE OF THE RECENT PASSWORD ---
+	if err := CheckPasswordReuse(ctx, db, userID, newPassword); err != nil {
+		if errors.Is(err, ErrPasswordReused) {
+			return fmt.Errorf("%w %w", utils.ErrValidation, err)
+		}
+		log.Println("Failed to check password history:", err)
+		return err
+	}
+
 	// --- HASH NEW PASSWORD ---
 	newHashed, err := libs.HashPassword(newPassword)
 	if err != nil {
@@ -158,14 +161,12 @@ func UpdatePassword(ctx context.Context, db *pgxpool.Pool, userID int, oldPasswo
 	}
 
 	// --- UPDATE PASSWORD ---
-	cmdTag, err := db.Exec(ctx, "UPDATE users SET password = $1 WHERE id = $2", newHashed, userID)
-	if err != nil {
-		log.Println("Failed to update password:", err)
+	if err := UpdatePasswordByID(ctx, db, userID, newHashed); err != nil {
+		if strings.Contains(err.Error(), "user not found") {
+			return fmt.Errorf(" %w failed update password, user not found", utils.ErrValidation)
+		}
 		return fmt.Errorf("failed update password")
 	}
-	if cmdTag.RowsAffected() != 1 {
-		return fmt.Errorf(" %w failed update password, user not found", utils.ErrValidation)
-	}
 	return nil
 }
 
@@ -173,7 +174,7 @@ func Profile(ctx context.Context, db *pgxpool.Pool, userId int) (Profiles, error
 	var profile Profiles
 	sql := `SELECT a.id, a.fullname, 
 	a.phonenumber, a.address, 
-	a.photos, u.email,
+	a.photos, u.email, u.pending_email,
 	a.createdat FROM account a
 	JOIN users u ON u.id = a.id_users
 	WHERE u.id = $1`
@@ -185,6 +186,7 @@ func Profile(ctx context.Context, db *pgxpool.Pool, userId int) (Profiles, error
 		&profile.Address,
 		&profile.Photos,
 		&profile.Email,
+		&profile.PendingEmail,
 		&profile.CreatedAt,
 	)
 
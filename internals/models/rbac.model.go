@@ -0,0 +1,279 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/federus1105/koda-b4-backend/internals/pkg/libs"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+)
+
+// --- REDIS KEY PREFIX ---
+const rolePermissionPrefix = "rbac:role:"
+
+const rolePermissionTTL = 10 * time.Minute
+
+var (
+	ErrRoleNotFound       = errors.New("role not found")
+	ErrRoleExists         = errors.New("role already exists")
+	ErrRoleSystem         = errors.New("system role can't be changed")
+	ErrRoleInUse          = errors.New("role is still assigned to users")
+	ErrPermissionNotFound = errors.New("permission not found")
+)
+
+type Role struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	IsSystem    bool     `json:"is_system"`
+	Users       int      `json:"users"`
+	Permissions []string `json:"permissions"`
+}
+
+type Permission struct {
+	Code        string `json:"code"`
+	Description string `json:"description"`
+}
+
+type ReqCreateRole struct {
+	Name        string   `json:"name" binding:"required,min=3,max=30,lowercase,alphanum"`
+	Description string   `json:"description" binding:"max=255"`
+	Permissions []string `json:"permissions" binding:"dive,required"`
+}
+
+type ReqUpdateRole struct {
+	Description *string  `json:"description" binding:"omitempty,max=255"`
+	Permissions []string `json:"permissions" binding:"required,dive,required"`
+}
+
+type ReqAssignRole struct {
+	Role string `json:"role" binding:"required,max=30"`
+}
+
+// --- PERMISSION OF ROLE, CACHED IN REDIS ---
+func GetRolePermissions(ctx context.Context, db *pgxpool.Pool, rdb *redis.Client, role string) ([]string, error) {
+	key := rolePermissionPrefix + role
+	cached, err := libs.GetFromCache[[]string](ctx, rdb, key)
+	if err != nil {
+		log.Println("Redis Error.\nCause: ", err)
+	}
+	if cached != nil {
+		return *cached, nil
+	}
+
+	rows, err := db.Query(ctx, `SELECT permission FROM role_permissions WHERE role = $1 ORDER BY permission`, role)
+	if err != nil {
+		return nil, err
+	}
+	permissions, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, err
+	}
+	if permissions == nil {
+		permissions = []string{}
+	}
+
+	if err := libs.SetToCache(ctx, rdb, key, permissions, rolePermissionTTL); err != nil {
+		log.Println("Redis Error.\nCause: ", err)
+	}
+	return permissions, nil
+}
+
+func invalidateRolePermissions(ctx context.Context, rdb *redis.Client, role string) {
+	if err := rdb.Del(ctx, rolePermissionPrefix+role).Err(); err != nil {
+		log.Println("Redis Error.\nCause: ", err)
+	}
+}
+
+// --- LIST ROLE WITH PERMISSION AND NUMBER OF USER ---
+func GetRoles(ctx context.Context, db *pgxpool.Pool) ([]Role, error) {
+	rows, err := db.Query(ctx, `
+		SELECT r.name, COALESCE(r.description, ''), r.is_system,
+			(SELECT COUNT(*) FROM users u WHERE u.role = r.name),
+			COALESCE(ARRAY_AGG(rp.permission ORDER BY rp.permission) FILTER (WHERE rp.permission IS NOT NULL), '{}')
+		FROM roles r
+		LEFT JOIN role_permissions rp ON rp.role = r.name
+		GROUP BY r.name
+		ORDER BY r.is_system DESC, r.name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := []Role{}
+	for rows.Next() {
+		var role Role
+		if err := rows.Scan(&role.Name, &role.Description, &role.IsSystem, &role.Users, &role.Permissions); err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+	return roles, rows.Err()
+}
+
+func GetPermissions(ctx context.Context, db *pgxpool.Pool) ([]Permission, error) {
+	rows, err := db.Query(ctx, `SELECT code, COALESCE(description, '') FROM permissions ORDER BY code`)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByPos[Permission])
+}
+
+// --- CREATE ROLE ---
+func CreateRole(ctx context.Context, db *pgxpool.Pool, req ReqCreateRole) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		log.Println("Failed to begin transaction : ", err)
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx,
+		`INSERT INTO roles (name, description) VALUES ($1, NULLIF($2, '')) ON CONFLICT (name) DO NOTHING`,
+		req.Name, req.Description)
+	if err != nil {
+		log.Println("Failed to insert role :", err)
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrRoleExists
+	}
+
+	if err := replaceRolePermissions(ctx, tx, req.Name, req.Permissions); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `INSERT INTO role_settings (role) VALUES ($1) ON CONFLICT (role) DO NOTHING`, req.Name); err != nil {
+		log.Println("Failed to insert role settings :", err)
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// --- UPDATE DESCRIPTION AND REPLACE PERMISSION OF ROLE ---
+func UpdateRole(ctx context.Context, db *pgxpool.Pool, rdb *redis.Client, name string, req ReqUpdateRole) error {
+	// --- ADMIN ALWAYS KEEP ALL PERMISSION, NOBODY CAN LOCK THEMSELVES OUT ---
+	if name == "admin" {
+		return ErrRoleSystem
+	}
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		log.Println("Failed to begin transaction : ", err)
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx,
+		`UPDATE roles SET description = COALESCE($2, description), updated_at = NOW() WHERE name = $1`,
+		name, req.Description)
+	if err != nil {
+		log.Println("Failed to update role :", err)
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrRoleNotFound
+	}
+
+	if err := replaceRolePermissions(ctx, tx, name, req.Permissions); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	invalidateRolePermissions(ctx, rdb, name)
+	return nil
+}
+
+func replaceRolePermissions(ctx context.Context, tx pgx.Tx, role string, permissions []string) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM role_permissions WHERE role = $1`, role); err != nil {
+		log.Println("Failed to delete role permissions :", err)
+		return err
+	}
+	if len(permissions) == 0 {
+		return nil
+	}
+
+	// --- ALL CODE MUST EXIST ---
+	unique := slices.Compact(slices.Sorted(slices.Values(permissions)))
+	var known int
+	if err := tx.QueryRow(ctx,
+		`SELECT COUNT(*) FROM permissions WHERE code = ANY($1)`, unique).Scan(&known); err != nil {
+		return err
+	}
+	if known != len(unique) {
+		return ErrPermissionNotFound
+	}
+
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO role_permissions (role, permission) SELECT $1, UNNEST($2::TEXT[])`,
+		role, unique); err != nil {
+		log.Println("Failed to insert role permissions :", err)
+		return err
+	}
+	return nil
+}
+
+// --- DELETE CUSTOM ROLE THAT IS NOT ASSIGNED ---
+func DeleteRole(ctx context.Context, db *pgxpool.Pool, rdb *redis.Client, name string) error {
+	var isSystem bool
+	var users int
+	err := db.QueryRow(ctx,
+		`SELECT is_system, (SELECT COUNT(*) FROM users WHERE role = $1) FROM roles WHERE name = $1`,
+		name).Scan(&isSystem, &users)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return ErrRoleNotFound
+		}
+		return err
+	}
+	if isSystem {
+		return ErrRoleSystem
+	}
+	if users > 0 {
+		return ErrRoleInUse
+	}
+
+	if _, err := db.Exec(ctx, `DELETE FROM roles WHERE name = $1`, name); err != nil {
+		log.Println("Failed to delete role :", err)
+		return err
+	}
+	invalidateRolePermissions(ctx, rdb, name)
+	return nil
+}
+
+func RoleExists(ctx context.Context, db *pgxpool.Pool, name string) (bool, error) {
+	var exists bool
+	err := db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM roles WHERE name = $1)`, name).Scan(&exists)
+	return exists, err
+}
+
+// --- ASSIGN ROLE TO USER ---
+func AssignRole(ctx context.Context, db *pgxpool.Pool, rdb *redis.Client, userID int, role string) error {
+	exists, err := RoleExists(ctx, db, role)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrRoleNotFound
+	}
+
+	result, err := db.Exec(ctx, `UPDATE users SET role = $1 WHERE id = $2`, role, userID)
+	if err != nil {
+		log.Println("Failed to assign role :", err)
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New("user not found")
+	}
+	if err := invalidateUserAPIKeys(ctx, db, rdb, userID); err != nil {
+		log.Println("Failed to invalidate api keys :", err)
+	}
+	return nil
+}
@@ -11,12 +11,14 @@ type ResponseSucces struct {
 	Result  any    `json:"result"`
 }
 
+// --- IN KEYSET MODE page IS LEFT OUT AND nextCursor IS SET, total AND totalPages ONLY WHEN COUNTED ---
 type PaginatedResponse[T any] struct {
-	Page       int     `json:"page"`
+	Page       *int    `json:"page,omitempty"`
 	Limit      int     `json:"limit"`
-	Total      int64   `json:"total"`
-	TotalPages int     `json:"totalPages"`
+	Total      *int64  `json:"total,omitempty"`
+	TotalPages *int    `json:"totalPages,omitempty"`
 	PrevURL    *string `json:"prevUrl"`
 	NextURL    *string `json:"nextUrl"`
+	NextCursor *string `json:"nextCursor,omitempty"`
 	Result     []T     `json:"data"`
 }
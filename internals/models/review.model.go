@@ -0,0 +1,246 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"log"
+	"mime/multipart"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+)
+
+const ReviewPageSize = 10
+
+// --- ORDER STATUS THAT COUNTS AS A PURCHASE ---
+const reviewOrderStatus = "done"
+
+var (
+	ErrReviewNotFound     = errors.New("review not found")
+	ErrReviewNotPurchased = errors.New("only customers with a completed order of this product can review it")
+	ErrReviewExists       = errors.New("you already reviewed this product")
+	ErrReviewOwnVote      = errors.New("you can't vote on your own review")
+	ErrReviewProduct      = errors.New("product not found")
+)
+
+type Review struct {
+	Id        int       `json:"id"`
+	ProductId int       `json:"product_id"`
+	Author    string    `json:"author"`
+	Rating    int       `json:"rating"`
+	Content   string    `json:"content"`
+	Photos    []string  `json:"photos"`
+	Helpful   int       `json:"helpful"`
+	Voted     bool      `json:"voted"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ReqCreateReview struct {
+	Rating    int                     `form:"rating" binding:"required,gte=1,lte=5"`
+	Content   string                  `form:"content" binding:"max=1000"`
+	Photos    []*multipart.FileHeader `form:"photos" binding:"max=4"`
+	PhotoStrs []string                `form:"-"`
+}
+
+type ReqModerateReview struct {
+	Status string `json:"status" binding:"required,oneof=approved rejected"`
+}
+
+// --- QueryRow IS SHARED BY *pgxpool.Pool AND pgx.Tx ---
+type reviewQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// --- CHECKED BEFORE THE PHOTOS ARE UPLOADED, CreateReview CHECKS AGAIN IN ITS TRANSACTION ---
+func CanReview(ctx context.Context, db *pgxpool.Pool, userID, productID int) error {
+	return checkCanReview(ctx, db, userID, productID)
+}
+
+func checkCanReview(ctx context.Context, q reviewQuerier, userID, productID int) error {
+	var exists, purchased, reviewed bool
+	err := q.QueryRow(ctx, `
+		SELECT
+			EXISTS (SELECT 1 FROM product WHERE id = $1 AND is_deleted = false),
+			EXISTS (
+				SELECT 1
+				FROM product_orders po
+				JOIN orders o ON o.id = po.id_order
+				JOIN status s ON s.id = o.id_status
+				WHERE po.id_product = $1 AND o.id_account = $2 AND s.name = $3
+			),
+			EXISTS (SELECT 1 FROM product_reviews WHERE id_product = $1 AND id_users = $2)
+	`, productID, userID, reviewOrderStatus).Scan(&exists, &purchased, &reviewed)
+	if err != nil {
+		log.Println("Failed to check review eligibility:", err)
+		return err
+	}
+	if !exists {
+		return ErrReviewProduct
+	}
+	if !purchased {
+		return ErrReviewNotPurchased
+	}
+	if reviewed {
+		return ErrReviewExists
+	}
+	return nil
+}
+
+// --- REVIEW IS PENDING UNTIL A MODERATOR APPROVES IT, ONE PER CUSTOMER AND PRODUCT ---
+func CreateReview(ctx context.Context, db *pgxpool.Pool, userID, productID int, req ReqCreateReview) (Review, error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		log.Println("Failed to begin transaction:", err)
+		return Review{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := checkCanReview(ctx, tx, userID, productID); err != nil {
+		return Review{}, err
+	}
+
+	review := Review{ProductId: productID, Rating: req.Rating, Content: req.Content, Photos: []string{}}
+	err = tx.QueryRow(ctx, `
+		INSERT INTO product_reviews (id_product, id_users, rating, content)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (id_product, id_users) DO NOTHING
+		RETURNING id, status, created_at
+	`, productID, userID, req.Rating, req.Content).Scan(&review.Id, &review.Status, &review.CreatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return Review{}, ErrReviewExists
+		}
+		log.Println("Failed to insert review:", err)
+		return Review{}, err
+	}
+
+	for _, photo := range req.PhotoStrs {
+		if _, err := tx.Exec(ctx, `INSERT INTO product_review_photos (id_review, photo) VALUES ($1, $2)`, review.Id, photo); err != nil {
+			log.Println("Failed to insert review photo:", err)
+			return Review{}, err
+		}
+		review.Photos = append(review.Photos, photo)
+	}
+
+	if err := tx.QueryRow(ctx, `SELECT COALESCE(a.fullname, '') FROM account a WHERE a.id_users = $1`, userID).Scan(&review.Author); err != nil && err != pgx.ErrNoRows {
+		return Review{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Println("Failed to commit transaction:", err)
+		return Review{}, err
+	}
+	return review, nil
+}
+
+// --- APPROVED REVIEWS OF A PRODUCT, sort "helpful" OR NEWEST FIRST, viewerID 0 FOR A GUEST ---
+func GetProductReviews(ctx context.Context, db *pgxpool.Pool, productID, viewerID int, sort string, page int) ([]Review, error) {
+	orderBy := "r.created_at DESC, r.id DESC"
+	if sort == "helpful" {
+		orderBy = "r.helpful_count DESC, " + orderBy
+	}
+	return queryReviews(ctx, db, `
+		WHERE r.id_product = $1 AND r.status = 'approved'
+		ORDER BY `+orderBy+`
+		LIMIT $3 OFFSET $4`, productID, viewerID, ReviewPageSize, (page-1)*ReviewPageSize)
+}
+
+// --- MODERATION QUEUE, OLDEST FIRST SO NOTHING WAITS FOREVER ---
+func GetReviewsByStatus(ctx context.Context, db *pgxpool.Pool, status string, page int) ([]Review, error) {
+	return queryReviews(ctx, db, `
+		WHERE r.status = $1
+		ORDER BY r.created_at ASC, r.id ASC
+		LIMIT $3 OFFSET $4`, status, 0, ReviewPageSize, (page-1)*ReviewPageSize)
+}
+
+// --- REVIEWS WRITTEN BY ONE USER, FOR DATA EXPORT ---
+func GetUserReviews(ctx context.Context, db *pgxpool.Pool, userID int) ([]Review, error) {
+	return queryReviews(ctx, db, `
+		WHERE r.id_users = $1
+		ORDER BY r.created_at DESC`, userID, userID)
+}
+
+// --- $1 IS FILTERED BY where, $2 IS THE VIEWER FOR voted ---
+func queryReviews(ctx context.Context, db *pgxpool.Pool, where string, args ...any) ([]Review, error) {
+	rows, err := db.Query(ctx, `
+		SELECT r.id, r.id_product, COALESCE(a.fullname, ''), r.rating, r.content,
+			COALESCE((SELECT array_agg(rp.photo ORDER BY rp.id) FROM product_review_photos rp WHERE rp.id_review = r.id), '{}'),
+			r.helpful_count,
+			EXISTS (SELECT 1 FROM product_review_votes rv WHERE rv.id_review = r.id AND rv.id_users = $2),
+			r.status, r.created_at
+		FROM product_reviews r
+		LEFT JOIN account a ON a.id_users = r.id_users
+		`+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	reviews, err := pgx.CollectRows(rows, pgx.RowToStructByPos[Review])
+	if reviews == nil {
+		reviews = []Review{}
+	}
+	return reviews, err
+}
+
+// --- APPROVE OR REJECT, PRODUCT RATING IS RECOMPUTED BY TRIGGER ---
+func ModerateReview(ctx context.Context, db *pgxpool.Pool, rd *redis.Client, reviewID, moderatorID int, status string) error {
+	result, err := db.Exec(ctx, `
+		UPDATE product_reviews SET status = $2, moderated_by = $3, moderated_at = NOW(), updated_at = NOW()
+		WHERE id = $1
+	`, reviewID, status, moderatorID)
+	if err != nil {
+		log.Println("Failed to moderate review:", err)
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrReviewNotFound
+	}
+	invalidateProductCaches(ctx, rd)
+	return nil
+}
+
+func DeleteReview(ctx context.Context, db *pgxpool.Pool, rd *redis.Client, reviewID int) error {
+	result, err := db.Exec(ctx, `DELETE FROM product_reviews WHERE id = $1`, reviewID)
+	if err != nil {
+		log.Println("Failed to delete review:", err)
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrReviewNotFound
+	}
+	invalidateProductCaches(ctx, rd)
+	return nil
+}
+
+// --- MARK AN APPROVED REVIEW HELPFUL, VOTING TWICE IS A NO-OP ---
+func VoteReview(ctx context.Context, db *pgxpool.Pool, reviewID, userID int, helpful bool) (int, error) {
+	var authorID int
+	err := db.QueryRow(ctx, `SELECT id_users FROM product_reviews WHERE id = $1 AND status = 'approved'`, reviewID).Scan(&authorID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return 0, ErrReviewNotFound
+		}
+		return 0, err
+	}
+	if authorID == userID {
+		return 0, ErrReviewOwnVote
+	}
+
+	if helpful {
+		_, err = db.Exec(ctx, `INSERT INTO product_review_votes (id_review, id_users) VALUES ($1, $2) ON CONFLICT DO NOTHING`, reviewID, userID)
+	} else {
+		_, err = db.Exec(ctx, `DELETE FROM product_review_votes WHERE id_review = $1 AND id_users = $2`, reviewID, userID)
+	}
+	if err != nil {
+		log.Println("Failed to vote review:", err)
+		return 0, err
+	}
+
+	var count int
+	if err := db.QueryRow(ctx, `SELECT helpful_count FROM product_reviews WHERE id = $1`, reviewID).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// --- LOWEST word_similarity THAT STILL COUNTS AS A MATCH, e.g. "capucino" AGAINST "Cappuccino" ---
+const searchSimilarity = 0.3
+
+// --- FULL-TEXT ON NAME, DESCRIPTION AND CATEGORY (product.search_vector) PLUS TRIGRAM SIMILARITY ON NAME FOR TYPOS ---
+type productSearch struct {
+	param string
+}
+
+// --- NIL WHEN THERE IS NOTHING TO SEARCH, argIdx IS THE PLACEHOLDER OF THE TERM ---
+func newProductSearch(term string, argIdx int) *productSearch {
+	if strings.TrimSpace(term) == "" {
+		return nil
+	}
+	return &productSearch{param: fmt.Sprintf("$%d::text", argIdx)}
+}
+
+func (s *productSearch) query() string {
+	return fmt.Sprintf("websearch_to_tsquery('simple', %s)", s.param)
+}
+
+// --- SUBSTRING MATCH IS KEPT SO NOTHING THE OLD ILIKE SEARCH FOUND GOES MISSING ---
+// <% uses product_name_trgm_idx, its threshold is set by beginProductSearch.
+func (s *productSearch) condition() string {
+	return fmt.Sprintf(`(p.search_vector @@ %[1]s
+		OR %[2]s <%% p.name
+		OR p.name ILIKE '%%' || %[2]s || '%%')`, s.query(), s.param)
+}
+
+func (s *productSearch) rank() string {
+	if s == nil {
+		return "0::float8"
+	}
+	return fmt.Sprintf("(ts_rank(p.search_vector, %s) + word_similarity(%s, p.name))", s.query(), s.param)
+}
+
+// --- NAME AND DESCRIPTION WITH MATCHED WORDS WRAPPED IN <mark>, THE ONLY HTML IN IT ---
+// The text is escaped before ts_headline, its parser keeps an entity like &lt; as one token.
+func (s *productSearch) snippet() string {
+	if s == nil {
+		return "''"
+	}
+	return fmt.Sprintf(`ts_headline('simple', %s, %s,
+		'StartSel=<mark>, StopSel=</mark>, MaxWords=20, MinWords=8')`, sqlHTMLEscape("p.name || ' - ' || p.description"), s.query())
+}
+
+func sqlHTMLEscape(expr string) string {
+	for _, r := range [][2]string{{"&", "&amp;"}, {"<", "&lt;"}, {">", "&gt;"}, {`"`, "&quot;"}, {"''", "&#39;"}} {
+		expr = fmt.Sprintf("REPLACE(%s, '%s', '%s')", expr, r[0], r[1])
+	}
+	return expr
+}
+
+type searchQuerier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// --- A SEARCH RUNS IN A READ-ONLY TRANSACTION WITH ITS SIMILARITY THRESHOLD, WITHOUT ONE THE POOL IS USED ---
+// done ends the transaction, rows must be closed before it.
+func beginProductSearch(ctx context.Context, db *pgxpool.Pool, search *productSearch) (searchQuerier, func(), error) {
+	if search == nil {
+		return db, func() {}, nil
+	}
+	tx, err := db.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := tx.Exec(ctx, fmt.Sprintf("SET LOCAL pg_trgm.word_similarity_threshold = %g", searchSimilarity)); err != nil {
+		tx.Rollback(ctx)
+		return nil, nil, err
+	}
+	return tx, func() { tx.Rollback(ctx) }, nil
+}
@@ -0,0 +1,234 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/federus1105/koda-b4-backend/internals/pkg/libs"
+	"github.com/federus1105/koda-b4-backend/internals/pkg/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+// --- REDIS KEY PREFIX ---
+// --- SESSION ID IS THE REFRESH TOKEN FAMILY, ONE SESSION PER LOGIN ---
+const (
+	sessionPrefix     = "session:"
+	userSessionPrefix = "session:user:"
+)
+
+// --- AVOID A WRITE ON EVERY REQUEST ---
+const sessionTouchInterval = time.Minute
+
+var ErrSessionNotFound = errors.New("session not found")
+
+// --- CHECK AND UPDATE IN ONE STEP, A SESSION REVOKED IN BETWEEN IS NEVER RECREATED ---
+// --- HSET ON AN EXISTING KEY KEEPS ITS TTL ---
+var touchSessionScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	return 0
+end
+local lastSeen = tonumber(redis.call("HGET", KEYS[1], "last_seen") or "0")
+if tonumber(ARGV[1]) - lastSeen >= tonumber(ARGV[2]) then
+	redis.call("HSET", KEYS[1], "last_seen", ARGV[1], "ip", ARGV[3])
+end
+return 1
+`)
+
+// --- SAME GUARD AS touchSessionScript, A REVOKED SESSION IS NOT RECREATED BY A REFRESH ---
+var extendSessionScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	return 0
+end
+redis.call("HSET", KEYS[1], "last_seen", ARGV[1], "ip", ARGV[2])
+redis.call("PEXPIRE", KEYS[1], ARGV[3])
+redis.call("SADD", KEYS[2], ARGV[4])
+redis.call("PEXPIRE", KEYS[2], ARGV[3])
+return 1
+`)
+
+type Session struct {
+	ID        string    `json:"id"`
+	Device    string    `json:"device"`
+	UserAgent string    `json:"user_agent"`
+	IP        string    `json:"ip"`
+	CreatedAt time.Time `json:"created_at"`
+	LastSeen  time.Time `json:"last_seen"`
+	Current   bool      `json:"current"`
+}
+
+// --- CLIENT INFO FROM REQUEST ---
+type SessionInfo struct {
+	Device    string
+	UserAgent string
+	IP        string
+}
+
+// --- CREATE SESSION, RETURN ID USED AS REFRESH FAMILY ---
+func CreateSession(ctx context.Context, rdb *redis.Client, userID int, info SessionInfo) (string, error) {
+	sid, err := utils.GenerateRandomToken(16)
+	if err != nil {
+		return "", err
+	}
+	now := time.Now().Unix()
+
+	pipe := rdb.TxPipeline()
+	pipe.HSet(ctx, sessionPrefix+sid,
+		"user_id", userID,
+		"device", info.Device,
+		"user_agent", info.UserAgent,
+		"ip", info.IP,
+		"created_at", now,
+		"last_seen", now,
+	)
+	pipe.Expire(ctx, sessionPrefix+sid, libs.RefreshTokenTTL)
+	pipe.SAdd(ctx, fmt.Sprintf("%s%d", userSessionPrefix, userID), sid)
+	pipe.Expire(ctx, fmt.Sprintf("%s%d", userSessionPrefix, userID), libs.RefreshTokenTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return "", err
+	}
+	return sid, nil
+}
+
+// --- UPDATE LAST SEEN, FALSE WHEN SESSION WAS REVOKED OR EXPIRED ---
+func TouchSession(ctx context.Context, rdb *redis.Client, sid, ip string) (bool, error) {
+	exists, err := touchSessionScript.Run(ctx, rdb, []string{sessionPrefix + sid},
+		time.Now().Unix(), int64(sessionTouchInterval.Seconds()), ip).Int()
+	if err != nil {
+		return false, err
+	}
+	return exists == 1, nil
+}
+
+// --- KEEP SESSION ALIVE AS LONG AS ITS REFRESH TOKEN, ErrSessionNotFound WHEN IT WAS REVOKED OR EXPIRED ---
+func ExtendSession(ctx context.Context, rdb *redis.Client, userID int, sid string, info SessionInfo) error {
+	setKey := fmt.Sprintf("%s%d", userSessionPrefix, userID)
+	exists, err := extendSessionScript.Run(ctx, rdb, []string{sessionPrefix + sid, setKey},
+		time.Now().Unix(), info.IP, libs.RefreshTokenTTL.Milliseconds(), sid).Int()
+	if err != nil {
+		return err
+	}
+	if exists == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// --- LIST ACTIVE SESSION OF USER, NEWEST FIRST ---
+func GetSessions(ctx context.Context, rdb *redis.Client, userID int, currentSID string) ([]Session, error) {
+	setKey := fmt.Sprintf("%s%d", userSessionPrefix, userID)
+	sids, err := rdb.SMembers(ctx, setKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := []Session{}
+	for _, sid := range sids {
+		data, err := rdb.HGetAll(ctx, sessionPrefix+sid).Result()
+		if err != nil {
+			return nil, err
+		}
+		// --- EXPIRED OR REVOKED, CLEAN UP INDEX ---
+		if len(data) == 0 {
+			rdb.SRem(ctx, setKey, sid)
+			continue
+		}
+		createdAt, _ := strconv.ParseInt(data["created_at"], 10, 64)
+		lastSeen, _ := strconv.ParseInt(data["last_seen"], 10, 64)
+		sessions = append(sessions, Session{
+			ID:        sid,
+			Device:    data["device"],
+			UserAgent: data["user_agent"],
+			IP:        data["ip"],
+			CreatedAt: time.Unix(createdAt, 0),
+			LastSeen:  time.Unix(lastSeen, 0),
+			Current:   sid == currentSID,
+		})
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastSeen.After(sessions[j].LastSeen)
+	})
+	return sessions, nil
+}
+
+// --- REVOKE ONE SESSION OWNED BY USER ---
+func RevokeSession(ctx context.Context, rdb *redis.Client, userID int, sid string) error {
+	owner, err := rdb.HGet(ctx, sessionPrefix+sid, "user_id").Result()
+	if err == redis.Nil || (err == nil && owner != strconv.Itoa(userID)) {
+		return ErrSessionNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if err := RevokeRefreshFamily(ctx, rdb, sid); err != nil {
+		return err
+	}
+	return rdb.SRem(ctx, fmt.Sprintf("%s%d", userSessionPrefix, userID), sid).Err()
+}
+
+// --- REVOKE ALL SESSION OF USER EXCEPT ONE, RETURN HOW MANY WERE REVOKED ---
+func RevokeOtherSessions(ctx context.Context, rdb *redis.Client, userID int, keepSID string) (int, error) {
+	setKey := fmt.Sprintf("%s%d", userSessionPrefix, userID)
+	sids, err := rdb.SMembers(ctx, setKey).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	revoked := 0
+	for _, sid := range sids {
+		if sid == keepSID {
+			continue
+		}
+		if err := RevokeRefreshFamily(ctx, rdb, sid); err != nil {
+			return revoked, err
+		}
+		rdb.SRem(ctx, setKey, sid)
+		revoked++
+	}
+	return revoked, nil
+}
+
+// --- SHORT DEVICE NAME FROM USER AGENT ---
+func DeviceFromUserAgent(ua string) string {
+	lower := strings.ToLower(ua)
+
+	platform := "Unknown device"
+	switch {
+	case strings.Contains(lower, "iphone"):
+		platform = "iPhone"
+	case strings.Contains(lower, "ipad"):
+		platform = "iPad"
+	case strings.Contains(lower, "android"):
+		platform = "Android"
+	case strings.Contains(lower, "windows"):
+		platform = "Windows"
+	case strings.Contains(lower, "mac os"), strings.Contains(lower, "macintosh"):
+		platform = "macOS"
+	case strings.Contains(lower, "linux"):
+		platform = "Linux"
+	}
+
+	browser := ""
+	switch {
+	case strings.Contains(lower, "edg/"):
+		browser = "Edge"
+	case strings.Contains(lower, "chrome/"):
+		browser = "Chrome"
+	case strings.Contains(lower, "firefox/"):
+		browser = "Firefox"
+	case strings.Contains(lower, "safari/"):
+		browser = "Safari"
+	case strings.Contains(lower, "okhttp"), strings.Contains(lower, "dart"), strings.Contains(lower, "cfnetwork"):
+		browser = "App"
+	}
+
+	if browser == "" {
+		return platform
+	}
+	return browser + " on " + platform
+}
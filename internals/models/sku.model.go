@@ -0,0 +1,345 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/federus1105/koda-b4-backend/internals/pkg/utils"
+	"github.com/jackc/pgx/v5"
+)
+
+// --- ONE ROW PER SIZE x VARIANT COMBINATION, SIZE OR VARIANT IS EMPTY WHEN THE PRODUCT HAS NONE ---
+type SkuInput struct {
+	Size       *int     `json:"size" binding:"omitempty,gt=0,lte=3"`
+	Variant    *int     `json:"variant" binding:"omitempty,gt=0,lte=2"`
+	FixedPrice *float64 `json:"fixed_price" binding:"omitempty,gt=0"`
+	PriceDelta float64  `json:"price_delta"`
+	Stock      int      `json:"stock" binding:"gte=0"`
+	Active     *bool    `json:"active"`
+}
+
+type ProductSku struct {
+	Id            int      `json:"id"`
+	Size          *Option  `json:"size"`
+	Variant       *Option  `json:"variant"`
+	Price         float64  `json:"price"`
+	PriceDiscount float64  `json:"priceDiscount"`
+	FixedPrice    *float64 `json:"fixed_price,omitempty"`
+	PriceDelta    float64  `json:"price_delta"`
+	Stock         int      `json:"stock"`
+	Active        bool     `json:"active"`
+}
+
+// --- Query IS SHARED BY *pgxpool.Pool AND pgx.Tx ---
+type skuQuerier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+const skuUnavailable = "size and variant combination is not available"
+
+// --- FIXED PRICE WINS, OTHERWISE PRODUCT PRICE PLUS DELTA ---
+func SkuPrice(basePrice float64, fixedPrice *float64, delta float64) float64 {
+	if fixedPrice != nil {
+		return *fixedPrice
+	}
+	return basePrice + delta
+}
+
+// --- DISCOUNTED PRODUCT PRICE TAKES THE SAME AMOUNT OFF EVERY SKU, 0 WHEN THE PRODUCT HAS NO DISCOUNT ---
+func SkuDiscountPrice(skuPrice, basePrice, discountPrice float64) float64 {
+	if discountPrice <= 0 {
+		return 0
+	}
+	price := skuPrice - (basePrice - discountPrice)
+	if price < 0 {
+		return 0
+	}
+	return price
+}
+
+// --- EVERY COMBINATION OF THE GIVEN SIZES AND VARIANTS, EACH WITH THE SAME STOCK ---
+func SkusFromOptions(sizes, variants []int, stock int) []SkuInput {
+	sizeIDs := optionalIDs(sizes)
+	variantIDs := optionalIDs(variants)
+
+	skus := make([]SkuInput, 0, len(sizeIDs)*len(variantIDs))
+	for _, size := range sizeIDs {
+		for _, variant := range variantIDs {
+			skus = append(skus, SkuInput{Size: size, Variant: variant, Stock: stock})
+		}
+	}
+	return skus
+}
+
+func optionalIDs(ids []int) []*int {
+	if len(ids) == 0 {
+		return []*int{nil}
+	}
+	seen := map[int]bool{}
+	result := []*int{}
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		result = append(result, &id)
+	}
+	return result
+}
+
+func skuKey(size, variant *int) [2]int {
+	var key [2]int
+	if size != nil {
+		key[0] = *size
+	}
+	if variant != nil {
+		key[1] = *variant
+	}
+	return key
+}
+
+// --- basePrice IS THE PRODUCT PRICE THE DELTAS ARE ADDED TO ---
+func validateSkus(skus []SkuInput, basePrice float64) error {
+	if len(skus) == 0 {
+		return utils.ValidationError{Field: "skus", Message: "must have at least one size and variant combination"}
+	}
+
+	seen := map[[2]int]bool{}
+	for _, sku := range skus {
+		key := skuKey(sku.Size, sku.Variant)
+		if seen[key] {
+			return utils.ValidationError{Field: "skus", Message: "has the same size and variant combination twice"}
+		}
+		seen[key] = true
+
+		// --- A CART ITEM WITHOUT SIZE WOULD BE AMBIGUOUS NEXT TO ONE WITH SIZE ---
+		if (sku.Size == nil) != (skus[0].Size == nil) {
+			return utils.ValidationError{Field: "skus", Message: "must all have a size or all have none"}
+		}
+		if (sku.Variant == nil) != (skus[0].Variant == nil) {
+			return utils.ValidationError{Field: "skus", Message: "must all have a variant or all have none"}
+		}
+		if SkuPrice(basePrice, sku.FixedPrice, sku.PriceDelta) <= 0 {
+			return utils.ValidationError{Field: "skus", Message: "price_delta can't bring the price to 0 or below"}
+		}
+	}
+	return nil
+}
+
+// --- UPSERT THE GIVEN SKUS AND DROP THE OTHERS, THEN SYNC SIZE, VARIANT AND STOCK OF THE PRODUCT ---
+func replaceProductSkus(ctx context.Context, tx pgx.Tx, productID int, skus []SkuInput) (int, error) {
+	var basePrice float64
+	if err := tx.QueryRow(ctx, `SELECT priceoriginal FROM product WHERE id = $1`, productID).Scan(&basePrice); err != nil {
+		if err == pgx.ErrNoRows {
+			return 0, ErrProductNotFound
+		}
+		return 0, err
+	}
+	if err := validateSkus(skus, basePrice); err != nil {
+		return 0, err
+	}
+
+	keep := make([]int, 0, len(skus))
+	for _, sku := range skus {
+		active := true
+		if sku.Active != nil {
+			active = *sku.Active
+		}
+
+		var id int
+		if err := tx.QueryRow(ctx, `
+			INSERT INTO product_skus (id_product, id_size, id_variant, price, price_delta, stock, is_active)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			ON CONFLICT (id_product, COALESCE(id_size, 0), COALESCE(id_variant, 0))
+			DO UPDATE SET
+				price = EXCLUDED.price,
+				price_delta = EXCLUDED.price_delta,
+				stock = EXCLUDED.stock,
+				is_active = EXCLUDED.is_active,
+				updated_at = NOW()
+			RETURNING id`,
+			productID, sku.Size, sku.Variant, sku.FixedPrice, sku.PriceDelta, sku.Stock, active,
+		).Scan(&id); err != nil {
+			log.Println("Failed to save product sku:", err)
+			return 0, err
+		}
+		keep = append(keep, id)
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM product_skus WHERE id_product = $1 AND NOT (id = ANY($2))`, productID, keep); err != nil {
+		log.Println("Failed to delete product skus:", err)
+		return 0, err
+	}
+
+	// --- size_product AND variant_product LIST WHAT CAN BE ORDERED ---
+	if _, err := tx.Exec(ctx, "DELETE FROM size_product WHERE id_product=$1", productID); err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO size_product (id_product, id_size)
+		SELECT DISTINCT id_product, id_size FROM product_skus
+		WHERE id_product = $1 AND id_size IS NOT NULL AND is_active`, productID); err != nil {
+		log.Println("Failed to sync size product:", err)
+		return 0, err
+	}
+	if _, err := tx.Exec(ctx, "DELETE FROM variant_product WHERE id_product=$1", productID); err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO variant_product (id_product, id_variant)
+		SELECT DISTINCT id_product, id_variant FROM product_skus
+		WHERE id_product = $1 AND id_variant IS NOT NULL AND is_active`, productID); err != nil {
+		log.Println("Failed to sync variant product:", err)
+		return 0, err
+	}
+
+	return syncProductStock(ctx, tx, productID)
+}
+
+// --- product.stock IS THE TOTAL OF ITS ACTIVE SKUS, KEPT FOR LISTS AND FILTERS ---
+func syncProductStock(ctx context.Context, tx pgx.Tx, productID int) (int, error) {
+	var stock int
+	err := tx.QueryRow(ctx, `
+		UPDATE product SET stock = (
+			SELECT COALESCE(SUM(stock), 0) FROM product_skus WHERE id_product = $1 AND is_active
+		)
+		WHERE id = $1
+		RETURNING stock`, productID).Scan(&stock)
+	if err != nil {
+		log.Println("Failed to sync product stock:", err)
+		return 0, err
+	}
+	return stock, nil
+}
+
+// --- SIZE OR VARIANT CHANGED WITHOUT skus: KEEP MATCHING SKUS, NEW COMBINATIONS GET THE GIVEN STOCK ---
+func regenerateSkus(current []ProductSku, sizes, variants []int, stock int) []SkuInput {
+	existing := map[[2]int]ProductSku{}
+	for _, sku := range current {
+		existing[skuKey(optionID(sku.Size), optionID(sku.Variant))] = sku
+	}
+
+	// --- KEEP THE SIDE THAT WASN'T SENT ---
+	if len(sizes) == 0 {
+		sizes = currentOptionIDs(current, func(sku ProductSku) *Option { return sku.Size })
+	}
+	if len(variants) == 0 {
+		variants = currentOptionIDs(current, func(sku ProductSku) *Option { return sku.Variant })
+	}
+
+	skus := SkusFromOptions(sizes, variants, stock)
+	for i, sku := range skus {
+		if old, ok := existing[skuKey(sku.Size, sku.Variant)]; ok {
+			active := old.Active
+			skus[i].FixedPrice = old.FixedPrice
+			skus[i].PriceDelta = old.PriceDelta
+			skus[i].Stock = old.Stock
+			skus[i].Active = &active
+		}
+	}
+	return skus
+}
+
+func optionID(option *Option) *int {
+	if option == nil {
+		return nil
+	}
+	return &option.Id
+}
+
+func currentOptionIDs(skus []ProductSku, pick func(ProductSku) *Option) []int {
+	ids := []int{}
+	seen := map[int]bool{}
+	for _, sku := range skus {
+		option := pick(sku)
+		if option == nil || seen[option.Id] {
+			continue
+		}
+		seen[option.Id] = true
+		ids = append(ids, option.Id)
+	}
+	return ids
+}
+
+// --- SKUS WITH THEIR FINAL PRICE, ADMIN ALSO SEES INACTIVE ONES ---
+func GetProductSkus(ctx context.Context, db skuQuerier, productID int, activeOnly bool) ([]ProductSku, error) {
+	rows, err := db.Query(ctx, `
+		SELECT ps.id, s.id, s.name, v.id, v.name, ps.price, ps.price_delta, ps.stock, ps.is_active,
+			p.priceoriginal, fs.sale_price, fs.discount_percent
+		FROM product_skus ps
+		JOIN product p ON p.id = ps.id_product
+		LEFT JOIN sizes s ON s.id = ps.id_size
+		LEFT JOIN variants v ON v.id = ps.id_variant`+activeFlashSaleJoin("ps.id_product")+`
+		WHERE ps.id_product = $1 AND (ps.is_active OR NOT $2)
+		ORDER BY ps.id_size NULLS FIRST, ps.id_variant NULLS FIRST`, productID, activeOnly)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	skus := []ProductSku{}
+	for rows.Next() {
+		var (
+			sku                        ProductSku
+			sizeID, variantID          *int
+			sizeName, variantName      *string
+			basePrice                  float64
+			salePrice, discountPercent *float64
+		)
+		if err := rows.Scan(&sku.Id, &sizeID, &sizeName, &variantID, &variantName, &sku.FixedPrice, &sku.PriceDelta, &sku.Stock, &sku.Active, &basePrice, &salePrice, &discountPercent); err != nil {
+			return nil, err
+		}
+		if sizeID != nil {
+			sku.Size = &Option{Id: *sizeID, Name: *sizeName}
+		}
+		if variantID != nil {
+			sku.Variant = &Option{Id: *variantID, Name: *variantName}
+		}
+		sku.Price = SkuPrice(basePrice, sku.FixedPrice, sku.PriceDelta)
+		sku.PriceDiscount = FlashSalePrice(sku.Price, basePrice, salePrice, discountPercent)
+		skus = append(skus, sku)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return skus, nil
+}
+
+// --- SINGLE SKU PRODUCT TAKES A PLAIN stock EDIT, OTHERWISE STOCK IS SET PER SKU ---
+func setSingleSkuStock(ctx context.Context, tx pgx.Tx, productID, stock int) error {
+	var count int
+	if err := tx.QueryRow(ctx, "SELECT COUNT(*) FROM product_skus WHERE id_product = $1", productID).Scan(&count); err != nil {
+		return err
+	}
+	if count != 1 {
+		return utils.ValidationError{Field: "stock", Message: fmt.Sprintf("can't be set for %d size and variant combinations, send skus instead", count)}
+	}
+
+	if _, err := tx.Exec(ctx, "UPDATE product_skus SET stock = $1, updated_at = NOW() WHERE id_product = $2", stock, productID); err != nil {
+		log.Println("Failed to update sku stock:", err)
+		return err
+	}
+	_, err := syncProductStock(ctx, tx, productID)
+	return err
+}
+
+// --- SIZE AND VARIANT IDS OF THE ACTIVE SKUS, FOR THE ADMIN RESPONSE ---
+func skuOptionIDs(skus []SkuInput) ([]int, []int) {
+	var sizes, variants []int
+	seenSize, seenVariant := map[int]bool{}, map[int]bool{}
+	for _, sku := range skus {
+		if sku.Active != nil && !*sku.Active {
+			continue
+		}
+		if sku.Size != nil && !seenSize[*sku.Size] {
+			seenSize[*sku.Size] = true
+			sizes = append(sizes, *sku.Size)
+		}
+		if sku.Variant != nil && !seenVariant[*sku.Variant] {
+			seenVariant[*sku.Variant] = true
+			variants = append(variants, *sku.Variant)
+		}
+	}
+	return sizes, variants
+}
@@ -0,0 +1,118 @@
+package models
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/federus1105/koda-b4-backend/internals/pkg/utils"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestSkuPrice(t *testing.T) {
+	tests := []struct {
+		name  string
+		base  float64
+		fixed *float64
+		delta float64
+		want  float64
+	}{
+		{"base price", 20000, nil, 0, 20000},
+		{"positive delta", 20000, nil, 5000, 25000},
+		{"negative delta", 20000, nil, -3000, 17000},
+		{"delta to zero", 20000, nil, -20000, 0},
+		{"delta below zero", 20000, nil, -25000, -5000},
+		{"fixed price wins over delta", 20000, ptr(12000.0), 5000, 12000},
+	}
+	for _, tt := range tests {
+		if got := SkuPrice(tt.base, tt.fixed, tt.delta); got != tt.want {
+			t.Errorf("%s: SkuPrice = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSkuDiscountPrice(t *testing.T) {
+	tests := []struct {
+		name                     string
+		skuPrice, base, discount float64
+		want                     float64
+	}{
+		{"no discount", 25000, 20000, 0, 0},
+		{"negative discount is none", 25000, 20000, -1, 0},
+		{"same amount off the base sku", 20000, 20000, 15000, 15000},
+		{"same amount off a dearer sku", 25000, 20000, 15000, 20000},
+		{"same amount off a cheaper sku", 18000, 20000, 15000, 13000},
+		{"never below zero", 4000, 20000, 15000, 0},
+	}
+	for _, tt := range tests {
+		if got := SkuDiscountPrice(tt.skuPrice, tt.base, tt.discount); got != tt.want {
+			t.Errorf("%s: SkuDiscountPrice = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSkusFromOptions(t *testing.T) {
+	got := SkusFromOptions([]int{1, 2, 1}, []int{3}, 4)
+	want := []SkuInput{
+		{Size: ptr(1), Variant: ptr(3), Stock: 4},
+		{Size: ptr(2), Variant: ptr(3), Stock: 4},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("SkusFromOptions = %+v, want %+v", got, want)
+	}
+
+	if got := SkusFromOptions(nil, nil, 9); !reflect.DeepEqual(got, []SkuInput{{Stock: 9}}) {
+		t.Fatalf("SkusFromOptions without options = %+v, want one sku without size and variant", got)
+	}
+}
+
+func TestValidateSkus(t *testing.T) {
+	tests := []struct {
+		name  string
+		skus  []SkuInput
+		valid bool
+	}{
+		{"single sku", []SkuInput{{}}, true},
+		{"sizes and variants", []SkuInput{{Size: ptr(1), Variant: ptr(1)}, {Size: ptr(2), Variant: ptr(1)}}, true},
+		{"negative delta above zero", []SkuInput{{Size: ptr(1), PriceDelta: -19999}}, true},
+		{"fixed price ignores delta", []SkuInput{{Size: ptr(1), FixedPrice: ptr(1000.0), PriceDelta: -50000}}, true},
+		{"none", []SkuInput{}, false},
+		{"same combination twice", []SkuInput{{Size: ptr(1)}, {Size: ptr(1)}}, false},
+		{"size on some only", []SkuInput{{Size: ptr(1)}, {}}, false},
+		{"variant on some only", []SkuInput{{Variant: ptr(1)}, {Variant: ptr(1), Size: ptr(2)}}, false},
+		{"negative delta to zero", []SkuInput{{Size: ptr(1), PriceDelta: -20000}}, false},
+		{"negative delta below zero", []SkuInput{{Size: ptr(1)}, {Size: ptr(2), PriceDelta: -25000}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSkus(tt.skus, 20000)
+			if tt.valid && err != nil {
+				t.Fatalf("validateSkus: %v", err)
+			}
+			var ve utils.ValidationError
+			if !tt.valid && !errors.As(err, &ve) {
+				t.Fatalf("validateSkus = %v, want a validation error", err)
+			}
+		})
+	}
+}
+
+func TestRegenerateSkus(t *testing.T) {
+	current := []ProductSku{
+		{Size: &Option{Id: 1}, Variant: &Option{Id: 1}, FixedPrice: ptr(30000.0), Stock: 3, Active: true},
+		{Size: &Option{Id: 2}, Variant: &Option{Id: 1}, PriceDelta: 2000, Stock: 5, Active: false},
+	}
+
+	// --- ONLY SIZES SENT, THE CURRENT VARIANT IS KEPT ---
+	got := regenerateSkus(current, []int{2, 3}, nil, 7)
+	want := []SkuInput{
+		{Size: ptr(2), Variant: ptr(1), PriceDelta: 2000, Stock: 5, Active: ptr(false)},
+		{Size: ptr(3), Variant: ptr(1), Stock: 7},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("regenerateSkus = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,152 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/federus1105/koda-b4-backend/internals/pkg/libs"
+	"github.com/federus1105/koda-b4-backend/internals/pkg/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+// --- REDIS KEY PREFIX ---
+const (
+	refreshTokenPrefix  = "refresh:token:"
+	refreshUsedPrefix   = "refresh:used:"
+	refreshFamilyPrefix = "refresh:family:"
+	jwtDenylistPrefix   = "jwt:deny:"
+)
+
+var (
+	ErrRefreshTokenInvalid = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected")
+)
+
+// --- CHECK FAMILY, MARK OLD TOKEN USED AND ISSUE THE NEW ONE IN ONE STEP ---
+// --- A FAMILY REVOKED MEANWHILE IS NEVER SET AGAIN: 0 REVOKED, -1 ALREADY USED, 1 ROTATED ---
+var rotateRefreshScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	return 0
+end
+if not redis.call("SET", KEYS[2], 1, "NX", "PX", ARGV[1]) then
+	return -1
+end
+redis.call("SET", KEYS[1], ARGV[2], "XX", "PX", ARGV[1])
+redis.call("SET", KEYS[3], ARGV[3], "PX", ARGV[1])
+return 1
+`)
+
+type RefreshSession struct {
+	UserID int    `json:"user_id"`
+	Family string `json:"family"`
+}
+
+type ReqRefreshToken struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type ReqLogout struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// --- ISSUE REFRESH TOKEN, EMPTY FAMILY STARTS A NEW ONE ---
+func IssueRefreshToken(ctx context.Context, rdb *redis.Client, userID int, family string) (string, error) {
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	if family == "" {
+		family, err = utils.GenerateRandomToken(16)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	// --- KEEP FAMILY ALIVE AS LONG AS ITS NEWEST TOKEN ---
+	if err := rdb.Set(ctx, refreshFamilyPrefix+family, strconv.Itoa(userID), libs.RefreshTokenTTL).Err(); err != nil {
+		return "", err
+	}
+
+	session := RefreshSession{UserID: userID, Family: family}
+	if err := libs.SetToCache(ctx, rdb, refreshTokenPrefix+token, session, libs.RefreshTokenTTL); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// --- ROTATE REFRESH TOKEN ---
+func RotateRefreshToken(ctx context.Context, rdb *redis.Client, token string) (RefreshSession, string, error) {
+	session, err := libs.GetFromCache[RefreshSession](ctx, rdb, refreshTokenPrefix+token)
+	if err != nil {
+		return RefreshSession{}, "", err
+	}
+	if session == nil {
+		return RefreshSession{}, "", ErrRefreshTokenInvalid
+	}
+
+	newToken, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return RefreshSession{}, "", err
+	}
+	payload, err := json.Marshal(RefreshSession{UserID: session.UserID, Family: session.Family})
+	if err != nil {
+		return RefreshSession{}, "", err
+	}
+
+	result, err := rotateRefreshScript.Run(ctx, rdb,
+		[]string{refreshFamilyPrefix + session.Family, refreshUsedPrefix + token, refreshTokenPrefix + newToken},
+		libs.RefreshTokenTTL.Milliseconds(), session.UserID, payload).Int()
+	if err != nil {
+		return RefreshSession{}, "", err
+	}
+	switch result {
+	case 0:
+		// --- FAMILY ALREADY REVOKED ---
+		return RefreshSession{}, "", ErrRefreshTokenInvalid
+	case -1:
+		// --- SECOND USE MEANS THE TOKEN LEAKED ---
+		if err := RevokeRefreshFamily(ctx, rdb, session.Family); err != nil {
+			return RefreshSession{}, "", err
+		}
+		return RefreshSession{}, "", ErrRefreshTokenReused
+	}
+	return *session, newToken, nil
+}
+
+// --- REVOKE ALL TOKEN IN FAMILY AND ITS SESSION ---
+func RevokeRefreshFamily(ctx context.Context, rdb *redis.Client, family string) error {
+	return rdb.Del(ctx, refreshFamilyPrefix+family, sessionPrefix+family).Err()
+}
+
+// --- REVOKE FAMILY OF REFRESH TOKEN OWNED BY USER ---
+func RevokeRefreshToken(ctx context.Context, rdb *redis.Client, userID int, token string) error {
+	session, err := libs.GetFromCache[RefreshSession](ctx, rdb, refreshTokenPrefix+token)
+	if err != nil || session == nil || session.UserID != userID {
+		return err
+	}
+	return RevokeRefreshFamily(ctx, rdb, session.Family)
+}
+
+// --- DENYLIST ACCESS TOKEN UNTIL IT EXPIRES ---
+func DenyAccessToken(ctx context.Context, rdb *redis.Client, jti string, ttl time.Duration) error {
+	if jti == "" || ttl <= 0 {
+		return nil
+	}
+	return rdb.Set(ctx, jwtDenylistPrefix+jti, 1, ttl).Err()
+}
+
+// --- CHECK ACCESS TOKEN IN DENYLIST ---
+func IsAccessTokenDenied(ctx context.Context, rdb *redis.Client, jti string) (bool, error) {
+	if jti == "" {
+		return false, nil
+	}
+	n, err := rdb.Exists(ctx, jwtDenylistPrefix+jti).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
@@ -0,0 +1,325 @@
+package models
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/federus1105/koda-b4-backend/internals/pkg/libs"
+	"github.com/federus1105/koda-b4-backend/internals/pkg/utils"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+)
+
+// --- REDIS KEY PREFIX ---
+const (
+	totpSetupPrefix         = "totp:setup:"
+	totpUsedPrefix          = "totp:used:"
+	loginChallengePrefix    = "login:challenge:"
+	loginChallengeTryPrefix = "login:challenge:tries:"
+)
+
+const (
+	LoginChallengeTTL    = 5 * time.Minute
+	loginChallengeMaxTry = 5
+	TOTPSetupTTL         = 10 * time.Minute
+	recoveryCodeCount    = 10
+)
+
+var ErrLoginChallengeInvalid = errors.New("invalid or expired login challenge")
+
+type TwoFactorStatus struct {
+	Enabled      bool `json:"enabled"`
+	Required     bool `json:"required"`
+	RecoveryLeft int  `json:"recovery_codes_left"`
+}
+
+type LoginChallenge struct {
+	UserID int    `json:"user_id"`
+	Role   string `json:"role"`
+	Setup  bool   `json:"setup"`
+}
+
+type ReqTOTPCode struct {
+	Code string `json:"code" binding:"required,len=6,numeric"`
+}
+
+type ReqLoginTwoFactor struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required_without=RecoveryCode,omitempty,len=6,numeric"`
+	RecoveryCode   string `json:"recovery_code" binding:"required_without=Code"`
+}
+
+type ReqDisableTwoFactor struct {
+	Code         string `json:"code" binding:"required_without=RecoveryCode,omitempty,len=6,numeric"`
+	RecoveryCode string `json:"recovery_code" binding:"required_without=Code"`
+}
+
+type ReqTwoFactorPolicy struct {
+	Role     string `json:"role" binding:"required,max=30"`
+	Required *bool  `json:"required" binding:"required"`
+}
+
+// --- GET TOTP SECRET, EMPTY WHEN 2FA DISABLED ---
+func GetTOTPSecret(ctx context.Context, db *pgxpool.Pool, userID int) (string, error) {
+	var secret *string
+	err := db.QueryRow(ctx,
+		"SELECT totp_secret FROM users WHERE id = $1 AND totp_enabled_at IS NOT NULL",
+		userID,
+	).Scan(&secret)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return "", nil
+		}
+		return "", err
+	}
+	return libs.StringOrEmpty(secret), nil
+}
+
+// --- IS 2FA MANDATORY FOR ROLE ---
+func IsTwoFactorRequired(ctx context.Context, db *pgxpool.Pool, role string) (bool, error) {
+	var required bool
+	err := db.QueryRow(ctx, "SELECT require_2fa FROM role_settings WHERE role = $1", role).Scan(&required)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+	return required, nil
+}
+
+// --- 2FA POLICY OF EVERY ROLE ---
+func GetTwoFactorPolicies(ctx context.Context, db *pgxpool.Pool) (map[string]bool, error) {
+	rows, err := db.Query(ctx, `
+		SELECT r.name, COALESCE(rs.require_2fa, false)
+		FROM roles r
+		LEFT JOIN role_settings rs ON rs.role = r.name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	policies := map[string]bool{}
+	for rows.Next() {
+		var role string
+		var required bool
+		if err := rows.Scan(&role, &required); err != nil {
+			return nil, err
+		}
+		policies[role] = required
+	}
+	return policies, rows.Err()
+}
+
+// --- SET 2FA POLICY FOR ROLE ---
+func SetTwoFactorRequired(ctx context.Context, db *pgxpool.Pool, role string, required bool) error {
+	exists, err := RoleExists(ctx, db, role)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrRoleNotFound
+	}
+
+	_, err = db.Exec(ctx, `
+		INSERT INTO role_settings (role, require_2fa, updated_at) VALUES ($1, $2, NOW())
+		ON CONFLICT (role) DO UPDATE SET require_2fa = EXCLUDED.require_2fa, updated_at = NOW()
+	`, role, required)
+	return err
+}
+
+// --- GET 2FA STATUS OF USER ---
+func GetTwoFactorStatus(ctx context.Context, db *pgxpool.Pool, userID int) (TwoFactorStatus, error) {
+	var status TwoFactorStatus
+	err := db.QueryRow(ctx, `
+		SELECT u.totp_enabled_at IS NOT NULL,
+			COALESCE(rs.require_2fa, false),
+			(SELECT COUNT(*) FROM recovery_codes rc WHERE rc.id_users = u.id AND rc.used_at IS NULL)
+		FROM users u
+		LEFT JOIN role_settings rs ON rs.role = u.role
+		WHERE u.id = $1
+	`, userID).Scan(&status.Enabled, &status.Required, &status.RecoveryLeft)
+	return status, err
+}
+
+// --- SAVE PENDING SECRET UNTIL FIRST CODE IS CONFIRMED ---
+func SavePendingTOTPSecret(ctx context.Context, rdb *redis.Client, userID int, secret string) error {
+	return rdb.Set(ctx, fmt.Sprintf("%s%d", totpSetupPrefix, userID), secret, TOTPSetupTTL).Err()
+}
+
+func GetPendingTOTPSecret(ctx context.Context, rdb *redis.Client, userID int) (string, error) {
+	secret, err := rdb.Get(ctx, fmt.Sprintf("%s%d", totpSetupPrefix, userID)).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return secret, err
+}
+
+// --- ENABLE 2FA AND REPLACE RECOVERY CODES ---
+func EnableTwoFactor(ctx context.Context, db *pgxpool.Pool, rdb *redis.Client, userID int, secret string) ([]string, error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		log.Println("Failed to begin transaction:", err)
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx,
+		"UPDATE users SET totp_secret = $1, totp_enabled_at = NOW() WHERE id = $2",
+		secret, userID,
+	); err != nil {
+		log.Println("Failed to enable 2fa:", err)
+		return nil, err
+	}
+
+	codes, err := replaceRecoveryCodes(ctx, tx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Println("Failed to commit transaction:", err)
+		return nil, err
+	}
+
+	rdb.Del(ctx, fmt.Sprintf("%s%d", totpSetupPrefix, userID))
+	return codes, nil
+}
+
+// --- DISABLE 2FA ---
+func DisableTwoFactor(ctx context.Context, db *pgxpool.Pool, userID int) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx,
+		"UPDATE users SET totp_secret = NULL, totp_enabled_at = NULL WHERE id = $1",
+		userID,
+	); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, "DELETE FROM recovery_codes WHERE id_users = $1", userID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// --- REGENERATE RECOVERY CODES ---
+func RegenerateRecoveryCodes(ctx context.Context, db *pgxpool.Pool, userID int) ([]string, error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	codes, err := replaceRecoveryCodes(ctx, tx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx pgx.Tx, userID int) ([]string, error) {
+	if _, err := tx.Exec(ctx, "DELETE FROM recovery_codes WHERE id_users = $1", userID); err != nil {
+		log.Println("Failed to delete recovery codes:", err)
+		return nil, err
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		raw, err := utils.GenerateRandomToken(5)
+		if err != nil {
+			return nil, err
+		}
+		code := raw[:5] + "-" + raw[5:]
+		if _, err := tx.Exec(ctx,
+			"INSERT INTO recovery_codes (id_users, code_hash) VALUES ($1, $2)",
+			userID, hashRecoveryCode(code),
+		); err != nil {
+			log.Println("Failed to insert recovery code:", err)
+			return nil, err
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+// --- CONSUME RECOVERY CODE, EACH CODE WORKS ONCE ---
+func UseRecoveryCode(ctx context.Context, db *pgxpool.Pool, userID int, code string) (bool, error) {
+	result, err := db.Exec(ctx, `
+		UPDATE recovery_codes SET used_at = NOW()
+		WHERE id = (
+			SELECT id FROM recovery_codes
+			WHERE id_users = $1 AND code_hash = $2 AND used_at IS NULL
+			LIMIT 1
+		)
+	`, userID, hashRecoveryCode(code))
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() == 1, nil
+}
+
+// --- RECOVERY CODE ARE RANDOM, SHA256 IS ENOUGH ---
+func hashRecoveryCode(code string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(code))))
+	return hex.EncodeToString(sum[:])
+}
+
+// --- BLOCK REPLAY OF THE SAME CODE ---
+func MarkTOTPStepUsed(ctx context.Context, rdb *redis.Client, userID int, step int64) (bool, error) {
+	return rdb.SetNX(ctx, fmt.Sprintf("%s%d:%d", totpUsedPrefix, userID, step), 1, 2*time.Minute).Result()
+}
+
+// --- CREATE LOGIN CHALLENGE AFTER PASSWORD IS VERIFIED ---
+func CreateLoginChallenge(ctx context.Context, rdb *redis.Client, challenge LoginChallenge) (string, error) {
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+	if err := libs.SetToCache(ctx, rdb, loginChallengePrefix+token, challenge, LoginChallengeTTL); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// --- GET LOGIN CHALLENGE, IT IS DROPPED AFTER TOO MANY WRONG CODE ---
+func GetLoginChallenge(ctx context.Context, rdb *redis.Client, token string) (LoginChallenge, error) {
+	challenge, err := libs.GetFromCache[LoginChallenge](ctx, rdb, loginChallengePrefix+token)
+	if err != nil {
+		return LoginChallenge{}, err
+	}
+	if challenge == nil {
+		return LoginChallenge{}, ErrLoginChallengeInvalid
+	}
+	return *challenge, nil
+}
+
+func FailLoginChallenge(ctx context.Context, rdb *redis.Client, token string) error {
+	tries, err := rdb.Incr(ctx, loginChallengeTryPrefix+token).Result()
+	if err != nil {
+		return err
+	}
+	rdb.Expire(ctx, loginChallengeTryPrefix+token, LoginChallengeTTL)
+	if tries >= loginChallengeMaxTry {
+		return DeleteLoginChallenge(ctx, rdb, token)
+	}
+	return nil
+}
+
+func DeleteLoginChallenge(ctx context.Context, rdb *redis.Client, token string) error {
+	return rdb.Del(ctx, loginChallengePrefix+token, loginChallengeTryPrefix+token).Err()
+}
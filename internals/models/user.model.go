@@ -7,10 +7,12 @@ import (
 	"mime/multipart"
 	"strings"
 
+	"github.com/federus1105/koda-b4-backend/internals/pkg/libs"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type UserList struct {
+	Id       int    `json:"id"`
 	Photo    string `json:"photo"`
 	Fullname string `json:"fullname"`
 	Phone    string `json:"phone"`
@@ -18,6 +20,9 @@ type UserList struct {
 	Email    string `json:"email"`
 }
 
+// --- ROLE OF A NEW ACCOUNT, ANY OTHER ROLE NEEDS roles:manage ---
+const DefaultUserRole = "user"
+
 type UserBody struct {
 	Id        int                   `form:"id"`
 	Photos    *multipart.FileHeader `form:"photos" binding:"required"`
@@ -25,9 +30,9 @@ type UserBody struct {
 	Fullname  string                `form:"fullname" binding:"required,max=30"`
 	Email     string                `form:"email"  binding:"required,email"`
 	Phone     string                `form:"phone" binding:"required,max=12"`
-	Password  string                `form:"password" binding:"required,password_complex"`
+	Password  string                `form:"password" binding:"required,password_length,password_complex,password_breached"`
 	Address   string                `form:"address" binding:"required,max=50"`
-	Role      string                `form:"role" binding:"required,oneof=user admin"`
+	Role      string                `form:"role" binding:"omitempty,max=30"`
 }
 
 type UserUpdateBody struct {
@@ -40,50 +45,67 @@ type UserUpdateBody struct {
 	PhotosStr *string               `form:"photosStr,omitempty"`
 }
 
-func GetListUser(ctx context.Context, db *pgxpool.Pool, name string, limit, offset int) ([]UserList, error) {
+func GetListUser(ctx context.Context, db *pgxpool.Pool, name string, page Page) ([]UserList, *libs.Cursor, error) {
 	sql := `SELECT 
+	u.id,
 	COALESCE(photos, '') AS photos, 
-	a.fullname, 
+	COALESCE(a.fullname, '') AS fullname, 
 	a.phonenumber, 
 	COALESCE(address, '') AS address,
 	u.email FROM account a
 	JOIN users u ON u.id = a.id_users`
 
+	whereClauses := []string{}
 	args := []interface{}{}
 	argIdx := 1
 
 	// --- SEARCH ---
 	if strings.TrimSpace(name) != "" {
-		sql += fmt.Sprintf(" WHERE a.fullname ILIKE $%d", argIdx)
+		whereClauses = append(whereClauses, fmt.Sprintf("a.fullname ILIKE $%d", argIdx))
 		args = append(args, "%"+name+"%")
 		argIdx++
 	}
 
+	// --- AFTER CURSOR, fullname IS NULLABLE SO THE KEY IS COALESCED LIKE THE ORDER ---
+	if condition, cursorArgs, next := page.afterCursor("COALESCE(a.fullname, '')", "u.id", "text", false, argIdx); condition != "" {
+		whereClauses = append(whereClauses, condition)
+		args = append(args, cursorArgs...)
+		argIdx = next
+	}
+
+	if len(whereClauses) > 0 {
+		sql += " WHERE " + strings.Join(whereClauses, " AND ")
+	}
+
 	// --- ORDER LIMIT, OFFSET ---
-	sql += fmt.Sprintf(" ORDER BY a.fullname ASC LIMIT $%d OFFSET $%d", argIdx, argIdx+1)
-	args = append(args, limit, offset)
+	limitSQL, limitArgs := page.limitClause(argIdx)
+	sql += " ORDER BY COALESCE(a.fullname, '') ASC, u.id ASC" + limitSQL
+	args = append(args, limitArgs...)
 
 	// --- EXECUTE QUERY ---
 	rows, err := db.Query(ctx, sql, args...)
 	if err != nil {
-		fmt.Println(err)
+		return nil, nil, err
 	}
 	defer rows.Close()
 
 	var users []UserList
 	for rows.Next() {
 		var p UserList
-		if err := rows.Scan(&p.Photo, &p.Fullname, &p.Phone, &p.Address, &p.Email); err != nil {
-			return nil, err
+		if err := rows.Scan(&p.Id, &p.Photo, &p.Fullname, &p.Phone, &p.Address, &p.Email); err != nil {
+			return nil, nil, err
 		}
 		users = append(users, p)
 	}
 
 	if rows.Err() != nil {
-		return nil, rows.Err()
+		return nil, nil, rows.Err()
 	}
 
-	return users, nil
+	users, next := keysetPage(page, "user", users, func(i int) (string, int) {
+		return users[i].Fullname, users[i].Id
+	})
+	return users, next, nil
 }
 
 func CreateUser(ctx context.Context, db *pgxpool.Pool, hashPassword string, user UserBody) (UserBody, error) {
@@ -96,14 +118,27 @@ func CreateUser(ctx context.Context, db *pgxpool.Pool, hashPassword string, user
 	defer tx.Rollback(ctx)
 	var userID int
 
+	// --- ROLE MUST EXIST ---
+	var roleExists bool
+	if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM roles WHERE name = $1)`, user.Role).Scan(&roleExists); err != nil {
+		log.Println("Failed to check role :", err)
+		return UserBody{}, err
+	}
+	if !roleExists {
+		return UserBody{}, ErrRoleNotFound
+	}
+
 	// --- INSERT TABLE USER ---
-	userSQL := `INSERT INTO users (email, password, role) VALUES ($1, $2, $3) 
+	userSQL := `INSERT INTO users (email, password, role, verified_at) VALUES ($1, $2, $3, NOW()) 
 		RETURNING id`
 
 	if err := tx.QueryRow(ctx, userSQL,
 		user.Email,
 		hashPassword,
 		user.Role).Scan(&userID); err != nil {
+		if IsUniqueViolation(err) {
+			return UserBody{}, ErrEmailTaken
+		}
 		log.Println("Failed to insert user :", err)
 		return UserBody{}, err
 	}
@@ -186,15 +221,7 @@ func EditUser(ctx context.Context, db *pgxpool.Pool, body UserUpdateBody, id int
 		}
 	}
 
-	// --- UPDATE EMAIL DI USERS TABLE ---
-	if body.Email != nil {
-		queryEmail := `UPDATE users SET email=$1 WHERE id=$2`
-		_, err := tx.Exec(ctx, queryEmail, *body.Email, id)
-		if err != nil {
-			log.Println("Failed to update email:", err)
-			return UserBody{}, err
-		}
-	}
+	// --- EMAIL IS NOT UPDATED HERE, IT STAYS PENDING UNTIL CONFIRMED (SEE RequestEmailChange) ---
 
 	// --- GET UPDATED DATA ---
 	var updated UserBody
@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"time"
+
+	"github.com/federus1105/koda-b4-backend/internals/pkg/libs"
+	"github.com/gin-gonic/gin"
+)
+
+// --- GIN CONTEXT KEY, ONLY SET BY THE AUTHENTICATION MIDDLEWARE ---
+const principalKey = "principal"
+
+// --- WHO IS MAKING THE REQUEST, RESOLVED ONCE FROM BEARER TOKEN OR API KEY ---
+type Identity struct {
+	ID        int
+	Role      string
+	SessionID string
+	// --- JTI OF ACCESS TOKEN, EMPTY FOR API KEY ---
+	TokenID   string
+	ExpiresAt time.Time
+	// --- ADMIN ACTING AS THE USER, SET ONLY ON IMPERSONATION TOKEN ---
+	Actor *libs.Actor
+	// --- SET ONLY FOR API KEY ---
+	APIKeyID int
+	Scopes   []string
+}
+
+func FromClaims(claims libs.Claims) Identity {
+	identity := Identity{
+		ID:        claims.ID,
+		Role:      claims.Role,
+		SessionID: claims.SessionID,
+		TokenID:   claims.RegisteredClaims.ID,
+		Actor:     claims.Actor,
+	}
+	if claims.ExpiresAt != nil {
+		identity.ExpiresAt = claims.ExpiresAt.Time
+	}
+	return identity
+}
+
+func FromAPIKey(keyID int, scopes []string) Identity {
+	return Identity{APIKeyID: keyID, Scopes: scopes}
+}
+
+func (i Identity) IsAPIKey() bool {
+	return i.APIKeyID != 0
+}
+
+func (i Identity) IsImpersonated() bool {
+	return i.Actor != nil
+}
+
+// --- REMAINING LIFETIME OF ACCESS TOKEN ---
+func (i Identity) TTL() time.Duration {
+	if i.ExpiresAt.IsZero() {
+		return 0
+	}
+	return time.Until(i.ExpiresAt)
+}
+
+func SetPrincipal(ctx *gin.Context, identity Identity) {
+	ctx.Set(principalKey, identity)
+}
+
+// --- FALSE ON ANONYMOUS REQUEST, e.g. PUBLIC ROUTE WITH OPTIONAL AUTHENTICATION ---
+func Principal(ctx *gin.Context) (Identity, bool) {
+	value, exists := ctx.Get(principalKey)
+	if !exists {
+		return Identity{}, false
+	}
+	identity, ok := value.(Identity)
+	return identity, ok
+}
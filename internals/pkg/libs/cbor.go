@@ -0,0 +1,163 @@
+package libs
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// --- MINIMAL CBOR DECODER (RFC 8949) FOR WEBAUTHN ATTESTATION AND COSE KEY ---
+// Authenticator output is definite length, so indefinite length item is rejected.
+
+const cborMaxDepth = 16
+
+var errCBOR = errors.New("malformed cbor")
+
+// --- DECODE ONE ITEM, RETURN VALUE AND NUMBER OF BYTES READ ---
+func decodeCBOR(b []byte) (any, int, error) {
+	return decodeCBORItem(b, 0)
+}
+
+func decodeCBORItem(b []byte, depth int) (any, int, error) {
+	if depth > cborMaxDepth || len(b) == 0 {
+		return nil, 0, errCBOR
+	}
+	major := b[0] >> 5
+	info := b[0] & 0x1f
+
+	// --- SIMPLE VALUE AND FLOAT ---
+	if major == 7 {
+		switch info {
+		case 20:
+			return false, 1, nil
+		case 21:
+			return true, 1, nil
+		case 22, 23:
+			return nil, 1, nil
+		case 25:
+			if len(b) < 3 {
+				return nil, 0, errCBOR
+			}
+			return float64(float16(binary.BigEndian.Uint16(b[1:3]))), 3, nil
+		case 26:
+			if len(b) < 5 {
+				return nil, 0, errCBOR
+			}
+			return float64(math.Float32frombits(binary.BigEndian.Uint32(b[1:5]))), 5, nil
+		case 27:
+			if len(b) < 9 {
+				return nil, 0, errCBOR
+			}
+			return math.Float64frombits(binary.BigEndian.Uint64(b[1:9])), 9, nil
+		}
+		return nil, 0, errCBOR
+	}
+
+	arg, n, err := cborArgument(b)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	switch major {
+	case 0:
+		if arg > math.MaxInt64 {
+			return nil, 0, errCBOR
+		}
+		return int64(arg), n, nil
+	case 1:
+		if arg > math.MaxInt64 {
+			return nil, 0, errCBOR
+		}
+		return -1 - int64(arg), n, nil
+	case 2, 3:
+		if arg > uint64(len(b)-n) {
+			return nil, 0, errCBOR
+		}
+		end := n + int(arg)
+		if major == 3 {
+			return string(b[n:end]), end, nil
+		}
+		return append([]byte(nil), b[n:end]...), end, nil
+	case 4:
+		if arg > uint64(len(b)) {
+			return nil, 0, errCBOR
+		}
+		items := make([]any, 0, arg)
+		for range arg {
+			item, m, err := decodeCBORItem(b[n:], depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			items = append(items, item)
+			n += m
+		}
+		return items, n, nil
+	case 5:
+		if arg > uint64(len(b)) {
+			return nil, 0, errCBOR
+		}
+		items := make(map[any]any, arg)
+		for range arg {
+			key, m, err := decodeCBORItem(b[n:], depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			n += m
+			switch key.(type) {
+			case int64, string:
+			default:
+				return nil, 0, errCBOR
+			}
+			value, m, err := decodeCBORItem(b[n:], depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			n += m
+			items[key] = value
+		}
+		return items, n, nil
+	case 6:
+		// --- TAG IS IGNORED, KEEP THE TAGGED ITEM ---
+		item, m, err := decodeCBORItem(b[n:], depth+1)
+		if err != nil {
+			return nil, 0, err
+		}
+		return item, n + m, nil
+	}
+	return nil, 0, errCBOR
+}
+
+// --- ARGUMENT OF ITEM HEAD, RETURN VALUE AND HEAD LENGTH ---
+func cborArgument(b []byte) (uint64, int, error) {
+	info := b[0] & 0x1f
+	switch {
+	case info < 24:
+		return uint64(info), 1, nil
+	case info == 24 && len(b) >= 2:
+		return uint64(b[1]), 2, nil
+	case info == 25 && len(b) >= 3:
+		return uint64(binary.BigEndian.Uint16(b[1:3])), 3, nil
+	case info == 26 && len(b) >= 5:
+		return uint64(binary.BigEndian.Uint32(b[1:5])), 5, nil
+	case info == 27 && len(b) >= 9:
+		return binary.BigEndian.Uint64(b[1:9]), 9, nil
+	}
+	return 0, 0, errCBOR
+}
+
+func float16(h uint16) float32 {
+	sign := uint32(h>>15) << 31
+	exp := uint32(h>>10) & 0x1f
+	frac := uint32(h & 0x3ff)
+	switch exp {
+	case 0:
+		f := float32(frac) / 1024 * float32(math.Pow(2, -14))
+		if sign != 0 {
+			return -f
+		}
+		return f
+	case 0x1f:
+		return math.Float32frombits(sign | 0x7f800000 | frac<<13)
+	}
+	return math.Float32frombits(sign | (exp+112)<<23 | frac<<13)
+}
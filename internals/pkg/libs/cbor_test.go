@@ -0,0 +1,217 @@
+package libs
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"reflect"
+	"testing"
+)
+
+// --- ENCODER FOR TESTS, MAP ENTRIES KEEP THE ORDER THEY ARE WRITTEN IN ---
+type cborPairs [][2]any
+
+func cborHead(major byte, arg uint64) []byte {
+	switch {
+	case arg < 24:
+		return []byte{major<<5 | byte(arg)}
+	case arg <= 0xff:
+		return []byte{major<<5 | 24, byte(arg)}
+	case arg <= 0xffff:
+		return binary.BigEndian.AppendUint16([]byte{major<<5 | 25}, uint16(arg))
+	case arg <= 0xffffffff:
+		return binary.BigEndian.AppendUint32([]byte{major<<5 | 26}, uint32(arg))
+	}
+	return binary.BigEndian.AppendUint64([]byte{major<<5 | 27}, arg)
+}
+
+func cborEncode(v any) []byte {
+	switch v := v.(type) {
+	case int:
+		if v < 0 {
+			return cborHead(1, uint64(-1-v))
+		}
+		return cborHead(0, uint64(v))
+	case string:
+		return append(cborHead(3, uint64(len(v))), v...)
+	case []byte:
+		return append(cborHead(2, uint64(len(v))), v...)
+	case []any:
+		out := cborHead(4, uint64(len(v)))
+		for _, item := range v {
+			out = append(out, cborEncode(item)...)
+		}
+		return out
+	case cborPairs:
+		out := cborHead(5, uint64(len(v)))
+		for _, pair := range v {
+			out = append(out, cborEncode(pair[0])...)
+			out = append(out, cborEncode(pair[1])...)
+		}
+		return out
+	}
+	panic("cborEncode: unsupported type")
+}
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// --- RFC 8949 APPENDIX A ---
+func TestDecodeCBORVectors(t *testing.T) {
+	tests := []struct {
+		in   string
+		want any
+	}{
+		{"00", int64(0)},
+		{"17", int64(23)},
+		{"1818", int64(24)},
+		{"190100", int64(256)},
+		{"1a000f4240", int64(1000000)},
+		{"1b000000e8d4a51000", int64(1000000000000)},
+		{"20", int64(-1)},
+		{"3863", int64(-100)},
+		{"3903e7", int64(-1000)},
+		{"40", []byte(nil)},
+		{"4401020304", []byte{1, 2, 3, 4}},
+		{"60", ""},
+		{"6161", "a"},
+		{"62c3bc", "ü"},
+		{"80", []any{}},
+		{"83010203", []any{int64(1), int64(2), int64(3)}},
+		{"8301820203820405", []any{int64(1), []any{int64(2), int64(3)}, []any{int64(4), int64(5)}}},
+		{"a0", map[any]any{}},
+		{"a201020304", map[any]any{int64(1): int64(2), int64(3): int64(4)}},
+		{"a26161016162820203", map[any]any{"a": int64(1), "b": []any{int64(2), int64(3)}}},
+		{"f4", false},
+		{"f5", true},
+		{"f6", nil},
+		{"f7", nil},
+		{"f93c00", float64(1)},
+		{"f97bff", float64(65504)},
+		{"f90001", float64(5.960464477539063e-08)},
+		{"f9c400", float64(-4)},
+		{"fa47c35000", float64(100000)},
+		{"fb3ff199999999999a", 1.1},
+		{"c11a514b67b0", int64(1363896240)},
+		{"d74401020304", []byte{1, 2, 3, 4}},
+	}
+	for _, tt := range tests {
+		in := mustHex(t, tt.in)
+		got, n, err := decodeCBOR(in)
+		if err != nil {
+			t.Errorf("decodeCBOR(%s): %v", tt.in, err)
+			continue
+		}
+		if n != len(in) {
+			t.Errorf("decodeCBOR(%s) read %d bytes, want %d", tt.in, n, len(in))
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("decodeCBOR(%s) = %#v, want %#v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeCBORTrailingBytes(t *testing.T) {
+	got, n, err := decodeCBOR(mustHex(t, "0102"))
+	if err != nil || got != int64(1) || n != 1 {
+		t.Fatalf("decodeCBOR = %v, %d, %v, want 1, 1, nil", got, n, err)
+	}
+}
+
+func TestDecodeCBORMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{"empty", ""},
+		{"missing 1 byte argument", "18"},
+		{"missing 2 byte argument", "1901"},
+		{"missing 4 byte argument", "1a0000"},
+		{"missing 8 byte argument", "1b00000000"},
+		{"reserved additional info", "1c"},
+		{"uint over int64", "1bffffffffffffffff"},
+		{"negative int over int64", "3bffffffffffffffff"},
+		{"truncated byte string", "44010203"},
+		{"truncated text string", "6361"},
+		{"huge byte string length", "5bffffffffffffffff"},
+		{"truncated array", "830102"},
+		{"huge array length", "9bffffffffffffffff"},
+		{"truncated map value", "a20102"},
+		{"map key without value", "a101"},
+		{"huge map length", "bbffffffffffffffff"},
+		{"array as map key", "a18001"},
+		{"bytes as map key", "a14001"},
+		{"indefinite byte string", "5f4101ff"},
+		{"indefinite array", "9f01ff"},
+		{"indefinite map", "bf0101ff"},
+		{"break without indefinite item", "ff"},
+		{"unsupported simple value", "f820"},
+		{"truncated half float", "f93c"},
+		{"truncated single float", "fa47c350"},
+		{"truncated double float", "fb3ff1999999"},
+		{"tag without item", "c1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _, err := decodeCBOR(mustHex(t, tt.in)); err == nil {
+				t.Fatalf("decodeCBOR(%s) = %#v, want error", tt.in, got)
+			}
+		})
+	}
+}
+
+func TestDecodeCBORDepth(t *testing.T) {
+	nested := func(depth int) []byte {
+		return append(bytes.Repeat([]byte{0x81}, depth), 0x00)
+	}
+	if _, _, err := decodeCBOR(nested(cborMaxDepth)); err != nil {
+		t.Fatalf("depth %d: %v", cborMaxDepth, err)
+	}
+	if _, _, err := decodeCBOR(nested(cborMaxDepth + 1)); err == nil {
+		t.Fatalf("depth %d: want error", cborMaxDepth+1)
+	}
+}
+
+// --- EVERY PREFIX OF A VALID ITEM IS TRUNCATED INPUT ---
+func TestDecodeCBORTruncated(t *testing.T) {
+	full := cborEncode(cborPairs{
+		{"fmt", "none"},
+		{"attStmt", cborPairs{}},
+		{"authData", bytes.Repeat([]byte{0xab}, 300)},
+		{1, []any{-7, 70000, "x"}},
+	})
+	if _, n, err := decodeCBOR(full); err != nil || n != len(full) {
+		t.Fatalf("full item: n=%d err=%v", n, err)
+	}
+	for i := range full {
+		if _, _, err := decodeCBOR(full[:i]); err == nil {
+			t.Fatalf("prefix of %d/%d bytes decoded without error", i, len(full))
+		}
+	}
+}
+
+func TestFloat16(t *testing.T) {
+	tests := []struct {
+		in   uint16
+		want float32
+	}{
+		{0x0000, 0},
+		{0x3c00, 1},
+		{0x3e00, 1.5},
+		{0xc000, -2},
+		{0x7bff, 65504},
+		{0x0400, 6.103515625e-05},
+		{0x8001, -5.960464477539063e-08},
+	}
+	for _, tt := range tests {
+		if got := float16(tt.in); got != tt.want {
+			t.Errorf("float16(%#04x) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
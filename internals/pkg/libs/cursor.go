@@ -0,0 +1,77 @@
+package libs
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"sync"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// --- POSITION AFTER THE LAST ROW OF A PAGE, OPAQUE TO THE CLIENT ---
+type Cursor struct {
+	// --- LIST THE CURSOR BELONGS TO, A CURSOR OF ANOTHER LIST IS REJECTED ---
+	List string `json:"l"`
+	// --- SORT KEY OF THE LAST ROW IN TEXT FORM, CAST BACK BY THE QUERY ---
+	Key string `json:"k"`
+	ID  int    `json:"i"`
+}
+
+var (
+	cursorSecret     []byte
+	cursorSecretOnce sync.Once
+)
+
+// --- CURSOR_SECRET, ELSE JWT_SECRET, ELSE A RANDOM KEY THAT ONLY LASTS UNTIL RESTART ---
+func cursorKey() []byte {
+	cursorSecretOnce.Do(func() {
+		for _, env := range []string{"CURSOR_SECRET", "JWT_SECRET"} {
+			if secret := os.Getenv(env); secret != "" {
+				cursorSecret = []byte(secret)
+				return
+			}
+		}
+		cursorSecret = make([]byte, 32)
+		if _, err := rand.Read(cursorSecret); err != nil {
+			panic(err)
+		}
+		log.Println("CURSOR_SECRET is not set, cursors stop working after restart")
+	})
+	return cursorSecret
+}
+
+func cursorMAC(payload string) string {
+	mac := hmac.New(sha256.New, cursorKey())
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (c Cursor) Encode() string {
+	b, _ := json.Marshal(c)
+	payload := base64.RawURLEncoding.EncodeToString(b)
+	return payload + "." + cursorMAC(payload)
+}
+
+func DecodeCursor(token, list string) (*Cursor, error) {
+	payload, signature, found := strings.Cut(token, ".")
+	if !found || !hmac.Equal([]byte(signature), []byte(cursorMAC(payload))) {
+		return nil, ErrInvalidCursor
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor Cursor
+	if err := json.Unmarshal(b, &cursor); err != nil || cursor.List != list {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
@@ -0,0 +1,64 @@
+package libs
+
+import (
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	for _, c := range []Cursor{
+		{List: "product", Key: "18000", ID: 7},
+		{List: "user", Key: "", ID: 1},
+		{List: "order", Key: "2026-10-18T08:00:00Z", ID: 42},
+		{List: "user", Key: "Ayu \"Kopi\" & <Susu>.", ID: 3},
+	} {
+		token := c.Encode()
+		if strings.ContainsAny(token, "+/=") {
+			t.Errorf("token %q is not url safe", token)
+		}
+		got, err := DecodeCursor(token, c.List)
+		if err != nil {
+			t.Fatalf("DecodeCursor(%+v): %v", c, err)
+		}
+		if !reflect.DeepEqual(*got, c) {
+			t.Fatalf("DecodeCursor = %+v, want %+v", *got, c)
+		}
+	}
+}
+
+func TestDecodeCursorRejected(t *testing.T) {
+	token := Cursor{List: "product", Key: "18000", ID: 7}.Encode()
+	payload, mac, _ := strings.Cut(token, ".")
+
+	// --- SAME MAC, PAYLOAD POINTING AT ANOTHER ROW ---
+	forged := base64.RawURLEncoding.EncodeToString([]byte(`{"l":"product","k":"0","i":1}`)) + "." + mac
+	flipped := []byte(mac)
+	flipped[0] ^= 0x01
+	// --- VALID MAC OVER A PAYLOAD THAT ISN'T JSON ---
+	garbage := base64.RawURLEncoding.EncodeToString([]byte("not json"))
+
+	tests := []struct {
+		name  string
+		token string
+		list  string
+	}{
+		{"other list", token, "user"},
+		{"empty", "", "product"},
+		{"no mac", payload, "product"},
+		{"empty mac", payload + ".", "product"},
+		{"bad mac", payload + "." + string(flipped), "product"},
+		{"forged payload", forged, "product"},
+		{"payload not base64", "!!!." + cursorMAC("!!!"), "product"},
+		{"payload not json", garbage + "." + cursorMAC(garbage), "product"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := DecodeCursor(tt.token, tt.list); !errors.Is(err, ErrInvalidCursor) {
+				t.Fatalf("DecodeCursor = %+v, %v, want %v", got, err, ErrInvalidCursor)
+			}
+		})
+	}
+}
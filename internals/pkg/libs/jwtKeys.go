@@ -0,0 +1,242 @@
+package libs
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var ErrJWTKeysNotLoaded = errors.New("jwt keys not loaded")
+
+type jwtKey struct {
+	kid     string
+	method  jwt.SigningMethod
+	private crypto.Signer
+	public  crypto.PublicKey
+}
+
+// --- KEYS USED TO SIGN AND VERIFY ACCESS TOKEN ---
+// --- ASYMMETRIC MODE WHEN KEYS ARE CONFIGURED, OTHERWISE HS256 WITH JWT_SECRET ---
+type JWTKeySet struct {
+	active *jwtKey
+	keys   map[string]*jwtKey
+	secret []byte
+}
+
+var jwtKeySet *JWTKeySet
+
+// --- LOAD KEYS ONCE AT STARTUP ---
+func InitJWTKeys() error {
+	set, err := LoadJWTKeys()
+	if err != nil {
+		return err
+	}
+	jwtKeySet = set
+	return nil
+}
+
+// --- KEYS FROM JWT_KEYS_DIR (<kid>.pem) AND JWT_KEYS (kid=base64 pem, comma separated) ---
+// --- PRIVATE KEY CAN SIGN, PUBLIC KEY ONLY VERIFY, KEEP OLD ONE DURING ROTATION ---
+func LoadJWTKeys() (*JWTKeySet, error) {
+	pems := map[string][]byte{}
+
+	if dir := os.Getenv("JWT_KEYS_DIR"); dir != "" {
+		files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return nil, err
+			}
+			pems[strings.TrimSuffix(filepath.Base(file), ".pem")] = data
+		}
+	}
+
+	if env := os.Getenv("JWT_KEYS"); env != "" {
+		for _, entry := range strings.Split(env, ",") {
+			kid, encoded, ok := strings.Cut(strings.TrimSpace(entry), "=")
+			if !ok || kid == "" {
+				return nil, fmt.Errorf("invalid JWT_KEYS entry %q", entry)
+			}
+			data, err := base64.StdEncoding.DecodeString(encoded)
+			if err != nil {
+				return nil, fmt.Errorf("decode JWT_KEYS entry %q: %w", kid, err)
+			}
+			pems[kid] = data
+		}
+	}
+
+	// --- LEGACY SHARED SECRET ---
+	if len(pems) == 0 {
+		secret := os.Getenv("JWT_SECRET")
+		if secret == "" {
+			return nil, errors.New("no JWT_KEYS_DIR, JWT_KEYS or JWT_SECRET configured")
+		}
+		log.Println("⚠ JWT signed with HS256 shared secret, configure JWT_KEYS_DIR to use asymmetric keys")
+		return &JWTKeySet{secret: []byte(secret)}, nil
+	}
+
+	set := &JWTKeySet{keys: map[string]*jwtKey{}}
+	for kid, data := range pems {
+		key, err := parseJWTKey(kid, data)
+		if err != nil {
+			return nil, err
+		}
+		set.keys[kid] = key
+	}
+
+	activeKid := os.Getenv("JWT_ACTIVE_KID")
+	if activeKid == "" {
+		return nil, errors.New("JWT_ACTIVE_KID is required when JWT keys are configured")
+	}
+	active, ok := set.keys[activeKid]
+	if !ok || active.private == nil {
+		return nil, fmt.Errorf("no private key found for JWT_ACTIVE_KID %q", activeKid)
+	}
+	set.active = active
+
+	log.Printf("✅ JWT keys loaded, signing with %s (%s), %d key(s) accepted", active.kid, active.method.Alg(), len(set.keys))
+	return set, nil
+}
+
+func parseJWTKey(kid string, data []byte) (*jwtKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("key %q is not PEM encoded", kid)
+	}
+
+	var parsed any
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		parsed, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("key %q has unsupported PEM type %q", kid, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse key %q: %w", kid, err)
+	}
+
+	key := &jwtKey{kid: kid}
+	if signer, ok := parsed.(crypto.Signer); ok {
+		key.private = signer
+		parsed = signer.Public()
+	}
+	key.public = parsed
+
+	switch pub := parsed.(type) {
+	case *rsa.PublicKey:
+		if pub.N.BitLen() < 2048 {
+			return nil, fmt.Errorf("rsa key %q must be at least 2048 bits", kid)
+		}
+		key.method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		key.method = jwt.SigningMethodEdDSA
+	case *ecdsa.PublicKey:
+		if pub.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("ecdsa key %q must use P-256", kid)
+		}
+		key.method = jwt.SigningMethodES256
+	default:
+		return nil, fmt.Errorf("key %q has unsupported type %T", kid, parsed)
+	}
+	return key, nil
+}
+
+// --- SIGN CLAIMS WITH ACTIVE KEY ---
+func (s *JWTKeySet) sign(claims jwt.Claims) (string, error) {
+	if s.active == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+	}
+	token := jwt.NewWithClaims(s.active.method, claims)
+	token.Header["kid"] = s.active.kid
+	return token.SignedString(s.active.private)
+}
+
+// --- SELECT VERIFICATION KEY BY KID ---
+func (s *JWTKeySet) keyFunc(t *jwt.Token) (any, error) {
+	if s.active == nil {
+		if t.Method != jwt.SigningMethodHS256 {
+			return nil, jwt.ErrTokenSignatureInvalid
+		}
+		return s.secret, nil
+	}
+	kid, _ := t.Header["kid"].(string)
+	key, ok := s.keys[kid]
+	if !ok || key.method.Alg() != t.Method.Alg() {
+		return nil, jwt.ErrTokenUnverifiable
+	}
+	return key.public, nil
+}
+
+func (s *JWTKeySet) validMethods() []string {
+	if s.active == nil {
+		return []string{jwt.SigningMethodHS256.Alg()}
+	}
+	return []string{"RS256", "EdDSA", "ES256"}
+}
+
+// --- PUBLIC KEYS FOR /.well-known/jwks.json ---
+func JWKS() (map[string]any, error) {
+	if jwtKeySet == nil {
+		return nil, ErrJWTKeysNotLoaded
+	}
+
+	kids := make([]string, 0, len(jwtKeySet.keys))
+	for kid := range jwtKeySet.keys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	keys := []map[string]string{}
+	for _, kid := range kids {
+		key := jwtKeySet.keys[kid]
+		jwk := map[string]string{
+			"kid": key.kid,
+			"use": "sig",
+			"alg": key.method.Alg(),
+		}
+		switch pub := key.public.(type) {
+		case *rsa.PublicKey:
+			jwk["kty"] = "RSA"
+			jwk["n"] = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk["e"] = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk["kty"] = "OKP"
+			jwk["crv"] = "Ed25519"
+			jwk["x"] = base64.RawURLEncoding.EncodeToString(pub)
+		case *ecdsa.PublicKey:
+			jwk["kty"] = "EC"
+			jwk["crv"] = "P-256"
+			jwk["x"] = base64.RawURLEncoding.EncodeToString(pub.X.FillBytes(make([]byte, 32)))
+			jwk["y"] = base64.RawURLEncoding.EncodeToString(pub.Y.FillBytes(make([]byte, 32)))
+		}
+		keys = append(keys, jwk)
+	}
+	return map[string]any{"keys": keys}, nil
+}
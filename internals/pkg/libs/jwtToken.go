@@ -1,50 +1,86 @@
 package libs
 
 import (
-	"errors"
 	"os"
 	"time"
 
+	"github.com/federus1105/koda-b4-backend/internals/pkg/utils"
 	"github.com/golang-jwt/jwt/v5"
-	"github.com/joho/godotenv"
+)
+
+// --- TOKEN LIFETIME ---
+const (
+	AccessTokenTTL   = 15 * time.Minute
+	RefreshTokenTTL  = 7 * 24 * time.Hour
+	ImpersonationTTL = 10 * time.Minute
 )
 
 type Claims struct {
-	ID   int    `json:"id"`
-	Role string `json:"role"`
+	ID        int    `json:"id"`
+	Role      string `json:"role"`
+	SessionID string `json:"sid,omitempty"`
+	// --- ADMIN ACTING AS THE USER, SET ONLY ON IMPERSONATION TOKEN ---
+	Actor *Actor `json:"act,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func NewJWTClaims(ID int, role string) *Claims {
+// --- jti IS HOW A TOKEN IS REVOKED, NO TOKEN IS ISSUED WITHOUT ONE ---
+func NewJWTClaims(ID int, role string) (*Claims, error) {
+	jti, err := utils.GenerateRandomToken(16)
+	if err != nil {
+		return nil, err
+	}
 	return &Claims{
 		ID:   ID,
 		Role: role,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute * 60)),
+			ID:        jti,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(AccessTokenTTL)),
 			Issuer:    os.Getenv("JWT_ISSUER"),
 		},
+	}, nil
+}
+
+type Actor struct {
+	ID    int    `json:"id"`
+	Email string `json:"email"`
+}
+
+// --- SHORT-LIVED TOKEN OF USER, WITHOUT SESSION OR REFRESH TOKEN ---
+func NewImpersonationClaims(ID int, role string, actor Actor) (*Claims, error) {
+	claims, err := NewJWTClaims(ID, role)
+	if err != nil {
+		return nil, err
 	}
+	claims.Actor = &actor
+	claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(ImpersonationTTL))
+	return claims, nil
+}
+
+// --- IMPERSONATION IS READ ONLY UNLESS IMPERSONATION_ALLOW_WRITE=true ---
+func ImpersonationAllowWrite() bool {
+	return os.Getenv("IMPERSONATION_ALLOW_WRITE") == "true"
+}
+
+// --- TOKEN WAS ISSUED TO AN ADMIN ACTING AS THE USER ---
+func (c *Claims) IsImpersonated() bool {
+	return c.Actor != nil
 }
 
 // --- GENERATE TOKEN ---
 func (c *Claims) GenToken() (string, error) {
-	godotenv.Load()
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		return "", errors.New("no secret found")
+	if jwtKeySet == nil {
+		return "", ErrJWTKeysNotLoaded
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, c)
-	return token.SignedString([]byte(jwtSecret))
+	return jwtKeySet.sign(c)
 }
 
 // ---- LOGIC VERIFY TOKEN ---
 func (c *Claims) VerifyToken(token string) error {
-	godotenv.Load()
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		return errors.New("no secret found")
+	if jwtKeySet == nil {
+		return ErrJWTKeysNotLoaded
 	}
-	parsedToken, err := jwt.ParseWithClaims(token, c, func(t *jwt.Token) (any, error) { return []byte(jwtSecret), nil })
+	parsedToken, err := jwt.ParseWithClaims(token, c, jwtKeySet.keyFunc, jwt.WithValidMethods(jwtKeySet.validMethods()))
 	if err != nil {
 		return err
 	}
@@ -60,3 +96,11 @@ func (c *Claims) VerifyToken(token string) error {
 	}
 	return nil
 }
+
+// --- REMAINING LIFETIME OF TOKEN ---
+func (c *Claims) TTL() time.Duration {
+	if c.ExpiresAt == nil {
+		return 0
+	}
+	return time.Until(c.ExpiresAt.Time)
+}
@@ -0,0 +1,141 @@
+package libs
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// --- RFC 6238 APPENDIX B SHA1 SEED "12345678901234567890" ---
+var rfc6238Secret = totpEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestHOTPRFC4226Vectors(t *testing.T) {
+	want := []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+	for counter, code := range want {
+		if got := hotp([]byte("12345678901234567890"), uint64(counter)); got != code {
+			t.Errorf("hotp(%d) = %s, want %s", counter, got, code)
+		}
+	}
+}
+
+func TestValidateTOTPRFC6238Vectors(t *testing.T) {
+	// --- 8 DIGIT VECTORS OF THE RFC, THE APP USES THE LAST 6 ---
+	tests := []struct {
+		unix int64
+		code string
+		step int64
+	}{
+		{59, "287082", 1},
+		{1111111109, "081804", 37037036},
+		{1111111111, "050471", 37037037},
+		{1234567890, "005924", 41152263},
+		{2000000000, "279037", 66666666},
+		{20000000000, "353130", 666666666},
+	}
+	for _, tt := range tests {
+		step, ok := ValidateTOTP(rfc6238Secret, tt.code, time.Unix(tt.unix, 0))
+		if !ok {
+			t.Errorf("ValidateTOTP at %d rejected %s", tt.unix, tt.code)
+			continue
+		}
+		if step != tt.step {
+			t.Errorf("ValidateTOTP at %d matched step %d, want %d", tt.unix, step, tt.step)
+		}
+	}
+}
+
+func TestValidateTOTPWindow(t *testing.T) {
+	// --- "081804" IS STEP 37037036, 1111111080 TO 1111111109 ---
+	const code = "081804"
+	tests := []struct {
+		name string
+		unix int64
+		ok   bool
+	}{
+		{"two steps early", 1111111049, false},
+		{"first second of previous step", 1111111050, true},
+		{"last second of previous step", 1111111079, true},
+		{"first second of its step", 1111111080, true},
+		{"last second of its step", 1111111109, true},
+		{"first second of next step", 1111111110, true},
+		{"last second of next step", 1111111139, true},
+		{"two steps late", 1111111140, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := ValidateTOTP(rfc6238Secret, code, time.Unix(tt.unix, 0))
+			if ok != tt.ok {
+				t.Fatalf("ValidateTOTP at %d = %v, want %v", tt.unix, ok, tt.ok)
+			}
+			if ok && step != 37037036 {
+				t.Fatalf("matched step %d, want 37037036", step)
+			}
+		})
+	}
+}
+
+func TestValidateTOTPInput(t *testing.T) {
+	at := time.Unix(59, 0)
+	tests := []struct {
+		name   string
+		secret string
+		code   string
+		ok     bool
+	}{
+		{"surrounding spaces", rfc6238Secret, " 287082 ", true},
+		{"lowercase secret", strings.ToLower(rfc6238Secret), "287082", true},
+		{"wrong code", rfc6238Secret, "287083", false},
+		{"too short", rfc6238Secret, "28708", false},
+		{"too long", rfc6238Secret, "2870820", false},
+		{"empty", rfc6238Secret, "", false},
+		{"invalid secret", "not base32!", "287082", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := ValidateTOTP(tt.secret, tt.code, at); ok != tt.ok {
+				t.Fatalf("ValidateTOTP = %v, want %v", ok, tt.ok)
+			}
+		})
+	}
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := totpEncoding.DecodeString(secret)
+	if err != nil {
+		t.Fatalf("secret %q is not base32: %v", secret, err)
+	}
+	if len(key) != 20 {
+		t.Fatalf("secret has %d bytes, want 20", len(key))
+	}
+
+	now := time.Now()
+	code := hotp(key, uint64(now.Unix()/totpPeriod))
+	if _, ok := ValidateTOTP(secret, code, now); !ok {
+		t.Fatal("code of a generated secret is rejected")
+	}
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	uri := TOTPProvisioningURI("Koda Coffee", "user@mail.com", "ABC")
+	u, err := url.Parse(uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Scheme != "otpauth" || u.Host != "totp" {
+		t.Fatalf("unexpected uri %s", uri)
+	}
+	if u.Path != "/Koda Coffee:user@mail.com" {
+		t.Fatalf("label = %q", u.Path)
+	}
+	q := u.Query()
+	for key, want := range map[string]string{"secret": "ABC", "issuer": "Koda Coffee", "digits": "6", "period": "30", "algorithm": "SHA1"} {
+		if q.Get(key) != want {
+			t.Errorf("%s = %q, want %q", key, q.Get(key), want)
+		}
+	}
+}
@@ -0,0 +1,430 @@
+package libs
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// --- COSE ALGORITHM, ORDER IS OUR PREFERENCE ---
+const (
+	coseAlgES256 = -7
+	coseAlgEdDSA = -8
+	coseAlgRS256 = -257
+)
+
+// --- AUTHENTICATOR DATA FLAG ---
+const (
+	authFlagUserPresent    = 0x01
+	authFlagUserVerified   = 0x04
+	authFlagBackupEligible = 0x08
+	authFlagBackedUp       = 0x10
+	authFlagAttestedData   = 0x40
+)
+
+const WebAuthnTimeout = 5 * time.Minute
+
+var (
+	ErrWebAuthnInvalid   = errors.New("invalid passkey response")
+	ErrWebAuthnAlgorithm = errors.New("passkey algorithm is not supported")
+	ErrWebAuthnCounter   = errors.New("passkey signature counter went backwards, the authenticator may be cloned")
+)
+
+type WebAuthnConfig struct {
+	RPID    string
+	RPName  string
+	Origins []string
+}
+
+// --- RELYING PARTY FROM ENV, DEFAULT TO FRONTEND_URL ---
+func WebAuthnConfigFromEnv() WebAuthnConfig {
+	cfg := WebAuthnConfig{
+		RPID:   os.Getenv("WEBAUTHN_RP_ID"),
+		RPName: os.Getenv("WEBAUTHN_RP_NAME"),
+	}
+	for origin := range strings.SplitSeq(os.Getenv("WEBAUTHN_ORIGINS"), ",") {
+		if origin = strings.TrimRight(strings.TrimSpace(origin), "/"); origin != "" {
+			cfg.Origins = append(cfg.Origins, origin)
+		}
+	}
+
+	frontend := strings.TrimRight(os.Getenv("FRONTEND_URL"), "/")
+	if len(cfg.Origins) == 0 && frontend != "" {
+		cfg.Origins = []string{frontend}
+	}
+	if cfg.RPID == "" {
+		cfg.RPID = "localhost"
+		if u, err := url.Parse(frontend); err == nil && u.Hostname() != "" {
+			cfg.RPID = u.Hostname()
+		}
+	}
+	if cfg.RPName == "" {
+		cfg.RPName = "Senja Kopi Kiri"
+	}
+	return cfg
+}
+
+// --- OPTIONS FOR navigator.credentials.create(), SAME SHAPE AS PublicKeyCredentialCreationOptionsJSON ---
+type WebAuthnCreationOptions struct {
+	Challenge              string                      `json:"challenge"`
+	RP                     WebAuthnRelyingParty        `json:"rp"`
+	User                   WebAuthnUser                `json:"user"`
+	PubKeyCredParams       []WebAuthnCredentialParam   `json:"pubKeyCredParams"`
+	Timeout                int64                       `json:"timeout"`
+	ExcludeCredentials     []WebAuthnCredentialDesc    `json:"excludeCredentials"`
+	AuthenticatorSelection WebAuthnAuthenticatorSelect `json:"authenticatorSelection"`
+	Attestation            string                      `json:"attestation"`
+}
+
+// --- OPTIONS FOR navigator.credentials.get(), SAME SHAPE AS PublicKeyCredentialRequestOptionsJSON ---
+type WebAuthnRequestOptions struct {
+	Challenge        string `json:"challenge"`
+	RPID             string `json:"rpId"`
+	Timeout          int64  `json:"timeout"`
+	UserVerification string `json:"userVerification"`
+}
+
+type WebAuthnRelyingParty struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type WebAuthnUser struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+type WebAuthnCredentialParam struct {
+	Type string `json:"type"`
+	Alg  int    `json:"alg"`
+}
+
+type WebAuthnCredentialDesc struct {
+	Type       string   `json:"type"`
+	ID         string   `json:"id"`
+	Transports []string `json:"transports,omitempty"`
+}
+
+type WebAuthnAuthenticatorSelect struct {
+	ResidentKey        string `json:"residentKey"`
+	RequireResidentKey bool   `json:"requireResidentKey"`
+	UserVerification   string `json:"userVerification"`
+}
+
+// --- CREDENTIAL ID, EXCLUDED FROM REGISTRATION SO A DEVICE IS NOT ADDED TWICE ---
+type WebAuthnExclude struct {
+	ID         []byte
+	Transports []string
+}
+
+// --- NEW CREDENTIAL FROM A VALID REGISTRATION ---
+type WebAuthnCredential struct {
+	ID             []byte
+	PublicKey      []byte
+	SignCount      uint32
+	AAGUID         []byte
+	BackupEligible bool
+	BackedUp       bool
+}
+
+// --- RESULT OF A VALID ASSERTION ---
+type WebAuthnAssertion struct {
+	SignCount    uint32
+	UserVerified bool
+	BackedUp     bool
+}
+
+type webAuthnClientData struct {
+	Type        string `json:"type"`
+	Challenge   string `json:"challenge"`
+	Origin      string `json:"origin"`
+	CrossOrigin bool   `json:"crossOrigin"`
+}
+
+type authenticatorData struct {
+	RPIDHash     []byte
+	Flags        byte
+	SignCount    uint32
+	AAGUID       []byte
+	CredentialID []byte
+	PublicKey    []byte
+}
+
+// --- BROWSER SENDS BASE64URL WITHOUT PADDING, PADDING IS TOLERATED ---
+func DecodeBase64URL(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
+
+func EncodeBase64URL(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func (c WebAuthnConfig) CreationOptions(challenge string, userHandle []byte, name, displayName string, exclude []WebAuthnExclude) WebAuthnCreationOptions {
+	options := WebAuthnCreationOptions{
+		Challenge: challenge,
+		RP:        WebAuthnRelyingParty{ID: c.RPID, Name: c.RPName},
+		User: WebAuthnUser{
+			ID:          EncodeBase64URL(userHandle),
+			Name:        name,
+			DisplayName: displayName,
+		},
+		PubKeyCredParams: []WebAuthnCredentialParam{
+			{Type: "public-key", Alg: coseAlgES256},
+			{Type: "public-key", Alg: coseAlgEdDSA},
+			{Type: "public-key", Alg: coseAlgRS256},
+		},
+		Timeout:            WebAuthnTimeout.Milliseconds(),
+		ExcludeCredentials: []WebAuthnCredentialDesc{},
+		AuthenticatorSelection: WebAuthnAuthenticatorSelect{
+			ResidentKey:        "required",
+			RequireResidentKey: true,
+			UserVerification:   "preferred",
+		},
+		Attestation: "none",
+	}
+	for _, cred := range exclude {
+		options.ExcludeCredentials = append(options.ExcludeCredentials, WebAuthnCredentialDesc{
+			Type:       "public-key",
+			ID:         EncodeBase64URL(cred.ID),
+			Transports: cred.Transports,
+		})
+	}
+	return options
+}
+
+func (c WebAuthnConfig) RequestOptions(challenge string) WebAuthnRequestOptions {
+	return WebAuthnRequestOptions{
+		Challenge:        challenge,
+		RPID:             c.RPID,
+		Timeout:          WebAuthnTimeout.Milliseconds(),
+		UserVerification: "preferred",
+	}
+}
+
+// --- CHALLENGE IN CLIENT DATA, USED TO FIND THE PENDING CEREMONY ---
+func WebAuthnChallenge(clientDataJSON []byte) (string, error) {
+	var data webAuthnClientData
+	if err := json.Unmarshal(clientDataJSON, &data); err != nil || data.Challenge == "" {
+		return "", ErrWebAuthnInvalid
+	}
+	return data.Challenge, nil
+}
+
+// --- VERIFY navigator.credentials.create() RESPONSE ---
+// Attestation is requested as "none", so the attestation statement is not checked,
+// the credential is trusted because the user is logged in when registering it.
+func (c WebAuthnConfig) VerifyRegistration(challenge string, clientDataJSON, attestationObject []byte) (WebAuthnCredential, error) {
+	if err := c.verifyClientData(clientDataJSON, "webauthn.create", challenge); err != nil {
+		return WebAuthnCredential{}, err
+	}
+
+	decoded, _, err := decodeCBOR(attestationObject)
+	if err != nil {
+		return WebAuthnCredential{}, ErrWebAuthnInvalid
+	}
+	object, ok := decoded.(map[any]any)
+	if !ok {
+		return WebAuthnCredential{}, ErrWebAuthnInvalid
+	}
+	rawAuthData, ok := object["authData"].([]byte)
+	if !ok {
+		return WebAuthnCredential{}, ErrWebAuthnInvalid
+	}
+
+	authData, err := c.verifyAuthenticatorData(rawAuthData)
+	if err != nil {
+		return WebAuthnCredential{}, err
+	}
+	if authData.Flags&authFlagAttestedData == 0 || len(authData.CredentialID) == 0 {
+		return WebAuthnCredential{}, ErrWebAuthnInvalid
+	}
+	if _, _, err := parseCOSEKey(authData.PublicKey); err != nil {
+		return WebAuthnCredential{}, err
+	}
+
+	return WebAuthnCredential{
+		ID:             authData.CredentialID,
+		PublicKey:      authData.PublicKey,
+		SignCount:      authData.SignCount,
+		AAGUID:         authData.AAGUID,
+		BackupEligible: authData.Flags&authFlagBackupEligible != 0,
+		BackedUp:       authData.Flags&authFlagBackedUp != 0,
+	}, nil
+}
+
+// --- VERIFY navigator.credentials.get() RESPONSE AGAINST STORED PUBLIC KEY ---
+func (c WebAuthnConfig) VerifyAssertion(challenge string, publicKey []byte, storedCount uint32, clientDataJSON, rawAuthData, signature []byte) (WebAuthnAssertion, error) {
+	if err := c.verifyClientData(clientDataJSON, "webauthn.get", challenge); err != nil {
+		return WebAuthnAssertion{}, err
+	}
+	authData, err := c.verifyAuthenticatorData(rawAuthData)
+	if err != nil {
+		return WebAuthnAssertion{}, err
+	}
+
+	alg, key, err := parseCOSEKey(publicKey)
+	if err != nil {
+		return WebAuthnAssertion{}, err
+	}
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signed := append(append([]byte(nil), rawAuthData...), clientDataHash[:]...)
+	if !verifyCOSESignature(alg, key, signed, signature) {
+		return WebAuthnAssertion{}, ErrWebAuthnInvalid
+	}
+
+	// --- AUTHENTICATOR WITHOUT COUNTER ALWAYS SENDS ZERO ---
+	if (authData.SignCount != 0 || storedCount != 0) && authData.SignCount <= storedCount {
+		return WebAuthnAssertion{}, ErrWebAuthnCounter
+	}
+
+	return WebAuthnAssertion{
+		SignCount:    authData.SignCount,
+		UserVerified: authData.Flags&authFlagUserVerified != 0,
+		BackedUp:     authData.Flags&authFlagBackedUp != 0,
+	}, nil
+}
+
+func (c WebAuthnConfig) verifyClientData(raw []byte, ceremony, challenge string) error {
+	var data webAuthnClientData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return ErrWebAuthnInvalid
+	}
+	if data.Type != ceremony || data.CrossOrigin {
+		return ErrWebAuthnInvalid
+	}
+	if subtle.ConstantTimeCompare([]byte(data.Challenge), []byte(challenge)) != 1 {
+		return ErrWebAuthnInvalid
+	}
+	if !slices.Contains(c.Origins, data.Origin) {
+		return ErrWebAuthnInvalid
+	}
+	return nil
+}
+
+func (c WebAuthnConfig) verifyAuthenticatorData(raw []byte) (authenticatorData, error) {
+	authData, err := parseAuthenticatorData(raw)
+	if err != nil {
+		return authenticatorData{}, err
+	}
+	rpIDHash := sha256.Sum256([]byte(c.RPID))
+	if !bytes.Equal(authData.RPIDHash, rpIDHash[:]) {
+		return authenticatorData{}, ErrWebAuthnInvalid
+	}
+	if authData.Flags&authFlagUserPresent == 0 {
+		return authenticatorData{}, ErrWebAuthnInvalid
+	}
+	return authData, nil
+}
+
+// --- rpIdHash(32) | flags(1) | signCount(4) | [aaguid(16) | idLen(2) | id | COSE key] | [extensions] ---
+func parseAuthenticatorData(b []byte) (authenticatorData, error) {
+	if len(b) < 37 {
+		return authenticatorData{}, ErrWebAuthnInvalid
+	}
+	data := authenticatorData{
+		RPIDHash:  b[:32],
+		Flags:     b[32],
+		SignCount: binary.BigEndian.Uint32(b[33:37]),
+	}
+	if data.Flags&authFlagAttestedData == 0 {
+		return data, nil
+	}
+
+	if len(b) < 55 {
+		return authenticatorData{}, ErrWebAuthnInvalid
+	}
+	data.AAGUID = b[37:53]
+	idLen := int(binary.BigEndian.Uint16(b[53:55]))
+	if len(b) < 55+idLen {
+		return authenticatorData{}, ErrWebAuthnInvalid
+	}
+	data.CredentialID = b[55 : 55+idLen]
+
+	rest := b[55+idLen:]
+	_, n, err := decodeCBOR(rest)
+	if err != nil {
+		return authenticatorData{}, ErrWebAuthnInvalid
+	}
+	data.PublicKey = rest[:n]
+	return data, nil
+}
+
+// --- COSE_Key (RFC 9053) TO PUBLIC KEY ---
+func parseCOSEKey(b []byte) (int64, crypto.PublicKey, error) {
+	decoded, _, err := decodeCBOR(b)
+	if err != nil {
+		return 0, nil, ErrWebAuthnInvalid
+	}
+	key, ok := decoded.(map[any]any)
+	if !ok {
+		return 0, nil, ErrWebAuthnInvalid
+	}
+	kty, _ := key[int64(1)].(int64)
+	alg, _ := key[int64(3)].(int64)
+
+	switch {
+	case kty == 2 && alg == coseAlgES256:
+		crv, _ := key[int64(-1)].(int64)
+		x, _ := key[int64(-2)].([]byte)
+		y, _ := key[int64(-3)].([]byte)
+		if crv != 1 || len(x) != 32 || len(y) != 32 {
+			return 0, nil, ErrWebAuthnInvalid
+		}
+		point := append(append([]byte{0x04}, x...), y...)
+		pub, err := ecdsa.ParseUncompressedPublicKey(elliptic.P256(), point)
+		if err != nil {
+			return 0, nil, ErrWebAuthnInvalid
+		}
+		return alg, pub, nil
+	case kty == 1 && alg == coseAlgEdDSA:
+		crv, _ := key[int64(-1)].(int64)
+		x, _ := key[int64(-2)].([]byte)
+		if crv != 6 || len(x) != ed25519.PublicKeySize {
+			return 0, nil, ErrWebAuthnInvalid
+		}
+		return alg, ed25519.PublicKey(x), nil
+	case kty == 3 && alg == coseAlgRS256:
+		n, _ := key[int64(-1)].([]byte)
+		e, _ := key[int64(-2)].([]byte)
+		if len(n) < 256 || len(e) == 0 || len(e) > 4 {
+			return 0, nil, ErrWebAuthnInvalid
+		}
+		return alg, &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+	}
+	return 0, nil, ErrWebAuthnAlgorithm
+}
+
+func verifyCOSESignature(alg int64, key crypto.PublicKey, signed, signature []byte) bool {
+	digest := sha256.Sum256(signed)
+	switch alg {
+	case coseAlgES256:
+		pub, ok := key.(*ecdsa.PublicKey)
+		return ok && ecdsa.VerifyASN1(pub, digest[:], signature)
+	case coseAlgEdDSA:
+		pub, ok := key.(ed25519.PublicKey)
+		return ok && ed25519.Verify(pub, signed, signature)
+	case coseAlgRS256:
+		pub, ok := key.(*rsa.PublicKey)
+		return ok && rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], signature) == nil
+	}
+	return false
+}
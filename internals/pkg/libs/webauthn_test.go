@@ -0,0 +1,250 @@
+package libs
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+var testWebAuthn = WebAuthnConfig{
+	RPID:    "kopi.example",
+	RPName:  "Kopi",
+	Origins: []string{"https://kopi.example"},
+}
+
+const testChallenge = "Y2hhbGxlbmdl"
+
+type testAuthenticator struct {
+	cose []byte
+	sign func(msg []byte) []byte
+}
+
+func newES256Authenticator(t *testing.T) testAuthenticator {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	point, err := key.PublicKey.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testAuthenticator{
+		cose: cborEncode(cborPairs{{1, 2}, {3, coseAlgES256}, {-1, 1}, {-2, point[1:33]}, {-3, point[33:]}}),
+		sign: func(msg []byte) []byte {
+			digest := sha256.Sum256(msg)
+			sig, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+			if err != nil {
+				t.Fatal(err)
+			}
+			return sig
+		},
+	}
+}
+
+func newEdDSAAuthenticator(t *testing.T) testAuthenticator {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testAuthenticator{
+		cose: cborEncode(cborPairs{{1, 1}, {3, coseAlgEdDSA}, {-1, 6}, {-2, []byte(pub)}}),
+		sign: func(msg []byte) []byte { return ed25519.Sign(priv, msg) },
+	}
+}
+
+func testClientData(t *testing.T, ceremony, challenge, origin string, crossOrigin bool) []byte {
+	t.Helper()
+	b, err := json.Marshal(webAuthnClientData{Type: ceremony, Challenge: challenge, Origin: origin, CrossOrigin: crossOrigin})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func testAuthData(rpID string, flags byte, count uint32, attested []byte) []byte {
+	hash := sha256.Sum256([]byte(rpID))
+	b := append(hash[:], flags)
+	b = binary.BigEndian.AppendUint32(b, count)
+	return append(b, attested...)
+}
+
+// --- aaguid(16) | idLen(2) | id | COSE key ---
+func testAttestedData(credentialID, cose []byte) []byte {
+	b := make([]byte, 16)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(credentialID)))
+	b = append(b, credentialID...)
+	return append(b, cose...)
+}
+
+type assertionInput struct {
+	rpID        string
+	origin      string
+	challenge   string
+	ceremony    string
+	crossOrigin bool
+	flags       byte
+	count       uint32
+	storedCount uint32
+	tamper      func(sig []byte) []byte
+}
+
+func runAssertion(t *testing.T, auth testAuthenticator, in assertionInput) (WebAuthnAssertion, error) {
+	t.Helper()
+	clientData := testClientData(t, in.ceremony, in.challenge, in.origin, in.crossOrigin)
+	authData := testAuthData(in.rpID, in.flags, in.count, nil)
+	clientHash := sha256.Sum256(clientData)
+	sig := auth.sign(append(append([]byte(nil), authData...), clientHash[:]...))
+	if in.tamper != nil {
+		sig = in.tamper(sig)
+	}
+	return testWebAuthn.VerifyAssertion(testChallenge, auth.cose, in.storedCount, clientData, authData, sig)
+}
+
+func validAssertion() assertionInput {
+	return assertionInput{
+		rpID:        testWebAuthn.RPID,
+		origin:      testWebAuthn.Origins[0],
+		challenge:   testChallenge,
+		ceremony:    "webauthn.get",
+		flags:       authFlagUserPresent | authFlagUserVerified,
+		count:       6,
+		storedCount: 5,
+	}
+}
+
+func TestVerifyAssertion(t *testing.T) {
+	flipLast := func(sig []byte) []byte {
+		sig = append([]byte(nil), sig...)
+		sig[len(sig)-1] ^= 0x01
+		return sig
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*assertionInput)
+		want   error
+	}{
+		{"valid", func(in *assertionInput) {}, nil},
+		{"counter not supported", func(in *assertionInput) { in.count, in.storedCount = 0, 0 }, nil},
+		{"bad signature", func(in *assertionInput) { in.tamper = flipLast }, ErrWebAuthnInvalid},
+		{"empty signature", func(in *assertionInput) { in.tamper = func([]byte) []byte { return nil } }, ErrWebAuthnInvalid},
+		{"wrong origin", func(in *assertionInput) { in.origin = "https://evil.example" }, ErrWebAuthnInvalid},
+		{"origin with other scheme", func(in *assertionInput) { in.origin = "http://kopi.example" }, ErrWebAuthnInvalid},
+		{"wrong rp id", func(in *assertionInput) { in.rpID = "evil.example" }, ErrWebAuthnInvalid},
+		{"wrong challenge", func(in *assertionInput) { in.challenge = "b3RoZXI" }, ErrWebAuthnInvalid},
+		{"registration ceremony", func(in *assertionInput) { in.ceremony = "webauthn.create" }, ErrWebAuthnInvalid},
+		{"cross origin", func(in *assertionInput) { in.crossOrigin = true }, ErrWebAuthnInvalid},
+		{"user not present", func(in *assertionInput) { in.flags = authFlagUserVerified }, ErrWebAuthnInvalid},
+		{"same sign count", func(in *assertionInput) { in.count = 5 }, ErrWebAuthnCounter},
+		{"lower sign count", func(in *assertionInput) { in.count = 4 }, ErrWebAuthnCounter},
+		{"counter reset to zero", func(in *assertionInput) { in.count = 0 }, ErrWebAuthnCounter},
+	}
+
+	for _, alg := range []struct {
+		name string
+		new  func(*testing.T) testAuthenticator
+	}{{"ES256", newES256Authenticator}, {"EdDSA", newEdDSAAuthenticator}} {
+		auth := alg.new(t)
+		for _, tt := range tests {
+			t.Run(alg.name+"/"+tt.name, func(t *testing.T) {
+				in := validAssertion()
+				tt.modify(&in)
+				got, err := runAssertion(t, auth, in)
+				if !errors.Is(err, tt.want) {
+					t.Fatalf("VerifyAssertion error = %v, want %v", err, tt.want)
+				}
+				if err == nil && (got.SignCount != in.count || !got.UserVerified) {
+					t.Fatalf("VerifyAssertion = %+v", got)
+				}
+			})
+		}
+	}
+}
+
+func TestVerifyAssertionOtherKey(t *testing.T) {
+	signer := newES256Authenticator(t)
+	other := newES256Authenticator(t)
+	signer.cose = other.cose
+	if _, err := runAssertion(t, signer, validAssertion()); !errors.Is(err, ErrWebAuthnInvalid) {
+		t.Fatalf("signature of another key: err = %v", err)
+	}
+}
+
+func TestVerifyAssertionMalformed(t *testing.T) {
+	auth := newES256Authenticator(t)
+	clientData := testClientData(t, "webauthn.get", testChallenge, testWebAuthn.Origins[0], false)
+	authData := testAuthData(testWebAuthn.RPID, authFlagUserPresent, 1, nil)
+
+	tests := []struct {
+		name       string
+		publicKey  []byte
+		clientData []byte
+		authData   []byte
+		want       error
+	}{
+		{"client data not json", auth.cose, []byte("{"), authData, ErrWebAuthnInvalid},
+		{"short authenticator data", auth.cose, clientData, authData[:36], ErrWebAuthnInvalid},
+		{"truncated public key", auth.cose[:len(auth.cose)-1], clientData, authData, ErrWebAuthnInvalid},
+		{"unsupported algorithm", cborEncode(cborPairs{{1, 2}, {3, -35}}), clientData, authData, ErrWebAuthnAlgorithm},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := testWebAuthn.VerifyAssertion(testChallenge, tt.publicKey, 0, tt.clientData, tt.authData, []byte{0x30})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyRegistration(t *testing.T) {
+	auth := newES256Authenticator(t)
+	credentialID := []byte("credential-1")
+	clientData := testClientData(t, "webauthn.create", testChallenge, testWebAuthn.Origins[0], false)
+	attestation := func(authData []byte) []byte {
+		return cborEncode(cborPairs{{"fmt", "none"}, {"attStmt", cborPairs{}}, {"authData", authData}})
+	}
+	flags := byte(authFlagUserPresent | authFlagAttestedData | authFlagBackupEligible)
+	valid := attestation(testAuthData(testWebAuthn.RPID, flags, 0, testAttestedData(credentialID, auth.cose)))
+
+	cred, err := testWebAuthn.VerifyRegistration(testChallenge, clientData, valid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(cred.ID) != string(credentialID) || string(cred.PublicKey) != string(auth.cose) {
+		t.Fatalf("credential = %+v", cred)
+	}
+	if !cred.BackupEligible || cred.BackedUp {
+		t.Fatalf("backup flags = %v, %v", cred.BackupEligible, cred.BackedUp)
+	}
+
+	tests := []struct {
+		name        string
+		clientData  []byte
+		attestation []byte
+	}{
+		{"assertion ceremony", testClientData(t, "webauthn.get", testChallenge, testWebAuthn.Origins[0], false), valid},
+		{"wrong origin", testClientData(t, "webauthn.create", testChallenge, "https://evil.example", false), valid},
+		{"truncated attestation", clientData, valid[:len(valid)-1]},
+		{"attestation not a map", clientData, cborEncode([]any{1})},
+		{"no attested data", clientData, attestation(testAuthData(testWebAuthn.RPID, authFlagUserPresent, 0, nil))},
+		{"wrong rp id", clientData, attestation(testAuthData("evil.example", flags, 0, testAttestedData(credentialID, auth.cose)))},
+		{"credential id longer than data", clientData, attestation(testAuthData(testWebAuthn.RPID, flags, 0, testAttestedData(credentialID, nil)[:20]))},
+		{"truncated public key", clientData, attestation(testAuthData(testWebAuthn.RPID, flags, 0, testAttestedData(credentialID, auth.cose[:10])))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := testWebAuthn.VerifyRegistration(testChallenge, tt.clientData, tt.attestation); err == nil {
+				t.Fatal("want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,294 @@
+package libs
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrXLSXInvalid     = errors.New("invalid xlsx file")
+	ErrXLSXTooLarge    = errors.New("xlsx file is too large once unpacked")
+	ErrXLSXTooManyRows = errors.New("xlsx file has too many rows")
+)
+
+const (
+	// --- A FEW MB OF UPLOAD CAN UNPACK TO GIGABYTES, EVERY PART IS READ UP TO THIS SIZE ---
+	xlsxMaxPartSize = 32 << 20
+	// --- COLUMN XFD, THE LAST ONE EXCEL HAS ---
+	xlsxMaxColumns = 16384
+)
+
+type xlsxText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+// --- PLAIN TEXT OF A SHARED OR INLINE STRING, RICH TEXT RUNS ARE JOINED ---
+func (t xlsxText) String() string {
+	if len(t.Runs) == 0 {
+		return t.T
+	}
+	var b strings.Builder
+	for _, r := range t.Runs {
+		b.WriteString(r.T)
+	}
+	return b.String()
+}
+
+type xlsxSheet struct {
+	Rows []struct {
+		Ref   int `xml:"r,attr"`
+		Cells []struct {
+			Ref    string   `xml:"r,attr"`
+			Type   string   `xml:"t,attr"`
+			Value  string   `xml:"v"`
+			Inline xlsxText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// --- CELLS OF THE FIRST WORKSHEET AS TEXT, EMPTY CELLS ARE "". A ROW PAST maxRows IS AN ERROR ---
+func ReadXLSX(r io.ReaderAt, size int64, maxRows int) ([][]string, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, ErrXLSXInvalid
+	}
+	files := map[string]*zip.File{}
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	sheetPath, err := firstSheetPath(files)
+	if err != nil {
+		return nil, err
+	}
+
+	var shared []string
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		var sst struct {
+			Items []xlsxText `xml:"si"`
+		}
+		if err := decodeZipXML(f, &sst); err != nil {
+			return nil, err
+		}
+		for _, item := range sst.Items {
+			shared = append(shared, item.String())
+		}
+	}
+
+	f, ok := files[sheetPath]
+	if !ok {
+		return nil, ErrXLSXInvalid
+	}
+	var sheet xlsxSheet
+	if err := decodeZipXML(f, &sheet); err != nil {
+		return nil, err
+	}
+
+	rows := make([][]string, 0, len(sheet.Rows))
+	for _, row := range sheet.Rows {
+		if row.Ref > maxRows || len(rows) >= maxRows {
+			return nil, ErrXLSXTooManyRows
+		}
+		// --- ROWS WITHOUT CELLS ARE LEFT OUT OF THE FILE, KEEP LINE NUMBERS RIGHT ---
+		for row.Ref > len(rows)+1 {
+			rows = append(rows, []string{})
+		}
+		values := []string{}
+		for i, cell := range row.Cells {
+			col := i
+			if cell.Ref != "" {
+				if col, err = xlsxColumn(cell.Ref); err != nil {
+					return nil, err
+				}
+			}
+			if col >= xlsxMaxColumns {
+				return nil, ErrXLSXInvalid
+			}
+			for len(values) <= col {
+				values = append(values, "")
+			}
+
+			switch cell.Type {
+			case "s":
+				idx, err := strconv.Atoi(cell.Value)
+				if err != nil || idx < 0 || idx >= len(shared) {
+					return nil, ErrXLSXInvalid
+				}
+				values[col] = shared[idx]
+			case "inlineStr":
+				values[col] = cell.Inline.String()
+			default:
+				values[col] = cell.Value
+			}
+		}
+		rows = append(rows, values)
+	}
+	return rows, nil
+}
+
+// --- WORKBOOK ORDER DECIDES THE FIRST SHEET, ITS FILE COMES FROM THE WORKBOOK RELATIONSHIPS ---
+func firstSheetPath(files map[string]*zip.File) (string, error) {
+	wb, ok := files["xl/workbook.xml"]
+	if !ok {
+		return "", ErrXLSXInvalid
+	}
+	var workbook struct {
+		Sheets []struct {
+			RelID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := decodeZipXML(wb, &workbook); err != nil {
+		return "", err
+	}
+	if len(workbook.Sheets) == 0 {
+		return "", ErrXLSXInvalid
+	}
+
+	if rels, ok := files["xl/_rels/workbook.xml.rels"]; ok {
+		var relationships struct {
+			Items []struct {
+				Id     string `xml:"Id,attr"`
+				Target string `xml:"Target,attr"`
+			} `xml:"Relationship"`
+		}
+		if err := decodeZipXML(rels, &relationships); err != nil {
+			return "", err
+		}
+		for _, rel := range relationships.Items {
+			if rel.Id != workbook.Sheets[0].RelID {
+				continue
+			}
+			if strings.HasPrefix(rel.Target, "/") {
+				return strings.TrimPrefix(rel.Target, "/"), nil
+			}
+			return path.Join("xl", rel.Target), nil
+		}
+	}
+	return "xl/worksheets/sheet1.xml", nil
+}
+
+func decodeZipXML(f *zip.File, v any) error {
+	rc, err := f.Open()
+	if err != nil {
+		return ErrXLSXInvalid
+	}
+	defer rc.Close()
+	if f.UncompressedSize64 > xlsxMaxPartSize {
+		return ErrXLSXTooLarge
+	}
+	// --- BOUNDED WHILE READING TOO, NOT ONLY BY THE SIZE IN THE HEADER ---
+	limited := &io.LimitedReader{R: rc, N: xlsxMaxPartSize + 1}
+	if err := xml.NewDecoder(limited).Decode(v); err != nil {
+		if limited.N == 0 {
+			return ErrXLSXTooLarge
+		}
+		return ErrXLSXInvalid
+	}
+	return nil
+}
+
+// --- "C12" IS COLUMN INDEX 2 ---
+func xlsxColumn(ref string) (int, error) {
+	col := 0
+	n := 0
+	for _, c := range ref {
+		if c < 'A' || c > 'Z' {
+			break
+		}
+		col = col*26 + int(c-'A'+1)
+		n++
+		if col > xlsxMaxColumns {
+			return 0, ErrXLSXInvalid
+		}
+	}
+	if n == 0 {
+		return 0, ErrXLSXInvalid
+	}
+	return col - 1, nil
+}
+
+func xlsxColumnName(col int) string {
+	name := ""
+	for col++; col > 0; col = (col - 1) / 26 {
+		name = string(rune('A'+(col-1)%26)) + name
+	}
+	return name
+}
+
+// --- SINGLE SHEET WORKBOOK, int AND float64 BECOME NUMBER CELLS, EVERYTHING ELSE TEXT ---
+func WriteXLSX(w io.Writer, sheetName string, rows [][]any) error {
+	var sheet bytes.Buffer
+	sheet.WriteString(xml.Header)
+	sheet.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	for r, row := range rows {
+		fmt.Fprintf(&sheet, `<row r="%d">`, r+1)
+		for c, value := range row {
+			ref := fmt.Sprintf("%s%d", xlsxColumnName(c), r+1)
+			switch v := value.(type) {
+			case nil:
+				continue
+			case int:
+				fmt.Fprintf(&sheet, `<c r="%s"><v>%d</v></c>`, ref, v)
+			case float64:
+				fmt.Fprintf(&sheet, `<c r="%s"><v>%s</v></c>`, ref, strconv.FormatFloat(v, 'f', -1, 64))
+			default:
+				fmt.Fprintf(&sheet, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">`, ref)
+				if err := xml.EscapeText(&sheet, []byte(fmt.Sprint(v))); err != nil {
+					return err
+				}
+				sheet.WriteString(`</t></is></c>`)
+			}
+		}
+		sheet.WriteString(`</row>`)
+	}
+	sheet.WriteString(`</sheetData></worksheet>`)
+
+	var name bytes.Buffer
+	if err := xml.EscapeText(&name, []byte(sheetName)); err != nil {
+		return err
+	}
+
+	parts := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+			`</Types>`},
+		{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="` + name.String() + `" sheetId="1" r:id="rId1"/></sheets>` +
+			`</workbook>`},
+		{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+			`</Relationships>`},
+		{"xl/worksheets/sheet1.xml", sheet.String()},
+	}
+
+	zw := zip.NewWriter(w)
+	for _, part := range parts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
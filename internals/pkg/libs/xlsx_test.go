@@ -0,0 +1,195 @@
+package libs
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testWorkbook = `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+	`<sheets><sheet name="first" sheetId="1" r:id="rId7"/><sheet name="second" sheetId="2" r:id="rId1"/></sheets></workbook>`
+
+const testWorkbookRels = `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Target="worksheets/sheet1.xml"/>` +
+	`<Relationship Id="rId7" Target="worksheets/data.xml"/></Relationships>`
+
+func testXLSX(t *testing.T, parts map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range parts {
+		f, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.WriteString(f, content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// --- WORKBOOK WITH THE GIVEN sheetData AS ITS FIRST SHEET ---
+func testSheetXLSX(t *testing.T, sheetData string) []byte {
+	t.Helper()
+	return testXLSX(t, map[string]string{
+		"xl/workbook.xml":            testWorkbook,
+		"xl/_rels/workbook.xml.rels": testWorkbookRels,
+		"xl/worksheets/data.xml":     `<worksheet><sheetData>` + sheetData + `</sheetData></worksheet>`,
+	})
+}
+
+func readTestXLSX(b []byte, maxRows int) ([][]string, error) {
+	return ReadXLSX(bytes.NewReader(b), int64(len(b)), maxRows)
+}
+
+func TestXLSXRoundTrip(t *testing.T) {
+	rows := [][]any{
+		{"sku", "name", "price", "stock"},
+		{"KP-1", `Kopi <Susu> & "Gula"`, 18000.5, 12},
+		{"KP-2", "  spaces kept  ", -1.25, 0},
+		{nil, "no sku", nil, 3},
+		{"KP-4", "trailing empty", nil, nil},
+		{},
+		{"KP-6", "ü 咖啡", 1e21, -7},
+	}
+	var buf bytes.Buffer
+	if err := WriteXLSX(&buf, "products & more", rows); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := readTestXLSX(buf.Bytes(), len(rows))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"sku", "name", "price", "stock"},
+		{"KP-1", `Kopi <Susu> & "Gula"`, "18000.5", "12"},
+		{"KP-2", "  spaces kept  ", "-1.25", "0"},
+		{"", "no sku", "", "3"},
+		{"KP-4", "trailing empty"},
+		{},
+		{"KP-6", "ü 咖啡", "1000000000000000000000", "-7"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ReadXLSX = %q, want %q", got, want)
+	}
+}
+
+func TestReadXLSXSharedStrings(t *testing.T) {
+	b := testXLSX(t, map[string]string{
+		"xl/workbook.xml":            testWorkbook,
+		"xl/_rels/workbook.xml.rels": testWorkbookRels,
+		"xl/sharedStrings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+			`<si><t>sku</t></si>` +
+			`<si><r><rPr><b/></rPr><t>Kopi </t></r><r><t xml:space="preserve">Susu </t></r><r><t>Aren</t></r></si>` +
+			`<si><t/></si></sst>`,
+		"xl/worksheets/data.xml": `<worksheet><sheetData>` +
+			`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="C1" t="s"><v>1</v></c></row>` +
+			`<row r="4"><c r="B4" t="s"><v>2</v></c><c r="D4"><v>42</v></c></row>` +
+			`<row r="5"><c t="inlineStr"><is><t>no ref</t></is></c><c><v>7</v></c></row>` +
+			`</sheetData></worksheet>`,
+		// --- NOT THE FIRST SHEET OF THE WORKBOOK, MUST NOT BE READ ---
+		"xl/worksheets/sheet1.xml": `<worksheet><sheetData><row r="1"><c r="A1"><v>wrong sheet</v></c></row></sheetData></worksheet>`,
+	})
+
+	got, err := readTestXLSX(b, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"sku", "", "Kopi Susu Aren"},
+		{},
+		{},
+		{"", "", "", "42"},
+		{"no ref", "7"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ReadXLSX = %q, want %q", got, want)
+	}
+}
+
+func TestReadXLSXDefaultSheetPath(t *testing.T) {
+	b := testXLSX(t, map[string]string{
+		"xl/workbook.xml":          testWorkbook,
+		"xl/worksheets/sheet1.xml": `<worksheet><sheetData><row r="1"><c r="B1"><v>1</v></c></row></sheetData></worksheet>`,
+	})
+	got, err := readTestXLSX(b, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := [][]string{{"", "1"}}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("ReadXLSX = %q, want %q", got, want)
+	}
+}
+
+func TestReadXLSXLimits(t *testing.T) {
+	rowsWithoutRef := strings.Repeat(`<row><c><v>1</v></c></row>`, 4)
+	tests := []struct {
+		name  string
+		sheet string
+		want  error
+	}{
+		{"last allowed row", `<row r="3"><c r="A3"><v>1</v></c></row>`, nil},
+		{"row past the limit", `<row r="4"><c r="A4"><v>1</v></c></row>`, ErrXLSXTooManyRows},
+		{"huge row number", `<row r="1048576"><c r="A1048576"><v>1</v></c></row>`, ErrXLSXTooManyRows},
+		{"rows without numbers past the limit", rowsWithoutRef, ErrXLSXTooManyRows},
+		{"last column", `<row r="1"><c r="XFD1"><v>1</v></c></row>`, nil},
+		{"column past XFD", `<row r="1"><c r="XFE1"><v>1</v></c></row>`, ErrXLSXInvalid},
+		{"column overflowing int", `<row r="1"><c r="ZZZZZZZZZZZZZZZZ1"><v>1</v></c></row>`, ErrXLSXInvalid},
+		{"cell reference without column", `<row r="1"><c r="12"><v>1</v></c></row>`, ErrXLSXInvalid},
+		{"shared string out of range", `<row r="1"><c r="A1" t="s"><v>0</v></c></row>`, ErrXLSXInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := readTestXLSX(testSheetXLSX(t, tt.sheet), 3); !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadXLSXOversizedPart(t *testing.T) {
+	padding := strings.Repeat(" ", xlsxMaxPartSize)
+	if _, err := readTestXLSX(testSheetXLSX(t, padding), 10); !errors.Is(err, ErrXLSXTooLarge) {
+		t.Fatalf("err = %v, want %v", err, ErrXLSXTooLarge)
+	}
+}
+
+func TestReadXLSXMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		file []byte
+	}{
+		{"not a zip", []byte("sku,name\n")},
+		{"no workbook", testXLSX(t, map[string]string{"xl/worksheets/sheet1.xml": `<worksheet/>`})},
+		{"workbook without sheets", testXLSX(t, map[string]string{"xl/workbook.xml": `<workbook><sheets/></workbook>`})},
+		{"missing sheet part", testXLSX(t, map[string]string{"xl/workbook.xml": testWorkbook, "xl/_rels/workbook.xml.rels": testWorkbookRels})},
+		{"sheet not xml", testXLSX(t, map[string]string{"xl/workbook.xml": testWorkbook, "xl/worksheets/sheet1.xml": `<worksheet><sheetData>`})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := readTestXLSX(tt.file, 10); !errors.Is(err, ErrXLSXInvalid) {
+				t.Fatalf("err = %v, want %v", err, ErrXLSXInvalid)
+			}
+		})
+	}
+}
+
+func TestXLSXColumnName(t *testing.T) {
+	for col, name := range map[int]string{0: "A", 25: "Z", 26: "AA", 701: "ZZ", 702: "AAA", xlsxMaxColumns - 1: "XFD"} {
+		if got := xlsxColumnName(col); got != name {
+			t.Errorf("xlsxColumnName(%d) = %s, want %s", col, got, name)
+		}
+		if got, err := xlsxColumn(name + "1"); err != nil || got != col {
+			t.Errorf("xlsxColumn(%s1) = %d, %v, want %d", name, got, err, col)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"bufio"
+	_ "embed"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// --- DEFAULT POLICY, OVERRIDE WITH PASSWORD_MIN_LENGTH AND PASSWORD_HISTORY ---
+const (
+	defaultPasswordMinLength = 8
+	defaultPasswordHistory   = 5
+)
+
+//go:embed common-passwords.txt
+var commonPasswords string
+
+type PasswordPolicy struct {
+	MinLength int
+	// --- NUMBER OF PREVIOUS PASSWORD THAT CAN'T BE REUSED, 0 DISABLES THE CHECK ---
+	HistorySize int
+	blocklist   map[string]struct{}
+}
+
+var (
+	passwordPolicy     PasswordPolicy
+	passwordPolicyOnce sync.Once
+)
+
+// --- POLICY IS LOADED ONCE FROM ENV, BLOCKLIST FROM EMBEDDED LIST AND PASSWORD_BLOCKLIST_FILE ---
+func GetPasswordPolicy() PasswordPolicy {
+	passwordPolicyOnce.Do(func() {
+		passwordPolicy = PasswordPolicy{
+			MinLength:   envInt("PASSWORD_MIN_LENGTH", defaultPasswordMinLength),
+			HistorySize: envInt("PASSWORD_HISTORY", defaultPasswordHistory),
+			blocklist:   map[string]struct{}{},
+		}
+		passwordPolicy.load(strings.NewReader(commonPasswords))
+
+		if path := os.Getenv("PASSWORD_BLOCKLIST_FILE"); path != "" {
+			file, err := os.Open(path)
+			if err != nil {
+				log.Println("Failed to load password blocklist :", err)
+				return
+			}
+			defer file.Close()
+			passwordPolicy.load(file)
+		}
+	})
+	return passwordPolicy
+}
+
+func (p PasswordPolicy) load(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		p.blocklist[strings.ToLower(line)] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		log.Println("Failed to read password blocklist :", err)
+	}
+}
+
+// --- PASSWORD IS ON COMMON OR BREACHED PASSWORD LIST ---
+func (p PasswordPolicy) IsBreached(password string) bool {
+	_, found := p.blocklist[strings.ToLower(password)]
+	return found
+}
+
+func envInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value < 0 {
+		return fallback
+	}
+	return value
+}
+
+// --- VALIDATION PASSWORD MINIMUM LENGTH ---
+func PasswordLengthValidator(fl validator.FieldLevel) bool {
+	return utf8.RuneCountInString(fl.Field().String()) >= GetPasswordPolicy().MinLength
+}
+
+// --- VALIDATION PASSWORD NOT ON BREACHED LIST ---
+func PasswordBreachedValidator(fl validator.FieldLevel) bool {
+	return !GetPasswordPolicy().IsBreached(fl.Field().String())
+}
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"errors"
+	"strconv"
 
 	"github.com/go-playground/validator/v10"
 )
@@ -14,7 +15,7 @@ func ErrorMessage(fe validator.FieldError) string {
 	switch field {
 	case "Rating":
 		if tag == "gte" || tag == "lte" {
-			return "rating must be between 1 - 10"
+			return "rating must be between 1 - 5"
 		}
 	case "Price":
 		if tag == "gte" {
@@ -65,14 +66,24 @@ func ErrorMessage(fe validator.FieldError) string {
 		if tag == "numeric" {
 			return "phone must contain only numbers"
 		}
+	case "Code":
+		if tag == "len" || tag == "numeric" {
+			return "code must be exactly 6 digits"
+		}
 	}
 
 	// --- Default messages ---
 	switch tag {
 	case "password_complex":
 		return "password must contain uppercase, lowercase, number, and special character"
+	case "password_length":
+		return "password must be at least " + strconv.Itoa(GetPasswordPolicy().MinLength) + " characters"
+	case "password_breached":
+		return "password is too common or has appeared in a data breach, please choose another"
 	case "required":
 		return field + " is required"
+	case "required_without":
+		return field + " or " + fe.Param() + " is required"
 	case "gt":
 		return field + " must be greater than " + fe.Param()
 	case "gte":
@@ -87,6 +98,12 @@ func ErrorMessage(fe validator.FieldError) string {
 		return field + " can have at most " + fe.Param() + " item(s)"
 	case "eqfield":
 		return field + " must match " + fe.Param()
+	case "gtfield":
+		return field + " must be after " + fe.Param()
+	case "alphanum":
+		return field + " must contain only letters and numbers"
+	case "lowercase":
+		return field + " must be lowercase"
 	default:
 		return field + " is invalid"
 	}
@@ -37,5 +37,7 @@ func PasswordComplexityValidator(fl validator.FieldLevel) bool {
 func InitValidator() {
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
 		v.RegisterValidation("password_complex", PasswordComplexityValidator)
+		v.RegisterValidation("password_length", PasswordLengthValidator)
+		v.RegisterValidation("password_breached", PasswordBreachedValidator)
 	}
 }
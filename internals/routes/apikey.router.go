@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"github.com/federus1105/koda-b4-backend/internals/controllers"
+	"github.com/federus1105/koda-b4-backend/internals/middlewares"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+)
+
+func InitAPIKeyRouter(router *gin.Engine, db *pgxpool.Pool, rdb *redis.Client) {
+	apiKeyRouter := router.Group("/admin/api-keys", middlewares.Authenticate(rdb), middlewares.RequirePermission(db, rdb, "api_keys:manage"))
+
+	apiKeyRouter.GET("", func(ctx *gin.Context) {
+		controllers.GetAPIKeys(ctx, db)
+	})
+	apiKeyRouter.POST("", func(ctx *gin.Context) {
+		controllers.CreateAPIKey(ctx, db, rdb)
+	})
+	apiKeyRouter.DELETE("/:id", func(ctx *gin.Context) {
+		controllers.RevokeAPIKey(ctx, db, rdb)
+	})
+}
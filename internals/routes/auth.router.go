@@ -2,6 +2,8 @@ package routes
 
 import (
 	"github.com/federus1105/koda-b4-backend/internals/controllers"
+	"github.com/federus1105/koda-b4-backend/internals/middlewares"
+	"github.com/federus1105/koda-b4-backend/internals/pkg/libs"
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/redis/go-redis/v9"
@@ -9,12 +11,43 @@ import (
 
 func InitAuthRouter(router *gin.Engine, db *pgxpool.Pool, rdb *redis.Client) {
 	authRouter := router.Group("/auth")
+	oidcProvider := libs.NewOIDCProvider(libs.OIDCConfigFromEnv())
 
 	authRouter.POST("/register", func(ctx *gin.Context) {
-		controllers.Register(ctx, db)
+		controllers.Register(ctx, db, rdb)
+	})
+	authRouter.POST("/verify-email", func(ctx *gin.Context) {
+		controllers.VerifyEmail(ctx, db, rdb)
+	})
+	authRouter.POST("/resend-verification", func(ctx *gin.Context) {
+		controllers.ResendVerification(ctx, db, rdb)
+	})
+	authRouter.POST("/confirm-email-change", func(ctx *gin.Context) {
+		controllers.ConfirmEmailChange(ctx, db, rdb)
 	})
 	authRouter.POST("/login", func(ctx *gin.Context) {
-		controllers.Login(ctx, db)
+		controllers.Login(ctx, db, rdb)
+	})
+	authRouter.POST("/login/2fa", func(ctx *gin.Context) {
+		controllers.LoginTwoFactor(ctx, db, rdb)
+	})
+	authRouter.POST("/magic-link", func(ctx *gin.Context) {
+		controllers.RequestMagicLink(ctx, db, rdb)
+	})
+	authRouter.POST("/magic-link/verify", func(ctx *gin.Context) {
+		controllers.MagicLinkLogin(ctx, db, rdb)
+	})
+	authRouter.GET("/oidc/login", func(ctx *gin.Context) {
+		controllers.OIDCLogin(ctx, rdb, oidcProvider)
+	})
+	authRouter.POST("/oidc/callback", func(ctx *gin.Context) {
+		controllers.OIDCCallback(ctx, db, rdb, oidcProvider)
+	})
+	authRouter.POST("/refresh", func(ctx *gin.Context) {
+		controllers.RefreshToken(ctx, db, rdb)
+	})
+	authRouter.POST("/logout", middlewares.Authenticate(rdb), func(ctx *gin.Context) {
+		controllers.Logout(ctx, rdb)
 	})
 	authRouter.POST("/forgot-password", func(ctx *gin.Context) {
 		controllers.ForgotPassword(ctx, db, rdb)
@@ -5,24 +5,25 @@ import (
 	"github.com/federus1105/koda-b4-backend/internals/middlewares"
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
 )
 
-func InitCategoriesRouter(router *gin.Engine, db *pgxpool.Pool) {
+func InitCategoriesRouter(router *gin.Engine, db *pgxpool.Pool, rdb *redis.Client) {
 	categoriesRouter := router.Group("/admin/categories")
 
-	categoriesRouter.GET("", middlewares.VerifyToken, middlewares.Access("admin"), func(ctx *gin.Context) {
+	categoriesRouter.GET("", middlewares.AuthenticateOrAPIKey(db, rdb), middlewares.RequirePermission(db, rdb, "categories:read"), func(ctx *gin.Context) {
 		controllers.GetListCategories(ctx, db)
 	})
 
-	categoriesRouter.POST("", middlewares.VerifyToken, middlewares.Access("admin"), func(ctx *gin.Context) {
+	categoriesRouter.POST("", middlewares.AuthenticateOrAPIKey(db, rdb), middlewares.RequirePermission(db, rdb, "categories:write"), func(ctx *gin.Context) {
 		controllers.CreateCategory(ctx, db)
 	})
 
-	categoriesRouter.PUT("/:id", middlewares.VerifyToken, middlewares.Access("admin"), func(ctx *gin.Context) {
+	categoriesRouter.PUT("/:id", middlewares.AuthenticateOrAPIKey(db, rdb), middlewares.RequirePermission(db, rdb, "categories:write"), func(ctx *gin.Context) {
 		controllers.UpdateCategories(ctx, db)
 	})
 
-	categoriesRouter.DELETE("/:id", middlewares.VerifyToken, middlewares.Access("admin"), func(ctx *gin.Context) {
+	categoriesRouter.DELETE("/:id", middlewares.AuthenticateOrAPIKey(db, rdb), middlewares.RequirePermission(db, rdb, "categories:write"), func(ctx *gin.Context) {
 		controllers.DeleteCategories(ctx, db)
 	})
 }
@@ -0,0 +1,33 @@
+package routes
+
+import (
+	"github.com/federus1105/koda-b4-backend/internals/controllers"
+	"github.com/federus1105/koda-b4-backend/internals/middlewares"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+)
+
+func InitFlashSaleRouter(router *gin.Engine, db *pgxpool.Pool, rdb *redis.Client) {
+	router.GET("/flash-sales", func(ctx *gin.Context) {
+		controllers.GetActiveFlashSales(ctx, db)
+	})
+
+	flashSaleRouter := router.Group("/admin/flash-sales", middlewares.AuthenticateOrAPIKey(db, rdb))
+
+	flashSaleRouter.GET("", middlewares.RequirePermission(db, rdb, "products:read"), func(ctx *gin.Context) {
+		controllers.GetFlashSales(ctx, db)
+	})
+	flashSaleRouter.GET("/:id", middlewares.RequirePermission(db, rdb, "products:read"), func(ctx *gin.Context) {
+		controllers.GetFlashSale(ctx, db)
+	})
+	flashSaleRouter.POST("", middlewares.RequirePermission(db, rdb, "products:write"), func(ctx *gin.Context) {
+		controllers.CreateFlashSale(ctx, db)
+	})
+	flashSaleRouter.PUT("/:id", middlewares.RequirePermission(db, rdb, "products:write"), func(ctx *gin.Context) {
+		controllers.UpdateFlashSale(ctx, db)
+	})
+	flashSaleRouter.DELETE("/:id", middlewares.RequirePermission(db, rdb, "products:write"), func(ctx *gin.Context) {
+		controllers.DeleteFlashSale(ctx, db)
+	})
+}
@@ -5,16 +5,17 @@ import (
 	"github.com/federus1105/koda-b4-backend/internals/middlewares"
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
 )
 
-func InitHistoryRouter(router *gin.Engine, db *pgxpool.Pool) {
+func InitHistoryRouter(router *gin.Engine, db *pgxpool.Pool, rdb *redis.Client) {
 	historyRouter := router.Group("/history")
 
-	historyRouter.GET("", middlewares.VerifyToken, middlewares.AuthMiddleware(), func(ctx *gin.Context) {
+	historyRouter.GET("", middlewares.Authenticate(rdb), func(ctx *gin.Context) {
 		controllers.GetHistory(ctx, db)
 	})
 
-	historyRouter.GET("/:id", middlewares.VerifyToken, middlewares.AuthMiddleware(), func(ctx *gin.Context) {
+	historyRouter.GET("/:id", middlewares.Authenticate(rdb), func(ctx *gin.Context) {
 		controllers.DetailHistory(ctx, db)
 	})
 }
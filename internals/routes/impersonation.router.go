@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"github.com/federus1105/koda-b4-backend/internals/controllers"
+	"github.com/federus1105/koda-b4-backend/internals/middlewares"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+)
+
+func InitImpersonationRouter(router *gin.Engine, db *pgxpool.Pool, rdb *redis.Client) {
+	// --- BEARER TOKEN ONLY, THE ACTOR MUST BE A PERSON ---
+	router.POST("/admin/user/:id/impersonate", middlewares.Authenticate(rdb), middlewares.RequirePermission(db, rdb, "users:impersonate"), func(ctx *gin.Context) {
+		controllers.Impersonate(ctx, db)
+	})
+
+	auditRouter := router.Group("/admin/impersonations", middlewares.Authenticate(rdb), middlewares.RequirePermission(db, rdb, "security:manage"))
+
+	auditRouter.GET("", func(ctx *gin.Context) {
+		controllers.GetImpersonations(ctx, db)
+	})
+	auditRouter.GET("/:id", func(ctx *gin.Context) {
+		controllers.GetImpersonationAudit(ctx, db)
+	})
+}
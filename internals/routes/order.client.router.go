@@ -5,24 +5,25 @@ import (
 	"github.com/federus1105/koda-b4-backend/internals/middlewares"
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
 )
 
-func InitOrderClientRoutes(router *gin.Engine, db *pgxpool.Pool) {
+func InitOrderClientRoutes(router *gin.Engine, db *pgxpool.Pool, rdb *redis.Client) {
 	InitOrderClientRoutes := router.Group("")
 
-	InitOrderClientRoutes.POST("/cart", middlewares.VerifyToken, middlewares.AuthMiddleware(), func(ctx *gin.Context) {
+	InitOrderClientRoutes.POST("/cart", middlewares.Authenticate(rdb), func(ctx *gin.Context) {
 		controllers.CreateCartProduct(ctx, db)
 	})
 
-	InitOrderClientRoutes.GET("/cart", middlewares.VerifyToken, middlewares.AuthMiddleware(), func(ctx *gin.Context) {
+	InitOrderClientRoutes.GET("/cart", middlewares.Authenticate(rdb), func(ctx *gin.Context) {
 		controllers.GetCartProduct(ctx, db)
 	})
 
-	InitOrderClientRoutes.POST("/transactions", middlewares.VerifyToken, middlewares.AuthMiddleware(), func(ctx *gin.Context) {
-		controllers.Transactions(ctx, db)
+	InitOrderClientRoutes.POST("/transactions", middlewares.Authenticate(rdb), func(ctx *gin.Context) {
+		controllers.Transactions(ctx, db, rdb)
 	})
 
-	InitOrderClientRoutes.DELETE("/cart/:id", middlewares.VerifyToken, middlewares.AuthMiddleware(), func(ctx *gin.Context) {
+	InitOrderClientRoutes.DELETE("/cart/:id", middlewares.Authenticate(rdb), func(ctx *gin.Context) {
 		controllers.DeleteCart(ctx, db)
 	})
 }
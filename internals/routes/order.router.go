@@ -5,20 +5,21 @@ import (
 	"github.com/federus1105/koda-b4-backend/internals/middlewares"
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
 )
 
-func InitOrderRouter(router *gin.Engine, db *pgxpool.Pool) {
+func InitOrderRouter(router *gin.Engine, db *pgxpool.Pool, rdb *redis.Client) {
 	orderRouter := router.Group("/admin/order")
 
-	orderRouter.GET("", middlewares.VerifyToken, middlewares.Access("admin"), func(ctx *gin.Context) {
+	orderRouter.GET("", middlewares.AuthenticateOrAPIKey(db, rdb), middlewares.RequirePermission(db, rdb, "orders:read"), func(ctx *gin.Context) {
 		controllers.GetListOrder(ctx, db)
 	})
 
-	orderRouter.GET("/:id", middlewares.VerifyToken, middlewares.Access("admin"), func(ctx *gin.Context) {
+	orderRouter.GET("/:id", middlewares.AuthenticateOrAPIKey(db, rdb), middlewares.RequirePermission(db, rdb, "orders:read"), func(ctx *gin.Context) {
 		controllers.GetDetailOrder(ctx, db)
 	})
 
-	orderRouter.PUT("/:id", middlewares.VerifyToken, middlewares.Access("admin"), func(ctx *gin.Context) {
+	orderRouter.PUT("/:id", middlewares.AuthenticateOrAPIKey(db, rdb), middlewares.RequirePermission(db, rdb, "orders:update"), func(ctx *gin.Context) {
 		controllers.UpdateOrderStatus(ctx, db)
 	})
 }
@@ -0,0 +1,38 @@
+package routes
+
+import (
+	"github.com/federus1105/koda-b4-backend/internals/controllers"
+	"github.com/federus1105/koda-b4-backend/internals/middlewares"
+	"github.com/federus1105/koda-b4-backend/internals/pkg/libs"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+)
+
+func InitPasskeyRouter(router *gin.Engine, db *pgxpool.Pool, rdb *redis.Client) {
+	webauthn := libs.WebAuthnConfigFromEnv()
+
+	loginRouter := router.Group("/auth/passkey")
+
+	loginRouter.POST("/options", func(ctx *gin.Context) {
+		controllers.PasskeyLoginOptions(ctx, rdb, webauthn)
+	})
+	loginRouter.POST("/login", func(ctx *gin.Context) {
+		controllers.PasskeyLogin(ctx, db, rdb, webauthn)
+	})
+
+	passkeyRouter := router.Group("/profile/passkeys", middlewares.Authenticate(rdb))
+
+	passkeyRouter.GET("", func(ctx *gin.Context) {
+		controllers.GetPasskeys(ctx, db)
+	})
+	passkeyRouter.POST("/options", func(ctx *gin.Context) {
+		controllers.PasskeyRegisterOptions(ctx, db, rdb, webauthn)
+	})
+	passkeyRouter.POST("", func(ctx *gin.Context) {
+		controllers.RegisterPasskey(ctx, db, rdb, webauthn)
+	})
+	passkeyRouter.DELETE("/:id", func(ctx *gin.Context) {
+		controllers.DeletePasskey(ctx, db)
+	})
+}
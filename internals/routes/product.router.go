@@ -14,26 +14,50 @@ func InitProductRouter(router *gin.Engine, db *pgxpool.Pool, rd *redis.Client, c
 	productRouterother := router.Group("/")
 	productRouterFilter := router.Group("/product")
 
-	productRouter.GET("", middlewares.VerifyToken, middlewares.Access("admin"), func(ctx *gin.Context) {
+	productRouter.GET("", middlewares.AuthenticateOrAPIKey(db, rd), middlewares.RequirePermission(db, rd, "products:read"), func(ctx *gin.Context) {
 		controllers.GetListProduct(ctx, db, rd)
 	})
 
-	productRouter.POST("", middlewares.VerifyToken, middlewares.Access("admin"), func(ctx *gin.Context) {
+	productRouter.POST("", middlewares.AuthenticateOrAPIKey(db, rd), middlewares.RequirePermission(db, rd, "products:write"), func(ctx *gin.Context) {
 		controllers.CreateProduct(ctx, db, rd, cld)
 	})
 
-	productRouter.PATCH("/:id", middlewares.VerifyToken, middlewares.Access("admin"), func(ctx *gin.Context) {
+	productRouter.POST("/import", middlewares.AuthenticateOrAPIKey(db, rd), middlewares.RequirePermission(db, rd, "products:write"), func(ctx *gin.Context) {
+		controllers.ImportProducts(ctx, db, rd)
+	})
+
+	productRouter.GET("/export", middlewares.AuthenticateOrAPIKey(db, rd), middlewares.RequirePermission(db, rd, "products:read"), func(ctx *gin.Context) {
+		controllers.ExportProducts(ctx, db)
+	})
+
+	productRouter.PATCH("/:id", middlewares.AuthenticateOrAPIKey(db, rd), middlewares.RequirePermission(db, rd, "products:write"), func(ctx *gin.Context) {
 		controllers.EditProduct(ctx, db, rd, cld)
 	})
 
-	productRouter.POST("/delete/:id", middlewares.VerifyToken, middlewares.Access("admin"), func(ctx *gin.Context) {
+	productRouter.POST("/delete/:id", middlewares.AuthenticateOrAPIKey(db, rd), middlewares.RequirePermission(db, rd, "products:write"), func(ctx *gin.Context) {
 		controllers.DeleteProduct(ctx, db, rd)
 	})
 
-	productRouter.GET("/:id/images", middlewares.VerifyToken, middlewares.Access("admin"), func(ctx *gin.Context) {
+	productRouter.GET("/:id/images", middlewares.AuthenticateOrAPIKey(db, rd), middlewares.RequirePermission(db, rd, "products:read"), func(ctx *gin.Context) {
 		controllers.GetListImageById(ctx, db)
 	})
 
+	productRouter.POST("/:id/images", middlewares.AuthenticateOrAPIKey(db, rd), middlewares.RequirePermission(db, rd, "products:write"), func(ctx *gin.Context) {
+		controllers.AddProductImages(ctx, db, rd, cld)
+	})
+
+	productRouter.PUT("/:id/images/order", middlewares.AuthenticateOrAPIKey(db, rd), middlewares.RequirePermission(db, rd, "products:write"), func(ctx *gin.Context) {
+		controllers.ReorderProductImages(ctx, db, rd)
+	})
+
+	productRouter.PATCH("/:id/images/:imageId", middlewares.AuthenticateOrAPIKey(db, rd), middlewares.RequirePermission(db, rd, "products:write"), func(ctx *gin.Context) {
+		controllers.UpdateProductImage(ctx, db, rd)
+	})
+
+	productRouter.DELETE("/:id/images/:imageId", middlewares.AuthenticateOrAPIKey(db, rd), middlewares.RequirePermission(db, rd, "products:write"), func(ctx *gin.Context) {
+		controllers.DeleteProductImage(ctx, db, rd)
+	})
+
 	// ============ CLIENT ROUTER ===========
 
 	productRouterother.GET("favorite-product", func(ctx *gin.Context) {
@@ -44,7 +68,7 @@ func InitProductRouter(router *gin.Engine, db *pgxpool.Pool, rd *redis.Client, c
 		controllers.GetListProductFilter(ctx, db, rd)
 	})
 
-	productRouterFilter.GET("/:id", middlewares.VerifyToken, func(ctx *gin.Context) {
+	productRouterFilter.GET("/:id", middlewares.OptionalAuthenticate(rd), func(ctx *gin.Context) {
 		controllers.GetProductById(ctx, db)
 	})
 }
@@ -6,20 +6,41 @@ import (
 	"github.com/federus1105/koda-b4-backend/internals/middlewares"
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
 )
 
-func InitProfileRouter(router *gin.Engine, db *pgxpool.Pool, cld *cloudinary.Cloudinary) {
+func InitProfileRouter(router *gin.Engine, db *pgxpool.Pool, rdb *redis.Client, cld *cloudinary.Cloudinary) {
 	profileRouter := router.Group("/profile")
 
-	profileRouter.PATCH("", middlewares.VerifyToken, middlewares.AuthMiddleware(), func(ctx *gin.Context) {
-		controllers.ProfileUpdate(ctx, db, cld)
+	profileRouter.PATCH("", middlewares.Authenticate(rdb), func(ctx *gin.Context) {
+		controllers.ProfileUpdate(ctx, db, rdb, cld)
 	})
 
-	profileRouter.PUT("", middlewares.VerifyToken, middlewares.AuthMiddleware(), func(ctx *gin.Context) {
+	profileRouter.PUT("", middlewares.Authenticate(rdb), func(ctx *gin.Context) {
 		controllers.UpdatePassword(ctx, db)
 	})
 
-	profileRouter.GET("", middlewares.VerifyToken, middlewares.AuthMiddleware(), func(ctx *gin.Context) {
+	profileRouter.GET("", middlewares.Authenticate(rdb), func(ctx *gin.Context) {
 		controllers.Profile(ctx, db)
 	})
+
+	profileRouter.DELETE("", middlewares.Authenticate(rdb), func(ctx *gin.Context) {
+		controllers.DeleteAccount(ctx, db, rdb)
+	})
+
+	profileRouter.GET("/export", middlewares.Authenticate(rdb), func(ctx *gin.Context) {
+		controllers.ExportAccount(ctx, db)
+	})
+
+	profileRouter.GET("/sessions", middlewares.Authenticate(rdb), func(ctx *gin.Context) {
+		controllers.GetSessions(ctx, rdb)
+	})
+
+	profileRouter.DELETE("/sessions", middlewares.Authenticate(rdb), func(ctx *gin.Context) {
+		controllers.RevokeOtherSessions(ctx, rdb)
+	})
+
+	profileRouter.DELETE("/sessions/:id", middlewares.Authenticate(rdb), func(ctx *gin.Context) {
+		controllers.RevokeSession(ctx, rdb)
+	})
 }
@@ -0,0 +1,32 @@
+package routes
+
+import (
+	"github.com/federus1105/koda-b4-backend/internals/controllers"
+	"github.com/federus1105/koda-b4-backend/internals/middlewares"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+)
+
+func InitRBACRouter(router *gin.Engine, db *pgxpool.Pool, rdb *redis.Client) {
+	rbacRouter := router.Group("/admin", middlewares.Authenticate(rdb), middlewares.RequirePermission(db, rdb, "roles:manage"))
+
+	rbacRouter.GET("/roles", func(ctx *gin.Context) {
+		controllers.GetRoles(ctx, db)
+	})
+	rbacRouter.POST("/roles", func(ctx *gin.Context) {
+		controllers.CreateRole(ctx, db)
+	})
+	rbacRouter.PUT("/roles/:name", func(ctx *gin.Context) {
+		controllers.UpdateRole(ctx, db, rdb)
+	})
+	rbacRouter.DELETE("/roles/:name", func(ctx *gin.Context) {
+		controllers.DeleteRole(ctx, db, rdb)
+	})
+	rbacRouter.GET("/permissions", func(ctx *gin.Context) {
+		controllers.GetPermissions(ctx, db)
+	})
+	rbacRouter.PUT("/user/:id/role", func(ctx *gin.Context) {
+		controllers.AssignUserRole(ctx, db, rdb)
+	})
+}
@@ -0,0 +1,41 @@
+package routes
+
+import (
+	"github.com/cloudinary/cloudinary-go/v2"
+	"github.com/federus1105/koda-b4-backend/internals/controllers"
+	"github.com/federus1105/koda-b4-backend/internals/middlewares"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+)
+
+func InitReviewRouter(router *gin.Engine, db *pgxpool.Pool, rdb *redis.Client, cld *cloudinary.Cloudinary) {
+	router.GET("/product/:id/reviews", middlewares.OptionalAuthenticate(rdb), func(ctx *gin.Context) {
+		controllers.GetProductReviews(ctx, db)
+	})
+	router.POST("/product/:id/reviews", middlewares.Authenticate(rdb), func(ctx *gin.Context) {
+		controllers.CreateReview(ctx, db, cld)
+	})
+
+	reviewRouter := router.Group("/reviews", middlewares.Authenticate(rdb))
+
+	reviewRouter.POST("/:id/helpful", func(ctx *gin.Context) {
+		controllers.VoteReview(ctx, db)
+	})
+	reviewRouter.DELETE("/:id/helpful", func(ctx *gin.Context) {
+		controllers.VoteReview(ctx, db)
+	})
+
+	// --- BEARER TOKEN ONLY, MODERATOR IS RECORDED ON THE REVIEW ---
+	moderationRouter := router.Group("/admin/reviews", middlewares.Authenticate(rdb), middlewares.RequirePermission(db, rdb, "reviews:moderate"))
+
+	moderationRouter.GET("", func(ctx *gin.Context) {
+		controllers.GetReviewsForModeration(ctx, db)
+	})
+	moderationRouter.PATCH("/:id", func(ctx *gin.Context) {
+		controllers.ModerateReview(ctx, db, rdb)
+	})
+	moderationRouter.DELETE("/:id", func(ctx *gin.Context) {
+		controllers.DeleteReview(ctx, db, rdb)
+	})
+}
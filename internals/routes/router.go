@@ -2,6 +2,7 @@ package routes
 
 import (
 	"github.com/cloudinary/cloudinary-go/v2"
+	"github.com/federus1105/koda-b4-backend/internals/middlewares"
 	"github.com/federus1105/koda-b4-backend/internals/models"
 	"github.com/federus1105/koda-b4-backend/internals/pkg/utils"
 	"github.com/gin-gonic/gin"
@@ -16,6 +17,9 @@ import (
 func InitRouter(app *gin.Engine, db *pgxpool.Pool, rd *redis.Client, cld *cloudinary.Cloudinary) {
 	utils.InitValidator()
 
+	// --- AUDIT REQUEST OF IMPERSONATION TOKEN, MUST BE REGISTERED BEFORE ROUTES ---
+	app.Use(middlewares.ImpersonationAudit(db))
+
 	// --- SWAGGER ---
 	docs.SwaggerInfo.BasePath = "/"
 	app.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
@@ -23,14 +27,22 @@ func InitRouter(app *gin.Engine, db *pgxpool.Pool, rd *redis.Client, cld *cloudi
 	app.Static("/img", "public")
 
 	// --- ROUTE ---
+	InitWellKnownRouter(app)
 	InitAuthRouter(app, db, rd)
 	InitProductRouter(app, db, rd, cld)
-	InitOrderRouter(app, db)
-	InitUserRoute(app, db)
-	InitCategoriesRouter(app, db)
-	InitOrderClientRoutes(app, db)
-	InitHistoryRouter(app, db)
-	InitProfileRouter(app, db, cld)
+	InitOrderRouter(app, db, rd)
+	InitUserRoute(app, db, rd)
+	InitCategoriesRouter(app, db, rd)
+	InitOrderClientRoutes(app, db, rd)
+	InitHistoryRouter(app, db, rd)
+	InitProfileRouter(app, db, rd, cld)
+	InitTwoFactorRouter(app, db, rd)
+	InitPasskeyRouter(app, db, rd)
+	InitRBACRouter(app, db, rd)
+	InitAPIKeyRouter(app, db, rd)
+	InitImpersonationRouter(app, db, rd)
+	InitReviewRouter(app, db, rd, cld)
+	InitFlashSaleRouter(app, db, rd)
 
 	app.NoRoute(func(ctx *gin.Context) {
 		ctx.JSON(404, models.Response{
@@ -0,0 +1,38 @@
+package routes
+
+import (
+	"github.com/federus1105/koda-b4-backend/internals/controllers"
+	"github.com/federus1105/koda-b4-backend/internals/middlewares"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+)
+
+func InitTwoFactorRouter(router *gin.Engine, db *pgxpool.Pool, rdb *redis.Client) {
+	twoFactorRouter := router.Group("/profile/2fa", middlewares.Authenticate(rdb))
+
+	twoFactorRouter.GET("", func(ctx *gin.Context) {
+		controllers.GetTwoFactorStatus(ctx, db)
+	})
+	twoFactorRouter.POST("/setup", func(ctx *gin.Context) {
+		controllers.SetupTwoFactor(ctx, db, rdb)
+	})
+	twoFactorRouter.POST("/enable", func(ctx *gin.Context) {
+		controllers.EnableTwoFactor(ctx, db, rdb)
+	})
+	twoFactorRouter.POST("/disable", func(ctx *gin.Context) {
+		controllers.DisableTwoFactor(ctx, db, rdb)
+	})
+	twoFactorRouter.POST("/recovery-codes", func(ctx *gin.Context) {
+		controllers.RegenerateRecoveryCodes(ctx, db, rdb)
+	})
+
+	policyRouter := router.Group("/admin/security/2fa", middlewares.Authenticate(rdb), middlewares.RequirePermission(db, rdb, "security:manage"))
+
+	policyRouter.GET("", func(ctx *gin.Context) {
+		controllers.GetTwoFactorPolicy(ctx, db)
+	})
+	policyRouter.PUT("", func(ctx *gin.Context) {
+		controllers.UpdateTwoFactorPolicy(ctx, db)
+	})
+}
@@ -5,20 +5,25 @@ import (
 	"github.com/federus1105/koda-b4-backend/internals/middlewares"
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
 )
 
-func InitUserRoute(router *gin.Engine, db *pgxpool.Pool) {
+func InitUserRoute(router *gin.Engine, db *pgxpool.Pool, rdb *redis.Client) {
 	userRouter := router.Group("/admin/user")
 
-	userRouter.GET("", middlewares.VerifyToken, middlewares.Access("admin"), func(ctx *gin.Context) {
+	userRouter.GET("", middlewares.AuthenticateOrAPIKey(db, rdb), middlewares.RequirePermission(db, rdb, "users:read"), func(ctx *gin.Context) {
 		controllers.GetListUser(ctx, db)
 	})
 
-	userRouter.POST("", middlewares.VerifyToken, middlewares.Access("admin"), func(ctx *gin.Context) {
+	userRouter.POST("", middlewares.AuthenticateOrAPIKey(db, rdb), middlewares.RequirePermission(db, rdb, "users:write"), func(ctx *gin.Context) {
 		controllers.CreateUser(ctx, db)
 	})
 
-	userRouter.PATCH("/:id", middlewares.VerifyToken, middlewares.Access("admin"), func(ctx *gin.Context) {
-		controllers.EditUser(ctx, db)
+	userRouter.PATCH("/:id", middlewares.AuthenticateOrAPIKey(db, rdb), middlewares.RequirePermission(db, rdb, "users:write"), func(ctx *gin.Context) {
+		controllers.EditUser(ctx, db, rdb)
+	})
+
+	userRouter.DELETE("/:id", middlewares.AuthenticateOrAPIKey(db, rdb), middlewares.RequirePermission(db, rdb, "users:write"), func(ctx *gin.Context) {
+		controllers.AdminDeleteUser(ctx, db, rdb)
 	})
 }
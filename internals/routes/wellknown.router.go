@@ -0,0 +1,12 @@
+package routes
+
+import (
+	"github.com/federus1105/koda-b4-backend/internals/controllers"
+	"github.com/gin-gonic/gin"
+)
+
+func InitWellKnownRouter(router *gin.Engine) {
+	wellKnownRouter := router.Group("/.well-known")
+
+	wellKnownRouter.GET("/jwks.json", controllers.JWKS)
+}